@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// Adaptive difficulty. Under the adaptive mutator the reactor watches how
+// close the player is to losing and leans on them accordingly: every tick
+// with two systems below the warning threshold, or one and no repair kits,
+// the pressure eases a step; every tick with none below it and kits in hand,
+// it grows a step. The pressure multiplies event damage and divides the time
+// between events, within the bounds of the config's [adaptive] table.
+
+// AdaptiveConfig bounds the pressure of the adaptive mutator.
+type AdaptiveConfig struct {
+	MinScale float64 `toml:"min_scale"` // Gentlest pressure, below 1
+	MaxScale float64 `toml:"max_scale"` // Harshest pressure, above 1
+	Step     float64 `toml:"step"`      // Change per degradation tick
+}
+
+func defaultAdaptive() AdaptiveConfig {
+	return AdaptiveConfig{MinScale: 0.6, MaxScale: 1.5, Step: 0.02}
+}
+
+func (c AdaptiveConfig) validate() error {
+	switch {
+	case c.MinScale <= 0 || c.MinScale > 1 || c.MaxScale < 1:
+		return fmt.Errorf("adaptive scales must satisfy 0 < min_scale <= 1 <= max_scale")
+	case c.Step <= 0:
+		return fmt.Errorf("adaptive step must be positive")
+	}
+	return nil
+}
+
+// adaptPressure moves the adaptive pressure a step after a tick left the
+// systems at values.
+func (g *Game) adaptPressure(values []int) {
+	if !hasMutator(g.Mutators, MutatorAdaptive) {
+		return
+	}
+	below := 0
+	for i, val := range values {
+		if val < g.Systems[i].WarningThreshold {
+			below++
+		}
+	}
+	c := g.Config.Adaptive
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	kits := g.Inventory[ItemRepairKit]
+	switch {
+	case below >= 2 || below == 1 && kits == 0:
+		g.pressure = max(c.MinScale, g.pressure-c.Step)
+	case below == 0 && kits > 0:
+		g.pressure = min(c.MaxScale, g.pressure+c.Step)
+	}
+}
+
+// adaptivePressure is the current pressure of the adaptive mutator, or 1
+// without it.
+func (g *Game) adaptivePressure() float64 {
+	if !hasMutator(g.Mutators, MutatorAdaptive) {
+		return 1
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.pressure
+}
+
+// pressureTag shows the adaptive pressure beside the mutators, or "" without
+// the adaptive mutator.
+func (s GameState) pressureTag() string {
+	if s.Pressure == 0 {
+		return ""
+	}
+	return tr(" (pressure x%.2f)", s.Pressure)
+}
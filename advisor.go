@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Advisor. `advise` has a heuristic engine look over the dashboard and log
+// the most valuable thing to do next, with why; `advise on` keeps its
+// recommendation on the dashboard. It reads the same state the player sees,
+// and the greedy bot plays whatever it recommends.
+
+// AdvisorWindow is how many of the latest readings the advisor projects a
+// system's decline from.
+const AdvisorWindow = 10
+
+// Advice is a recommendation: the command to type, or "" to wait, why, and
+// another command that would do, if there is one.
+type Advice struct {
+	Command     string
+	Reason      string
+	Alternative string
+}
+
+// String phrases the advice the way the advisor says it.
+func (a Advice) String() string {
+	switch {
+	case a.Command == "":
+		return a.Reason
+	case a.Alternative != "":
+		return tr("%s; %s or %s", a.Reason, a.Command, a.Alternative)
+	}
+	return fmt.Sprintf("%s; %s", a.Reason, a.Command)
+}
+
+// advise picks the most urgent problem on the dashboard and what to do about
+// it: a pending code, then critical alarms, then a looming meltdown, then a
+// crisis step, then an offline system, then a saboteur, then the weakest system once it falls to
+// the warning threshold, then low morale. Repair kits are kept for critical
+// systems.
+func (s GameState) advise() Advice {
+	if s.Code != nil {
+		return Advice{Command: s.Code.Code, Reason: tr("An emergency code is waiting: type it before it expires")}
+	}
+	for _, a := range s.Alarms {
+		if a.Critical {
+			return Advice{Command: "ack all", Reason: tr("Critical alarms are waiting to be acknowledged")}
+		}
+	}
+	if s.Action != "" && s.ActionLeft > 0 {
+		return Advice{Reason: tr("Let the current action finish (%s).", strings.TrimSuffix(s.Action, "..."))}
+	}
+	if s.Meltdown > 0 {
+		for _, sys := range s.Systems {
+			if sys.Offline {
+				return Advice{Command: fmt.Sprintf("reboot %d", sys.ID), Reason: tr("MELTDOWN IMMINENT in %ds: bring %s back online", ceilSeconds(s.Meltdown), tr(sys.Name)), Alternative: "scram"}
+			}
+		}
+	}
+	if c := s.Crisis; c != nil {
+		step := c.Steps[c.Stage]
+		return Advice{Command: step.Command, Reason: tr("%s: the next step is: %s", tr(c.Title), tr(step.Task))}
+	}
+
+	var offline, weakest *SystemState
+	low := 0
+	for i := range s.Systems {
+		sys := &s.Systems[i]
+		if sys.Offline {
+			offline = sys
+			continue
+		}
+		if weakest == nil || sys.Reading-sys.Warning < low-weakest.Warning {
+			weakest, low = sys, sys.Reading
+		}
+	}
+	var strongest *SystemState // The healthiest system linked to the weakest
+	high := 0
+	for i := range s.Systems {
+		sys := &s.Systems[i]
+		if weakest != nil && sys != weakest && !sys.Offline && s.Matrix.allowsDivert(sys.ID, weakest.ID) && (strongest == nil || sys.Reading > high) {
+			strongest, high = sys, sys.Reading
+		}
+	}
+	var divert string
+	if strongest != nil && high >= GreedyDivert+strongest.Critical/2 && s.ready(CommandDivert) {
+		divert = fmt.Sprintf("divert %d %d %d", strongest.ID, weakest.ID, GreedyDivert)
+	}
+	kits := s.Inventory[ItemRepairKit]
+	if weakest != nil && low <= weakest.Critical && kits > 0 {
+		// Keep a second system from going down first
+		return Advice{Command: fmt.Sprintf("stabilize %d", weakest.ID), Reason: s.forecast(weakest), Alternative: divert}
+	}
+	if kits == 0 && (offline != nil || weakest != nil && low <= weakest.Critical) {
+		failing := offline
+		if failing == nil {
+			failing = weakest
+		}
+		// Shut down while it is still safe
+		return Advice{Command: "scram", Reason: tr("No repair kits are left and %s is failing", tr(failing.Name))}
+	}
+	if offline != nil {
+		return Advice{Command: fmt.Sprintf("reboot %d", offline.ID), Reason: tr("%s is OFFLINE: one more and the reactor melts down", tr(offline.Name))}
+	}
+	if s.Saboteur != nil {
+		if suspects := s.Saboteur.suspects(len(s.Systems)); len(suspects) > 0 {
+			// Halve the suspects with every sweep
+			target := suspects[len(suspects)/2]
+			return Advice{Command: fmt.Sprintf("sweep %d", target), Reason: tr("A saboteur is loose and %d systems fit the clues", len(suspects))}
+		}
+	}
+	if (weakest == nil || low > weakest.Warning) && s.Morale < MoraleLow {
+		return Advice{Command: "rally", Reason: tr("Crew morale is down to %d: the crew is slow and fumbling", s.Morale)}
+	}
+	if weakest == nil || low > weakest.Warning {
+		return Advice{Reason: tr("Every system is above WARNING: nothing needs doing yet.")}
+	}
+	vent := ""
+	if s.ready(CommandVent) && s.Matrix.allows(CommandVent, weakest.ID) {
+		vent = fmt.Sprintf("vent %d", weakest.ID)
+	}
+	if divert != "" {
+		return Advice{Command: divert, Reason: s.forecast(weakest), Alternative: vent}
+	}
+	if vent != "" {
+		return Advice{Command: vent, Reason: s.forecast(weakest)}
+	}
+	return Advice{Reason: tr("%s. Divert and vent are cooling down, short of power or ruled out: keep the repair kits until it is CRITICAL.", s.forecast(weakest))}
+}
+
+// ready reports whether command is off cooldown and Power Output can spare
+// its power.
+func (s GameState) ready(command string) bool {
+	return s.Cooldowns[command] == 0 && s.affords(command)
+}
+
+// forecast says where sys is heading: when it will hit the bottom at its
+// recent rate of decline, or else how low it stands.
+func (s GameState) forecast(sys *SystemState) string {
+	samples := sys.Trend[max(0, len(sys.Trend)-AdvisorWindow):]
+	if len(samples) > 1 {
+		perTick := float64(samples[len(samples)-1]-samples[0]) / float64(len(samples)-1)
+		if perTick < 0 && s.Tick > 0 {
+			ticks := float64(sys.Reading-s.MinValue) / -perTick
+			return tr("%s will hit %d in ~%.0fs", tr(sys.Name), s.MinValue, math.Max(1, ticks*s.Tick.Seconds()))
+		}
+	}
+	return tr("%s is down to %d", tr(sys.Name), sys.Reading)
+}
+
+// handleAdvise logs the advisor's recommendation, or shows or hides it on
+// the current operator's dashboard.
+func (g *Game) handleAdvise(args []string) {
+	if len(args) == 0 {
+		g.AddLog(LogInfo, tr("ADVISOR: %s", g.snapshot(g.Operator).advise().String()))
+		return
+	}
+	switch args[0] {
+	case "on", "off":
+		g.locked(func() {
+			g.advisorOpen = args[0] == "on"
+		})
+	default:
+		g.AddLog(LogInfo, tr("Usage: advise [on|off]"))
+	}
+}
+
+// advisorPanel renders the standing recommendation for the dashboard, or ""
+// when the advisor is not shown.
+func (s GameState) advisorPanel() string {
+	if !s.Advisor || s.GameOver || s.GameWon {
+		return ""
+	}
+	return theme.Notice.Sprint(tr("ADVISOR: %s", s.advise()))
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Alarm annunciator. An alarm is raised whenever a system falls to the
+// warning or critical threshold, and stays on the strip at the top of the
+// dashboard until it is acknowledged with `ack`, even once the system has
+// recovered. A critical alarm left unacknowledged for AlarmAckTime costs
+// UnackedAlarmPenalty.
+const AlarmAckTime = 30 * time.Second
+
+// Alarm levels, in order of urgency.
+const (
+	alarmNone = iota
+	alarmWarning
+	alarmCritical
+)
+
+// Alarm is one alarm on the annunciator.
+type Alarm struct {
+	ID        int           `json:"id"`
+	System    int           `json:"system_id"`
+	Name      string        `json:"name"` // e.g. "CORE TEMP CRITICAL"
+	Critical  bool          `json:"critical"`
+	Active    bool          `json:"active"`    // The system is still past the threshold
+	Raised    time.Duration `json:"raised_ns"` // Game time
+	Penalized bool          `json:"penalized,omitempty"`
+}
+
+func (a Alarm) level() int {
+	if a.Critical {
+		return alarmCritical
+	}
+	return alarmWarning
+}
+
+// level returns the alarm sys calls for, going by its reading.
+func (sys SystemState) level() int {
+	switch {
+	case sys.Reading <= sys.Critical:
+		return alarmCritical
+	case sys.Reading <= sys.Warning:
+		return alarmWarning
+	}
+	return alarmNone
+}
+
+// alarmLevel returns the alarm sys at value calls for.
+func alarmLevel(sys *System, value int) int {
+	switch {
+	case value <= sys.CriticalThreshold:
+		return alarmCritical
+	case value <= sys.WarningThreshold:
+		return alarmWarning
+	}
+	return alarmNone
+}
+
+// updateAlarms raises an alarm for every system that has fallen past a
+// threshold since the last tick, and charges for critical alarms that have
+// waited too long. values holds each system's integrity, indexed by ID.
+func (g *Game) updateAlarms(values []int) {
+	elapsed := g.Elapsed()
+	var raised, penalized []Alarm
+	g.locked(func() {
+		if len(g.alarmLevels) != len(values) {
+			g.alarmLevels = make([]int, len(values))
+		}
+		for i, val := range values {
+			level := alarmLevel(g.Systems[i], val)
+			pending := false
+			for j := range g.Alarms {
+				a := &g.Alarms[j]
+				if a.System == i {
+					a.Active = level >= a.level()
+					pending = pending || a.level() == level
+				}
+			}
+			if level > g.alarmLevels[i] && !pending {
+				g.nextAlarm++
+				a := Alarm{ID: g.nextAlarm, System: i, Critical: level == alarmCritical, Active: true, Raised: elapsed}
+				a.Name = strings.ToUpper(tr("%s low", g.Systems[i].Name))
+				if a.Critical {
+					a.Name = strings.ToUpper(tr("%s critical", g.Systems[i].Name))
+				}
+				g.Alarms = append(g.Alarms, a)
+				raised = append(raised, a)
+			}
+			g.alarmLevels[i] = level
+		}
+		for j := range g.Alarms {
+			a := &g.Alarms[j]
+			if a.Critical && !a.Penalized && elapsed-a.Raised >= AlarmAckTime {
+				a.Penalized = true
+				g.Score.UnackedAlarms++
+				penalized = append(penalized, *a)
+			}
+		}
+	})
+
+	for _, a := range raised {
+		severity := LogWarning
+		if a.Critical {
+			severity = LogCritical
+			g.cue(CueAlarm)
+		}
+		g.AddLog(severity, tr("ALARM %d: %s. Acknowledge with 'ack %d'.", a.ID, a.Name, a.ID))
+		if a.Critical {
+			g.changeMorale(-MoraleAlarmLoss, tr("The alarm wears on the crew."))
+		}
+	}
+	for _, a := range penalized {
+		g.AddLog(LogCritical, tr("ALARM %d: %s unacknowledged for %.0fs. Penalty -%d.", a.ID, a.Name, AlarmAckTime.Seconds(), UnackedAlarmPenalty))
+	}
+}
+
+// handleAck acknowledges the alarm named by args, or every alarm.
+func (g *Game) handleAck(args []string) {
+	if len(args) == 0 {
+		g.AddLog(LogInfo, tr("Usage: ack <alarm_id> | ack all"))
+		return
+	}
+	severity, note := LogWarning, tr("Error: No alarm %s on the annunciator.", args[0])
+	g.locked(func() {
+		if len(g.Alarms) == 0 {
+			note = tr("No alarms to acknowledge.")
+			return
+		}
+		if args[0] == "all" {
+			severity, note = LogInfo, tr("Acknowledged %d alarm(s).", len(g.Alarms))
+			g.Alarms = nil
+			return
+		}
+		id, err := strconv.Atoi(args[0])
+		for i, a := range g.Alarms {
+			if err == nil && a.ID == id {
+				g.Alarms = append(g.Alarms[:i:i], g.Alarms[i+1:]...)
+				severity, note = LogInfo, tr("Alarm %d acknowledged: %s.", a.ID, a.Name)
+				return
+			}
+		}
+	})
+	g.AddLog(severity, note)
+}
+
+// pendingAlarms reports whether a critical alarm is waiting to be
+// acknowledged.
+func (g *Game) pendingAlarms() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, a := range g.Alarms {
+		if a.Critical {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeAlarmsLocked restores saved alarms, as if the tick that raised them
+// had just run on values. The caller must hold g.mu.
+func (g *Game) resumeAlarmsLocked(alarms []Alarm, values []int) {
+	g.Alarms = append([]Alarm(nil), alarms...)
+	g.nextAlarm = 0
+	for _, a := range alarms {
+		g.nextAlarm = max(g.nextAlarm, a.ID)
+	}
+	g.alarmLevels = make([]int, len(values))
+	for i, val := range values {
+		g.alarmLevels[i] = alarmLevel(g.Systems[i], val)
+	}
+}
+
+// annunciator renders the alarm strip: alarms whose condition still holds
+// blink, those that have cleared burn steady until acknowledged. It returns
+// "" when there are no alarms.
+func (s GameState) annunciator() string {
+	if len(s.Alarms) == 0 {
+		return ""
+	}
+	tiles := make([]string, 0, len(s.Alarms))
+	for _, a := range s.Alarms {
+		style, tag := theme.Caution, TagWarning
+		if a.Critical {
+			style, tag = theme.Inverse, TagCritical
+		}
+		if a.Active {
+			style = style.With(color.BlinkSlow)
+		}
+		tile := fmt.Sprintf(" %s%d %s %s ", theme.tag(tag), a.ID, a.Name, formatDuration(s.Elapsed-a.Raised))
+		tiles = append(tiles, style.Sprint(tile))
+	}
+	return tr("ALARMS: %s", strings.Join(tiles, " "))
+}
+
+// Meltdown banner. While any system is critical, a banner across the screen
+// above the status table names them, flashing between inverse video and
+// plain on every BannerFlash of game time, so each UI tick picks up the
+// phase.
+const BannerFlash = 500 * time.Millisecond
+
+// meltdownBanner returns the banner centered in width, or "" while no system
+// is critical.
+func (s GameState) meltdownBanner(width int) string {
+	var risks []string
+	for _, sys := range s.Systems {
+		switch {
+		case sys.Offline:
+			risks = append(risks, strings.ToUpper(tr(sys.Name))+" "+tr("OFFLINE"))
+		case sys.Reading <= sys.Critical:
+			risks = append(risks, fmt.Sprintf("%s %d%%", strings.ToUpper(tr(sys.Name)), sys.Reading*100/sys.MaxValue))
+		}
+	}
+	if len(risks) == 0 {
+		return ""
+	}
+	text := tr("!!! MELTDOWN RISK: %s !!!", strings.Join(risks, ", "))
+	if pad := width - 1 - visibleLen(text); pad > 0 {
+		text = strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+	}
+	style := theme.Alert.With(color.Bold)
+	if s.Elapsed/BannerFlash%2 == 0 {
+		style = style.With(color.ReverseVideo)
+	}
+	return style.Sprint(text)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Balance analysis. --analyze adds to a --simulate batch what a scenario
+// designer tunes by: how much more often a run is lost when each event hits
+// it, what each command is worth in each state of the plant, when in the run
+// the losses come, and the strategies that dominate. A command's value is
+// the change in the plant's total integrity over the AnalyzeHorizon after
+// it, next to the same change after waiting.
+
+const (
+	AnalyzeHorizon    = 10 * time.Second
+	AnalyzeMinSamples = 20 // Uses of a command in a state before its value counts
+	AnalyzeLossBins   = 10
+	AnalyzeBarWidth   = 30
+	AnalyzeSpamShare  = 50 // Percent of the commands of won games one command can take before it is flagged
+
+	analyzeWait = "(wait)" // The policy's choice to do nothing
+)
+
+// analyzeStates are the states of the plant a command is valued in, by its
+// worst system.
+var analyzeStates = []string{"healthy", "warning", "critical", "offline"}
+
+// Analysis aggregates the balance figures of a batch.
+type Analysis struct {
+	Games, Losses int
+	EventHits     map[string]int                      // Games each event hit
+	EventLosses   map[string]int                      // Of those, the games lost
+	Values        map[string]map[string]*commandValue // Per state, per command
+	LossTimes     []time.Duration
+	WonCommands   map[string]int // Commands run in won games
+}
+
+// commandValue sums the integrity changes that followed a command.
+type commandValue struct {
+	Uses int
+	Gain int
+}
+
+func (v *commandValue) mean() float64 {
+	return float64(v.Gain) / float64(v.Uses)
+}
+
+func newAnalysis() *Analysis {
+	a := &Analysis{
+		EventHits:   make(map[string]int),
+		EventLosses: make(map[string]int),
+		Values:      make(map[string]map[string]*commandValue),
+		WonCommands: make(map[string]int),
+	}
+	for _, state := range analyzeStates {
+		a.Values[state] = make(map[string]*commandValue)
+	}
+	return a
+}
+
+// commandSample is a command waiting out the AnalyzeHorizon to be valued.
+type commandSample struct {
+	command, state string
+	before         int
+	due            time.Duration
+}
+
+// analysisRecorder takes the samples of one game for an Analysis. Its
+// methods do nothing on a nil recorder, for a batch not analyzed.
+type analysisRecorder struct {
+	a       *Analysis
+	pending []commandSample
+}
+
+func (a *Analysis) recorder() *analysisRecorder {
+	if a == nil {
+		return nil
+	}
+	return &analysisRecorder{a: a}
+}
+
+// act notes the line the policy chose, "" to wait.
+func (r *analysisRecorder) act(g *Game, input string) {
+	if r == nil {
+		return
+	}
+	command := analyzeWait
+	if fields := strings.Fields(strings.ToLower(input)); len(fields) > 0 {
+		command = g.resolveVerb(fields[0])
+	}
+	total, state := g.plantHealth()
+	r.pending = append(r.pending, commandSample{command: command, state: state, before: total, due: g.Elapsed() + AnalyzeHorizon})
+}
+
+// step values the samples whose horizon has passed.
+func (r *analysisRecorder) step(g *Game) {
+	if r == nil || len(r.pending) == 0 || g.Elapsed() < r.pending[0].due {
+		return
+	}
+	total, _ := g.plantHealth()
+	for len(r.pending) > 0 && g.Elapsed() >= r.pending[0].due {
+		r.settle(r.pending[0], total)
+		r.pending = r.pending[1:]
+	}
+}
+
+func (r *analysisRecorder) settle(s commandSample, total int) {
+	v := r.a.Values[s.state][s.command]
+	if v == nil {
+		v = &commandValue{}
+		r.a.Values[s.state][s.command] = v
+	}
+	v.Uses++
+	v.Gain += total - s.before
+}
+
+// finish values the samples still pending by the plant as the game ended,
+// and adds the game's outcome, events and commands.
+func (r *analysisRecorder) finish(g *Game, res SimResult) {
+	if r == nil {
+		return
+	}
+	total, _ := g.plantHealth()
+	for _, s := range r.pending {
+		r.settle(s, total)
+	}
+	lost := !res.Won && !res.Scrammed
+	a := r.a
+	a.Games++
+	if lost {
+		a.Losses++
+		a.LossTimes = append(a.LossTimes, res.Survived)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for name := range g.eventHits {
+		a.EventHits[name]++
+		if lost {
+			a.EventLosses[name]++
+		}
+	}
+	if res.Won {
+		for command, n := range g.Stats.Commands {
+			a.WonCommands[command] += n
+		}
+	}
+}
+
+// plantHealth returns the total true integrity of the systems and the
+// state of the plant by its worst system.
+func (g *Game) plantHealth() (int, string) {
+	total, level, offline := 0, alarmNone, false
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		total += sys.Value
+		level = max(level, alarmLevel(sys, sys.Value))
+		offline = offline || sys.Offline
+		sys.mu.Unlock()
+	}
+	switch {
+	case offline:
+		return total, "offline"
+	case level == alarmCritical:
+		return total, "critical"
+	case level == alarmWarning:
+		return total, "warning"
+	}
+	return total, "healthy"
+}
+
+// PrintAnalysis renders the balance figures of the batch.
+func (sum SimSummary) PrintAnalysis() {
+	a := sum.Analysis
+	if a == nil || a.Games == 0 {
+		return
+	}
+	a.printEvents()
+	a.printValues()
+	a.printLossTimes(sum.Mode, sum.Config.GameDuration)
+	a.printFlags(sum.Won)
+}
+
+// printEvents ranks the events by how much more often the games they hit
+// were lost than the games they did not.
+func (a *Analysis) printEvents() {
+	fmt.Println(theme.Heading.Sprint(tr("\nEVENT CONTRIBUTION TO LOSSES:")))
+	if len(a.EventHits) == 0 {
+		fmt.Println(tr("  No events hit."))
+		return
+	}
+	type row struct {
+		name          string
+		hit, with, no float64 // Share of games hit; loss rates with and without the event
+	}
+	var rows []row
+	for name, hit := range a.EventHits {
+		r := row{name: name, hit: percent(hit, a.Games), with: percent(a.EventLosses[name], hit), no: -1}
+		if rest := a.Games - hit; rest > 0 {
+			r.no = percent(a.Losses-a.EventLosses[name], rest)
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		di, dj := rows[i].with-rows[i].no, rows[j].with-rows[j].no
+		if di != dj {
+			return di > dj
+		}
+		return rows[i].name < rows[j].name
+	})
+	fmt.Printf("  %-22s %8s %10s %10s %8s\n", tr("Event"), tr("Hit"), tr("Lost with"), tr("Without"), tr("Effect"))
+	for _, r := range rows {
+		if r.no < 0 { // Hit every game: nothing to compare with
+			fmt.Printf("  %-22s %7.0f%% %9.0f%% %10s %8s\n", r.name, r.hit, r.with, "-", "-")
+			continue
+		}
+		effect := math.Round(r.with - r.no) // No -0%
+		fmt.Printf("  %-22s %7.0f%% %9.0f%% %9.0f%% %+7.0f%%\n", r.name, r.hit, r.with, r.no, effect)
+	}
+}
+
+// printValues lays out the mean integrity change after each command, in
+// each state of the plant, less the change after waiting.
+func (a *Analysis) printValues() {
+	fmt.Println(theme.Heading.Sprint(tr("\nCOMMAND VALUE (integrity change over %s, against waiting):", formatDuration(AnalyzeHorizon))))
+	for _, state := range analyzeStates {
+		values := a.Values[state]
+		if len(values) == 0 {
+			continue
+		}
+		wait := values[analyzeWait]
+		fmt.Printf("  %s:\n", strings.ToUpper(tr(state)))
+		for _, command := range sortedCommands(values) {
+			v := values[command]
+			line := fmt.Sprintf("    %-14s %6d %s %+8.1f", command, v.Uses, tr("uses"), v.mean())
+			if wait != nil && command != analyzeWait {
+				line += fmt.Sprintf(" (%+.1f)", v.mean()-wait.mean())
+			}
+			if v.Uses < AnalyzeMinSamples {
+				line += " " + tr("few samples")
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// sortedCommands orders the commands valued in a state best first.
+func sortedCommands(values map[string]*commandValue) []string {
+	commands := make([]string, 0, len(values))
+	for command := range values {
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		mi, mj := values[commands[i]].mean(), values[commands[j]].mean()
+		if mi != mj {
+			return mi > mj
+		}
+		return commands[i] < commands[j]
+	})
+	return commands
+}
+
+// printLossTimes draws a histogram of when in the run the losses came.
+func (a *Analysis) printLossTimes(mode string, duration time.Duration) {
+	fmt.Println(theme.Heading.Sprint(tr("\nLOSS TIMES:")))
+	if len(a.LossTimes) == 0 {
+		fmt.Println(tr("  No games were lost."))
+		return
+	}
+	span := duration
+	if mode == ModeEndless {
+		span = 0
+		for _, t := range a.LossTimes {
+			span = max(span, t)
+		}
+	}
+	bin := max(span/AnalyzeLossBins, time.Second)
+	counts := make([]int, AnalyzeLossBins)
+	most := 0
+	for _, t := range a.LossTimes {
+		i := min(int(t/bin), AnalyzeLossBins-1)
+		counts[i]++
+		most = max(most, counts[i])
+	}
+	for i, n := range counts {
+		bar := strings.Repeat("#", (n*AnalyzeBarWidth+most-1)/most)
+		fmt.Printf("  %s-%s %-*s %d\n", formatDuration(bin*time.Duration(i)), formatDuration(bin*time.Duration(i+1)), AnalyzeBarWidth, bar, n)
+	}
+}
+
+// printFlags flags the strategies that dominate: a command worth the most
+// in every state the plant was in, and one command making up most of what
+// won games ran.
+func (a *Analysis) printFlags(won int) {
+	var flags []string
+	best, states := "", 0
+	for _, state := range analyzeStates {
+		var top string
+		for _, command := range sortedCommands(a.Values[state]) {
+			if a.Values[state][command].Uses >= AnalyzeMinSamples {
+				top = command
+				break
+			}
+		}
+		if top == "" {
+			continue
+		}
+		if states == 0 {
+			best = top
+		} else if top != best {
+			best = ""
+		}
+		states++
+	}
+	if best != "" && states > 1 {
+		if best == analyzeWait {
+			flags = append(flags, tr("Waiting beats every command in every state of the plant."))
+		} else {
+			flags = append(flags, tr("%s is the best command in every state of the plant.", best))
+		}
+	}
+	total := 0
+	for _, n := range a.WonCommands {
+		total += n
+	}
+	for _, command := range sortedKeys(a.WonCommands) {
+		if share := percent(a.WonCommands[command], total); share > AnalyzeSpamShare {
+			flags = append(flags, tr("%s makes up %.0f%% of the commands of the %d won games.", command, share, won))
+		}
+	}
+
+	fmt.Println(theme.Heading.Sprint(tr("\nDOMINANT STRATEGIES:")))
+	if len(flags) == 0 {
+		fmt.Println(tr("  None flagged."))
+	}
+	for _, flag := range flags {
+		fmt.Println(theme.Warning.Sprint("  " + flag))
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
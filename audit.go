@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit trail. Every command that runs is recorded with the game time it ran
+// at, who ran it, the systems it named, its parameters and what came of it:
+// the log entries it wrote as it ran. `history` shows the latest commands
+// mid-game, the summary screen closes with the last ones before the end, and
+// the report carries the whole trail. `history save <file>` writes the
+// commands typed as a script, to replay them with --script on the same seed.
+
+const (
+	HistoryTopic   = "history"
+	HistoryLines   = 20 // Commands the history page shows, the latest
+	AuditLastLines = 5  // Commands the summary screen shows, the last before the end
+)
+
+// AuditEntry is one command of the audit trail.
+type AuditEntry struct {
+	At       time.Duration `json:"at_ns"` // Game time
+	Operator string        `json:"operator,omitempty"`
+	Rule     int           `json:"rule,omitempty"` // The automation rule that ran it, if one did
+	Command  string        `json:"command"`
+	Args     []string      `json:"args,omitempty"`    // Systems by ID
+	Target   string        `json:"target,omitempty"`  // The systems it named
+	Outcome  string        `json:"outcome,omitempty"` // The log entries it wrote
+}
+
+// Line is the command line of the entry, as a script types it.
+func (e AuditEntry) Line() string {
+	return strings.Join(append([]string{e.Command}, e.Args...), " ")
+}
+
+// auditCommand records the command line parts about to run, and returns the
+// func that completes its entry once it has. parts is read again then, with
+// its verb and system names resolved.
+func (g *Game) auditCommand(parts []string) (done func()) {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	operator, rule, start := g.Operator.Name, g.rule, len(g.History)
+	g.mu.Unlock()
+	return func() {
+		e := AuditEntry{At: elapsed, Operator: operator, Rule: rule, Command: parts[0], Args: append([]string(nil), parts[1:]...)}
+		var targets []string
+		for _, i := range systemArgs[parts[0]] {
+			if i < len(parts) {
+				if sys, ok := g.systemByArg(parts[i]); ok {
+					targets = append(targets, sys.Name)
+				}
+			}
+		}
+		e.Target = strings.Join(targets, ", ")
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		var outcome []string
+		for _, entry := range g.History[min(start, len(g.History)):] {
+			outcome = append(outcome, logMessage(entry))
+		}
+		e.Outcome = strings.Join(outcome, " / ")
+		g.Stats.Audit = append(g.Stats.Audit, e)
+	}
+}
+
+// systemByArg returns the system a resolved system argument names.
+func (g *Game) systemByArg(arg string) (*System, bool) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id < 0 || id >= len(g.Systems) {
+		return nil, false
+	}
+	return g.Systems[id], true
+}
+
+// logMessage is the text of a log entry without the time it was logged.
+func logMessage(entry LogEntry) string {
+	_, text, _ := strings.Cut(entry.Text, " ")
+	return text
+}
+
+// auditLines renders entries for the history page and the summary screen.
+func auditLines(entries []AuditEntry) []string {
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		by := ""
+		switch {
+		case e.Rule > 0:
+			by = tr("rule %d: ", e.Rule)
+		case e.Operator != "":
+			by = e.Operator + ": "
+		}
+		line := fmt.Sprintf("  %s  %s%s", formatDuration(e.At), by, e.Line())
+		if e.Target != "" {
+			line += fmt.Sprintf(" (%s)", e.Target)
+		}
+		if e.Outcome != "" {
+			line += " -> " + e.Outcome
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// historyPageLocked is the page of the history topic: the latest commands.
+// The caller must hold g.mu.
+func (g *Game) historyPageLocked() []string {
+	audit := g.Stats.Audit
+	if len(audit) == 0 {
+		return []string{tr("No commands run yet.")}
+	}
+	shown := audit[max(0, len(audit)-HistoryLines):]
+	lines := auditLines(shown)
+	if earlier := len(audit) - len(shown); earlier > 0 {
+		lines = append([]string{tr("  ... %d earlier, in the --report file.", earlier)}, lines...)
+	}
+	return append(lines, "", tr("history save <file> writes the commands as a script for --script."))
+}
+
+// handleHistory opens the history page, or with save <file> writes the
+// trail as a script.
+func (g *Game) handleHistory(rawParts []string) {
+	switch {
+	case len(rawParts) < 2:
+		g.openHelp(HistoryTopic)
+	case strings.ToLower(rawParts[1]) != "save" || len(rawParts) < 3:
+		g.AddLog(LogInfo, tr("Usage: history [save <file>]"))
+	case !g.localPlayer():
+		g.AddLog(LogWarning, tr("History not saved: Only the host, at the machine the game runs on, can save it."))
+	default:
+		g.mu.Lock()
+		audit := append([]AuditEntry(nil), g.Stats.Audit...)
+		seed := g.Seed
+		g.mu.Unlock()
+		if err := writeAuditScript(rawParts[2], audit, seed); err != nil {
+			g.AddLog(LogWarning, tr("History not saved: %v", err))
+			return
+		}
+		g.AddLog(LogSuccess, tr("History saved to %s. Replay it with --seed %d --script %s.", rawParts[2], seed, rawParts[2]))
+	}
+}
+
+// writeAuditScript writes the commands of audit to path as a script, leaving
+// out those the automation rules ran, as the rules run them again.
+func writeAuditScript(path string, audit []AuditEntry, seed int64) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Commands of the run with seed %d\n", seed)
+	for _, e := range audit {
+		if e.Rule > 0 || e.Command == HistoryTopic {
+			continue
+		}
+		at := e.At.Truncate(time.Millisecond)
+		m := at / time.Minute
+		fmt.Fprintf(&b, "@%02d:%06.3f %s\n", m, (at - m*time.Minute).Seconds(), e.Line())
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MaxRules      = 3               // Active automation rules at once
+	RulePowerCost = 2               // Power Output integrity drained per rule execution
+	RuleRetry     = 5 * time.Second // Minimum gap between executions of one rule
+)
+
+// ruleActions are the commands a rule may run. The value is the index of the
+// first argument that names a system; earlier arguments are passed through.
+var ruleActions = map[string]int{
+	"stabilize":  0,
+	"divert":     0,
+	"vent":       0,
+	"reboot":     0,
+	"overcharge": 0,
+	"use":        1,
+}
+
+// Rule is a player-programmed "when <condition> do <action>" automation.
+type Rule struct {
+	Condition string `json:"condition"` // As typed, e.g. "coolant<30"
+	Action    string `json:"action"`    // As typed, e.g. "divert power coolant 15"
+
+	cond    condition
+	command string // Action with system names resolved to IDs
+	lastRun time.Time
+}
+
+// parseRule validates a rule against the current layout.
+func (g *Game) parseRule(condText, actionText string) (*Rule, error) {
+	cond, err := g.parseCondition(condText)
+	if err != nil {
+		return nil, err
+	}
+	args := strings.Fields(strings.ToLower(actionText))
+	if len(args) == 0 {
+		return nil, fmt.Errorf("rule needs an action")
+	}
+	args[0] = g.resolveVerb(args[0])
+	firstSystem, ok := ruleActions[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("%q cannot be automated", args[0])
+	}
+	for i := 1 + firstSystem; i < len(args); i++ {
+		if _, err := strconv.Atoi(args[i]); err == nil {
+			continue // Amounts and IDs pass through
+		}
+		sys := g.findSystem(args[i])
+		if sys == nil {
+			return nil, fmt.Errorf("unknown system %q", args[i])
+		}
+		args[i] = strconv.Itoa(sys.ID)
+	}
+	return &Rule{Condition: condText, Action: actionText, cond: cond, command: strings.Join(args, " ")}, nil
+}
+
+// handleRule implements `rule add|list|del`.
+func (g *Game) handleRule(args []string) {
+	if len(args) == 0 {
+		g.AddLog(LogInfo, tr("Usage: rule add <condition> <action> | rule list | rule del <n>"))
+		return
+	}
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			g.AddLog(LogInfo, tr("Usage: rule add <condition> <action>, e.g. rule add coolant<30 divert power coolant 15"))
+			return
+		}
+		rule, err := g.parseRule(args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			g.AddLog(LogWarning, tr("Error: %v", err))
+			return
+		}
+		g.mu.Lock()
+		full := len(g.rules) >= MaxRules
+		if !full {
+			g.rules = append(g.rules, rule)
+		}
+		n := len(g.rules)
+		g.mu.Unlock()
+		if full {
+			g.AddLog(LogWarning, tr("Cannot add rule: Limit of %d rules reached. Delete one first.", MaxRules))
+			return
+		}
+		g.AddLog(LogSuccess, tr("Rule %d added: when %s, %s.", n, rule.Condition, rule.Action))
+	case "list":
+		g.mu.Lock()
+		rules := append([]*Rule(nil), g.rules...)
+		g.mu.Unlock()
+		if len(rules) == 0 {
+			g.AddLog(LogInfo, tr("No automation rules set."))
+		}
+		for i, rule := range rules {
+			g.AddLog(LogInfo, tr("Rule %d: when %s, %s.", i+1, rule.Condition, rule.Action))
+		}
+	case "del":
+		if len(args) < 2 {
+			g.AddLog(LogInfo, tr("Usage: rule del <n>"))
+			return
+		}
+		n, err := strconv.Atoi(args[1])
+		g.mu.Lock()
+		valid := err == nil && n >= 1 && n <= len(g.rules)
+		if valid {
+			g.rules = append(g.rules[:n-1], g.rules[n:]...)
+		}
+		g.mu.Unlock()
+		if !valid {
+			g.AddLog(LogWarning, tr("Error: No rule %s.", args[1]))
+			return
+		}
+		g.AddLog(LogInfo, tr("Rule %d deleted.", n))
+	default:
+		g.AddLog(LogWarning, tr("Unknown rule command: %s", args[0]))
+	}
+}
+
+// runRules executes every rule whose condition holds after a degradation
+// tick. values holds each system's integrity, indexed by ID. Rules wait while
+// the player is busy or their command is cooling down, and each execution
+// draws RulePowerCost from Power Output.
+func (g *Game) runRules(values []int) {
+	g.mu.Lock()
+	rules := append([]*Rule(nil), g.rules...)
+	g.mu.Unlock()
+	if len(rules) == 0 {
+		return
+	}
+	power := g.systemNamed("Power Output", nil)
+	if power != nil && power.IsOffline() {
+		return // Automation runs on plant power
+	}
+
+	g.actAs(g.crew()[0]) // Rules work the host's console
+	now := g.clock.Now()
+	for i, rule := range rules {
+		if !rule.cond.holds(values[rule.cond.System]) || now.Sub(rule.lastRun) < RuleRetry {
+			continue
+		}
+		command := strings.Fields(rule.command)[0]
+		if g.IsPlayerBusy() || g.cooldownLeft(command) > 0 {
+			continue
+		}
+		rule.lastRun = now
+		if power != nil {
+			power.Harm(RulePowerCost)
+		}
+		g.AddLog(LogEvent, tr("RULE %d: %s triggered, running '%s'.", i+1, rule.Condition, rule.Action))
+		g.rule = i + 1
+		g.execute(rule.command)
+		g.rule = 0
+	}
+}
+
+// rulesPanel renders the active rules for the dashboard, or "" if none.
+func (s GameState) rulesPanel() string {
+	if len(s.Rules) == 0 {
+		return ""
+	}
+	lines := make([]string, len(s.Rules))
+	for i, rule := range s.Rules {
+		lines[i] = tr("  %d. when %s: %s", i+1, rule.Condition, rule.Action)
+	}
+	return strings.Join(lines, "\n")
+}
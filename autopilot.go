@@ -0,0 +1,131 @@
+package main
+
+// Autopilot. The autopilot command hands the reactor to the bot policy of
+// --policy, which acts every BotReaction through the game loop as the host
+// operator, as it would in a simulated game, while the player watches or
+// types alongside it. A run the autopilot has flown is off the record, like
+// a sandbox run. A remote bot is asked away from the game loop, which goes
+// on while it thinks, and its answer is typed once it comes.
+
+// autopilot is the bot flying the reactor.
+type autopilot struct {
+	policy  Policy
+	rng     *Rand
+	waiting bool // A remote bot's answer is on its way; only the game loop touches it
+}
+
+// handleAutopilot turns the autopilot on or off, or toggles it.
+func (g *Game) handleAutopilot(args []string) {
+	g.mu.Lock()
+	on := g.autopilot == nil
+	g.mu.Unlock()
+	if len(args) > 0 {
+		switch args[0] {
+		case "on", "off":
+			on = args[0] == "on"
+		default:
+			g.AddLog(LogInfo, tr("Usage: autopilot [on|off]"))
+			return
+		}
+	}
+	switch {
+	case !on:
+		g.mu.Lock()
+		flying := g.autopilot != nil
+		g.autopilot = nil
+		g.mu.Unlock()
+		if flying {
+			g.AddLog(LogInfo, tr("AUTOPILOT: Off. The reactor is yours."))
+		}
+	case g.rival != nil:
+		g.AddLog(LogWarning, tr("The autopilot is off limits in a versus match."))
+	case g.tutorial != nil:
+		g.AddLog(LogWarning, tr("The tutorial is for you to play: the autopilot is off."))
+	case g.ironman != nil:
+		g.AddLog(LogWarning, tr("An ironman run is flown by hand: the autopilot is off."))
+	default:
+		g.locked(func() {
+			if g.autopilot == nil {
+				g.autopilot = &autopilot{policy: g.policy, rng: NewRand(g.Seed ^ botSeed)}
+			}
+			g.assisted = true
+		})
+		g.AddLog(LogWarning, tr("AUTOPILOT: On, flown by the %s policy. This run will not be recorded.", g.policy.Name()))
+	}
+}
+
+// fly lets the autopilot act, if it is on. It must run on the game loop.
+func (g *Game) fly() {
+	pilot := g.pilot()
+	if pilot == nil || pilot.waiting || g.ended() || g.isPaused() {
+		return
+	}
+	g.actAs(g.crew()[0])
+	remote, ok := pilot.policy.(*remotePolicy)
+	if !ok {
+		g.pilotInput(pilot.policy.Decide(policyView(g), pilot.rng))
+		return
+	}
+	pilot.waiting = true
+	state := marshalState(policyView(g))
+	go func() {
+		c, failed := remote.actOn(state)
+		g.post(func() {
+			pilot.waiting = false
+			if failed != nil {
+				g.AddLog(LogWarning, tr("The %s policy failed, and waits from now on: %v", remote.Name(), failed))
+			}
+			if g.pilot() == pilot && !g.ended() && !g.isPaused() { // Still flying, as when it asked
+				g.actAs(g.crew()[0])
+				g.pilotInput(c)
+			}
+		})
+	}()
+}
+
+// pilot returns the autopilot flying the reactor, or nil.
+func (g *Game) pilot() *autopilot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.autopilot
+}
+
+// pilotInput types the autopilot's command as the host operator. It runs as
+// a bot's command, which writes no files. It must run on the game loop.
+func (g *Game) pilotInput(c Command) {
+	input := c.String()
+	if input == "" {
+		return
+	}
+	g.AddLog(LogInfo, tr("AUTOPILOT: %s", input))
+	if !g.interceptCode(input) {
+		g.botCommand = true
+		g.execute(input)
+		g.botCommand = false
+	}
+}
+
+// isAssisted reports whether the autopilot has flown the run.
+func (g *Game) isAssisted() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.assisted
+}
+
+// assisted reports whether the autopilot has flown any unit of the plant.
+func (p *Plant) assisted() bool {
+	for _, unit := range p.Units {
+		if unit.isAssisted() {
+			return true
+		}
+	}
+	return false
+}
+
+// autopilotBanner renders the autopilot header line, or "" while it is off.
+func (s GameState) autopilotBanner() string {
+	if s.Autopilot == "" {
+		return ""
+	}
+	return theme.Accent.Sprint(tr("AUTOPILOT: Flown by the %s policy. Type autopilot off to take over.", s.Autopilot))
+}
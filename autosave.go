@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Autosave. The player's run is written to an autosave slot of the
+// profile every AutosaveInterval and just before an override, and the file
+// is removed when the session shuts down. One still there at launch was left
+// by a session that never did, and the game offers to resume its run.
+
+const AutosaveInterval = 10 * time.Second
+
+// saveable reports whether the run can go to a save file: save refuses
+// multi-reactor runs, versus matches, the sandbox, practice and the tutorial.
+func (g *Game) saveable() bool {
+	return g.plant == nil && g.rival == nil && !g.Sandbox && g.practice == nil && g.tutorial == nil
+}
+
+// writeAutosave writes the run to its autosave, or removes the file once
+// the run is over. A failed write turns autosaving off for the run.
+func (g *Game) writeAutosave() {
+	if g.autosavePath == "" || !g.saveable() {
+		return
+	}
+	if g.ended() {
+		g.clearAutosave()
+		return
+	}
+	tmp := g.autosavePath + ".tmp" // Renamed into place, so a kill mid-write leaves the last one whole
+	err := g.SaveToFile(tmp)
+	if err == nil {
+		err = os.Rename(tmp, g.autosavePath)
+	}
+	if err != nil {
+		g.autosavePath = ""
+		g.AddLog(LogWarning, tr("Autosave failed, and is off for this run: %v", err))
+	}
+}
+
+// autosaveInterval is how often the run is autosaved.
+func (g *Game) autosaveInterval() time.Duration {
+	if g.ironman != nil {
+		return IronmanInterval
+	}
+	return AutosaveInterval
+}
+
+// clearAutosave removes the run's autosave, as the session shuts down
+// cleanly. An ironman run still under way is saved instead, to go on with.
+func (g *Game) clearAutosave() {
+	if g.ironman != nil && !g.ended() {
+		g.writeAutosave()
+		return
+	}
+	if g.autosavePath != "" {
+		_ = os.Remove(g.autosavePath) // Nothing to remove if no autosave was due yet
+	}
+}
+
+// leftAutosave returns the newest autosave left in dir, or "" if there is
+// none.
+func leftAutosave(dir string) string {
+	slots, err := listSlots(dir)
+	if err != nil {
+		return ""
+	}
+	if left := autosaves(slots); len(left) > 0 {
+		return slotPath(dir, left[0].Name)
+	}
+	return ""
+}
+
+// offerAutosave asks at a terminal whether to resume the run of the
+// autosave at path, left behind, and reports whether the player wants to.
+func offerAutosave(path string) bool {
+	if path == "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	st, err := ReadSaveFile(path)
+	if err != nil {
+		return false // None, or unreadable
+	}
+	fmt.Print(theme.Warning.Sprint(tr("The last session did not shut down cleanly. Resume its run from the autosave, at %s? [y/N] ", formatDuration(st.Elapsed))))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes" || answer == tr("y")
+}
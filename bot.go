@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BotReaction is how often a simulated operator gets to act.
+const BotReaction = time.Second
+
+// Policy plays a simulated game. Decide is given the game's state about once
+// every BotReaction, the host's view of it as policyView takes it, and
+// returns the command the operator types, or the zero Command to wait. It
+// sees what a player sees: sensor readings, not true values.
+type Policy interface {
+	Name() string
+	Decide(s GameState, rng *Rand) Command
+}
+
+// Command is a line a policy has the operator type: a verb and its
+// arguments, as typed.
+type Command struct {
+	Verb string
+	Args []string
+}
+
+// ParseCommand splits a typed line into a Command.
+func ParseCommand(line string) Command {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Command{}
+	}
+	return Command{Verb: fields[0], Args: fields[1:]}
+}
+
+// command is the Command of verb on the numbers args.
+func command(verb string, args ...int) Command {
+	c := Command{Verb: verb}
+	for _, arg := range args {
+		c.Args = append(c.Args, strconv.Itoa(arg))
+	}
+	return c
+}
+
+// String is the line the command types, "" for waiting.
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Verb}, c.Args...), " ")
+}
+
+// policyView is the state a policy decides on: the host's, with the log in
+// plain text. It must run on the game loop.
+func policyView(g *Game) GameState {
+	s := g.snapshot(g.Operator)
+	s.Log = plainLog(s.Log)
+	return s
+}
+
+var policies = map[string]Policy{
+	"random": randomPolicy{},
+	"greedy": greedyPolicy{},
+}
+
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PolicyByName looks up a bot policy, case-insensitively, or loads an
+// external one; see externalPolicy.
+func PolicyByName(name string) (Policy, error) {
+	if p, ok, err := externalPolicy(name); ok {
+		return p, err
+	}
+	p, ok := policies[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q (choose %s, %s<file.so> or %s<addr>)", name, strings.Join(policyNames(), "|"), pluginPrefix, remotePrefix)
+	}
+	return p, nil
+}
+
+// randomPolicy mashes keys: half the time it waits, otherwise it tries a
+// random action on random systems. It never scrams and ignores codes.
+type randomPolicy struct{}
+
+func (randomPolicy) Name() string { return "random" }
+
+func (randomPolicy) Decide(s GameState, rng *Rand) Command {
+	if s.ActionLeft > 0 || rng.Intn(2) == 0 {
+		return Command{}
+	}
+	n := len(s.Systems)
+	id := rng.Intn(n)
+	switch rng.Intn(8) {
+	case 0:
+		return command("stabilize", id)
+	case 1:
+		return command("divert", id, rng.Intn(n), 10+rng.Intn(21))
+	case 2:
+		return command("vent", id)
+	case 3:
+		return command("override", id)
+	case 4:
+		return command("reboot", id)
+	case 5:
+		return command("overcharge", id)
+	case 6:
+		return command("inspect", id)
+	default:
+		return command("fabricate")
+	}
+}
+
+// GreedyDivert is how much the advisor, and so the greedy policy, diverts at
+// a time.
+const GreedyDivert = 20
+
+// greedyPolicy does whatever the advisor recommends; see advise.
+type greedyPolicy struct{}
+
+func (greedyPolicy) Name() string { return "greedy" }
+
+func (greedyPolicy) Decide(s GameState, rng *Rand) Command {
+	return ParseCommand(s.advise().Command)
+}
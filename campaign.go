@@ -0,0 +1,141 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+//go:embed campaign/*.toml
+var campaignFS embed.FS
+
+const CampaignProgressFile = "campaign.json"
+
+// CampaignProgress is persisted between runs to track unlocked levels.
+type CampaignProgress struct {
+	Unlocked int `json:"unlocked"` // Highest playable level, 1-based
+}
+
+// LoadCampaign returns the built-in campaign levels in play order.
+func LoadCampaign() ([]*Scenario, error) {
+	files, err := fs.Glob(campaignFS, "campaign/*.toml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	levels := make([]*Scenario, 0, len(files))
+	for _, name := range files {
+		data, err := campaignFS.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		sc, err := ParseScenario(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		levels = append(levels, sc)
+	}
+	return levels, nil
+}
+
+func campaignProgressPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CampaignProgressFile), nil
+}
+
+// LoadCampaignProgress reads saved progress; a fresh player has level 1 unlocked.
+func LoadCampaignProgress() (CampaignProgress, error) {
+	progress := CampaignProgress{Unlocked: 1}
+	path, err := campaignProgressPath()
+	if err != nil {
+		return progress, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return progress, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if progress.Unlocked < 1 {
+		progress.Unlocked = 1
+	}
+	return progress, nil
+}
+
+func SaveCampaignProgress(progress CampaignProgress) error {
+	path, err := campaignProgressPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// recordCampaignWin unlocks the next level after a campaign victory. Clearing
+// a level for the first time earns prof an upgrade pick when another level
+// follows; prof may be nil.
+func (g *Game) recordCampaignWin(levels []*Scenario, prof *Profile) {
+	progress, err := LoadCampaignProgress()
+	if err != nil {
+		g.AddLog(LogCritical, tr("Failed to read campaign progress: %v", err))
+		return
+	}
+	note := tr("CAMPAIGN COMPLETE: Every level cleared. Outstanding work, engineer.")
+	if g.CampaignLevel < len(levels) {
+		note = tr("LEVEL UNLOCKED: %d. %s", g.CampaignLevel+1, levels[g.CampaignLevel].Name)
+	}
+	if g.CampaignLevel+1 > progress.Unlocked {
+		progress.Unlocked = g.CampaignLevel + 1
+		if err := SaveCampaignProgress(progress); err != nil {
+			g.AddLog(LogCritical, tr("Failed to save campaign progress: %v", err))
+			return
+		}
+		if prof != nil && g.CampaignLevel < len(levels) && prof.earnUpgrade() {
+			note += "\n" + tr("UPGRADE EARNED: Choose it before the next level, or with the upgrade command.")
+			if err := prof.Save(); err != nil {
+				g.AddLog(LogCritical, tr("Failed to save profile: %v", err))
+			}
+		}
+	}
+	g.mu.Lock()
+	g.campaignNote = note
+	g.mu.Unlock()
+}
+
+// selectCampaignLevel picks the requested level, or the furthest unlocked one
+// when level is 0.
+func selectCampaignLevel(levels []*Scenario, level int) (int, error) {
+	progress, err := LoadCampaignProgress()
+	if err != nil {
+		return 0, err
+	}
+	unlocked := progress.Unlocked
+	if unlocked > len(levels) {
+		unlocked = len(levels)
+	}
+	if level == 0 {
+		return unlocked, nil
+	}
+	if level < 1 || level > len(levels) {
+		return 0, fmt.Errorf("no campaign level %d (levels 1-%d)", level, len(levels))
+	}
+	if level > unlocked {
+		return 0, fmt.Errorf("level %d is locked; complete level %d first", level, unlocked)
+	}
+	return level, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DependencyWhenCritical = "critical" // Source at or below CriticalThreshold
+	DependencyWhenOffline  = "offline"  // Source at MinSystemValue
+)
+
+// Dependency says how a failing source system hurts the rest of the plant.
+// Systems are named so layouts from the config or a scenario can reuse the
+// defaults; dependencies naming systems that do not exist are ignored.
+type Dependency struct {
+	Source            string  `toml:"source"`
+	Target            string  `toml:"target"`             // Dependent system; may be empty for plant-wide effects
+	When              string  `toml:"when"`               // DependencyWhenCritical (default) or DependencyWhenOffline
+	ExtraDegradation  int     `toml:"extra_degradation"`  // Extra damage per tick to Target while active
+	StabilizeSlowdown float64 `toml:"stabilize_slowdown"` // Fraction added to stabilize time while active
+}
+
+// dependencyLink is a Dependency resolved against the current layout.
+type dependencyLink struct {
+	Dependency
+	source *System
+	target *System // nil for plant-wide effects
+}
+
+func defaultDependencies() []Dependency {
+	return []Dependency{
+		{Source: "Coolant Flow", Target: "Core Temp", ExtraDegradation: 2},
+		{Source: "Pressure Ctrl", Target: "Coolant Flow", ExtraDegradation: 1},
+		{Source: "Core Temp", Target: "Shield Integrity", ExtraDegradation: 1},
+		{Source: "Power Output", Target: "Coolant Flow", ExtraDegradation: 1},
+		{Source: "Power Output", When: DependencyWhenOffline, StabilizeSlowdown: 1.0},
+	}
+}
+
+func (d Dependency) validate() error {
+	switch {
+	case d.Source == "":
+		return fmt.Errorf("dependency needs a source system")
+	case d.When != "" && d.When != DependencyWhenCritical && d.When != DependencyWhenOffline:
+		return fmt.Errorf("dependency on %q: unknown when %q", d.Source, d.When)
+	case d.ExtraDegradation < 0 || d.StabilizeSlowdown < 0:
+		return fmt.Errorf("dependency on %q: effects cannot be negative", d.Source)
+	case d.ExtraDegradation > 0 && d.Target == "":
+		return fmt.Errorf("dependency on %q: extra_degradation needs a target", d.Source)
+	}
+	return nil
+}
+
+// resolveDependencies links the configured dependencies to this game's systems.
+func (g *Game) resolveDependencies() {
+	g.dependencies = nil
+	for _, dep := range g.Config.Dependencies {
+		link := dependencyLink{Dependency: dep, source: g.systemNamed(dep.Source, nil)}
+		if link.source == nil {
+			continue
+		}
+		if dep.Target != "" {
+			if link.target = g.systemNamed(dep.Target, nil); link.target == nil {
+				continue
+			}
+		}
+		if link.When == "" {
+			link.When = DependencyWhenCritical
+		}
+		g.dependencies = append(g.dependencies, link)
+	}
+	g.cascadeActive = make([]bool, len(g.dependencies))
+}
+
+// dependencyActive reports whether link's source is failing badly enough,
+// given each system's current value.
+func (g *Game) dependencyActive(link dependencyLink, values []int) bool {
+	val := values[link.source.ID]
+	if link.When == DependencyWhenOffline {
+		return val <= link.source.MinValue
+	}
+	return val <= link.source.CriticalThreshold
+}
+
+// applyCascades runs once per degradation tick: every active dependency adds
+// its extra degradation to its target, and newly active ones are logged.
+func (g *Game) applyCascades(values []int) {
+	for i, link := range g.dependencies {
+		active := g.dependencyActive(link, values)
+		started := false
+		g.locked(func() {
+			started = active && !g.cascadeActive[i]
+			g.cascadeActive[i] = active
+		})
+		if !active {
+			continue
+		}
+		if started {
+			g.logCascade(link)
+		}
+		if link.target != nil && link.ExtraDegradation > 0 {
+			link.target.mu.Lock()
+			stable := link.target.IsStable
+			link.target.mu.Unlock()
+			if !stable {
+				link.target.Harm(link.ExtraDegradation)
+			}
+		}
+	}
+}
+
+func (g *Game) logCascade(link dependencyLink) {
+	switch {
+	case link.target != nil && link.ExtraDegradation > 0:
+		g.AddLog(LogCritical, tr("CASCADE: %s failing! %s (%d) degrading faster.", link.source.Name, link.target.Name, link.target.ID))
+	case link.StabilizeSlowdown > 0:
+		g.AddLog(LogCritical, tr("CASCADE: %s failing! Stabilization will take longer.", link.source.Name))
+	}
+}
+
+// stabilizeDuration is the configured stabilize time, lengthened by any
+// active slowdown from failing dependencies and shortened by overcharged power
+// and Quick Hands.
+func (g *Game) stabilizeDuration() time.Duration {
+	values := make([]int, len(g.Systems))
+	for i, sys := range g.Systems {
+		sys.locked(func() {
+			values[i] = sys.Value
+		})
+	}
+	factor := 1.0
+	for _, link := range g.dependencies {
+		if link.StabilizeSlowdown > 0 && g.dependencyActive(link, values) {
+			factor += link.StabilizeSlowdown
+		}
+	}
+	if g.overchargeBuffActive(OverchargePowerSystem) {
+		factor *= PowerStabilizeCut
+	}
+	if g.hasUpgrade(UpgradeQuickHands) {
+		factor *= QuickHandsCut
+	}
+	return time.Duration(float64(g.Config.StabilizeTime) * factor)
+}
+
+// dependentsOf returns the systems that depend on sys.
+func (g *Game) dependentsOf(sys *System) []*System {
+	var dependents []*System
+	for _, link := range g.dependencies {
+		if link.source == sys && link.target != nil {
+			dependents = append(dependents, link.target)
+		}
+	}
+	return dependents
+}
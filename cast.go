@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Terminal recordings. --cast records the session as it is drawn into an
+// asciinema v2 .cast file, to be played back with asciinema or shared on
+// asciinema.org without a screen recorder. --cast-last keeps only the final
+// stretch of the run, cut at the start of a frame.
+
+// castFrameStart begins every dashboard frame.
+const castFrameStart = ansiClear
+
+// castHeader is the first line of a .cast file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castEvent is one write to the terminal, at its offset from the start.
+type castEvent struct {
+	at   time.Duration
+	data string
+}
+
+// castRecorder passes the session's output through to the terminal and
+// records it. The whole session is streamed to the file as it goes; the
+// last stretch alone is kept in memory until Close.
+type castRecorder struct {
+	w      io.Writer
+	f      *os.File
+	file   *bufio.Writer
+	start  time.Time
+	last   time.Duration // Keep only this much of the end; 0 keeps it all
+	events []castEvent
+}
+
+func newCastRecorder(w io.Writer, path string, size ScreenSize, last time.Duration) (*castRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &castRecorder{w: w, f: f, file: bufio.NewWriter(f), start: time.Now(), last: last}
+	header, _ := json.Marshal(castHeader{ // Plain fields always marshal
+		Version: 2, Width: size.Width, Height: size.Height, Timestamp: c.start.Unix(),
+		Title: "Reactor Meltdown", Env: map[string]string{"TERM": os.Getenv("TERM")},
+	})
+	fmt.Fprintf(c.file, "%s\n", header)
+	return c, nil
+}
+
+func (c *castRecorder) Write(p []byte) (int, error) {
+	ev := castEvent{
+		at:   time.Since(c.start),
+		data: strings.ReplaceAll(strings.ReplaceAll(string(p), "\r\n", "\n"), "\n", "\r\n"), // Played back raw, as a terminal shows it
+	}
+	switch {
+	case c.last == 0:
+		c.writeEvent(ev, 0)
+	case strings.HasPrefix(ev.data, castFrameStart):
+		c.trim(ev.at)
+		fallthrough
+	default:
+		c.events = append(c.events, ev)
+	}
+	return c.w.Write(p)
+}
+
+// Flush flushes the terminal's writer, for a buffered Session.Out.
+func (c *castRecorder) Flush() error {
+	if f, ok := c.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// trim drops the frames that ended before the last stretch began, as of
+// now.
+func (c *castRecorder) trim(now time.Duration) {
+	keep := 0
+	for i, ev := range c.events {
+		if now-ev.at < c.last {
+			break
+		}
+		if strings.HasPrefix(ev.data, castFrameStart) {
+			keep = i // The latest frame still on screen when the stretch began
+		}
+	}
+	c.events = append(c.events[:0], c.events[keep:]...)
+}
+
+func (c *castRecorder) writeEvent(ev castEvent, offset time.Duration) {
+	event, _ := json.Marshal([]any{(ev.at - offset).Seconds(), "o", ev.data})
+	fmt.Fprintf(c.file, "%s\n", event)
+}
+
+// Close writes out the last stretch, if that is all that is kept, and
+// closes the file.
+func (c *castRecorder) Close() error {
+	if len(c.events) > 0 {
+		offset := c.events[0].at // A trimmed recording starts at its first frame
+		for _, ev := range c.events {
+			c.writeEvent(ev, offset)
+		}
+	}
+	if err := c.file.Flush(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
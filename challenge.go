@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Challenges. Every run is stamped with its seed and settings: the
+// difficulty, mode, mutators, reactor count and a hash of the config it
+// started on. The summary screen shows the stamp as a challenge string, the
+// high scores and the leaderboard keep it, and --challenge <string> starts
+// the same run for a friend to attempt. The config does not travel with the
+// string: the friend plays with the same --config and --scenario files, and
+// the hash makes sure of it.
+
+const ChallengePrefix = "RM1-"
+
+// RunStamp is the seed and settings a run started with.
+type RunStamp struct {
+	Seed       int64
+	Difficulty string
+	Mode       string
+	Mutators   []string
+	Reactors   int
+	ConfigHash string
+}
+
+// configHash identifies the reactor cfg describes. What only changes how the
+// run looks and sounds, or which keys and words drive it, is left out.
+func configHash(cfg Config) string {
+	cfg.Sound, cfg.Chat, cfg.Spectators = SoundConfig{}, ChatConfig{}, SpectatorConfig{}
+	cfg.Aliases, cfg.Verbs = nil, nil
+	cfg.Keys = KeysConfig{Cooldowns: cfg.Keys.Cooldowns}
+	data, _ := json.Marshal(cfg) // Plain data, so it always marshals
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Challenge encodes the stamp as a string to pass to --challenge. A short
+// checksum at the end catches a string mangled on the way.
+func (st RunStamp) Challenge() string {
+	payload := []byte(strings.Join([]string{
+		strconv.FormatInt(st.Seed, 36), st.Difficulty, st.Mode, strings.Join(st.Mutators, "+"), strconv.Itoa(st.Reactors), st.ConfigHash,
+	}, "."))
+	sum := sha256.Sum256(payload)
+	return ChallengePrefix + base64.RawURLEncoding.EncodeToString(append(payload, sum[:2]...))
+}
+
+// ParseChallenge decodes a challenge string and checks its settings.
+func ParseChallenge(s string) (RunStamp, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(s), ChallengePrefix)
+	if !ok {
+		return RunStamp{}, fmt.Errorf("%q is not a challenge string (they start with %s)", s, ChallengePrefix)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	damaged := errors.New("the challenge string is damaged; copy it whole")
+	if err != nil || len(data) < 3 {
+		return RunStamp{}, damaged
+	}
+	payload := data[:len(data)-2]
+	if sum := sha256.Sum256(payload); sum[0] != data[len(data)-2] || sum[1] != data[len(data)-1] {
+		return RunStamp{}, damaged
+	}
+	fields := strings.Split(string(payload), ".")
+	if len(fields) != 6 {
+		return RunStamp{}, damaged
+	}
+	st := RunStamp{Difficulty: fields[1], Mode: fields[2], ConfigHash: fields[5]}
+	if st.Seed, err = strconv.ParseInt(fields[0], 36, 64); err != nil {
+		return RunStamp{}, damaged
+	}
+	if _, err := DifficultyByName(st.Difficulty); err != nil {
+		return RunStamp{}, err
+	}
+	if _, err := ParseMode(st.Mode); err != nil {
+		return RunStamp{}, err
+	}
+	if fields[3] != "" {
+		st.Mutators = strings.Split(fields[3], "+")
+		for _, name := range st.Mutators {
+			if _, err := MutatorByName(name); err != nil {
+				return RunStamp{}, err
+			}
+		}
+	}
+	if st.Reactors, err = strconv.Atoi(fields[4]); err != nil || st.Reactors < 1 || st.Reactors > MaxReactors {
+		return RunStamp{}, damaged
+	}
+	return st, nil
+}
+
+// stamp is the seed and settings the run started with. The units of a plant
+// share the first unit's.
+func (g *Game) stamp() RunStamp {
+	first, reactors := g, 1
+	if g.plant != nil {
+		first, reactors = g.plant.Units[0], len(g.plant.Units)
+	}
+	return RunStamp{
+		Seed:       first.Seed,
+		Difficulty: g.Difficulty.Name,
+		Mode:       g.Mode,
+		Mutators:   mutatorList(g.Mutators),
+		Reactors:   reactors,
+		ConfigHash: g.configHash,
+	}
+}
+
+// challenge is the run's challenge string, or "" for a campaign level, which
+// a challenge cannot replay.
+func (g *Game) challenge() string {
+	if g.CampaignLevel > 0 {
+		return ""
+	}
+	return g.stamp().Challenge()
+}
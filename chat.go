@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Chat voting. With a [chat] table in the config, the viewers of a stream
+// pick the random events: whenever the next one is scheduled, Choices
+// candidates are drawn from the pool by weight and put to a vote, and when it
+// strikes the candidate with the most votes fires. Viewers vote by typing a
+// candidate's number or name ("2", "!2" or "!coolant_leak"); a viewer's last
+// vote is the one that counts. A tie, or a vote nobody joined, is settled by
+// the event generator.
+const (
+	TwitchIRCAddr       = "irc.chat.twitch.tv:6697"
+	DiscordAPI          = "https://discord.com/api/v10"
+	DiscordPollInterval = 2 * time.Second // Between reads of the Discord channel
+	ChatRetry           = 15 * time.Second
+	MaxChatChoices      = 9
+)
+
+// ChatConfig says where the votes come from. Twitch chat is read anonymously;
+// Discord needs a bot with the Message Content intent that can read the channel.
+type ChatConfig struct {
+	TwitchChannel  string `toml:"twitch_channel"`
+	DiscordToken   string `toml:"discord_token"`
+	DiscordChannel string `toml:"discord_channel"` // Channel ID
+	Choices        int    `toml:"choices"`         // Candidates per vote
+}
+
+// enabled reports whether any chat is configured.
+func (c ChatConfig) enabled() bool {
+	return c.TwitchChannel != "" || c.DiscordChannel != ""
+}
+
+func (c ChatConfig) validate() error {
+	switch {
+	case c.Choices < 2 || c.Choices > MaxChatChoices:
+		return fmt.Errorf("chat choices must be within 2-%d", MaxChatChoices)
+	case (c.DiscordToken == "") != (c.DiscordChannel == ""):
+		return fmt.Errorf("chat needs both discord_token and discord_channel")
+	case strings.ContainsAny(c.TwitchChannel, " #:\r\n"):
+		return fmt.Errorf("chat twitch_channel %q is not a channel name", c.TwitchChannel)
+	}
+	if c.DiscordChannel != "" {
+		if _, err := strconv.ParseUint(c.DiscordChannel, 10, 64); err != nil {
+			return fmt.Errorf("chat discord_channel must be the channel's numeric ID, got %q", c.DiscordChannel)
+		}
+	}
+	return nil
+}
+
+// ChatPoll is the vote on the next random event. Chat readers cast votes
+// from their own goroutines; the game loop opens and closes the ballots.
+type ChatPoll struct {
+	mu      sync.Mutex
+	choices []string       // Event names on the ballot
+	votes   map[string]int // Viewer to index into choices
+}
+
+// VoteState is the ballot as the dashboard shows it.
+type VoteState struct {
+	Choices []string      `json:"choices"`
+	Votes   []int         `json:"votes"`
+	Left    time.Duration `json:"left_ns"` // Until the winner strikes
+}
+
+// open starts a vote between choices, discarding the last one.
+func (p *ChatPoll) open(choices []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.choices, p.votes = choices, make(map[string]int)
+}
+
+// vote records viewer's vote if text names a candidate.
+func (p *ChatPoll) vote(viewer, text string) {
+	text = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(text), "!"))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= len(p.choices) {
+		p.votes[viewer] = n - 1
+		return
+	}
+	for i, name := range p.choices {
+		if text == name {
+			p.votes[viewer] = i
+			return
+		}
+	}
+}
+
+// tally returns the candidates and their vote counts.
+func (p *ChatPoll) tally() ([]string, []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make([]int, len(p.choices))
+	for _, choice := range p.votes {
+		counts[choice]++
+	}
+	return p.choices, counts
+}
+
+// openChatVote draws the candidates for the next random event.
+func (g *Game) openChatVote() {
+	var choices []string
+	for tries := 0; len(choices) < g.Config.Chat.Choices && tries < 10*g.Config.Chat.Choices; tries++ {
+		ev := g.pickEvent(g.Elapsed())
+		if ev == nil {
+			break
+		}
+		if !slices.Contains(choices, ev.Name()) {
+			choices = append(choices, ev.Name())
+		}
+	}
+	g.chat.open(choices)
+}
+
+// chatWinner closes the vote and returns the event it chose, or nil if the
+// ballot was empty.
+func (g *Game) chatWinner() Event {
+	choices, counts := g.chat.tally()
+	best, total := 0, 0
+	var leaders []string
+	for i, name := range choices {
+		total += counts[i]
+		switch {
+		case counts[i] > best:
+			best, leaders = counts[i], []string{name}
+		case counts[i] == best:
+			leaders = append(leaders, name)
+		}
+	}
+	if len(leaders) == 0 {
+		return nil
+	}
+	name := leaders[g.rng.Intn(len(leaders))]
+	switch {
+	case total == 0:
+		g.AddLog(LogEvent, tr("CHAT VOTE: Nobody voted; the reactor picks %s.", name))
+	case len(leaders) > 1:
+		g.AddLog(LogEvent, tr("CHAT VOTE: A tie at %d votes, settled on %s.", best, name))
+	default:
+		g.AddLog(LogEvent, tr("CHAT VOTE: Chat picked %s with %d of %d votes.", name, best, total))
+	}
+	ev, _ := g.Events.Lookup(name)
+	return ev
+}
+
+// voteSnapshotLocked copies the open vote, or returns nil without chat voting.
+// The caller must hold g.mu.
+func (g *Game) voteSnapshotLocked(now time.Time) *VoteState {
+	if g.chat == nil || !g.randomEventsEnabled() {
+		return nil
+	}
+	choices, counts := g.chat.tally()
+	if len(choices) == 0 {
+		return nil
+	}
+	return &VoteState{Choices: choices, Votes: counts, Left: max(0, g.nextEvent.Sub(now))}
+}
+
+// votePanel renders the open vote for the dashboard, or "" if there is none.
+func (s GameState) votePanel() string {
+	v := s.Vote
+	if v == nil {
+		return ""
+	}
+	lead := 0
+	for _, n := range v.Votes {
+		lead = max(lead, n)
+	}
+	lines := []string{theme.Info.Sprint(tr("CHAT VOTE (next event in %ds): type the number in chat", ceilSeconds(v.Left)))}
+	for i, name := range v.Choices {
+		line := fmt.Sprintf("  !%d %-20s %d", i+1, name, v.Votes[i])
+		if lead > 0 && v.Votes[i] == lead {
+			line = theme.Info.With(color.Bold).Sprint(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chatSource reads one chat, passing every message to vote, until ctx ends
+// or the connection fails.
+type chatSource struct {
+	Name string
+	Read func(ctx context.Context, vote func(viewer, text string)) error
+}
+
+// startChat puts g's random events to the vote of the chats in cfg. The
+// returned stop disconnects from them.
+func (g *Game) startChat(cfg ChatConfig) (stop func()) {
+	g.chat = &ChatPoll{}
+	var sources []chatSource
+	if cfg.TwitchChannel != "" {
+		sources = append(sources, chatSource{"twitch.tv/" + strings.ToLower(cfg.TwitchChannel), twitchChat(cfg.TwitchChannel)})
+	}
+	if cfg.DiscordChannel != "" {
+		sources = append(sources, chatSource{"Discord", discordChat(cfg.DiscordToken, cfg.DiscordChannel)})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				g.post(func() { g.AddLog(LogEvent, tr("CHAT: Counting votes from %s.", src.Name)) })
+				err := src.Read(ctx, g.chat.vote)
+				if ctx.Err() != nil {
+					return
+				}
+				g.post(func() {
+					g.AddLog(LogWarning, tr("CHAT: Lost %s (%v). Retrying in %s.", src.Name, err, ChatRetry))
+				})
+				select {
+				case <-time.After(ChatRetry):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// twitchChat reads a Twitch channel's chat over IRC, logged in anonymously.
+func twitchChat(channel string) func(context.Context, func(viewer, text string)) error {
+	return func(ctx context.Context, vote func(viewer, text string)) error {
+		dialer := tls.Dialer{Config: &tls.Config{MinVersion: tls.VersionTLS12}}
+		conn, err := dialer.DialContext(ctx, "tcp", TwitchIRCAddr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		defer context.AfterFunc(ctx, func() { conn.Close() })()
+		if _, err := fmt.Fprintf(conn, "NICK justinfan%d\r\nJOIN #%s\r\n", 10000+rand.Intn(90000), strings.ToLower(channel)); err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if rest, ok := strings.CutPrefix(line, "PING"); ok {
+				fmt.Fprintf(conn, "PONG%s\r\n", rest)
+				continue
+			}
+			if viewer, text, ok := parsePrivmsg(line); ok {
+				vote(viewer, text)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("disconnected")
+	}
+}
+
+// parsePrivmsg picks the sender and text out of an IRC chat message, such as
+// ":kim!kim@kim.tmi.twitch.tv PRIVMSG #channel :!2".
+func parsePrivmsg(line string) (nick, text string, ok bool) {
+	prefix, rest, found := strings.Cut(line, " ")
+	if !found || !strings.HasPrefix(prefix, ":") || !strings.HasPrefix(rest, "PRIVMSG ") {
+		return "", "", false
+	}
+	nick, _, _ = strings.Cut(prefix[1:], "!")
+	if _, text, found = strings.Cut(rest, " :"); !found || nick == "" {
+		return "", "", false
+	}
+	return nick, text, true
+}
+
+// discordChat polls a Discord channel for new messages as the bot with token.
+func discordChat(token, channel string) func(context.Context, func(viewer, text string)) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(ctx context.Context, vote func(viewer, text string)) error {
+		after := "" // The newest message seen; the ones before the game are not votes
+		for {
+			url := fmt.Sprintf("%s/channels/%s/messages?limit=100", DiscordAPI, channel)
+			if after != "" {
+				url += "&after=" + after
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bot "+token)
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			var messages []struct {
+				ID      string `json:"id"`
+				Content string `json:"content"`
+				Author  struct {
+					ID  string `json:"id"`
+					Bot bool   `json:"bot"`
+				} `json:"author"`
+			}
+			wait := DiscordPollInterval
+			switch {
+			case resp.StatusCode == http.StatusTooManyRequests:
+				if secs, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil {
+					wait = time.Duration(secs * float64(time.Second))
+				}
+			case resp.StatusCode != http.StatusOK:
+				resp.Body.Close()
+				return fmt.Errorf("Discord answered %s", resp.Status)
+			default:
+				err = json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&messages)
+			}
+			resp.Body.Close()
+			if err != nil {
+				return fmt.Errorf("reading Discord messages: %w", err)
+			}
+			sort.Slice(messages, func(i, j int) bool { return snowflakeLess(messages[i].ID, messages[j].ID) })
+			for _, msg := range messages {
+				if after != "" && !msg.Author.Bot {
+					vote(msg.Author.ID, msg.Content)
+				}
+			}
+			if n := len(messages); n > 0 {
+				after = messages[n-1].ID
+			} else if after == "" {
+				after = "0" // An empty channel: every message from now on counts
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// snowflakeLess orders Discord IDs, which are decimal and grow over time.
+func snowflakeLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
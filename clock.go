@@ -0,0 +1,160 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the game's source of time. Interactive games run on the wall
+// clock; tests and the headless simulator run on a simClock that only moves
+// when advanced, so a whole game can play out in a fraction of a second.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) // Runs f once d has passed
+}
+
+// Ticker delivers ticks on Chan every period, like time.Ticker.
+type Ticker interface {
+	Chan() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time                         { return time.Now() }
+func (wallClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (wallClock) NewTicker(d time.Duration) Ticker       { return wallTicker{time.NewTicker(d)} }
+func (wallClock) AfterFunc(d time.Duration, f func())    { time.AfterFunc(d, f) }
+
+type wallTicker struct{ t *time.Ticker }
+
+func (w wallTicker) Chan() <-chan time.Time { return w.t.C }
+func (w wallTicker) Reset(d time.Duration)  { w.t.Reset(d) }
+func (w wallTicker) Stop()                  { w.t.Stop() }
+
+// simClock is a manual clock. Timers fire from Advance, in deadline order, on
+// the advancing goroutine; channels from After and NewTicker are buffered
+// like the standard library's, so a slow reader misses ticks rather than
+// blocking the clock. The headless simulator drives it from one goroutine,
+// which keeps a run reproducible from its seed.
+type simClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []simTimer
+	seq    int // Breaks ties between timers due at the same instant
+}
+
+type simTimer struct {
+	at  time.Time
+	seq int
+	f   func()
+}
+
+func newSimClock(start time.Time) *simClock {
+	return &simClock{now: start}
+}
+
+func (c *simClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *simClock) AfterFunc(d time.Duration, f func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.timers = append(c.timers, simTimer{at: c.now.Add(d), seq: c.seq, f: f})
+}
+
+func (c *simClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.AfterFunc(d, func() { ch <- c.Now() })
+	return ch
+}
+
+func (c *simClock) NewTicker(d time.Duration) Ticker {
+	t := &simTicker{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer that comes due on
+// the way at its own deadline.
+func (c *simClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		sort.Slice(c.timers, func(i, j int) bool {
+			if !c.timers[i].at.Equal(c.timers[j].at) {
+				return c.timers[i].at.Before(c.timers[j].at)
+			}
+			return c.timers[i].seq < c.timers[j].seq
+		})
+		if len(c.timers) == 0 || c.timers[0].at.After(target) {
+			break
+		}
+		next := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = next.at
+		c.mu.Unlock()
+		next.f() // May schedule further timers
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// simTicker re-arms itself on its simClock after every tick. Each Reset
+// starts a new generation, so ticks armed before it are dropped.
+type simTicker struct {
+	clock *simClock
+	c     chan time.Time
+	mu    sync.Mutex
+	gen   int
+}
+
+func (t *simTicker) Chan() <-chan time.Time { return t.c }
+
+func (t *simTicker) Reset(d time.Duration) {
+	t.mu.Lock()
+	t.gen++
+	gen := t.gen
+	t.mu.Unlock()
+	t.arm(gen, d)
+}
+
+func (t *simTicker) Stop() {
+	t.mu.Lock()
+	t.gen++
+	t.mu.Unlock()
+}
+
+func (t *simTicker) arm(gen int, d time.Duration) {
+	t.clock.AfterFunc(d, func() {
+		t.mu.Lock()
+		current := t.gen == gen
+		t.mu.Unlock()
+		if !current {
+			return
+		}
+		select {
+		case t.c <- t.clock.Now():
+		default: // The reader is behind; drop the tick
+		}
+		t.arm(gen, d)
+	})
+}
+
+// useClock moves a new game onto clock before it starts.
+func (g *Game) useClock(clock Clock) {
+	g.clock = clock
+	g.StartTime = clock.Now()
+	for _, sys := range g.Systems {
+		sys.clock = clock
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSimClockAdvanceOrder(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := newSimClock(start)
+	var fired []string
+	var at []time.Duration
+	timer := func(name string) func() {
+		return func() {
+			fired = append(fired, name)
+			at = append(at, c.Now().Sub(start))
+		}
+	}
+	c.AfterFunc(3*time.Second, timer("c"))
+	c.AfterFunc(1*time.Second, timer("a"))
+	c.AfterFunc(2*time.Second, timer("b1"))
+	c.AfterFunc(2*time.Second, timer("b2")) // Same deadline: after b1, scheduled first
+	c.AfterFunc(1500*time.Millisecond, func() {
+		timer("x")()
+		c.AfterFunc(time.Second, timer("y")) // Due at 2.5s, inside this Advance
+	})
+	c.AfterFunc(10*time.Second, timer("late"))
+
+	c.Advance(5 * time.Second)
+
+	wantFired := []string{"a", "x", "b1", "b2", "y", "c"}
+	wantAt := []time.Duration{time.Second, 1500 * time.Millisecond, 2 * time.Second, 2 * time.Second, 2500 * time.Millisecond, 3 * time.Second}
+	if !slices.Equal(fired, wantFired) {
+		t.Errorf("fired %v, want %v", fired, wantFired)
+	}
+	if !slices.Equal(at, wantAt) {
+		t.Errorf("fired at %v, want %v", at, wantAt)
+	}
+	if got := c.Now().Sub(start); got != 5*time.Second {
+		t.Errorf("clock at %v after Advance, want 5s", got)
+	}
+
+	c.Advance(5 * time.Second)
+	if fired[len(fired)-1] != "late" || at[len(at)-1] != 10*time.Second {
+		t.Errorf("late timer: fired %v at %v", fired, at)
+	}
+}
+
+func TestSimTickerReset(t *testing.T) {
+	c := newSimClock(time.Unix(0, 0))
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	select {
+	case <-ticker.Chan():
+	default:
+		t.Fatal("no tick after one period")
+	}
+	ticker.Reset(3 * time.Second) // Drops the tick armed for 2s
+	c.Advance(2 * time.Second)
+	select {
+	case <-ticker.Chan():
+		t.Fatal("tick from before Reset")
+	default:
+	}
+	c.Advance(time.Second)
+	select {
+	case <-ticker.Chan():
+	default:
+		t.Fatal("no tick after the new period")
+	}
+	ticker.Stop()
+	c.Advance(10 * time.Second)
+	select {
+	case <-ticker.Chan():
+		t.Fatal("tick after Stop")
+	default:
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Command arguments. A system can be given by its ID or by its name, with
+// any case and spacing, or by the start of its name or of one of its words
+// as long as only one system fits: `stabilize coretemp`, `vent shield`,
+// `divert power cool 20`. A name with spaces can also be quoted. Aliases
+// from the config stand for a command, or the start of one.
+
+// systemArgs are the arguments of each command that name a system.
+var systemArgs = map[string][]int{
+	"stabilize": {1}, "vent": {1}, "reboot": {1}, "inspect": {1}, "override": {1},
+	"overcharge": {1}, "trend": {1}, "sweep": {1}, "divert": {1, 2}, "use": {2},
+	CrisisSuppress: {1}, CrisisIsolate: {1}, CrisisRestart: {1},
+	DebugSet: {1}, DebugEvent: {2},
+}
+
+// splitCommand splits a command line into words at spaces, keeping what
+// is inside quotes as one word.
+func splitCommand(input string) []string {
+	var words []string
+	var word strings.Builder
+	inWord, quote := false, rune(0)
+	for _, r := range input {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			inWord = false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// squash folds a system name or reference for matching: lower case, with
+// no spaces.
+func squash(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), ""))
+}
+
+// matchSystems returns the systems a name reference fits: the one it names
+// exactly, or else every one whose name or a word of it starts with it.
+// Names match in English and in the language of the game.
+func (g *Game) matchSystems(ref string) []*System {
+	ref = squash(ref)
+	if ref == "" {
+		return nil
+	}
+	var matches []*System
+	for _, sys := range g.Systems {
+		fits := false
+		for _, name := range []string{sys.Name, tr(sys.Name)} {
+			if squash(name) == ref {
+				return []*System{sys}
+			}
+			fits = fits || strings.HasPrefix(squash(name), ref)
+			for _, word := range strings.Fields(name) {
+				fits = fits || strings.HasPrefix(strings.ToLower(word), ref)
+			}
+		}
+		if fits {
+			matches = append(matches, sys)
+		}
+	}
+	return matches
+}
+
+// resolveSystemArg turns a system reference into its ID. An ID is left as
+// it is, for the command to check.
+func (g *Game) resolveSystemArg(ref string) (string, bool) {
+	if _, err := strconv.Atoi(ref); err == nil {
+		return ref, true
+	}
+	matches := g.matchSystems(ref)
+	switch len(matches) {
+	case 0:
+		g.AddLog(LogWarning, tr("Error: No system matches %q.", ref))
+		return "", false
+	case 1:
+		return strconv.Itoa(matches[0].ID), true
+	}
+	names := make([]string, len(matches))
+	for i, sys := range matches {
+		names[i] = fmt.Sprintf("%s (%d)", tr(sys.Name), sys.ID)
+	}
+	g.AddLog(LogWarning, tr("Error: %q could be %s. Type more of the name.", ref, strings.Join(names, tr(" or "))))
+	return "", false
+}
+
+// resolveSystemArgs replaces the system names among the arguments of
+// command with their IDs, in both parts and rawParts. It reports a name
+// that fits no system or several, and returns false.
+func (g *Game) resolveSystemArgs(command string, parts, rawParts []string) bool {
+	for _, i := range systemArgs[command] {
+		if i >= len(parts) {
+			continue
+		}
+		id, ok := g.resolveSystemArg(parts[i])
+		if !ok {
+			return false
+		}
+		parts[i], rawParts[i] = id, id
+	}
+	return true
+}
+
+// expandAlias replaces an alias at the start of input with what it stands
+// for.
+func (g *Game) expandAlias(input string) string {
+	words := strings.Fields(input)
+	if len(words) == 0 {
+		return input
+	}
+	expansion, ok := g.Config.Aliases[strings.ToLower(words[0])]
+	if !ok {
+		return input
+	}
+	return strings.Join(append([]string{expansion}, words[1:]...), " ")
+}
+
+func (g *Game) aliasNames() []string {
+	names := make([]string, 0, len(g.Config.Aliases))
+	for name := range g.Config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateAliases rejects aliases that are not one word, or that would hide
+// a command.
+func validateAliases(aliases map[string]string) error {
+	for name, expansion := range aliases {
+		switch {
+		case name == "" || strings.ContainsFunc(name, unicode.IsSpace) || name != strings.ToLower(name):
+			return fmt.Errorf("aliases: %q must be one lower-case word", name)
+		case isCommand(name):
+			return fmt.Errorf("aliases: %q is already a command", name)
+		case strings.TrimSpace(expansion) == "":
+			return fmt.Errorf("aliases: %q stands for nothing", name)
+		}
+	}
+	return nil
+}
+
+func isCommand(name string) bool {
+	switch name {
+	case "quit", "debug", DebugSet, DebugEvent, DebugKits, DebugTimescale:
+		return true
+	}
+	for _, c := range commandWords {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
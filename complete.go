@@ -0,0 +1,92 @@
+package main
+
+import "strings"
+
+// Line editing. At an interactive terminal the prompt is a line editor, as
+// over SSH: left and right move through the line, up and down recall earlier
+// commands, and Tab completes command names, system names and items.
+
+// commandWords are the commands Tab completes.
+var commandWords = []string{
+	"stabilize", "divert", "vent", "override", "overcharge", "reboot", "inspect", "sweep",
+	"use", "fabricate", "rally", "scram", "cancel", "undo", "rewind", CrisisSuppress, CrisisIsolate, CrisisRestart,
+	"rule", "ack", "log", HistoryTopic, "trend", "schematic", "view", "advise", "spectators", "save", SavesTopic, "load", "reload", "reactor", "sabotage", "respond", "autopilot", "help", ManualTopic, KeysTopic, "quit",
+}
+
+// completions returns what the word being typed as argument arg (0 for the
+// command itself) of the line whose command is command may complete to.
+// Systems complete to the first word of their name.
+func (g *Game) completions(command string, arg int) []string {
+	if arg == 0 {
+		return append(append(g.aliasNames(), g.verbNames()...), commandWords...)
+	}
+	command = g.resolveVerb(command)
+	if command == "use" && arg == 1 {
+		return itemNames()
+	}
+	if command == "help" && arg == 1 {
+		return commandWords
+	}
+	if (command == "advise" || command == "autopilot") && arg == 1 {
+		return []string{"on", "off"}
+	}
+	if command == "respond" && arg == 1 {
+		return []string{"yes", "no"}
+	}
+	if command == "view" && arg == 1 {
+		return viewNames
+	}
+	if command == "spectators" && arg == 1 {
+		return []string{"on", "off", "mute", "unmute"}
+	}
+	if command == HistoryTopic && arg == 1 {
+		return []string{"save"}
+	}
+	words := make([]string, 0, len(g.Systems))
+	for _, sys := range g.Systems {
+		words = append(words, strings.ToLower(strings.Fields(sys.Name)[0]))
+		if name := tr(sys.Name); name != sys.Name {
+			words = append(words, strings.ToLower(strings.Fields(name)[0]))
+		}
+	}
+	return words
+}
+
+// complete is the line editor's AutoCompleteCallback: on Tab it extends the
+// word before the cursor as far as every completion agrees, and past the
+// end of the word when only one is left.
+func (p *Plant) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	head := line[:pos]
+	words := strings.Fields(head)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(head, " ") {
+		partial, words = words[len(words)-1], words[:len(words)-1]
+	}
+	command := ""
+	if len(words) > 0 {
+		command = strings.ToLower(words[0])
+	}
+	var matches []string
+	for _, word := range p.Focused().completions(command, len(words)) {
+		if strings.HasPrefix(word, strings.ToLower(partial)) {
+			matches = append(matches, word)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(matches) == 1 {
+		common += " "
+	}
+	head = head[:len(head)-len(partial)] + common
+	return head + line[pos:], len(head), true
+}
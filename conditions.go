@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// External conditions. Beyond the plant's fence the weather and the grid
+// change slowly: a heat wave makes Core Temp degrade faster, a storm may
+// strike Power Output with lightning, and a spike in grid demand requires
+// Power Output to stay above a floor or costs points. Each condition is
+// forecast Lead ahead of its arrival, so the player can prepare. Conditions
+// move on their own goroutine, which hands every step to the game loop.
+
+// Kinds of condition.
+const (
+	ConditionHeatWave = "heat_wave"
+	ConditionStorm    = "storm"
+	ConditionDemand   = "grid_demand"
+)
+
+var conditionKinds = []string{ConditionHeatWave, ConditionStorm, ConditionDemand}
+
+// ConditionsConfig tunes the external conditions. Damage is per tick.
+type ConditionsConfig struct {
+	Tick          time.Duration `toml:"tick"` // How often conditions move; 0 disables them
+	Lead          time.Duration `toml:"lead"` // How far ahead a condition is forecast
+	GapMin        time.Duration `toml:"gap_min"`
+	GapMax        time.Duration `toml:"gap_max"`
+	Duration      time.Duration `toml:"duration"`
+	HeatSystem    string        `toml:"heat_system"`
+	HeatDamage    int           `toml:"heat_damage"`
+	StormSystem   string        `toml:"storm_system"`
+	StormChance   int           `toml:"storm_chance"` // Percent chance of a lightning strike
+	StormDamage   int           `toml:"storm_damage"`
+	DemandSystem  string        `toml:"demand_system"`
+	DemandFloor   int           `toml:"demand_floor"`
+	DemandPenalty int           `toml:"demand_penalty"` // Points lost every tick below the floor
+}
+
+func defaultConditions() ConditionsConfig {
+	return ConditionsConfig{
+		Tick:          time.Second,
+		Lead:          20 * time.Second,
+		GapMin:        30 * time.Second,
+		GapMax:        60 * time.Second,
+		Duration:      30 * time.Second,
+		HeatSystem:    "Core Temp",
+		HeatDamage:    1,
+		StormSystem:   "Power Output",
+		StormChance:   10,
+		StormDamage:   12,
+		DemandSystem:  "Power Output",
+		DemandFloor:   60,
+		DemandPenalty: 5,
+	}
+}
+
+func (c ConditionsConfig) validate() error {
+	switch {
+	case c.Tick < 0 || c.Lead < 0:
+		return fmt.Errorf("conditions: tick and lead cannot be negative")
+	case c.Tick == 0:
+		return nil
+	case c.GapMin <= 0 || c.GapMax < c.GapMin || c.Duration <= 0:
+		return fmt.Errorf("conditions: must satisfy 0 < gap_min <= gap_max and a positive duration")
+	case c.HeatDamage < 0 || c.StormDamage < 0 || c.DemandPenalty < 0 || c.DemandFloor < 0:
+		return fmt.Errorf("conditions: damage, floor and penalty cannot be negative")
+	case c.StormChance < 0 || c.StormChance > 100:
+		return fmt.Errorf("conditions: storm_chance must be within 0-100")
+	}
+	return nil
+}
+
+// Condition is a spell of weather or demand, from Start to End in elapsed
+// game time.
+type Condition struct {
+	Kind  string        `json:"kind"`
+	Start time.Duration `json:"start_ns"`
+	End   time.Duration `json:"end_ns"`
+}
+
+// conditions is the forecast: the condition under way, if any, and the
+// next one.
+type conditions struct {
+	current   *Condition
+	next      *Condition
+	announced bool // The next condition has been forecast in the log
+	short     bool // Demand is going unmet
+}
+
+// conditionsTick moves the forecast along and lets the condition under way
+// take its toll.
+func (g *Game) conditionsTick() {
+	c := g.conditions
+	if c == nil {
+		return
+	}
+	cfg := g.Config.Conditions
+	elapsed := g.Elapsed()
+	var ended, forecast, arrived, current *Condition
+	g.locked(func() {
+		if c.current != nil && elapsed >= c.current.End {
+			ended, c.current, c.short = c.current, nil, false
+		}
+		if c.next == nil {
+			start := elapsed
+			if c.current != nil {
+				start = c.current.End
+			}
+			start += cfg.GapMin + time.Duration(g.weatherRng.Float64()*float64(cfg.GapMax-cfg.GapMin))
+			c.next = &Condition{Kind: conditionKinds[g.weatherRng.Intn(len(conditionKinds))], Start: start, End: start + cfg.Duration}
+			c.announced = false
+		}
+		if !c.announced && c.next.Start-elapsed <= cfg.Lead {
+			c.announced = true
+			forecast = c.next
+		}
+		if c.current == nil && elapsed >= c.next.Start {
+			c.current, c.next = c.next, nil
+			c.current.End = elapsed + cfg.Duration
+			arrived = c.current
+		}
+		current = c.current
+	})
+
+	if ended != nil {
+		g.AddLog(LogSuccess, tr("CONDITIONS: The %s is over.", conditionName(ended.Kind)))
+	}
+	if forecast != nil {
+		g.AddLog(LogEvent, tr("FORECAST: %s in %.0fs. %s", conditionTitle(forecast.Kind), (forecast.Start-elapsed).Seconds(), g.conditionAdvice(forecast.Kind)))
+	}
+	if arrived != nil {
+		g.AddLog(LogWarning, tr("CONDITIONS: The %s has arrived and lasts %.0fs.", conditionName(arrived.Kind), cfg.Duration.Seconds()))
+	}
+	if current != nil {
+		g.applyCondition(current.Kind)
+	}
+}
+
+// applyCondition lets a condition of kind take its toll for one tick.
+func (g *Game) applyCondition(kind string) {
+	cfg := g.Config.Conditions
+	switch kind {
+	case ConditionHeatWave:
+		if sys := g.systemNamed(cfg.HeatSystem, nil); sys != nil && cfg.HeatDamage > 0 {
+			sys.Harm(g.scaleDamage(cfg.HeatDamage))
+		}
+	case ConditionStorm:
+		sys := g.systemNamed(cfg.StormSystem, nil)
+		if sys == nil || g.weatherRng.Intn(100) >= cfg.StormChance {
+			return
+		}
+		damage := g.scaleDamage(cfg.StormDamage)
+		sys.Harm(damage)
+		g.AddLog(LogCritical, tr("STORM: Lightning strikes %s (%d)! Integrity -%d.", sys.Name, sys.ID, damage))
+	case ConditionDemand:
+		sys := g.systemNamed(cfg.DemandSystem, nil)
+		if sys == nil {
+			return
+		}
+		sys.mu.Lock()
+		short := sys.Value < cfg.DemandFloor
+		sys.mu.Unlock()
+		g.mu.Lock()
+		was := g.conditions.short
+		g.conditions.short = short
+		if short {
+			g.Score.Shortfalls++
+		}
+		g.mu.Unlock()
+		switch {
+		case short && !was:
+			g.AddLog(LogWarning, tr("GRID: Demand unmet! Raise %s to %d or lose %d points every %.0fs.", sys.Name, cfg.DemandFloor, cfg.DemandPenalty, cfg.Tick.Seconds()))
+		case !short && was:
+			g.AddLog(LogSuccess, tr("GRID: Demand met again."))
+		}
+	}
+}
+
+// conditionAdvice is how to prepare for a condition of kind.
+func (g *Game) conditionAdvice(kind string) string {
+	cfg := g.Config.Conditions
+	switch kind {
+	case ConditionHeatWave:
+		return tr("%s will lose %d more every %.0fs.", cfg.HeatSystem, cfg.HeatDamage, cfg.Tick.Seconds())
+	case ConditionStorm:
+		return tr("Lightning may strike %s for %d.", cfg.StormSystem, cfg.StormDamage)
+	}
+	return tr("Keep %s at %d or above.", cfg.DemandSystem, cfg.DemandFloor)
+}
+
+// conditionName names a condition of kind within a sentence.
+func conditionName(kind string) string {
+	switch kind {
+	case ConditionHeatWave:
+		return tr("heat wave")
+	case ConditionStorm:
+		return tr("storm")
+	}
+	return tr("grid demand spike")
+}
+
+// conditionTitle names a condition of kind on its own.
+func conditionTitle(kind string) string {
+	switch kind {
+	case ConditionHeatWave:
+		return tr("Heat wave")
+	case ConditionStorm:
+		return tr("Storm")
+	}
+	return tr("Grid demand spike")
+}
+
+// ConditionsState is the forecast as the dashboard shows it, and as it is
+// saved.
+type ConditionsState struct {
+	Current   *Condition `json:"current,omitempty"`
+	Next      *Condition `json:"next,omitempty"`
+	Announced bool       `json:"announced,omitempty"` // The next condition is within the forecast lead
+	Short     bool       `json:"short,omitempty"`
+}
+
+// conditionsSnapshotLocked copies the forecast, or returns nil when
+// conditions are disabled. The caller must hold g.mu.
+func (g *Game) conditionsSnapshotLocked() *ConditionsState {
+	c := g.conditions
+	if c == nil {
+		return nil
+	}
+	v := &ConditionsState{Announced: c.announced, Short: c.short}
+	if c.current != nil {
+		current := *c.current
+		v.Current = &current
+	}
+	if c.next != nil {
+		next := *c.next
+		v.Next = &next
+	}
+	return v
+}
+
+// forecastWidget renders the forecast line for the dashboard, or "".
+func (s GameState) forecastWidget() string {
+	v := s.Conditions
+	if v == nil {
+		return ""
+	}
+	var parts []string
+	if v.Current != nil {
+		now := tr("%s, %.0fs left", strings.ToUpper(conditionTitle(v.Current.Kind)), (v.Current.End - s.Elapsed).Seconds())
+		if v.Short {
+			now = tr("%s, DEMAND UNMET", now)
+		}
+		parts = append(parts, theme.Warning.With(color.Bold).Sprint(now))
+	}
+	switch {
+	case v.Next != nil && v.Announced:
+		parts = append(parts, theme.Notice.Sprint(tr("%s in %.0fs", conditionTitle(v.Next.Kind), max(0, v.Next.Start-s.Elapsed).Seconds())))
+	case v.Current == nil:
+		parts = append(parts, theme.OK.Sprint(tr("Clear")))
+	}
+	return tr("FORECAST:  %s", strings.Join(parts, " | "))
+}
+
+// restoreConditions rebuilds a forecast saved as its snapshot.
+func restoreConditions(st ConditionsState) (*conditions, error) {
+	for _, c := range []*Condition{st.Current, st.Next} {
+		if c != nil && !isCondition(c.Kind) {
+			return nil, fmt.Errorf("save has unknown condition %q", c.Kind)
+		}
+	}
+	return &conditions{current: st.Current, next: st.Next, announced: st.Announced, short: st.Short}, nil
+}
+
+func isCondition(kind string) bool {
+	for _, k := range conditionKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
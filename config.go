@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds every reactor tunable. DefaultConfig provides the built-in
+// layout; a TOML file passed via --config overrides any subset of it.
+type Config struct {
+	NumSystems        int                    `toml:"num_systems"`
+	SystemNames       []string               `toml:"system_names"`
+	Systems           []SystemDef            `toml:"systems"` // Per system, by ID; those left out keep the defaults
+	MaxSystemValue    int                    `toml:"max_system_value"`
+	MinSystemValue    int                    `toml:"min_system_value"`
+	CriticalThreshold int                    `toml:"critical_threshold"`
+	WarningThreshold  int                    `toml:"warning_threshold"`
+	StabilizeTime     time.Duration          `toml:"stabilize_time"`
+	StabilizeMinigame bool                   `toml:"stabilize_minigame"` // Stabilize plays a sequence to repeat; false for the passive wait
+	GameDuration      time.Duration          `toml:"game_duration"`
+	EventIntervalMin  time.Duration          `toml:"event_interval_min"`
+	EventIntervalMax  time.Duration          `toml:"event_interval_max"`
+	DegradationTick   time.Duration          `toml:"degradation_tick"`
+	InitialRepairKits int                    `toml:"initial_repair_kits"`
+	RebootTime        time.Duration          `toml:"reboot_time"`
+	RebootPercent     int                    `toml:"reboot_percent"` // Share of max value an offline system reboots to
+	Confirm           bool                   `toml:"confirm"`        // Override, scram and large diverts wait for CONFIRM
+	EventWeights      map[string]int         `toml:"event_weights"`  // Overrides per event; 0 disables
+	EventTable        map[string]EventTuning `toml:"event_table"`    // Overrides the numbers of the events named
+	PowerCosts        PowerCostTable         `toml:"power_costs"`    // Overrides the costs a difficulty scales
+	Dependencies      []Dependency           `toml:"dependencies"`   // Replaces the default cascade graph when set
+	Actions           ActionRules            `toml:"actions"`        // Which systems each command works on
+	Divert            DivertConfig           `toml:"divert"`         // Efficiency and confirmation of transfers
+	Phases            []Phase                `toml:"phases"`         // Stretches of a timed run; empty for none
+	Thermal           ThermalConfig          `toml:"thermal"`
+	Conditions        ConditionsConfig       `toml:"conditions"` // Weather and grid demand
+	Chat              ChatConfig             `toml:"chat"`       // Viewers vote on the random events when set
+	Spectators        SpectatorConfig        `toml:"spectators"` // Messages from the spectators of the status API
+	Inspections       InspectionConfig       `toml:"inspections"`
+	Sound             SoundConfig            `toml:"sound"`
+	Aliases           map[string]string      `toml:"aliases"`  // Short names for commands, such as st = "stabilize"
+	Verbs             map[string]string      `toml:"verbs"`    // Other words for commands, such as purge = "vent"
+	Adaptive          AdaptiveConfig         `toml:"adaptive"` // Bounds of the adaptive mutator
+	Keys              KeysConfig             `toml:"keys"`     // Hotkeys and cooldowns
+}
+
+// DefaultConfig returns the classic five-system reactor.
+func DefaultConfig() Config {
+	return Config{
+		NumSystems:        5,
+		SystemNames:       []string{"Coolant Flow", "Pressure Ctrl", "Core Temp", "Shield Integrity", "Power Output"},
+		MaxSystemValue:    100,
+		MinSystemValue:    0,
+		CriticalThreshold: 20,
+		WarningThreshold:  50,
+		StabilizeTime:     5 * time.Second,
+		StabilizeMinigame: false,
+		GameDuration:      3 * time.Minute, // 3 minutes to survive
+		EventIntervalMin:  8 * time.Second,
+		EventIntervalMax:  15 * time.Second,
+		DegradationTick:   750 * time.Millisecond,
+		InitialRepairKits: 3,
+		RebootTime:        10 * time.Second,
+		RebootPercent:     30,
+		Confirm:           true,
+		Dependencies:      defaultDependencies(),
+		Actions:           defaultActions(),
+		Divert:            defaultDivert(),
+		Phases:            defaultPhases(),
+		Thermal:           defaultThermal(),
+		Conditions:        defaultConditions(),
+		Chat:              ChatConfig{Choices: 3},
+		Spectators:        defaultSpectators(),
+		Inspections:       defaultInspections(),
+		Sound:             defaultSound(),
+		Adaptive:          defaultAdaptive(),
+		Keys:              defaultKeys(),
+	}
+}
+
+// LoadConfig reads a TOML config file on top of the defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return cfg, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return cfg, fmt.Errorf("unknown config key %q", undecoded[0].String())
+	}
+	completeEventTable(md, cfg.EventTable)
+	// A custom name list or roster without an explicit count defines the
+	// layout.
+	switch {
+	case md.IsDefined("systems") && !md.IsDefined("num_systems"):
+		cfg.setSystems(cfg.Systems, cfg.SystemNames)
+	case md.IsDefined("system_names") && !md.IsDefined("num_systems"):
+		cfg.NumSystems = len(cfg.SystemNames)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Validate rejects configs the game logic cannot run with.
+func (c *Config) Validate() error {
+	switch {
+	case c.NumSystems < 2 || c.NumSystems > MaxSystems:
+		return fmt.Errorf("num_systems must be within 2-%d, got %d", MaxSystems, c.NumSystems)
+	case len(c.SystemNames) < c.NumSystems:
+		return fmt.Errorf("system_names lists %d names for %d systems", len(c.SystemNames), c.NumSystems)
+	case !(c.MinSystemValue < c.CriticalThreshold && c.CriticalThreshold < c.WarningThreshold && c.WarningThreshold < c.MaxSystemValue):
+		return fmt.Errorf("thresholds must satisfy min < critical < warning < max")
+	case c.StabilizeTime <= 0 || c.GameDuration <= 0 || c.DegradationTick <= 0:
+		return fmt.Errorf("stabilize_time, game_duration and degradation_tick must be positive")
+	case c.EventIntervalMin <= 0 || c.EventIntervalMax < c.EventIntervalMin:
+		return fmt.Errorf("event intervals must satisfy 0 < event_interval_min <= event_interval_max")
+	case c.RebootTime <= 0 || c.RebootPercent <= 0 || c.RebootPercent > 100:
+		return fmt.Errorf("reboot_time must be positive and reboot_percent within 1-100")
+	case c.InitialRepairKits < 0:
+		return fmt.Errorf("initial_repair_kits cannot be negative")
+	}
+	if err := c.validateSystems(); err != nil {
+		return err
+	}
+	if err := c.Thermal.validate(); err != nil {
+		return err
+	}
+	if err := validateActions(c.Actions); err != nil {
+		return err
+	}
+	if err := c.Divert.validate(); err != nil {
+		return err
+	}
+	if err := validatePhases(c.Phases); err != nil {
+		return err
+	}
+	if err := validatePowerCosts(c.PowerCosts); err != nil {
+		return err
+	}
+	if err := c.Conditions.validate(); err != nil {
+		return err
+	}
+	if err := c.Chat.validate(); err != nil {
+		return err
+	}
+	if err := c.Spectators.validate(); err != nil {
+		return err
+	}
+	if err := c.Inspections.validate(); err != nil {
+		return err
+	}
+	if err := c.Sound.validate(); err != nil {
+		return err
+	}
+	if err := validateAliases(c.Aliases); err != nil {
+		return err
+	}
+	if err := validateVerbs(c.Verbs); err != nil {
+		return err
+	}
+	for verb := range c.Verbs {
+		if _, ok := c.Aliases[verb]; ok {
+			return fmt.Errorf("verbs: %q is already an alias", verb)
+		}
+	}
+	if err := c.Adaptive.validate(); err != nil {
+		return err
+	}
+	if err := c.Keys.validate(); err != nil {
+		return err
+	}
+	for _, dep := range c.Dependencies {
+		if err := dep.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateEventWeights(c.EventWeights); err != nil {
+		return err
+	}
+	return validateEventTable(c.EventTable)
+}
+
+// validateEventWeights checks the event weights of a config or scenario.
+func validateEventWeights(weights map[string]int) error {
+	for name, weight := range weights {
+		if _, ok := lookupEvent(name); !ok {
+			return fmt.Errorf("event_weights: unknown event %q", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("event_weights: %q weight cannot be negative", name)
+		}
+	}
+	return nil
+}
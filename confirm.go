@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// Confirmation of dangerous commands. A typed override, scram or large
+// divert first says what it risks and waits: CONFIRM within ConfirmTime runs
+// it, and anything else typed drops it. Commands that rules, the queue or a
+// bot run go ahead at once, and the config's confirm = false turns the step
+// off for experts.
+
+const (
+	ConfirmTime = 5 * time.Second
+	ConfirmWord = "confirm"
+)
+
+// pendingConfirm is a typed command line awaiting its confirmation.
+type pendingConfirm struct {
+	input    string
+	deadline time.Duration // Elapsed game time CONFIRM is due by
+}
+
+// confirmed reports whether the command running may go ahead. A typed one
+// may not while confirmations are on: it is held, and warning is logged
+// with the request to CONFIRM it.
+func (g *Game) confirmed(warning string) bool {
+	if g.typed == "" || !g.Config.Confirm {
+		return true
+	}
+	deadline := g.Elapsed() + ConfirmTime
+	g.mu.Lock()
+	g.confirm = &pendingConfirm{input: g.typed, deadline: deadline}
+	g.mu.Unlock()
+	g.AddLog(LogWarning, tr("%s Type CONFIRM within %.0fs.", warning, ConfirmTime.Seconds()))
+	return false
+}
+
+// confirmHelp is the help line on whether a dangerous command waits for
+// CONFIRM.
+func (g *Game) confirmHelp() string {
+	if !g.Config.Confirm {
+		return tr("Runs at once: confirmations are off.")
+	}
+	return tr("Typed, it first waits for CONFIRM within %.0fs.", ConfirmTime.Seconds())
+}
+
+// interceptConfirm checks input against the command awaiting confirmation
+// before it reaches normal command parsing. It returns the command line to
+// run when input confirms it in time, and reports whether input was
+// consumed. Any other input drops the held command.
+func (g *Game) interceptConfirm(input string) (string, bool) {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	pending := g.confirm
+	g.confirm = nil
+	g.mu.Unlock()
+	if !strings.EqualFold(strings.TrimSpace(input), ConfirmWord) {
+		return "", false
+	}
+	switch {
+	case pending == nil:
+		g.AddLog(LogInfo, tr("Nothing to confirm."))
+		return "", true
+	case elapsed > pending.deadline:
+		g.AddLog(LogWarning, tr("Too late to confirm %s: Type it again.", pending.input))
+		return "", true
+	}
+	return pending.input, true
+}
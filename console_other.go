@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+// enableANSI reports that the terminal draws ANSI escapes: every terminal
+// outside Windows does.
+func enableANSI() bool { return true }
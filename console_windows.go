@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableANSI turns on virtual terminal processing for the console, so it
+// draws the ANSI escapes itself, and reports whether it could: older
+// consoles and redirected output cannot.
+func enableANSI() bool {
+	console := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(console, &mode); err != nil {
+		return false
+	}
+	return windows.SetConsoleMode(console, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
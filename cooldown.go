@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Commands with a cooldown between uses.
+const (
+	CommandDivert   = "divert"
+	CommandVent     = "vent"
+	CommandOverride = "override"
+)
+
+// commandCooldowns is how long each command is locked out after use, unless
+// the config's [keys.cooldowns] says otherwise.
+var commandCooldowns = map[string]time.Duration{
+	CommandDivert:   8 * time.Second,
+	CommandVent:     20 * time.Second,
+	CommandOverride: 45 * time.Second,
+}
+
+// cooldownLeft returns how long until command may be used again.
+func (g *Game) cooldownLeft(command string) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	left := g.cooldownUntil[command].Sub(g.clock.Now())
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// startCooldown locks command out for its configured cooldown.
+func (g *Game) startCooldown(command string) {
+	g.setCooldown(command, g.Config.Keys.cooldown(command))
+}
+
+func (g *Game) setCooldown(command string, d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cooldownUntil == nil {
+		g.cooldownUntil = make(map[string]time.Time)
+	}
+	g.cooldownUntil[command] = g.clock.Now().Add(d)
+}
+
+// onCooldown logs a refusal and returns true while command is cooling down.
+func (g *Game) onCooldown(command string) bool {
+	left := g.cooldownLeft(command)
+	if left <= 0 {
+		return false
+	}
+	g.AddLog(LogWarning, tr("Cannot %s: On cooldown for %ds.", command, ceilSeconds(left)))
+	return true
+}
+
+// cooldownTag renders the remaining cooldown for the command help area.
+func (s GameState) cooldownTag(command string) string {
+	left := s.Cooldowns[command]
+	if left <= 0 {
+		return ""
+	}
+	return theme.Warning.Sprint(tr(" [cooldown %ds]", ceilSeconds(left)))
+}
+
+// cooldownState returns the remaining cooldowns for saving.
+func (g *Game) cooldownState() map[string]time.Duration {
+	var state map[string]time.Duration
+	for command := range commandCooldowns {
+		if left := g.cooldownLeft(command); left > 0 {
+			if state == nil {
+				state = make(map[string]time.Duration)
+			}
+			state[command] = left
+		}
+	}
+	return state
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// describeCooldown is the help text suffix naming a cooldown of d.
+func describeCooldown(d time.Duration) string {
+	return tr("%ds cooldown", int(d.Seconds()))
+}
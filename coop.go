@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Co-op protocol. The host plays in their own terminal and accepts up to
+// MaxCrew-1 more players over TCP, all working the same reactor. Messages are
+// JSON, one object per line. A joining client opens with its name and then
+// sends the lines its player types:
+//
+//	{"name": "alice"}
+//	{"command": "stabilize 2"}
+//
+// The host redraws the client's dashboard whenever it changes, or explains
+// why it turned the player away:
+//
+//	{"screen": "..."}
+//	{"error": "the crew is full (4 players)"}
+//
+// The host runs every command on the game loop, so the crew's commands never
+// interleave. Each player is an Operator with their own timed action, queue
+// and cooldowns; the reactor, the inventory and the score are shared.
+const (
+	MaxCrew     = 4        // Players per reactor, the host included
+	CoopMaxLine = 64 << 10 // Longest message accepted
+)
+
+// CoopMessage is a message from a crew member to the host.
+type CoopMessage struct {
+	Name    string `json:"name,omitempty"`    // The first message: who is joining
+	Command string `json:"command,omitempty"` // A line the player typed
+}
+
+// CoopFrame is a message from the host to a crew member.
+type CoopFrame struct {
+	Screen string `json:"screen,omitempty"` // The whole dashboard, to replace the last one
+	Error  string `json:"error,omitempty"`  // Why the player was turned away
+}
+
+// serveCoop accepts crew members on addr.
+func (g *Game) serveCoop(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return // Closed as the game exits
+			}
+			go g.coopSession(conn)
+		}
+	}()
+	return ln, nil
+}
+
+// coopSession serves one crew member until they quit or hang up.
+func (g *Game) coopSession(conn net.Conn) {
+	defer conn.Close()
+	scanner, send := coopStream(conn)
+	name, ok := readHello(scanner, send)
+	if !ok {
+		return
+	}
+	op, err := g.join(name)
+	if err != nil {
+		send(CoopFrame{Error: err.Error()})
+		return
+	}
+	defer g.leave(op)
+	g.crewSession(op, scanner, send)
+}
+
+// coopStream wraps a crew member's connection: scanner reads their messages
+// and send writes a frame, from any goroutine.
+func coopStream(conn net.Conn) (*bufio.Scanner, func(CoopFrame) bool) {
+	var mu sync.Mutex // The frames and a rejection share the connection
+	send := func(frame CoopFrame) bool {
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = conn.Write(append(data, '\n'))
+		return err == nil
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), CoopMaxLine)
+	return scanner, send
+}
+
+// readHello reads the name a joining player opens with.
+func readHello(scanner *bufio.Scanner, send func(CoopFrame) bool) (string, bool) {
+	if !scanner.Scan() {
+		return "", false
+	}
+	var hello CoopMessage
+	if err := json.Unmarshal(scanner.Bytes(), &hello); err != nil {
+		send(CoopFrame{Error: tr("invalid message: %v", err)})
+		return "", false
+	}
+	if !profileNamePattern.MatchString(hello.Name) {
+		send(CoopFrame{Error: tr("invalid name %q (letters, digits, - and _ only)", hello.Name)})
+		return "", false
+	}
+	return hello.Name, true
+}
+
+// crewSession runs op's commands and redraws their dashboard until they quit
+// or hang up.
+func (g *Game) crewSession(op *Operator, scanner *bufio.Scanner, send func(CoopFrame) bool) {
+	done := make(chan struct{})
+	defer close(done)
+	go g.pushFrames(op, done, send)
+	for scanner.Scan() {
+		var msg CoopMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if !g.crewInput(op, msg.Command) {
+			return
+		}
+	}
+}
+
+// join adds a crew member called name, numbering it if the name is taken.
+func (g *Game) join(name string) (*Operator, error) {
+	var op *Operator
+	var err error
+	g.call(func() {
+		g.locked(func() {
+			switch {
+			case g.GameOver || g.GameWon:
+				err = errors.New("the game has ended")
+			case len(g.operators) >= MaxCrew:
+				err = fmt.Errorf("the crew is full (%d players)", MaxCrew)
+			default:
+				op = &Operator{Name: g.freeNameLocked(name), ActionSystem: -1}
+				g.operators = append(g.operators, op)
+			}
+		})
+		if op != nil {
+			g.AddLog(LogEvent, tr("CREW: %s joined the control room.", op.Name))
+		}
+	})
+	if op == nil && err == nil {
+		err = errors.New("the game has ended")
+	}
+	return op, err
+}
+
+// freeNameLocked returns name, or name-2, name-3... if it is taken.
+func (g *Game) freeNameLocked(name string) string {
+	taken := func(candidate string) bool {
+		for _, op := range g.operators {
+			if strings.EqualFold(op.Name, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+	free := name
+	for n := 2; taken(free); n++ {
+		free = fmt.Sprintf("%s-%d", name, n)
+	}
+	return free
+}
+
+// leave removes a crew member. An action they started still completes; a
+// command they queued is dropped.
+func (g *Game) leave(op *Operator) {
+	g.call(func() {
+		g.mu.Lock()
+		for i, member := range g.operators {
+			if member == op {
+				g.operators = append(g.operators[:i:i], g.operators[i+1:]...)
+				break
+			}
+		}
+		g.mu.Unlock()
+		g.AddLog(LogEvent, tr("CREW: %s left the control room.", op.Name))
+	})
+}
+
+// crewInput handles a line typed by a crew member the way the host's terminal
+// handles the host's. It returns false once the member quits.
+func (g *Game) crewInput(op *Operator, input string) bool {
+	input = strings.TrimSpace(input)
+	parts := strings.Fields(strings.ToLower(g.resolveInputVerb(input)))
+	if len(parts) == 0 {
+		return true
+	}
+	switch {
+	case parts[0] == "quit":
+		return false
+	case g.ended():
+		return true // Only quit is left
+	}
+	consumed := false
+	g.call(func() { consumed = g.interceptCode(input) }) // Any member may type an authorization code
+	if !consumed {
+		g.SubmitAs(op, input)
+	}
+	return true
+}
+
+// pushFrames redraws op's dashboard every WatchInterval when it has changed,
+// until done is closed or send fails.
+func (g *Game) pushFrames(op *Operator, done <-chan struct{}, send func(CoopFrame) bool) {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	var last []byte
+	for {
+		var screen bytes.Buffer
+		if g.ended() {
+			g.DisplaySummary(&screen)
+			fmt.Fprintln(&screen, theme.Title.Sprint(tr("Game has ended. Type 'quit' to leave.")))
+		} else {
+			g.DisplayFor(&screen, op, DefaultScreen)
+		}
+		if !bytes.Equal(screen.Bytes(), last) {
+			if !send(CoopFrame{Screen: screen.String()}) {
+				return
+			}
+			last = screen.Bytes()
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// joinCoop plays as name in the co-op game hosted at addr, until the player
+// quits or the host goes away.
+func joinCoop(addr, name string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	enc := json.NewEncoder(conn) // Only the input goroutine writes after the greeting
+	if err := enc.Encode(CoopMessage{Name: name}); err != nil {
+		return err
+	}
+	go func() {
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				conn.Close() // Out of input: leave the crew
+				return
+			}
+			if enc.Encode(CoopMessage{Command: strings.TrimSpace(line)}) != nil {
+				return
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64<<10), CoopMaxLine)
+	joined := false
+	for scanner.Scan() {
+		var frame CoopFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return fmt.Errorf("invalid message from the host: %v", err)
+		}
+		if frame.Error != "" {
+			return errors.New(frame.Error)
+		}
+		joined = true
+		fmt.Fprint(color.Output, frame.Screen)
+	}
+	if !joined {
+		return errors.New("the host closed the connection")
+	}
+	fmt.Fprintln(color.Output, theme.Title.Sprint(tr("\nDisconnected from the host.")))
+	return nil
+}
+
+// crewPanel renders what every crew member is doing, or "" when playing alone.
+func (s GameState) crewPanel() string {
+	var lines []string
+	for _, member := range s.Crew {
+		doing := theme.Dim.Sprint(tr("idle"))
+		if member.Action != "" {
+			doing = tr("%s (%.1fs left)", member.Action, member.ActionLeft.Seconds())
+		}
+		lines = append(lines, fmt.Sprintf("  %-12s %s", member.Name, doing))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CrewState is what one crew member is busy with.
+type CrewState struct {
+	Name       string        `json:"name"`
+	Action     string        `json:"action,omitempty"`
+	ActionLeft time.Duration `json:"action_left_ns,omitempty"`
+}
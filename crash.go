@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Interrupts and crashes. Ctrl+C or a SIGTERM ends a session the way quit
+// does, with the final display and the journal and records written out, and
+// a run cut short that way goes to EmergencySaveFile, among the profile's
+// saves, to be resumed with --load. A panic in a game loop is recovered into
+// CrashReportFile, in the app data directory, and the same emergency save
+// before the session shuts down. Both are written only where the player sits
+// at this machine: not for a session over SSH.
+
+const (
+	EmergencySaveFile = "reactor_emergency.json"
+	CrashReportFile   = "reactor_crash.txt"
+	CrashSaveTimeout  = 2 * time.Second // In case the state is still locked
+)
+
+// interruptReader passes input through, and reports a Ctrl+C read from a
+// terminal in raw mode, where it raises no signal, on interrupt.
+type interruptReader struct {
+	r         io.Reader
+	interrupt chan<- os.Signal
+}
+
+func (ir interruptReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	for _, b := range p[:n] {
+		if b == keyCtrlC {
+			select {
+			case ir.interrupt <- os.Interrupt:
+			default: // One is already pending
+			}
+		}
+	}
+	return n, err
+}
+
+// recoverCrash recovers a panic of g's game loop, writes the crash report
+// where the player sits at this machine, and sends the note for the player
+// on crashed. It must be deferred.
+func (g *Game) recoverCrash(crashed chan<- string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	if !g.local {
+		crashed <- tr("CRASH: The game loop failed (%v).", r)
+		return
+	}
+	var report strings.Builder
+	fmt.Fprintf(&report, "Reactor Meltdown crash report, %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&report, "Seed %d, difficulty %s, mode %s", g.Seed, g.Difficulty.Name, g.Mode)
+	if g.Unit > 0 {
+		fmt.Fprintf(&report, ", unit %d", g.Unit)
+	}
+	if g.Scenario != nil {
+		fmt.Fprintf(&report, ", scenario %s", g.Scenario.Name)
+	}
+	fmt.Fprintf(&report, "\n\npanic: %v\n\n%s", r, stack)
+	dir, err := appDataDir()
+	path := filepath.Join(dir, CrashReportFile)
+	if err == nil {
+		err = os.WriteFile(path, []byte(report.String()), 0o644)
+	}
+	if err != nil {
+		crashed <- tr("CRASH: The game loop failed (%v), and the crash report could not be written: %v", r, err)
+		return
+	}
+	crashed <- tr("CRASH: The game loop failed (%v). The crash report is in %s.", r, path)
+}
+
+// locked runs f holding g.mu. The game loop takes its locks this way, or
+// with a deferred Unlock, wherever it could panic while holding them: the
+// panic then lets go of them on its way out, and the session can still
+// shut down. Sections that only copy fields or add up numbers, which cannot
+// panic, unlock in place.
+func (g *Game) locked(f func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f()
+}
+
+// locked runs f holding s.mu, as Game.locked does g.mu.
+func (s *System) locked(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f()
+}
+
+// emergencySave saves a run still under way to EmergencySaveFile, once its
+// game loop has stopped, and returns the note for the player, or "" when
+// there is nothing to save. The file goes among the profile's saves, or in
+// the app data directory outside the player's own run.
+func (g *Game) emergencySave() string {
+	if !g.saveable() {
+		return ""
+	}
+	dir := g.savesDir
+	if dir == "" {
+		var err error
+		if dir, err = appDataDir(); err != nil {
+			return tr("Emergency save failed: %v", err)
+		}
+	}
+	path := filepath.Join(dir, EmergencySaveFile)
+	saved := make(chan string, 1)
+	go func() {
+		if g.ended() {
+			saved <- ""
+		} else if err := g.SaveToFile(path); err != nil {
+			saved <- tr("Emergency save failed: %v", err)
+		} else {
+			saved <- tr("Run saved to %s. Resume it with --load %s.", path, path)
+		}
+	}()
+	select {
+	case note := <-saved:
+		return note
+	case <-time.After(CrashSaveTimeout):
+		return tr("Emergency save failed: The game state is locked.")
+	}
+}
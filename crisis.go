@@ -0,0 +1,357 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	CrisisStageTime  = 25 * time.Second // Time to complete each stage before damage escalates
+	CrisisEscalation = 10 * time.Second // Gap between escalating hits once a stage is overdue
+	CrisisDamage     = 4                // Damage per victim on the first hit, growing with every hit
+	CrisisBlast      = 10               // Damage per victim when the incident breaks out
+)
+
+// Crisis response commands. A stage names the command that completes it.
+const (
+	CrisisSuppress = "suppress"
+	CrisisIsolate  = "isolate"
+	CrisisRestart  = "restart"
+)
+
+// crisisStage is one step of a major incident. When system is set the
+// command must name that system's ID; layouts without it use the system the
+// event was aimed at.
+type crisisStage struct {
+	command string
+	system  string
+	task    string // Shown on the crisis panel
+}
+
+// crisisEvent is a rare major incident. Instead of a one-off hit it opens a
+// crisis the player must work through stage by stage.
+type crisisEvent struct {
+	name    string
+	weight  int
+	title   string
+	victims []string // Systems hurt by the outbreak and every escalation
+	stages  []crisisStage
+}
+
+func (e *crisisEvent) Name() string                  { return e.name }
+func (e *crisisEvent) Weight() int                   { return e.weight }
+func (e *crisisEvent) Apply(g *Game, target *System) { g.startCrisis(e, target) }
+
+var turbineHallFire = &crisisEvent{
+	name:    "turbine_hall_fire",
+	weight:  1,
+	title:   "TURBINE HALL FIRE",
+	victims: []string{"Power Output", "Shield Integrity"},
+	stages: []crisisStage{
+		{CrisisSuppress, "", "Suppress the fire"},
+		{CrisisIsolate, "Shield Integrity", "Isolate the burnt shield bus"},
+		{CrisisRestart, "Power Output", "Restart the turbine"},
+	},
+}
+
+var primaryLoopRupture = &crisisEvent{
+	name:    "primary_loop_rupture",
+	weight:  1,
+	title:   "PRIMARY LOOP RUPTURE",
+	victims: []string{"Coolant Flow", "Pressure Ctrl"},
+	stages: []crisisStage{
+		{CrisisIsolate, "Coolant Flow", "Isolate the ruptured loop"},
+		{CrisisSuppress, "", "Suppress the steam release"},
+		{CrisisRestart, "Pressure Ctrl", "Restart pressure control"},
+	},
+}
+
+// Crisis is a major incident in progress.
+type Crisis struct {
+	event    *crisisEvent
+	targets  []*System // Per stage, the system its command must name, or nil
+	victims  []*System
+	stage    int
+	deadline time.Time // When the current stage becomes overdue
+	nextHit  time.Time // Next escalation once overdue
+	hits     int       // Escalations during the current stage
+}
+
+// startCrisis opens a crisis for ev. Only one crisis runs at a time; a second
+// incident during a crisis compounds it into an immediate escalation.
+func (g *Game) startCrisis(ev *crisisEvent, target *System) {
+	g.mu.Lock()
+	active := g.crisis
+	g.mu.Unlock()
+	if active != nil {
+		g.AddLog(LogCritical, tr("MAJOR INCIDENT: %s compounds the ongoing crisis!", ev.title))
+		g.escalateCrisis(active)
+		return
+	}
+
+	c := &Crisis{event: ev, targets: make([]*System, len(ev.stages))}
+	for i, stage := range ev.stages {
+		if stage.system != "" {
+			c.targets[i] = g.systemNamed(stage.system, target)
+		}
+	}
+	seen := make(map[*System]bool)
+	for _, name := range ev.victims {
+		if sys := g.systemNamed(name, target); !seen[sys] {
+			seen[sys] = true
+			c.victims = append(c.victims, sys)
+		}
+	}
+	c.deadline = g.clock.Now().Add(CrisisStageTime)
+	c.nextHit = c.deadline
+
+	g.mu.Lock()
+	g.crisis = c
+	g.mu.Unlock()
+	g.AddLog(LogCritical, tr("MAJOR INCIDENT: %s! Follow the crisis procedure.", ev.title))
+	blast := g.tuning(ev.name).Damage
+	for _, sys := range c.victims {
+		g.harmLogged(sys, g.rollRange(blast))
+	}
+}
+
+// escalateCrisis deals the next, heavier round of crisis damage.
+func (g *Game) escalateCrisis(c *Crisis) {
+	g.mu.Lock()
+	c.hits++
+	hits := c.hits
+	g.mu.Unlock()
+	damage := g.rollRange(g.tuning(c.event.name).Secondary) * hits
+	g.AddLog(LogCritical, tr("CRISIS ESCALATING: %s is spreading!", strings.ToLower(tr(c.event.title))))
+	for _, sys := range c.victims {
+		g.harmLogged(sys, damage)
+	}
+}
+
+// updateCrisis escalates an overdue crisis. It runs from the event loop.
+func (g *Game) updateCrisis() {
+	now := g.clock.Now()
+	g.mu.Lock()
+	c := g.crisis
+	due := c != nil && !now.Before(c.nextHit)
+	if due {
+		c.nextHit = now.Add(CrisisEscalation)
+	}
+	g.mu.Unlock()
+	if due {
+		g.escalateCrisis(c)
+	}
+}
+
+// handleCrisis runs a crisis response command. Wrong steps cost nothing but
+// time.
+func (g *Game) handleCrisis(command string, args []string) {
+	g.mu.Lock()
+	c := g.crisis
+	g.mu.Unlock()
+	if c == nil {
+		g.AddLog(LogWarning, tr("Cannot %s: No major incident in progress.", command))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot %s: Player busy with another action.", command))
+		return
+	}
+
+	var stage crisisStage
+	var target *System
+	g.locked(func() {
+		stage = c.event.stages[c.stage]
+		target = c.targets[c.stage]
+	})
+	if command != stage.command {
+		g.AddLog(LogWarning, tr("Wrong step: The procedure calls for '%s' next (%s).", crisisCommand(stage, target), stage.task))
+		return
+	}
+	if target != nil {
+		if len(args) == 0 {
+			g.AddLog(LogInfo, tr("Usage: %s <system_id>", command))
+			return
+		}
+		if sysID, err := strconv.Atoi(args[0]); err != nil || sysID != target.ID {
+			g.AddLog(LogWarning, tr("Wrong system: %s must target %s (%d).", command, target.Name, target.ID))
+			return
+		}
+	}
+
+	done := false
+	g.locked(func() {
+		c.stage++
+		done = c.stage == len(c.event.stages)
+		if done {
+			g.crisis = nil
+		} else {
+			c.deadline = g.clock.Now().Add(CrisisStageTime)
+			c.nextHit = c.deadline
+			c.hits = 0
+		}
+	})
+	if done {
+		g.AddLog(LogSuccess, tr("CRISIS RESOLVED: %s is under control. Success!", strings.ToLower(tr(c.event.title))))
+		return
+	}
+	g.AddLog(LogSuccess, tr("Crisis step complete: %s. Next: %s.", stage.task, c.event.stages[c.stage].task))
+}
+
+// crisisCommand spells out the command that completes stage.
+func crisisCommand(stage crisisStage, target *System) string {
+	if target == nil {
+		return stage.command
+	}
+	return fmt.Sprintf("%s %d", stage.command, target.ID)
+}
+
+// crisisStep returns the command that completes the current crisis stage,
+// or "" if there is no crisis.
+func (g *Game) crisisStep() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.crisis == nil {
+		return ""
+	}
+	return crisisCommand(g.crisis.event.stages[g.crisis.stage], g.crisis.targets[g.crisis.stage])
+}
+
+// CrisisState is a snapshot of the crisis procedure.
+type CrisisState struct {
+	Title    string        `json:"title"`
+	Steps    []CrisisStep  `json:"steps"`
+	Stage    int           `json:"stage"`
+	Deadline time.Duration `json:"deadline_ns"` // Negative once overdue
+	NextHit  time.Duration `json:"next_hit_ns"`
+}
+
+// CrisisStep is one stage of a crisis procedure.
+type CrisisStep struct {
+	Command string `json:"command"` // As the player types it
+	Task    string `json:"task"`
+}
+
+// crisisSnapshotLocked copies the crisis at now, or returns nil if there is
+// none. The caller must hold g.mu.
+func (g *Game) crisisSnapshotLocked(now time.Time) *CrisisState {
+	c := g.crisis
+	if c == nil {
+		return nil
+	}
+	s := &CrisisState{Title: c.event.title, Stage: c.stage, Deadline: c.deadline.Sub(now), NextHit: c.nextHit.Sub(now)}
+	for i, stage := range c.event.stages {
+		s.Steps = append(s.Steps, CrisisStep{Command: crisisCommand(stage, c.targets[i]), Task: stage.task})
+	}
+	return s
+}
+
+// crisisPanel renders the crisis procedure for the dashboard, or "" if there
+// is no crisis.
+func (s GameState) crisisPanel() string {
+	c := s.Crisis
+	if c == nil {
+		return ""
+	}
+	lines := []string{theme.Inverse.Sprint(tr(" MAJOR INCIDENT: %s ", tr(c.Title)))}
+	for i, stage := range c.Steps {
+		step := fmt.Sprintf("%-12s %s", stage.Command, tr(stage.Task))
+		switch {
+		case i < c.Stage:
+			lines = append(lines, theme.OK.Sprintf("  [x] %s", step))
+		case i > c.Stage:
+			lines = append(lines, fmt.Sprintf("  [ ] %s", step))
+		case c.Deadline > 0:
+			lines = append(lines, theme.Notice.With(color.Bold).Sprint(tr("  [>] %s  (%ds left)", step, ceilSeconds(c.Deadline))))
+		default:
+			lines = append(lines, theme.Alert.With(color.Bold).Sprint(tr("  [>] %s  (OVERDUE: next hit in %ds)", step, ceilSeconds(c.NextHit))))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SavedCrisis is the serialized form of a Crisis.
+type SavedCrisis struct {
+	Event    string        `json:"event"`
+	Targets  []int         `json:"targets"` // Per stage, -1 when the stage names no system
+	Victims  []int         `json:"victims"`
+	Stage    int           `json:"stage"`
+	Deadline time.Duration `json:"deadline_ns"` // Negative once overdue
+	NextHit  time.Duration `json:"next_hit_ns"`
+	Hits     int           `json:"hits,omitempty"`
+}
+
+// crisisStateLocked captures the crisis for a save, or nil. The caller
+// holds g.mu.
+func (g *Game) crisisStateLocked(now time.Time) *SavedCrisis {
+	c := g.crisis
+	if c == nil {
+		return nil
+	}
+	sc := &SavedCrisis{
+		Event:    c.event.name,
+		Stage:    c.stage,
+		Deadline: c.deadline.Sub(now),
+		NextHit:  c.nextHit.Sub(now),
+		Hits:     c.hits,
+	}
+	for _, sys := range c.targets {
+		id := -1
+		if sys != nil {
+			id = sys.ID
+		}
+		sc.Targets = append(sc.Targets, id)
+	}
+	for _, sys := range c.victims {
+		sc.Victims = append(sc.Victims, sys.ID)
+	}
+	return sc
+}
+
+// restoreCrisis rebuilds a saved crisis against this game's systems.
+func (g *Game) restoreCrisis(sc SavedCrisis, now time.Time) (*Crisis, error) {
+	ev, ok := lookupEvent(sc.Event)
+	crisis, isCrisis := ev.(*crisisEvent)
+	if !ok || !isCrisis {
+		return nil, fmt.Errorf("save has unknown crisis %q", sc.Event)
+	}
+	if len(sc.Targets) != len(crisis.stages) || sc.Stage < 0 || sc.Stage >= len(crisis.stages) {
+		return nil, fmt.Errorf("save has invalid %s crisis", sc.Event)
+	}
+	system := func(id int) (*System, error) {
+		if id < 0 || id >= len(g.Systems) {
+			return nil, fmt.Errorf("save has crisis on invalid system %d", id)
+		}
+		return g.Systems[id], nil
+	}
+	c := &Crisis{
+		event:    crisis,
+		targets:  make([]*System, len(sc.Targets)),
+		stage:    sc.Stage,
+		deadline: now.Add(sc.Deadline),
+		nextHit:  now.Add(sc.NextHit),
+		hits:     sc.Hits,
+	}
+	for i, id := range sc.Targets {
+		if id == -1 {
+			continue
+		}
+		sys, err := system(id)
+		if err != nil {
+			return nil, err
+		}
+		c.targets[i] = sys
+	}
+	for _, id := range sc.Victims {
+		sys, err := system(id)
+		if err != nil {
+			return nil, err
+		}
+		c.victims = append(c.victims, sys)
+	}
+	return c, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Difficulty scales the base tunables for a run.
+type Difficulty struct {
+	Name        string
+	TickScale   float64 // Multiplies DegradationTick; lower means faster decay
+	EventScale  float64 // Multiplies the event interval; lower means more events
+	KitBonus    int     // Added to the configured starting repair kits
+	DamageScale float64 // Multiplies damage dealt by events and risky actions
+	PowerScale  float64 // Multiplies the power commands draw
+}
+
+var difficulties = map[string]Difficulty{
+	"easy":      {Name: "easy", TickScale: 1.4, EventScale: 1.5, KitBonus: 2, DamageScale: 0.7, PowerScale: 0.5},
+	"normal":    {Name: "normal", TickScale: 1.0, EventScale: 1.0, KitBonus: 0, DamageScale: 1.0, PowerScale: 1.0},
+	"hard":      {Name: "hard", TickScale: 0.8, EventScale: 0.75, KitBonus: -1, DamageScale: 1.3, PowerScale: 1.5},
+	"nightmare": {Name: "nightmare", TickScale: 0.6, EventScale: 0.5, KitBonus: -2, DamageScale: 1.6, PowerScale: 2.0},
+}
+
+// DifficultyByName looks up a difficulty level, case-insensitively.
+func DifficultyByName(name string) (Difficulty, error) {
+	d, ok := difficulties[strings.ToLower(name)]
+	if !ok {
+		return Difficulty{}, fmt.Errorf("unknown difficulty %q (choose %s)", name, strings.Join(difficultyNames(), "|"))
+	}
+	return d, nil
+}
+
+func difficultyNames() []string {
+	names := make([]string, 0, len(difficulties))
+	for name := range difficulties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return difficulties[names[i]].DamageScale < difficulties[names[j]].DamageScale
+	})
+	return names
+}
+
+// startingKits applies the difficulty's kit bonus to the configured count.
+func (d Difficulty) startingKits(base int) int {
+	if kits := base + d.KitBonus; kits > 0 {
+		return kits
+	}
+	return 0
+}
+
+func (g *Game) degradationTick() time.Duration {
+	return time.Duration(float64(g.Config.DegradationTick) * g.Difficulty.TickScale / g.phaseDegradation())
+}
+
+// nextEventDelay picks a random wait before the next event.
+func (g *Game) nextEventDelay() time.Duration {
+	scale := g.Difficulty.EventScale * g.mutatorEventScale()
+	min := float64(g.Config.EventIntervalMin) * scale
+	max := float64(g.Config.EventIntervalMax) * scale
+	return time.Duration(min + g.rng.Float64()*(max-min))
+}
+
+// scaleDamage applies the difficulty damage multiplier, any endless-mode
+// escalation, mutators and overcharged shields, never returning less than 1.
+func (g *Game) scaleDamage(damage int) int {
+	scale := g.Difficulty.DamageScale * g.severityScale() * g.mutatorDamageScale() * g.upgradeDamageScale()
+	if g.overchargeBuffActive(OverchargeShieldSystem) {
+		scale *= ShieldAbsorb
+	}
+	scaled := int(float64(damage)*scale + 0.5)
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
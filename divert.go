@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Divert transfers. Only Efficiency percent of a divert arrives, less the
+// loss of its route in the action matrix. A typed divert of Confirm or more
+// first shows what it would deliver, and waits for CONFIRM.
+
+// DivertConfig tunes divert transfers.
+type DivertConfig struct {
+	Efficiency int `toml:"efficiency"` // Percent of the amount that arrives
+	Confirm    int `toml:"confirm"`    // Amounts from this need confirming; 0 never
+}
+
+func defaultDivert() DivertConfig {
+	return DivertConfig{Efficiency: 80, Confirm: 20}
+}
+
+func (c DivertConfig) validate() error {
+	switch {
+	case c.Efficiency < 1 || c.Efficiency > 100:
+		return fmt.Errorf("divert: efficiency must be within 1-100")
+	case c.Confirm < 0:
+		return fmt.Errorf("divert: confirm cannot be negative")
+	}
+	return nil
+}
+
+// RouteLoss is the percent of a divert lost on its way from one system to
+// another, on top of the divert efficiency.
+type RouteLoss struct {
+	From string `toml:"from"`
+	To   string `toml:"to"`
+	Loss int    `toml:"loss"`
+}
+
+// divertShare is the percent of a divert from one system to another that
+// arrives.
+func (g *Game) divertShare(from, to int) int {
+	return max(0, g.Config.Divert.Efficiency-g.matrix.loss(from, to))
+}
+
+// divertDelivery is how much of amount a divert from one system to another
+// delivers.
+func (g *Game) divertDelivery(from, to, amount int) int {
+	return int(math.Round(float64(amount*g.divertShare(from, to)) / 100))
+}
+
+// divertConfirmHelp is the help line on which diverts need confirming.
+func (g *Game) divertConfirmHelp() string {
+	if g.Config.Divert.Confirm == 0 || !g.Config.Confirm {
+		return tr("Runs at once, whatever the amount.")
+	}
+	return tr("From %d up it first shows what it would deliver, and waits for CONFIRM.", g.Config.Divert.Confirm)
+}
+
+// divertConfirmed reports whether a divert may run: it is small, or it is
+// confirmed; see confirmed.
+func (g *Game) divertConfirmed(from, to, amount int) bool {
+	if g.Config.Divert.Confirm == 0 || amount < g.Config.Divert.Confirm {
+		return true
+	}
+	toSys := g.Systems[to]
+	return g.confirmed(tr("Divert %d delivers %d to %s (%d), %d%%.", amount, g.divertDelivery(from, to, amount), toSys.Name, to, g.divertShare(from, to)))
+}
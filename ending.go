@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// End-of-run animation. Once a run ends, before the summary screen, a
+// meltdown plays a blast that spreads from the core and shakes the screen,
+// and a win or a scram a shutdown that powers the systems down one by one.
+// Commands still go through while it plays, so quit leaves at once.
+
+const (
+	EndingFrame  = 80 * time.Millisecond
+	BlastTime    = 2400 * time.Millisecond
+	ShutdownStep = 400 * time.Millisecond  // Per system, at most
+	ShutdownTime = 2400 * time.Millisecond // For every system, at most
+	ShutdownHold = 1200 * time.Millisecond // On the last frame of a shutdown
+	blastRows    = 20                      // Rows the blast fills, at most
+	blastShake   = 0.7                     // Of BlastTime, the screen shakes for
+	blastClear   = 0.7                     // Of BlastTime, after which the smoke clears
+)
+
+// blastShakes are the columns the blast is shifted by, frame by frame; an
+// odd shift drops it a row too.
+var blastShakes = []int{0, 2, -1, 1, -2, 1, 0, -1}
+
+// ending is the animation of a run that has ended.
+type ending struct {
+	state GameState
+	start time.Time
+}
+
+// newEnding starts the animation of the run ended in s at now.
+func newEnding(s GameState, now time.Time) *ending {
+	return &ending{state: s, start: now}
+}
+
+// shutdown reports whether the run ended in a shutdown rather than a
+// meltdown.
+func (e *ending) shutdown() bool {
+	return e.state.GameWon || e.state.Scrammed
+}
+
+// step is how long each system takes to power down.
+func (e *ending) step() time.Duration {
+	return min(ShutdownStep, ShutdownTime/time.Duration(max(1, len(e.state.Systems))))
+}
+
+// over reports whether the animation has played out at now.
+func (e *ending) over(now time.Time) bool {
+	length := BlastTime
+	if e.shutdown() {
+		length = e.step()*time.Duration(len(e.state.Systems)) + ShutdownHold
+	}
+	return now.Sub(e.start) >= length
+}
+
+// draw renders the frame of the animation at now to fit size.
+func (e *ending) draw(w io.Writer, size ScreenSize, now time.Time) {
+	clearScreen(w)
+	var b strings.Builder
+	if e.shutdown() {
+		e.drawShutdown(&b, now.Sub(e.start))
+	} else {
+		e.drawBlast(&b, now.Sub(e.start), size)
+	}
+	writeFitted(w, b.String(), size.column())
+}
+
+// drawBlast draws the blast at t to w: rings of debris spreading from the
+// core at the center, smoke behind them, and the screen shaking at first.
+func (e *ending) drawBlast(w io.Writer, t time.Duration, size ScreenSize) {
+	width, height := min(size.Width, ScreenWidth)-4, min(size.Height-2, blastRows)
+	progress := float64(t) / float64(BlastTime)
+	shake := 0
+	if progress < blastShake {
+		shake = blastShakes[int(t/EndingFrame)%len(blastShakes)]
+	}
+	if shake%2 != 0 {
+		fmt.Fprintln(w)
+	}
+	glyphs := []string{"▒", "█", "▓", "░"}
+	if theme.ASCII {
+		glyphs = []string{"*", "#", "%", "."}
+	}
+	styles := []Style{theme.Warning, theme.Alert.With(color.Bold), theme.Critical, theme.Dim}
+	cx, cy := float64(width)/2, float64(height)/2
+	radius := progress * 1.3 * math.Hypot(cx/2, cy) // Columns count half, as a cell is twice as tall as wide
+	label := ""
+	if progress > 0.35 {
+		label = tr(" M E L T D O W N ")
+	}
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		line.WriteString(strings.Repeat(" ", 2+shake))
+		for x := 0; x < width; x++ {
+			if label != "" && y == height/2 && x == width/2-visibleLen(label)/2 {
+				line.WriteString(theme.Inverse.With(color.Bold).Sprint(label))
+				x += visibleLen(label) - 1
+				continue
+			}
+			depth := radius - math.Hypot((float64(x)-cx)/2, float64(y)-cy)
+			ring := -1
+			switch {
+			case depth < 0:
+			case depth < 1:
+				ring = 0 // The front of the blast
+			case depth < 2.5:
+				ring = 1
+			case depth < 5:
+				ring = 2
+			case (x*x+7*y*y+3*x*y)%10 < int(10*(progress-blastClear)/(1-blastClear)): // Clearing, a cell at a time
+			default:
+				ring = 3 // Smoke
+			}
+			if ring < 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(styles[ring].Sprint(glyphs[ring]))
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// drawShutdown draws the shutdown at t to w: the systems power down in
+// turn, their bars draining, until every one is safe.
+func (e *ending) drawShutdown(w io.Writer, t time.Duration) {
+	title := tr("SHIFT COMPLETE: CONTROLLED SHUTDOWN")
+	if e.state.Scrammed {
+		title = tr("EMERGENCY SHUTDOWN: SCRAM")
+	}
+	fmt.Fprintln(w, theme.Title.Sprint(title))
+	fmt.Fprintln(w)
+	step := e.step()
+	for i, sys := range e.state.Systems {
+		start := step * time.Duration(i)
+		on := 1.0 // Of the reading, left to power down
+		switch {
+		case t >= start+step:
+			on = 0
+		case t > start:
+			on = 1 - float64(t-start)/float64(step)
+		}
+		reading := 0
+		if !sys.Offline {
+			reading = int(float64(min(sys.Reading, sys.MaxValue)) * on)
+		}
+		fill := reading * BarWidth / max(1, sys.MaxValue)
+		bar := "[" + strings.Repeat("=", fill) + strings.Repeat("-", BarWidth-fill) + "]"
+		label := fmt.Sprintf("[%d] %-18s", sys.ID, tr(sys.Name))
+		switch {
+		case on == 0 || sys.Offline && t > start:
+			fmt.Fprintf(w, "%s: %s %s\n", theme.Dim.Sprint(label), theme.Dim.Sprint(bar), theme.Dim.Sprint(tr("SAFE")))
+		case t > start:
+			fmt.Fprintf(w, "%s: %s %s\n", label, theme.Notice.Sprint(bar), theme.Notice.Sprint(tr("POWERING DOWN")))
+		case sys.Offline:
+			fmt.Fprintf(w, "%s: %s %s\n", label, theme.Dim.Sprint(bar), theme.Inverse.Sprint(tr("OFFLINE")))
+		default:
+			fmt.Fprintf(w, "%s: %s %s\n", label, theme.OK.Sprint(bar), theme.OK.Sprintf("%3d", reading))
+		}
+	}
+	if t >= step*time.Duration(len(e.state.Systems)) {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, theme.Success.Sprint(tr("ALL SYSTEMS SAFE. The reactor is shut down.")))
+	}
+}
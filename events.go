@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Event is one kind of random or scripted incident. Apply receives the system
+// the event was aimed at; events that hit several systems pick their own.
+type Event interface {
+	Name() string
+	Weight() int // Relative chance of being drawn from the random pool
+	Apply(g *Game, target *System)
+}
+
+// simpleEvent adapts a function into an Event.
+type simpleEvent struct {
+	name   string
+	weight int
+	apply  func(g *Game, target *System)
+}
+
+func (e simpleEvent) Name() string                  { return e.name }
+func (e simpleEvent) Weight() int                   { return e.weight }
+func (e simpleEvent) Apply(g *Game, target *System) { e.apply(g, target) }
+
+// lookupEvent finds a built-in event by name, for validating config and
+// scenario files before a game exists.
+func lookupEvent(name string) (Event, bool) {
+	for _, ev := range eventCatalog {
+		if ev.Name() == name {
+			return ev, true
+		}
+	}
+	return nil, false
+}
+
+// EventRegistry holds the events available to a game and draws random ones
+// by weight. A weight of zero keeps an event out of the random pool while it
+// can still be fired by name from a scenario.
+type EventRegistry struct {
+	events  []Event
+	weights map[string]int
+}
+
+func NewEventRegistry(events []Event) *EventRegistry {
+	r := &EventRegistry{weights: make(map[string]int)}
+	for _, ev := range events {
+		r.Register(ev)
+	}
+	return r
+}
+
+// Register adds an event, replacing any existing event with the same name.
+func (r *EventRegistry) Register(ev Event) {
+	for i, existing := range r.events {
+		if existing.Name() == ev.Name() {
+			r.events[i] = ev
+			r.weights[ev.Name()] = ev.Weight()
+			return
+		}
+	}
+	r.events = append(r.events, ev)
+	r.weights[ev.Name()] = ev.Weight()
+}
+
+// SetWeight changes an event's weight; 0 disables it in the random pool.
+func (r *EventRegistry) SetWeight(name string, weight int) error {
+	if _, ok := r.Lookup(name); !ok {
+		return fmt.Errorf("unknown event %q", name)
+	}
+	if weight < 0 {
+		return fmt.Errorf("event %q weight cannot be negative", name)
+	}
+	r.weights[name] = weight
+	return nil
+}
+
+func (r *EventRegistry) Lookup(name string) (Event, bool) {
+	for _, ev := range r.events {
+		if ev.Name() == name {
+			return ev, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the registered event names, sorted.
+func (r *EventRegistry) Names() []string {
+	names := make([]string, len(r.events))
+	for i, ev := range r.events {
+		names[i] = ev.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pick draws an event by weight, or returns nil if every weight is zero.
+func (r *EventRegistry) Pick(rng *Rand) Event {
+	return r.PickWith(rng, nil)
+}
+
+// PickWith draws an event like Pick, with the weights of over in place of
+// those of the events it names. An event out of the pool stays out.
+func (r *EventRegistry) PickWith(rng *Rand, over map[string]int) Event {
+	weight := func(ev Event) int {
+		w := r.weights[ev.Name()]
+		if o, ok := over[ev.Name()]; ok && w > 0 {
+			w = o
+		}
+		return w
+	}
+	total := 0
+	for _, ev := range r.events {
+		total += weight(ev)
+	}
+	if total == 0 {
+		return nil
+	}
+	n := rng.Intn(total)
+	for _, ev := range r.events {
+		n -= weight(ev)
+		if n < 0 {
+			return ev
+		}
+	}
+	return nil
+}
+
+func (g *Game) triggerRandomEvent() {
+	if next := g.takeIncoming(); next != nil {
+		g.triggerEvent(next.event, next.target)
+		return
+	}
+	var ev Event
+	if g.chat != nil {
+		ev = g.chatWinner()
+	} else {
+		ev = g.pickEvent(g.Elapsed())
+	}
+	if ev == nil {
+		return
+	}
+	g.triggerEvent(ev, g.rng.Intn(len(g.Systems)))
+}
+
+// triggerEvent applies ev aimed at system sysID.
+func (g *Game) triggerEvent(ev Event, sysID int) {
+	g.countEvent(ev.Name())
+	g.cue(CueEvent)
+	defer g.journalEvent(ev, g.Systems[sysID])()
+	ev.Apply(g, g.Systems[sysID])
+}
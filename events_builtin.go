@@ -0,0 +1,303 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// eventCatalog lists every built-in event with its default weight.
+var eventCatalog = []Event{
+	simpleEvent{"power_surge", 4, applyPowerSurge},
+	simpleEvent{"coolant_leak", 4, applyCoolantLeak},
+	simpleEvent{"sensor_glitch", 4, applySensorGlitch},
+	simpleEvent{"efficiency_boost", 4, applyEfficiencyBoost},
+	simpleEvent{"cosmic_rays", 4, applyCosmicRays},
+	simpleEvent{"turbine_trip", 3, applyTurbineTrip},
+	simpleEvent{"control_rod_jam", 3, applyControlRodJam},
+	simpleEvent{"steam_void", 3, applySteamVoid},
+	simpleEvent{"earthquake", 1, applyEarthquake},
+	simpleEvent{"electrical_fire", 2, applyElectricalFire},
+	simpleEvent{"crew_error", 3, applyCrewError},
+	simpleEvent{"backup_generator", 2, applyBackupGenerator},
+	simpleEvent{"partial_scram", 2, applyPartialScram},
+	simpleEvent{"xenon_poisoning", 2, applyXenonPoisoning},
+	simpleEvent{"pressure_transient", 3, applyPressureTransient},
+	simpleEvent{"pump_cavitation", 3, applyPumpCavitation},
+	simpleEvent{"solar_flare", 2, applySolarFlare},
+	simpleEvent{"maintenance_crew", 2, applyMaintenanceCrew},
+	simpleEvent{"grid_fluctuation", 3, applyGridFluctuation},
+	simpleEvent{"relief_valve", 2, applyReliefValve},
+	simpleEvent{"micro_fracture", 2, applyMicroFracture},
+	simpleEvent{"supply_drop", 1, applySupplyDrop},
+	simpleEvent{"spare_parts", 2, applySpareParts},
+	simpleEvent{"emergency_code", 2, applyEmergencyCode},
+	turbineHallFire,
+	primaryLoopRupture,
+	simpleEvent{"saboteur", 1, applySaboteur},
+}
+
+// roll returns a random integer in [min, max] from the world RNG.
+func (g *Game) roll(min, max int) int {
+	return g.rng.Intn(max-min+1) + min
+}
+
+// systemNamed returns the system called name, or fallback when the current
+// layout has no such system.
+func (g *Game) systemNamed(name string, fallback *System) *System {
+	for _, sys := range g.Systems {
+		if sys.Name == name {
+			return sys
+		}
+	}
+	return fallback
+}
+
+// harmLogged damages sys by the scaled amount and logs it as a sub-line of an event.
+func (g *Game) harmLogged(sys *System, damage int) {
+	damage = g.scaleDamage(damage)
+	sys.Harm(damage)
+	g.AddLog(LogInfo, tr("  - %s (%d) took %d damage.", sys.Name, sys.ID, damage))
+}
+
+func applyPowerSurge(g *Game, target *System) {
+	if g.blowFuse() {
+		g.AddLog(LogSuccess, tr("EVENT: Power surge in %s (%d) absorbed by the fuse pack!", target.Name, target.ID))
+		return
+	}
+	damage := g.scaleDamage(g.rollRange(g.tuning("power_surge").Damage))
+	target.Harm(damage)
+	g.AddLog(LogWarning, tr("EVENT: Power surge in %s (%d)! Damage: %d", target.Name, target.ID, damage))
+}
+
+func applyCoolantLeak(g *Game, target *System) {
+	t := g.tuning("coolant_leak")
+	damage := g.scaleDamage(g.rollRange(t.Damage))
+	target.Harm(damage)
+	g.AddLog(LogWarning, tr("EVENT: Coolant leak detected near %s (%d)! Damage: %d", target.Name, target.ID, damage))
+	// A leak upstream permanently strains every system that depends on this one
+	for _, dependent := range g.dependentsOf(target) {
+		dependent.locked(func() {
+			dependent.DegradationRate += t.Rate
+		})
+		g.AddLog(LogWarning, tr("INFO: %s (%d) degradation increased due to %s issue.", dependent.Name, dependent.ID, target.Name))
+	}
+}
+
+func applySensorGlitch(g *Game, target *System) {
+	g.AddLog(LogEvent, tr("EVENT: Sensor glitch on %s (%d). Readings may be unreliable.", target.Name, target.ID))
+	g.faultSensor(target, g.tuning("sensor_glitch").Duration)
+}
+
+// addTimedDegradation raises sys's degradation rate by boost for duration.
+// expiry is logged with the system name and ID when the effect wears off.
+func (g *Game) addTimedDegradation(sys *System, boost int, duration time.Duration, expiry string) {
+	sys.locked(func() {
+		sys.DegradationRate += boost
+		sys.GlitchBoost += boost
+		sys.GlitchEnd = g.clock.Now().Add(duration)
+	})
+	g.expireTimedDegradation(sys, boost, duration, expiry)
+}
+
+// expireTimedDegradation removes a timed effect's extra degradation once it
+// wears off, after the given time.
+func (g *Game) expireTimedDegradation(sys *System, boost int, after time.Duration, expiry string) {
+	g.after(after, func() {
+		sys.mu.Lock()
+		sys.DegradationRate -= boost
+		sys.GlitchBoost -= boost
+		sys.mu.Unlock()
+		g.AddLog(LogInfo, tr(expiry, sys.Name, sys.ID))
+	})
+}
+
+func applyEfficiencyBoost(g *Game, target *System) {
+	boost := g.rollRange(g.tuning("efficiency_boost").Boost)
+	target.Boost(boost)
+	g.AddLog(LogSuccess, tr("EVENT: Unexpected efficiency boost in %s (%d)! Value +%d", target.Name, target.ID, boost))
+}
+
+func applyCosmicRays(g *Game, _ *System) {
+	numSystems := len(g.Systems)
+	numAffected := g.rng.Intn(numSystems-1) + 1
+	g.AddLog(LogWarning, tr("EVENT: Cosmic ray shower detected! Multiple systems affected."))
+	affectedIndices := make(map[int]bool)
+	for i := 0; i < numAffected; {
+		idx := g.rng.Intn(numSystems)
+		if !affectedIndices[idx] {
+			affectedIndices[idx] = true
+			affectedSys := g.Systems[idx]
+			damage := g.scaleDamage(g.rollRange(g.tuning("cosmic_rays").Damage))
+			affectedSys.Harm(damage)
+			g.AddLog(LogInfo, tr("  - %s (%d) took %d damage.", affectedSys.Name, idx, damage))
+			i++
+		}
+	}
+}
+
+func applyTurbineTrip(g *Game, target *System) {
+	power := g.systemNamed("Power Output", target)
+	pressure := g.systemNamed("Pressure Ctrl", target)
+	t := g.tuning("turbine_trip")
+	g.AddLog(LogWarning, tr("EVENT: Turbine trip! Generator load rejected."))
+	g.harmLogged(power, g.rollRange(t.Damage))
+	if pressure != power {
+		g.harmLogged(pressure, g.rollRange(t.Secondary))
+	}
+}
+
+func applyControlRodJam(g *Game, target *System) {
+	core := g.systemNamed("Core Temp", target)
+	g.AddLog(LogCritical, tr("EVENT: Control rod jammed! %s (%d) is running away.", core.Name, core.ID))
+	t := g.tuning("control_rod_jam")
+	g.addTimedDegradation(core, t.Rate, t.Duration, "INFO: Control rods freed; %s (%d) settling.")
+}
+
+func applySteamVoid(g *Game, target *System) {
+	core := g.systemNamed("Core Temp", target)
+	pressure := g.systemNamed("Pressure Ctrl", target)
+	t := g.tuning("steam_void")
+	g.AddLog(LogCritical, tr("EVENT: Steam void forming in the core channels!"))
+	g.harmLogged(core, g.rollRange(t.Damage))
+	if pressure != core {
+		g.harmLogged(pressure, g.rollRange(t.Secondary))
+	}
+}
+
+func applyEarthquake(g *Game, _ *System) {
+	g.AddLog(LogCritical, tr("EVENT: EARTHQUAKE! The whole plant is shaking!"))
+	damage := g.tuning("earthquake").Damage
+	for _, sys := range g.Systems {
+		g.harmLogged(sys, g.rollRange(damage))
+	}
+}
+
+func applyElectricalFire(g *Game, target *System) {
+	power := g.systemNamed("Power Output", target)
+	shield := g.systemNamed("Shield Integrity", target)
+	t := g.tuning("electrical_fire")
+	g.AddLog(LogCritical, tr("EVENT: Fire in the electrical bay!"))
+	g.harmLogged(power, g.rollRange(t.Damage))
+	if shield != power {
+		g.harmLogged(shield, g.rollRange(t.Secondary))
+	}
+}
+
+func applyCrewError(g *Game, target *System) {
+	damage := g.scaleDamage(g.rollRange(g.tuning("crew_error").Damage))
+	target.Harm(damage)
+	g.AddLog(LogEvent, tr("EVENT: Crew error! Someone flipped the wrong switch on %s (%d). Damage: %d", target.Name, target.ID, damage))
+}
+
+func applyBackupGenerator(g *Game, target *System) {
+	power := g.systemNamed("Power Output", target)
+	boost := g.rollRange(g.tuning("backup_generator").Boost)
+	power.Boost(boost)
+	g.AddLog(LogSuccess, tr("EVENT: Backup generator kicked in! %s (%d) +%d", power.Name, power.ID, boost))
+}
+
+func applyPartialScram(g *Game, target *System) {
+	core := g.systemNamed("Core Temp", target)
+	power := g.systemNamed("Power Output", target)
+	t := g.tuning("partial_scram")
+	g.AddLog(LogEvent, tr("EVENT: Partial scram! Rods inserted automatically."))
+	boost := g.rollRange(t.Boost)
+	core.Boost(boost)
+	g.AddLog(LogInfo, tr("  - %s (%d) cooled, +%d.", core.Name, core.ID, boost))
+	if power != core {
+		g.harmLogged(power, g.rollRange(t.Damage))
+	}
+}
+
+func applyXenonPoisoning(g *Game, target *System) {
+	power := g.systemNamed("Power Output", target)
+	g.AddLog(LogEvent, tr("EVENT: Xenon poisoning building up! %s (%d) output sagging.", power.Name, power.ID))
+	t := g.tuning("xenon_poisoning")
+	g.addTimedDegradation(power, t.Rate, t.Duration, "INFO: Xenon burned off; %s (%d) recovering.")
+}
+
+func applyPressureTransient(g *Game, target *System) {
+	pressure := g.systemNamed("Pressure Ctrl", target)
+	damage := g.scaleDamage(g.rollRange(g.tuning("pressure_transient").Damage))
+	pressure.Harm(damage)
+	g.AddLog(LogWarning, tr("EVENT: Pressure transient on %s (%d)! Damage: %d", pressure.Name, pressure.ID, damage))
+}
+
+func applyPumpCavitation(g *Game, target *System) {
+	coolant := g.systemNamed("Coolant Flow", target)
+	t := g.tuning("pump_cavitation")
+	damage := g.scaleDamage(g.rollRange(t.Damage))
+	coolant.Harm(damage)
+	g.AddLog(LogEvent, tr("EVENT: Pump cavitation in %s (%d)! Damage: %d", coolant.Name, coolant.ID, damage))
+	g.addTimedDegradation(coolant, t.Rate, t.Duration, "INFO: %s (%d) pumps re-primed.")
+}
+
+func applySolarFlare(g *Game, target *System) {
+	shield := g.systemNamed("Shield Integrity", target)
+	damage := g.scaleDamage(g.rollRange(g.tuning("solar_flare").Damage))
+	shield.Harm(damage)
+	g.AddLog(LogEvent, tr("EVENT: Solar flare! %s (%d) absorbing radiation. Damage: %d", shield.Name, shield.ID, damage))
+}
+
+func applyMaintenanceCrew(g *Game, target *System) {
+	boost := g.rollRange(g.tuning("maintenance_crew").Boost)
+	target.Boost(boost)
+	g.AddLog(LogSuccess, tr("EVENT: Maintenance crew patched up %s (%d)! Value +%d", target.Name, target.ID, boost))
+}
+
+func applyGridFluctuation(g *Game, target *System) {
+	power := g.systemNamed("Power Output", target)
+	t := g.tuning("grid_fluctuation")
+	delta := g.roll(-t.Damage[1], t.Boost[1])
+	if delta >= 0 {
+		power.Boost(delta)
+		g.AddLog(LogEvent, tr("EVENT: Grid fluctuation. %s (%d) +%d", power.Name, power.ID, delta))
+		return
+	}
+	damage := g.scaleDamage(-delta)
+	power.Harm(damage)
+	g.AddLog(LogEvent, tr("EVENT: Grid fluctuation. %s (%d) -%d", power.Name, power.ID, damage))
+}
+
+func applyReliefValve(g *Game, target *System) {
+	pressure := g.systemNamed("Pressure Ctrl", target)
+	coolant := g.systemNamed("Coolant Flow", target)
+	t := g.tuning("relief_valve")
+	boost := g.rollRange(t.Boost)
+	pressure.Boost(boost)
+	g.AddLog(LogSuccess, tr("EVENT: Relief valve lifted. %s (%d) +%d", pressure.Name, pressure.ID, boost))
+	if coolant != pressure {
+		g.harmLogged(coolant, g.rollRange(t.Secondary))
+	}
+}
+
+func applyMicroFracture(g *Game, target *System) {
+	target.locked(func() {
+		target.DegradationRate += g.tuning("micro_fracture").Rate
+	})
+	g.AddLog(LogCritical, tr("EVENT: Micro-fracture found in %s (%d)! Permanent degradation increase.", target.Name, target.ID))
+}
+
+func applySupplyDrop(g *Game, _ *System) {
+	kits := g.tuning("supply_drop").Items
+	g.locked(func() {
+		g.Inventory[ItemRepairKit] += kits
+	})
+	if kits == 1 {
+		g.AddLog(LogSuccess, tr("EVENT: Supply drop arrived! +1 repair kit."))
+		return
+	}
+	g.AddLog(LogSuccess, tr("EVENT: Supply drop arrived! +%d repair kits.", kits))
+}
+
+func applySpareParts(g *Game, _ *System) {
+	found := []string{ItemCoolant, ItemFuse, ItemScrubber}[g.rng.Intn(3)]
+	n := g.tuning("spare_parts").Items
+	g.addItem(found, n)
+	item, _ := lookupItem(found)
+	if n == 1 {
+		g.AddLog(LogSuccess, tr("EVENT: Spare parts recovered from storage! +1 %s.", strings.TrimSuffix(strings.ToLower(item.Label), "s")))
+		return
+	}
+	g.AddLog(LogSuccess, tr("EVENT: Spare parts recovered from storage! +%d %s.", n, strings.ToLower(item.Label)))
+}
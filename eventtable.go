@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Event tables. The numbers of every built-in event live in eventTable, and
+// the config's and a scenario's [event_table.<event>] tables override any of
+// them: the damage it deals, the integrity it restores and the degradation
+// it adds, for how long. `reload`, or a SIGHUP, re-reads the event weights
+// and tables of the config and scenario files mid-run, so tuning a scenario
+// does not mean replaying it from the start.
+
+// EventTuning holds the numbers of one event. Ranges are [min, max],
+// inclusive; what an event does not use is zero.
+type EventTuning struct {
+	Damage    [2]int        `toml:"damage"`    // To the system it hits first
+	Secondary [2]int        `toml:"secondary"` // To the second system it hits
+	Boost     [2]int        `toml:"boost"`     // Integrity it restores
+	Rate      int           `toml:"rate"`      // Degradation it adds per tick
+	Duration  time.Duration `toml:"duration"`  // How long its effect lasts; 0 for good
+	Items     int           `toml:"items"`     // Supplies it brings
+}
+
+// eventTable holds the built-in numbers of every event.
+var eventTable = map[string]EventTuning{
+	"power_surge":          {Damage: [2]int{10, 29}},
+	"coolant_leak":         {Damage: [2]int{10, 24}, Rate: 1}, // Rate: on every dependent, for good
+	"sensor_glitch":        {Duration: SensorFaultTime},
+	"efficiency_boost":     {Boost: [2]int{5, 14}},
+	"cosmic_rays":          {Damage: [2]int{5, 9}},
+	"turbine_trip":         {Damage: [2]int{15, 25}, Secondary: [2]int{5, 10}},
+	"control_rod_jam":      {Rate: 2, Duration: 20 * time.Second},
+	"steam_void":           {Damage: [2]int{10, 20}, Secondary: [2]int{10, 15}},
+	"earthquake":           {Damage: [2]int{3, 8}},
+	"electrical_fire":      {Damage: [2]int{20, 30}, Secondary: [2]int{5, 10}},
+	"crew_error":           {Damage: [2]int{8, 15}},
+	"backup_generator":     {Boost: [2]int{15, 25}},
+	"partial_scram":        {Boost: [2]int{15, 25}, Damage: [2]int{15, 25}},
+	"xenon_poisoning":      {Rate: 2, Duration: 20 * time.Second},
+	"pressure_transient":   {Damage: [2]int{15, 25}},
+	"pump_cavitation":      {Damage: [2]int{10, 20}, Rate: 1, Duration: 10 * time.Second},
+	"solar_flare":          {Damage: [2]int{15, 30}},
+	"maintenance_crew":     {Boost: [2]int{10, 20}},
+	"grid_fluctuation":     {Damage: [2]int{0, 10}, Boost: [2]int{0, 10}}, // Swings from -damage to +boost
+	"relief_valve":         {Boost: [2]int{15, 25}, Secondary: [2]int{3, 6}},
+	"micro_fracture":       {Rate: 1},
+	"supply_drop":          {Items: 1},
+	"spare_parts":          {Items: 1},
+	"emergency_code":       {Damage: [2]int{CodeDamage, CodeDamage}, Boost: [2]int{CodeBonus, CodeBonus}, Duration: CodeTime},
+	"turbine_hall_fire":    {Damage: [2]int{CrisisBlast, CrisisBlast}, Secondary: [2]int{CrisisDamage, CrisisDamage}}, // Secondary: per escalation
+	"primary_loop_rupture": {Damage: [2]int{CrisisBlast, CrisisBlast}, Secondary: [2]int{CrisisDamage, CrisisDamage}},
+	"saboteur":             {Damage: [2]int{SaboteurDamage, SaboteurDamage}}, // Per tick
+}
+
+// tuning returns the numbers of the event name: the config's table for it,
+// or the built-in one.
+func (g *Game) tuning(name string) EventTuning {
+	if t, ok := g.Config.EventTable[name]; ok {
+		return t
+	}
+	return eventTable[name]
+}
+
+// rollRange returns a random integer in the range r from the world RNG, or
+// its one value without a draw.
+func (g *Game) rollRange(r [2]int) int {
+	if r[0] == r[1] {
+		return r[0]
+	}
+	return g.roll(r[0], r[1])
+}
+
+// completeEventTable fills in what the [event_table.<event>] tables of a
+// decoded file leave out from the built-in numbers.
+func completeEventTable(md toml.MetaData, table map[string]EventTuning) {
+	for name, t := range table {
+		def := eventTable[name]
+		unset := func(key string) bool { return !md.IsDefined("event_table", name, key) }
+		if unset("damage") {
+			t.Damage = def.Damage
+		}
+		if unset("secondary") {
+			t.Secondary = def.Secondary
+		}
+		if unset("boost") {
+			t.Boost = def.Boost
+		}
+		if unset("rate") {
+			t.Rate = def.Rate
+		}
+		if unset("duration") {
+			t.Duration = def.Duration
+		}
+		if unset("items") {
+			t.Items = def.Items
+		}
+		table[name] = t
+	}
+}
+
+// validateEventTable checks the event tables of a config or scenario.
+func validateEventTable(table map[string]EventTuning) error {
+	for name, t := range table {
+		if _, ok := eventTable[name]; !ok {
+			return fmt.Errorf("event_table: unknown event %q", name)
+		}
+		for _, r := range [][2]int{t.Damage, t.Secondary, t.Boost} {
+			if r[0] < 0 || r[1] < r[0] {
+				return fmt.Errorf("event_table.%s: ranges must satisfy 0 <= min <= max", name)
+			}
+		}
+		if t.Rate < 0 || t.Duration < 0 || t.Items < 0 {
+			return fmt.Errorf("event_table.%s: rate, duration and items cannot be negative", name)
+		}
+	}
+	return nil
+}
+
+// mergeEventTable returns base with the tables of over in place of the
+// events they name.
+func mergeEventTable(base, over map[string]EventTuning) map[string]EventTuning {
+	merged := make(map[string]EventTuning, len(base)+len(over))
+	for name, t := range base {
+		merged[name] = t
+	}
+	for name, t := range over {
+		merged[name] = t
+	}
+	return merged
+}
+
+// handleReload re-reads the event weights and tables of the config and
+// scenario files the run was started with, leaving the rest of the run as it
+// is.
+func (g *Game) handleReload() {
+	if g.configPath == "" && g.ScenarioPath == "" {
+		g.AddLog(LogWarning, tr("Nothing to reload: start with --config or --scenario to tune the events mid-run."))
+		return
+	}
+	cfg, sc := DefaultConfig(), g.Scenario
+	var files []string
+	if g.configPath != "" {
+		loaded, err := LoadConfig(g.configPath)
+		if err != nil {
+			g.AddLog(LogWarning, tr("Reload failed: %v", err))
+			return
+		}
+		cfg, files = loaded, append(files, g.configPath)
+	}
+	if g.ScenarioPath != "" {
+		loaded, err := LoadScenarioFile(g.ScenarioPath)
+		if err != nil {
+			g.AddLog(LogWarning, tr("Reload failed: %v", err))
+			return
+		}
+		sc, files = loaded, append(files, g.ScenarioPath)
+	}
+	if sc != nil {
+		cfg = sc.Config(cfg)
+	}
+	g.locked(func() {
+		g.Config.EventWeights = cfg.EventWeights
+		g.Config.EventTable = cfg.EventTable
+	})
+	for _, ev := range eventCatalog {
+		_ = g.Events.SetWeight(ev.Name(), ev.Weight()) // Built in, so always known
+	}
+	for name, weight := range cfg.EventWeights {
+		_ = g.Events.SetWeight(name, weight) // Validated when the file was loaded
+	}
+	g.applyMutatorWeights()
+	g.AddLog(LogSuccess, tr("RELOAD: Event weights and tables re-read from %s.", strings.Join(files, ", ")))
+}
@@ -0,0 +1,57 @@
+package main
+
+import "time"
+
+const (
+	FabricateTime = 20 * time.Second // How long building one repair kit takes
+	FabricateCost = 15               // Power Output integrity consumed per kit
+)
+
+// handleFabricate starts building a repair kit from Power Output's reserves.
+func (g *Game) handleFabricate() {
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot fabricate: Player busy with another action."))
+		return
+	}
+	power := g.systemNamed("Power Output", nil)
+	if power == nil {
+		g.AddLog(LogWarning, tr("Cannot fabricate: This reactor has no Power Output system."))
+		return
+	}
+
+	power.mu.Lock()
+	switch {
+	case power.Offline:
+		power.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot fabricate: %s (%d) is OFFLINE.", power.Name, power.ID))
+		return
+	case power.Value-FabricateCost <= power.CriticalThreshold:
+		power.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot fabricate: Not enough reserve in %s (%d) to drain %d.", power.Name, power.ID, FabricateCost))
+		return
+	}
+	power.Value -= FabricateCost
+	power.mu.Unlock()
+
+	g.AddLog(LogInfo, tr("Fabricating a repair kit. Drained %d from %s (%d).", FabricateCost, power.Name, power.ID))
+	g.startFabrication(power, FabricateTime)
+}
+
+// startFabrication occupies the player until the kit is built. It is shared
+// by handleFabricate and by resuming a saved game.
+func (g *Game) startFabrication(power *System, duration time.Duration) {
+	g.SetPlayerAction(tr("Fabricating repair kit..."), duration)
+	g.locked(func() {
+		g.ActionSystem = power.ID
+		g.ActionKind = ActionFabricate
+	})
+
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		g.ClearPlayerAction()
+		g.locked(func() {
+			g.Inventory[ItemRepairKit]++
+		})
+		g.AddLog(LogSuccess, tr("Fabrication complete. Repair kit added to stores."))
+	})
+}
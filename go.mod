@@ -2,10 +2,18 @@ module example/reactor_meltdown
 
 go 1.23.3
 
-require github.com/fatih/color v1.18.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fatih/color v1.18.0
+	github.com/gliderlabs/ssh v0.3.8
+	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.28.0
+	golang.org/x/term v0.27.0
+	golang.org/x/text v0.21.0
+)
 
 require (
+	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
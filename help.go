@@ -0,0 +1,435 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Help pages. `help` lists the commands, `help <command>` explains one in
+// full, with its costs, odds and cooldown as this run has them, and `manual`
+// covers the rules of the plant. A page shows in place of the dashboard,
+// like the log viewer, until the next command.
+
+// HelpViewState is the help page an operator is reading.
+type HelpViewState struct {
+	Title string   `json:"title"`
+	Lines []string `json:"lines"`
+}
+
+// ManualTopic is the help topic of the manual.
+const ManualTopic = "manual"
+
+// helpTopic is the help page of one command.
+type helpTopic struct {
+	command string
+	usage   string
+	summary string
+	page    func(g *Game) []string // The details, after the usage and summary
+}
+
+var helpTopics = []helpTopic{
+	{"stabilize", "stabilize <system>", "Repair a system; costs a repair kit and takes time",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs, during which the system stops degrading. Slower while the", g.Config.StabilizeTime.Seconds()),
+				tr("systems it depends on are weak, faster with Power Output overcharged."),
+				tr("Up to %.0f%% slower while crew morale is below %d.", MoraleSlowdown*100, MoraleLow),
+				tr("Cannot be used on an OFFLINE system: reboot it first."),
+				tr("Cancelling loses the repair kit."),
+				tr("The first repair of a system restores it to %d; every one after restores it", g.Config.MaxSystemValue),
+				tr("%d less, down to %d.", StabilizeFalloff*g.Config.MaxSystemValue/100, g.Config.WarningThreshold),
+				tr("With stabilize_minigame = true in the config, the crew calls out %d WASD", MinigameLength),
+				tr("keys to repeat at the terminal: type them fast to finish at once and keep"),
+				tr("all of the restore, or as little as %d%% when slow, wrong or untyped.", MinigameFloor),
+				"",
+				tr("Example: stabilize coolant"),
+			}
+		}},
+	{"divert", "divert <from> <to> <amount>", "Move 10-30 integrity from one system to another",
+		func(g *Game) []string {
+			return []string{
+				tr("Instant. The source must keep at least half the critical threshold"),
+				tr("(%d) above the amount, and neither system may be OFFLINE.", g.Config.CriticalThreshold/2),
+				tr("%d%% of the amount arrives, less the loss of its route.", g.Config.Divert.Efficiency),
+				g.divertConfirmHelp(),
+				tr("%s between uses.", describeCooldown(g.Config.Keys.cooldown(CommandDivert))),
+				g.powerHelp(CommandDivert),
+				"",
+				tr("Example: divert power coolant 20"),
+			}
+		}},
+	{"vent", "vent <system>", "Blow off pressure for an instant boost; risky",
+		func(g *Game) []string {
+			return []string{
+				tr("Instant. Restores half of what the system is missing, at least 10."),
+				tr("Releases %.0f radiation.", RadiationPerVent),
+				tr("%d%% chance of backflow: another system takes 5-19 damage.", VentBackflowChance),
+				tr("%s between uses.", describeCooldown(g.Config.Keys.cooldown(CommandVent))),
+				g.powerHelp(CommandVent),
+				"",
+				tr("Example: vent shield"),
+			}
+		}},
+	{"override", "override <system>", "Force a system manually; VERY risky",
+		func(g *Game) []string {
+			failed := 100 - OverrideSuccessChance - OverrideNeutralChance
+			return []string{
+				tr("The outcome comes in after %.1fs:", OverrideDelay.Seconds()),
+				tr("  %d%% the system is fully stabilized,", OverrideSuccessChance),
+				tr("  %d%% nothing happens,", OverrideNeutralChance),
+				tr("  %d%% it FAILS and the system takes 30-69 damage.", failed),
+				tr("%s between uses. Damage is scaled x%.1f on %s.", describeCooldown(g.Config.Keys.cooldown(CommandOverride)), g.Difficulty.DamageScale, g.Difficulty.Name),
+				g.powerHelp(CommandOverride),
+				g.confirmHelp(),
+				"",
+				tr("Example: override core"),
+			}
+		}},
+	{"overcharge", "overcharge <system>", "Push a healthy system past its maximum",
+		func(g *Game) []string {
+			return []string{
+				tr("Needs %d%% integrity or more, and raises it to %d%%.", OverchargeReady, OverchargePercent),
+				tr("Decays %d faster per tick, with a %d%% chance each tick of a blowout", OverchargeDrain, BlowoutChance),
+				tr("that leaves it at %d%%.", BlowoutPercent),
+				tr("Overcharged shields absorb %.0f%% of damage; overcharged power speeds up", (1-ShieldAbsorb)*100),
+				tr("stabilization by %.0f%%.", (1-PowerStabilizeCut)*100),
+				"",
+				tr("Example: overcharge shield"),
+			}
+		}},
+	{"reboot", "reboot <system>", "Bring an OFFLINE system back; takes time",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs and brings the system back at %d%%.", g.Config.RebootTime.Seconds(), g.Config.RebootPercent),
+				tr("Works on OFFLINE systems only."),
+				"",
+				tr("Example: reboot pressure"),
+			}
+		}},
+	{"inspect", "inspect <system>", "Read a system's true value by hand; takes time",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs. A faulty sensor drifts up to %d from the truth for", InspectTime.Seconds(), SensorNoise),
+				tr("%.0fs; inspecting shows the real value.", g.tuning("sensor_glitch").Duration.Seconds()),
+				"",
+				tr("Example: inspect core"),
+			}
+		}},
+	{"sweep", "sweep <system>", "Search a system for a saboteur; takes time",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs. Works only while a saboteur is loose, damaging a random", SweepTime.Seconds()),
+				tr("system by %d every tick until caught.", g.tuning("saboteur").Damage[1]),
+				tr("A miss tells whether the trail is warm (one system ID away) or cold, then"),
+				tr("whether each sweep is hotter or colder than the one before."),
+				"",
+				tr("Example: sweep coolant"),
+			}
+		}},
+	{"use", "use <item> [system]", "Consume an item from the inventory",
+		func(g *Game) []string {
+			lines := make([]string, 0, len(itemCatalog)+2)
+			for _, item := range itemCatalog {
+				lines = append(lines, fmt.Sprintf("  %-12s %s", item.Name, tr(item.Help)))
+			}
+			return append(lines, "", "Example: use coolant")
+		}},
+	{"fabricate", "fabricate", "Build a repair kit from Power Output",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs and drains %d from Power Output.", FabricateTime.Seconds(), FabricateCost),
+			}
+		}},
+	{"rally", "rally", "Lift the crew's morale; takes time",
+		func(g *Game) []string {
+			return []string{
+				tr("Takes %.0fs and raises morale by %d.", RallyTime.Seconds(), RallyGain),
+				tr("Morale falls %d with every critical alarm and %d with every failed override,", MoraleAlarmLoss, MoraleOverrideLoss),
+				tr("and rises %d for every %.0fs without a CRITICAL system. Below %d, stabilize", MoraleCalmGain, MoraleCalmTime.Seconds(), MoraleLow),
+				tr("takes up to %.0f%% longer and up to %d%% of actions land on the system next", MoraleSlowdown*100, MoraleFumbleChance),
+				tr("to the one they name."),
+			}
+		}},
+	{"scram", "scram", "Emergency shutdown: ends the run safely at half score",
+		func(g *Game) []string {
+			return []string{
+				tr("The reactor must hold for %.0fs, with fewer than two systems OFFLINE.", ScramHoldTime.Seconds()),
+				tr("If it holds, the run ends without a meltdown and the score is halved."),
+				g.confirmHelp(),
+			}
+		}},
+	{"undo", "undo", "Take back the last divert",
+		func(g *Game) []string {
+			return []string{
+				tr("Within %.0fs of a divert, puts back what it moved between the two systems.", UndoWindow.Seconds()),
+				tr("The power it drew and its cooldown stay spent. %d undos per run.", UndoLimit),
+			}
+		}},
+	{"rewind", "rewind [seconds]", "Take a --practice run back in time",
+		func(g *Game) []string {
+			return []string{
+				tr("Only in a --practice run, which is snapshot every %.0fs. Goes back to the", PracticeInterval.Seconds()),
+				tr("newest snapshot at least that far back (%.0fs when not given), up to %.0fs.", RewindDefault.Seconds(), (PracticeKeep * PracticeInterval).Seconds()),
+				tr("The systems, repair kits, log and score go back; the events ahead are"),
+				tr("drawn afresh, and the action under way is dropped."),
+				"",
+				tr("Example: rewind 15"),
+			}
+		}},
+	{"cancel", "cancel", "Abort the current timed action",
+		func(g *Game) []string {
+			return []string{"Whatever the action used up, like a repair kit, is lost."}
+		}},
+	{CrisisSuppress, "suppress | isolate <system> | restart <system>", "Work through a major incident",
+		func(g *Game) []string {
+			return []string{
+				tr("A major incident opens a procedure shown on the CRISIS panel. Type its"),
+				tr("steps in order, each within %.0fs, or the damage escalates every %.0fs.", CrisisStageTime.Seconds(), CrisisEscalation.Seconds()),
+				tr("A wrong step costs nothing but time."),
+			}
+		}},
+	{"rule", "rule add <condition> <action> | rule list | rule del <n>", "Automate a command",
+		func(g *Game) []string {
+			return []string{
+				tr("Up to %d rules run their action whenever their condition holds, at most", MaxRules),
+				tr("every %.0fs, draining %d Power Output each time.", RuleRetry.Seconds(), RulePowerCost),
+				"",
+				tr("Example: rule add coolant<30 divert power coolant 15"),
+			}
+		}},
+	{"ack", "ack <alarm> | ack all", "Acknowledge alarms on the annunciator",
+		func(g *Game) []string {
+			return []string{
+				tr("A critical alarm left unacknowledged for %.0fs costs %d points.", AlarmAckTime.Seconds(), UnackedAlarmPenalty),
+			}
+		}},
+	{"log", "log", "Browse the full event log", nil},
+	{"trend", "trend <system>", "Graph a system's history over the whole run", nil},
+	{"schematic", "schematic", "Toggle the plant diagram in place of the status table", nil},
+	{"view", "view compact|bars|gauges", "Draw the systems as short lines, bars or tall gauges",
+		func(g *Game) []string {
+			return []string{
+				tr("bars, the default, draws a line per system with a bar and a sparkline."),
+				tr("compact draws a short line per system, for terminals too small for the bars."),
+				tr("gauges draws a %d-row column per system side by side, for wide terminals,", GaugeHeight),
+				tr("with the warning and critical thresholds marked; too narrow, it falls back to bars."),
+			}
+		}},
+	{"spectators", "spectators [on|off|mute <name>|unmute <name>]", "Let the spectators of the status API chat, or silence one",
+		func(g *Game) []string {
+			cfg := g.Config.Spectators
+			return []string{
+				tr("With --http, spectators on the spectator page can send short messages,"),
+				tr("shown under the log: one per address every %.0fs, at most %d characters.", cfg.Interval.Seconds(), cfg.MaxLength),
+				tr("They cannot send commands. off clears the panel and refuses new messages;"),
+				tr("mute silences the address a name last spoke from, whatever name it gives next."),
+				tr("Games hosted with --ssh have no spectators."),
+				"",
+				tr("Example: spectators mute troll"),
+			}
+		}},
+	{"advise", "advise [on|off]", "Recommend the most valuable thing to do next",
+		func(g *Game) []string {
+			return []string{
+				tr("Looks over the dashboard and logs what to do next, and why: a waiting code,"),
+				tr("critical alarms, a crisis step, an offline system, a saboteur, then the"),
+				tr("weakest system once it falls to WARNING. Repair kits are kept for CRITICAL systems."),
+				tr("How soon a system hits bottom is projected from its last %d readings.", AdvisorWindow),
+				tr("advise on keeps the recommendation above the status table; advise off hides it."),
+				tr("The greedy bot of --simulate plays whatever the advisor recommends."),
+				"",
+				tr("Example: advise on"),
+			}
+		}},
+	{"autopilot", "autopilot [on|off]", "Let a bot play for you",
+		func(g *Game) []string {
+			return []string{
+				tr("Hands the reactor to the %s policy, which acts every %.0fs as a bot", g.policy.Name(), BotReaction.Seconds()),
+				tr("of --simulate would, without waiting for CONFIRM. You can still type"),
+				tr("commands alongside it. Choose the bot with --policy when starting the game."),
+				tr("A run the autopilot has flown, even for a moment, is not recorded."),
+			}
+		}},
+	{"save", "save <slot or file>", "Write the game to a save slot or a file, to resume with --load",
+		func(g *Game) []string {
+			return []string{
+				tr("A name of letters, digits, - and _ is a slot of your profile's saves;"),
+				tr("anything else, such as run.json, is a file. saves lists the slots, and"),
+				tr("load <slot> resumes one. A slot saved to again is overwritten."),
+				"",
+				tr("Example: save slot1"),
+			}
+		}},
+	{"reload", "reload", "Re-read the event weights and tables of the config and scenario",
+		func(g *Game) []string {
+			return []string{
+				tr("Re-reads event_weights and the [event_table.<event>] tables of the"),
+				tr("--config and --scenario files the run was started with, and keeps the"),
+				tr("rest of the run as it is. A SIGHUP does the same. A file that fails to"),
+				tr("load leaves the events as they were."),
+			}
+		}},
+	{HistoryTopic, "history [save <file>]", "Show the latest commands: when they ran, on what and what came of them", nil},
+	{SavesTopic, "saves", "List your profile's save slots, with the bars as they were saved", nil},
+	{"load", "load <slot>", "Resume a save slot in place of the run under way", nil},
+	{"reactor", "reactor <n>", "Switch focus to another unit of a multi-reactor plant", nil},
+	{"sabotage", "sabotage <player> <event>", "Spend sabotage points on your rival's reactor in a versus match", nil},
+	{"respond", "respond yes|no", "Answer a story transmission that asks for a decision",
+		func(g *Game) []string {
+			return []string{
+				tr("With --story, the Plant Director and Off-site Emergency Services send"),
+				tr("transmissions now and then. Some ask for a decision, with a cost and a"),
+				tr("reward; one left unanswered for %.0fs lapses as no.", StoryChoiceTime.Seconds()),
+				"",
+				tr("Example: respond yes"),
+			}
+		}},
+	{KeysTopic, "keys", "Show the hotkeys, cooldowns, aliases and verbs in effect", nil},
+	{"quit", "quit", "Leave the game", nil},
+}
+
+// lookupHelpTopic finds the page of command, which may be any of the
+// alternatives in a page's usage.
+func lookupHelpTopic(command string) (helpTopic, bool) {
+	for _, t := range helpTopics {
+		for _, alt := range strings.Split(t.usage, "|") {
+			if strings.Fields(alt)[0] == command {
+				return t, true
+			}
+		}
+	}
+	return helpTopic{}, false
+}
+
+// handleHelp opens the help index, or the page of the command in args.
+func (g *Game) handleHelp(args []string) {
+	topic := ""
+	if len(args) > 0 {
+		topic = g.resolveVerb(strings.Fields(g.expandAlias(args[0]))[0])
+		if _, ok := lookupHelpTopic(topic); !ok && topic != ManualTopic {
+			g.AddLog(LogWarning, tr("No help on %q. Type help for the list of commands.", args[0]))
+			return
+		}
+	}
+	g.openHelp(topic)
+}
+
+// openHelp shows the current operator the page on topic: "" for the index,
+// or ManualTopic.
+func (g *Game) openHelp(topic string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.helpOpen, g.helpTopic, g.logOpen, g.trendOpen = true, topic, false, false
+	g.savesPage = nil
+}
+
+// handleHelpView closes the help page if the current operator is reading
+// one, and reports whether input did nothing else. Any other command runs as
+// usual. It must run on the game loop.
+func (g *Game) handleHelpView(input string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.helpOpen {
+		return false
+	}
+	g.helpOpen = false
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "", "q", "close":
+		return true
+	}
+	return false
+}
+
+// helpViewLocked returns the page op is reading, or nil if op is at the
+// dashboard. The caller must hold g.mu.
+func (g *Game) helpViewLocked(op *Operator, objective string) *HelpViewState {
+	if !op.helpOpen {
+		return nil
+	}
+	if op.helpTopic == SavesTopic && op.savesPage != nil { // Listed by saves, not help saves
+		return &HelpViewState{Title: tr("SAVES: Your profile's slots"), Lines: op.savesPage}
+	}
+	switch op.helpTopic {
+	case "":
+		lines := make([]string, 0, len(helpTopics)+3)
+		var others []string
+		for _, t := range helpTopics {
+			if t.page == nil {
+				others = append(others, t.command)
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %-12s %s", t.command, tr(t.summary)))
+		}
+		lines = append(lines, tr("  Also: %s", strings.Join(others, ", ")), "",
+			tr("help <command> explains one in full; manual covers the rules of the plant."))
+		return &HelpViewState{Title: tr("HELP: Commands"), Lines: lines}
+	case ManualTopic:
+		return &HelpViewState{Title: tr("MANUAL: Running the plant"), Lines: g.manual(objective)}
+	case KeysTopic:
+		return &HelpViewState{Title: tr("KEYS: The key map in effect"), Lines: g.keysPage()}
+	case HistoryTopic:
+		return &HelpViewState{Title: tr("HISTORY: The latest commands"), Lines: g.historyPageLocked()}
+	}
+	t, _ := lookupHelpTopic(op.helpTopic)
+	lines := []string{tr("Usage: %s", t.usage), tr(t.summary) + "."}
+	if verbs := g.verbsFor(t.command); len(verbs) > 0 {
+		lines = append(lines, tr("Also typed as: %s", strings.Join(verbs, ", ")))
+	}
+	lines = append(lines, "")
+	if t.page != nil {
+		lines = append(lines, t.page(g)...)
+	}
+	lines = append(lines, g.matrixHelp(t.command)...)
+	return &HelpViewState{Title: tr("HELP: %s", t.command), Lines: lines}
+}
+
+// manual is the overview of the rules, as this run has them.
+func (g *Game) manual(objective string) []string {
+	cfg := g.Config
+	lines := []string{
+		tr("Every system runs from %d to %d and degrades every %.2fs. At %d or less it is", cfg.MinSystemValue, cfg.MaxSystemValue, cfg.DegradationTick.Seconds(), cfg.WarningThreshold),
+		tr("in WARNING, at %d or less CRITICAL, and at %d it goes OFFLINE until rebooted.", cfg.CriticalThreshold, cfg.MinSystemValue),
+	}
+	for _, sys := range g.Systems {
+		if sys.MaxValue != cfg.MaxSystemValue || sys.WarningThreshold != cfg.WarningThreshold || sys.CriticalThreshold != cfg.CriticalThreshold {
+			lines = append(lines, tr("  %s (%d) runs to %d, in WARNING at %d and CRITICAL at %d.", tr(sys.Name), sys.ID, sys.MaxValue, sys.WarningThreshold, sys.CriticalThreshold))
+		}
+	}
+	return append(lines,
+		tr("Random events strike every few seconds, and weak systems drag down the"),
+		tr("ones that depend on them."),
+		"",
+		tr("The run is lost when:"),
+		tr("  - two or more systems stay OFFLINE for %.0fs: meltdown,", MeltdownGrace.Seconds()),
+		tr("  - radiation stays above %.0f for %.0fs: the crew evacuates,", RadiationLimit, EvacuationDelay.Seconds()),
+		tr("  - or the scenario's own failure condition is met."),
+		tr("Radiation rises %.1f per tick for each critical system and %.0f per vent, and", RadiationPerCritical, RadiationPerVent),
+		tr("falls faster while the shields are healthy."),
+		tr("Crew morale falls with critical alarms and failed overrides; below %d the", MoraleLow),
+		tr("crew stabilizes slowly and fumbles commands. Calm stretches and rally lift it."),
+		tr("The FORECAST line announces heat waves, storms and grid demand spikes ahead."),
+		tr("While %s is above %d, an INCOMING banner names the next event %.0fs ahead.", IncomingSystem, g.Config.WarningThreshold, IncomingLead.Seconds()),
+		tr("Phases of the run, each with its own events and pace of degradation:"),
+		"  "+g.phaseSummary(),
+		"",
+		tr("Objective: %s. scram ends it safely at half score.", objective),
+		tr("Difficulty %s: damage x%.1f, power drawn x%.1f.", g.Difficulty.Name, g.Difficulty.DamageScale, g.Difficulty.PowerScale),
+		"",
+		tr("Commands name a system by its ID or its name: stabilize 2, stabilize core."),
+		tr("Only one timed action runs at once; the next command waits in the queue."),
+	)
+}
+
+// drawHelpView renders the help page in place of the dashboard.
+func (s GameState) drawHelpView(w io.Writer) {
+	v := s.HelpView
+	fmt.Fprintln(w, theme.Title.Sprintf("--- %s ---", v.Title))
+	fmt.Fprintln(w, theme.Dim.Sprint(tr("The reactor keeps running while you read.")))
+	fmt.Fprintln(w)
+	for _, line := range v.Lines {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, theme.Title.Sprint(tr("\n  q: back to the dashboard (or type any command)")))
+	fmt.Fprint(w, theme.Title.Sprint(tr("Enter command: ")))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	AppDirName    = "reactor_meltdown"
+	HighScoreFile = "highscores.json"
+	TopScoreCount = 10
+)
+
+// HighScore is one finished run in the high-score file.
+type HighScore struct {
+	Score      int           `json:"score"`
+	Duration   time.Duration `json:"duration_ns"`
+	Difficulty string        `json:"difficulty"`
+	Mode       string        `json:"mode"`
+	Seed       int64         `json:"seed"`
+	Won        bool          `json:"won"`
+	Scram      bool          `json:"scram,omitempty"`
+	Reactors   int           `json:"reactors,omitempty"`  // Units played; 0 means one
+	Challenge  string        `json:"challenge,omitempty"` // Replays the run's seed and settings with --challenge
+	Date       time.Time     `json:"date"`
+}
+
+// appDataDir returns (and creates) the per-user directory for game data.
+func appDataDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, AppDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func highScorePath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HighScoreFile), nil
+}
+
+// LoadHighScores reads every recorded run. A missing file is not an error.
+func LoadHighScores() ([]HighScore, error) {
+	path, err := highScorePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var scores []HighScore
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return scores, nil
+}
+
+// AppendHighScore records a run and returns the updated list of all runs.
+func AppendHighScore(hs HighScore) ([]HighScore, error) {
+	scores, err := LoadHighScores()
+	if err != nil {
+		return nil, err
+	}
+	scores = append(scores, hs)
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path, err := highScorePath()
+	if err != nil {
+		return nil, err
+	}
+	return scores, os.WriteFile(path, data, 0o644)
+}
+
+// TopScores returns the n best runs, highest score first.
+func TopScores(scores []HighScore, n int) []HighScore {
+	top := append([]HighScore(nil), scores...)
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Score > top[j].Score })
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// recordHighScore saves the finished game and keeps the top table for the
+// summary screen.
+func (g *Game) recordHighScore() {
+	g.saveHighScore(g.highScoreEntry())
+}
+
+// highScoreEntry describes this finished game as a high-score entry.
+func (g *Game) highScoreEntry() HighScore {
+	g.mu.Lock()
+	won := g.GameWon
+	scram := g.Scrammed
+	g.mu.Unlock()
+	return HighScore{
+		Score:      g.CurrentScore().Total,
+		Duration:   g.Elapsed(),
+		Difficulty: g.Difficulty.Name,
+		Mode:       g.Mode,
+		Seed:       g.Seed,
+		Won:        won,
+		Scram:      scram,
+		Challenge:  g.challenge(),
+		Date:       time.Now(),
+	}
+}
+
+// saveHighScore appends entry to the high-score file and keeps the top table
+// for the summary screen.
+func (g *Game) saveHighScore(entry HighScore) {
+	scores, err := AppendHighScore(entry)
+	if err != nil {
+		g.AddLog(LogCritical, tr("Failed to save high score: %v", err))
+		return
+	}
+	g.mu.Lock()
+	g.topScores = TopScores(scores, TopScoreCount)
+	g.lastScore = &entry
+	g.mu.Unlock()
+}
+
+// printHighScores renders a TOP 10 table, highlighting the entry equal to mark.
+func printHighScores(w io.Writer, scores []HighScore, mark *HighScore) {
+	fmt.Fprintln(w, theme.Heading.Sprint(tr("TOP %d:", TopScoreCount)))
+	if len(scores) == 0 {
+		fmt.Fprintln(w, tr("  No runs recorded yet."))
+		return
+	}
+	fmt.Fprintf(w, "  %-4s %8s  %-6s  %-10s %-10s %-6s %-20s  %s\n", "#", "SCORE", "TIME", "DIFFICULTY", "MODE", "RESULT", "SEED", "DATE")
+	for i, hs := range scores {
+		result := "LOSS"
+		if hs.Won {
+			result = "WIN"
+		} else if hs.Scram {
+			result = "SCRAM"
+		}
+		mode := hs.Mode
+		if hs.Reactors > 1 {
+			mode = fmt.Sprintf("%s x%d", hs.Mode, hs.Reactors)
+		}
+		line := fmt.Sprintf("  %-4d %8d  %-6s  %-10s %-10s %-6s %-20d  %s", i+1, hs.Score, formatDuration(hs.Duration),
+			strings.ToUpper(hs.Difficulty), mode, result, hs.Seed, hs.Date.Format("2006-01-02 15:04"))
+		if mark != nil && hs == *mark {
+			fmt.Fprintln(w, theme.Success.Sprint(line+tr("  <- this run")))
+		} else {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// PrintHighScoreTable loads and prints the table for --scores.
+func PrintHighScoreTable() error {
+	scores, err := LoadHighScores()
+	if err != nil {
+		return err
+	}
+	printHighScores(color.Output, TopScores(scores, TopScoreCount), nil)
+	return nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Hotkeys. With --hotkeys the terminal is read a key at a time in raw mode:
+// a digit selects a system and a letter acts on it at once, with no Enter.
+// : opens a line to type any other command on, and Esc backs out of
+// anything half done. The keys become the same command lines typing them
+// out would. The config's [keys] table picks the letters. While a stabilize
+// sequence awaits its keys, W, A, S and D are taken as keys of the sequence.
+
+// Keys that are not printable.
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x7f
+	keyCtrlH     = 0x08
+	keyEscape    = 0x1b
+)
+
+// hotkeys turns keys into command lines and keeps what the prompt shows.
+type hotkeys struct {
+	mu       sync.Mutex
+	names    []string // Of the systems, for the prompt
+	keys     KeysConfig
+	actions  map[byte]string // The keys that act on the selected system, and their commands
+	selected int             // The system the letters act on, -1 for none
+	divert   []int           // The source and target picked so far in the divert dialog, nil outside it
+	typing   bool            // Typing a command line after :
+	line     []rune
+	note     string      // What became of the last key
+	sequence []byte      // Keys of the stabilize sequence pressed so far
+	pending  func() bool // Whether a stabilize sequence awaits its keys
+
+	screen  *screenTracker // The frame clicks land on, nil without --mouse
+	buttons []promptButton // On the prompt line, as last drawn
+	pressed int            // The system the mouse button went down on, -1 for none
+
+	lines  chan string
+	redraw chan<- struct{}
+}
+
+func newHotkeys(names []string, keys KeysConfig, pending func() bool, redraw chan<- struct{}) *hotkeys {
+	return &hotkeys{names: names, keys: keys, pending: pending, actions: keys.actions(), selected: -1, pressed: -1, lines: make(chan string), redraw: redraw}
+}
+
+// run reads keys and mouse reports from r until it ends, sending the
+// command lines they make.
+func (h *hotkeys) run(r io.Reader) {
+	defer close(h.lines)
+	buf := make([]byte, 64)
+	var partial []byte // A mouse report cut off by the end of the last read
+	for {
+		n, err := r.Read(buf)
+		data := append(partial, buf[:n]...)
+		partial = nil
+		for i := 0; i < len(data); i++ {
+			ev, size, whole := mouseReport(data[i:])
+			if size > 0 && !whole {
+				partial = data[i:]
+				break
+			}
+			h.mu.Lock()
+			var line string
+			var ok bool
+			if size > 0 {
+				line, ok = h.click(ev)
+				i += size - 1
+			} else {
+				line, ok = h.key(data[i])
+			}
+			h.mu.Unlock()
+			if ok {
+				h.lines <- line
+			}
+			select {
+			case h.redraw <- struct{}{}:
+			default: // A redraw is already due
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ReadLine returns the next command line, for a Session.
+func (h *hotkeys) ReadLine() (string, error) {
+	line, ok := <-h.lines
+	if !ok {
+		return "", io.EOF
+	}
+	return line, nil
+}
+
+// key handles one key, and returns the command line it completes, if any.
+// The caller must hold h.mu.
+func (h *hotkeys) key(b byte) (string, bool) {
+	switch {
+	case b == keyCtrlC || b == keyCtrlD:
+		return "quit", true
+	case b == keyEscape:
+		h.selected, h.divert, h.typing, h.line, h.note = -1, nil, false, nil, ""
+		h.pressed, h.sequence = -1, nil
+		return "", false
+	case h.typing:
+		return h.typeKey(b)
+	case b == h.keys.Command[0]:
+		h.typing, h.note = true, ""
+		return "", false
+	case strings.IndexByte(MinigameKeys, b|0x20) >= 0 && h.pending():
+		return h.sequenceKey(b | 0x20)
+	case b >= '0' && b <= '9':
+		return h.systemKey(int(b - '0'))
+	case h.divert != nil:
+		h.note = tr("Pick a system by its number, or Esc.")
+		return "", false
+	case b == h.keys.Divert[0]:
+		if h.selected < 0 {
+			h.note = tr("Select the system to divert from first.")
+			return "", false
+		}
+		h.divert, h.note = []int{h.selected}, ""
+		return "", false
+	}
+	action, ok := h.actions[b]
+	if !ok {
+		if b > ' ' && b < keyBackspace {
+			h.note = tr("No hotkey %q.", b)
+		}
+		return "", false
+	}
+	if h.selected < 0 {
+		h.note = tr("Select a system for %s first.", action)
+		return "", false
+	}
+	h.note = ""
+	return fmt.Sprintf("%s %d", action, h.selected), true
+}
+
+// systemKey handles a digit: it picks the divert dialog's next system, or
+// selects one.
+func (h *hotkeys) systemKey(id int) (string, bool) {
+	h.note = ""
+	switch len(h.divert) {
+	case 0:
+		h.selected = id
+	case 1:
+		h.divert = append(h.divert, id)
+	default:
+		if id < 1 || id > 3 {
+			h.note = tr("Divert 10, 20 or 30: press 1, 2 or 3.")
+			return "", false
+		}
+		line := fmt.Sprintf("divert %d %d %d", h.divert[0], h.divert[1], id*10)
+		h.divert = nil
+		return line, true
+	}
+	return "", false
+}
+
+// sequenceKey handles a key of the stabilize sequence awaiting input, and
+// returns the sequence once its keys are all pressed.
+func (h *hotkeys) sequenceKey(b byte) (string, bool) {
+	h.sequence, h.note = append(h.sequence, b), ""
+	if len(h.sequence) < MinigameLength {
+		return "", false
+	}
+	line := string(h.sequence)
+	h.sequence = nil
+	return line, true
+}
+
+// typeKey handles a key of the command line being typed.
+func (h *hotkeys) typeKey(b byte) (string, bool) {
+	switch b {
+	case '\r', '\n':
+		line := string(h.line)
+		h.typing, h.line = false, nil
+		return line, true
+	case keyBackspace, keyCtrlH:
+		if len(h.line) > 0 {
+			h.line = h.line[:len(h.line)-1]
+		}
+	default:
+		if b >= ' ' && b < keyBackspace {
+			h.line = append(h.line, rune(b))
+		}
+	}
+	return "", false
+}
+
+// prompt is the line the dashboard ends with, in place of its command
+// prompt: what the keys have picked so far, and what the next can do. With
+// the mouse it carries buttons for them.
+func (h *hotkeys) prompt() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buttons = h.buttons[:0]
+	name := func(id int) string {
+		if id >= 0 && id < len(h.names) {
+			return fmt.Sprintf("[%d] %s", id, tr(h.names[id]))
+		}
+		return fmt.Sprintf("[%d]", id)
+	}
+	var text string
+	if !h.pending() {
+		h.sequence = nil // The stabilize has ended
+	}
+	switch {
+	case h.typing:
+		return theme.Title.Sprint(tr("Enter command: ")) + string(h.line)
+	case h.sequence != nil:
+		text = tr("STABILIZE: Press the sequence's keys: %s", spacedKeys(string(h.sequence)))
+	case len(h.divert) == 1 && h.screen != nil:
+		text = tr("DIVERT from %s to: click it or press its number", name(h.divert[0]))
+		h.button(&text, "Esc", keyEscape)
+	case len(h.divert) == 1:
+		text = tr("DIVERT from %s to: press its number", name(h.divert[0]))
+	case len(h.divert) == 2 && h.screen != nil:
+		text = tr("DIVERT from %s to %s:", name(h.divert[0]), name(h.divert[1]))
+		for i := byte(1); i <= 3; i++ {
+			h.button(&text, strconv.Itoa(int(i)*10), '0'+i)
+		}
+		h.button(&text, "Esc", keyEscape)
+	case len(h.divert) == 2:
+		text = tr("DIVERT from %s to %s: 1, 2 or 3 for 10, 20 or 30", name(h.divert[0]), name(h.divert[1]))
+	case h.screen != nil:
+		text = tr("Click a system:")
+		if h.selected >= 0 {
+			text = name(h.selected) + ":"
+		}
+		bindings := h.keys.bindings()
+		for _, b := range bindings[:len(bindings)-1] {
+			h.button(&text, strings.ToUpper(b.action), b.key[0])
+		}
+	case h.selected >= 0:
+		bindings := h.keys.bindings()
+		keys := make([]string, 0, len(bindings)-1)
+		for _, b := range bindings[:len(bindings)-1] {
+			keys = append(keys, b.key+" "+b.action)
+		}
+		text = fmt.Sprintf("%s: %s", name(h.selected), strings.Join(keys, "  "))
+	default:
+		text = tr("HOTKEYS: 0-9 select a system  %s type a command  Esc clear", h.keys.Command)
+	}
+	if h.note != "" {
+		text += "  " + theme.Warning.Sprint(h.note)
+	}
+	return theme.Title.Sprint(text)
+}
+
+// crlfWriter ends lines with a carriage return as well, which a terminal in
+// raw mode no longer adds by itself.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(c.w, strings.ReplaceAll(string(p), "\n", "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const WatchInterval = 200 * time.Millisecond // How often spectators are sent the state, like the UI refresh
+
+// watchPage is the spectator page served at the root of the status API.
+//
+//go:embed watch.html
+var watchPage []byte
+
+// ansiColor matches the terminal color codes in log entries.
+var ansiColor = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plainLog strips the terminal colors that entries from older saves carry in
+// their text.
+func plainLog(entries []LogEntry) []LogEntry {
+	plain := make([]LogEntry, len(entries))
+	for i, entry := range entries {
+		plain[i] = LogEntry{Text: ansiColor.ReplaceAllString(entry.Text, ""), Severity: entry.Severity}
+	}
+	return plain
+}
+
+// serveStatus starts the status API on addr for the endpoints below, so
+// external dashboards and stream overlays can follow the run; spectators can
+// chat through it, but nothing sent to it reaches the reactor. Each takes
+// ?unit=<n> in a multi-reactor plant and defaults to the focused unit.
+//
+//	/       the spectator page, which renders /watch
+//	/state  the full GameState snapshot
+//	/log    the event log, one plain-text line per entry
+//	/score  the current score breakdown
+//	/watch  a WebSocket streaming state changes and new log entries, and
+//	        taking the spectators' chat messages
+func (p *Plant) serveStatus(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p.Units[0].spectators.host() // Shared by every unit
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(watchPage)
+	})
+	mux.HandleFunc("/watch", p.handleWatch)
+	mux.HandleFunc("/state", p.statusHandler(func(s GameState) any { return s }))
+	mux.HandleFunc("/log", p.statusHandler(func(s GameState) any { return s.Log }))
+	mux.HandleFunc("/score", p.statusHandler(func(s GameState) any { return s.Score }))
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln) // Returns http.ErrServerClosed once the game exits
+	return srv, nil
+}
+
+// statusHandler serves the part of a unit's snapshot picked out by view as JSON.
+func (p *Plant) statusHandler(view func(GameState) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		unit := p.requestedUnit(w, r)
+		if unit == nil {
+			return
+		}
+		s := unit.Snapshot()
+		s.Log = plainLog(s.Log)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*") // Overlays are usually served from elsewhere
+		w.Header().Set("Cache-Control", "no-store")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(view(s)) // The client hung up; nothing to do
+	}
+}
+
+// requestedUnit returns the unit named by ?unit=<n>, or the focused one. It
+// answers 404 and returns nil for a unit that does not exist.
+func (p *Plant) requestedUnit(w http.ResponseWriter, r *http.Request) *Game {
+	arg := r.URL.Query().Get("unit")
+	if arg == "" {
+		return p.Focused()
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(p.Units) {
+		http.Error(w, fmt.Sprintf("no reactor unit %s (units 1-%d)", arg, len(p.Units)), http.StatusNotFound)
+		return nil
+	}
+	return p.Units[n-1]
+}
+
+// watchMessage is one message on the /watch stream.
+type watchMessage struct {
+	Type    string     `json:"type"`              // "state", "log" or "chat_error"
+	State   *GameState `json:"state,omitempty"`   // The whole snapshot, whenever it changes
+	Entries []LogEntry `json:"entries,omitempty"` // Log entries added since the last message
+	Error   string     `json:"error,omitempty"`   // Why the spectator's chat message was refused
+}
+
+// watchChat is a chat message a spectator sends on the /watch stream.
+type watchChat struct {
+	Type string `json:"type"` // "chat"
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// handleWatch streams a unit to a spectator: the state every WatchInterval
+// when it has changed, preceded by any log entries added since the last one.
+// Without ?unit the stream follows the player's focus. The spectator may send
+// watchChat messages the other way, which go to the plant's spectator chat.
+func (p *Plant) handleWatch(w http.ResponseWriter, r *http.Request) {
+	unit := p.requestedUnit(w, r)
+	if unit == nil {
+		return
+	}
+	follow := r.URL.Query().Get("unit") == ""
+	from, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		from = r.RemoteAddr
+	}
+	c, err := upgradeWebSocket(w, r, func(msg []byte) []byte {
+		var chat watchChat
+		if json.Unmarshal(msg, &chat) != nil || chat.Type != "chat" {
+			return nil
+		}
+		if err := unit.spectatorSay(from, chat.Name, chat.Text); err != nil {
+			reply, _ := json.Marshal(watchMessage{Type: "chat_error", Error: err.Error()})
+			return reply
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	p.watch(unit, follow, c.Closed(), func(msg []byte) bool { return c.WriteText(msg) == nil })
+}
+
+// watch sends unit's watchMessages, JSON-encoded, until done is closed or
+// send fails. With follow it moves to whichever unit the player focuses.
+func (p *Plant) watch(unit *Game, follow bool, done <-chan struct{}, send func(msg []byte) bool) {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+
+	var last []byte
+	seen := -1
+	for {
+		if focused := p.Focused(); follow && focused != unit {
+			unit, seen = focused, -1 // The new unit's state carries its log
+		}
+		s := unit.Snapshot()
+		s.Log = plainLog(s.Log)
+		if fresh := s.LogCount - seen; seen >= 0 && fresh > 0 {
+			entries := s.Log
+			if fresh < len(entries) {
+				entries = entries[len(entries)-fresh:]
+			}
+			if data, err := json.Marshal(watchMessage{Type: "log", Entries: entries}); err != nil || !send(data) {
+				return
+			}
+		}
+		seen = s.LogCount
+		if data, err := json.Marshal(watchMessage{Type: "state", State: &s}); err == nil && !bytes.Equal(data, last) {
+			if !send(data) {
+				return
+			}
+			last = data
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// Languages. Every message the player reads goes through tr, which looks its
+// English text up in the message catalog of the language picked with --lang
+// and formats the translation like fmt.Sprintf. Catalogs are JSON files in
+// locales/, from English text to its translation; what a catalog lacks stays
+// English. Numbers keep their English formatting, so seeds and codes read
+// the same in any language. Commands take their English verbs in any
+// language, and the language's own verbs too, as verbs.go resolves them.
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var (
+	catalogs = loadCatalogs()
+	locale   = language.English // Set by --lang before the game starts
+)
+
+// loadCatalogs builds the message catalog from the embedded locale files.
+func loadCatalogs() *catalog.Builder {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	_ = b.SetString(language.English, "", "") // English is the source language, with no file of its own
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		tag := language.MustParse(strings.TrimSuffix(f.Name(), ".json"))
+		data, err := localeFiles.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("locales/%s: %v", f.Name(), err))
+		}
+		for key, msg := range messages {
+			if err := b.SetString(tag, key, msg); err != nil {
+				panic(fmt.Sprintf("locales/%s: %q: %v", f.Name(), key, err))
+			}
+		}
+	}
+	return b
+}
+
+func languageNames() []string {
+	names := []string{}
+	for _, tag := range catalogs.Languages() {
+		names = append(names, tag.String())
+	}
+	return names
+}
+
+// setLanguage picks the catalog for --lang, which may name a regional
+// variant of a language there is a catalog for, such as de-AT.
+func setLanguage(name string) error {
+	tag, err := language.Parse(name)
+	if err != nil {
+		return fmt.Errorf("unknown language %q (choose %s)", name, strings.Join(languageNames(), "|"))
+	}
+	_, index, confidence := catalogs.Matcher().Match(tag)
+	if confidence == language.No {
+		return fmt.Errorf("no translation for %q (choose %s)", name, strings.Join(languageNames(), "|"))
+	}
+	locale = catalogs.Languages()[index]
+	return nil
+}
+
+// tr translates message and formats it with args, as fmt.Sprintf would.
+// Arguments that are messages of their own, such as system names, are
+// translated too.
+func tr(message string, args ...any) string {
+	message = lookup(message)
+	if len(args) == 0 {
+		return message
+	}
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			args[i] = lookup(s)
+		}
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// lookup returns the translation of message, or message itself when the
+// catalog has none.
+func lookup(message string) string {
+	if locale == language.English {
+		return message
+	}
+	var r renderer
+	if catalogs.Context(locale, &r).Execute(message) != nil {
+		return message
+	}
+	return r.String()
+}
+
+// renderer collects the text of a catalog message.
+type renderer struct{ strings.Builder }
+
+func (r *renderer) Render(s string)       { r.WriteString(s) }
+func (r *renderer) Arg(i int) interface{} { return nil }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Early warning. The next random event is drawn when it is scheduled rather
+// than when it strikes, so a healthy Shield Integrity picks it up on its way:
+// IncomingLead before it strikes, a banner names the event and the system it
+// is aimed at, which leaves time to divert ahead of it. Events picked by a
+// chat vote are decided at the last moment and give no warning.
+
+const (
+	IncomingLead   = 6 * time.Second
+	IncomingSystem = "Shield Integrity" // Picks up events while above the warning threshold
+)
+
+// incomingEvent is the next random event, drawn ahead of time.
+type incomingEvent struct {
+	event     Event
+	target    int
+	announced bool // Picked up and shown to the player
+}
+
+// eventStrikes names the systems the built-in events strike in place of the
+// one they are aimed at, for the early warning; a layout without them falls
+// back on the target. Events it leaves out strike their target, and those
+// listed without systems strike the plant at large.
+var eventStrikes = map[string][]string{
+	"cosmic_rays":        nil,
+	"turbine_trip":       {"Power Output", "Pressure Ctrl"},
+	"control_rod_jam":    {"Core Temp"},
+	"steam_void":         {"Core Temp", "Pressure Ctrl"},
+	"earthquake":         nil,
+	"electrical_fire":    {"Power Output", "Shield Integrity"},
+	"backup_generator":   {"Power Output"},
+	"partial_scram":      {"Core Temp", "Power Output"},
+	"xenon_poisoning":    {"Power Output"},
+	"pressure_transient": {"Pressure Ctrl"},
+	"pump_cavitation":    {"Coolant Flow"},
+	"solar_flare":        {"Shield Integrity"},
+	"grid_fluctuation":   {"Power Output"},
+	"relief_valve":       {"Pressure Ctrl", "Coolant Flow"},
+	"supply_drop":        nil,
+	"spare_parts":        nil,
+}
+
+// IncomingState is the early warning as the dashboard shows it.
+type IncomingState struct {
+	Event   string        `json:"event"`
+	Systems []string      `json:"systems,omitempty"` // Struck by it; none for the plant at large
+	Left    time.Duration `json:"left_ns"`
+}
+
+// drawIncoming draws the next random event and its target, by the weights
+// of the phase it will strike in, unless a chat vote will pick it.
+func (g *Game) drawIncoming() {
+	var next *incomingEvent
+	if g.chat == nil && g.randomEventsEnabled() {
+		var at time.Duration
+		g.locked(func() {
+			at = g.elapsedAtLocked(g.nextEvent)
+		})
+		if ev := g.pickEvent(at); ev != nil {
+			next = &incomingEvent{event: ev, target: g.rng.Intn(len(g.Systems))}
+		}
+	}
+	g.mu.Lock()
+	g.incoming = next
+	g.mu.Unlock()
+}
+
+// takeIncoming returns the event drawn ahead of time, or nil, and clears
+// it.
+func (g *Game) takeIncoming() *incomingEvent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	next := g.incoming
+	g.incoming = nil
+	return next
+}
+
+// detectIncoming announces the next event once it is within IncomingLead
+// and the early warning system can pick it up.
+func (g *Game) detectIncoming() {
+	sensor := g.systemNamed(IncomingSystem, nil)
+	if sensor == nil {
+		return
+	}
+	sensor.mu.Lock()
+	healthy := !sensor.Offline && sensor.Value > sensor.WarningThreshold
+	sensor.mu.Unlock()
+	now, lead := g.clock.Now(), g.wallTime(IncomingLead)
+	g.mu.Lock()
+	next := g.incoming
+	left := g.nextEvent.Sub(now)
+	detected := healthy && next != nil && !next.announced && left <= lead
+	if detected {
+		next.announced = true
+	}
+	g.mu.Unlock()
+	if detected {
+		g.AddLog(LogWarning, tr("INCOMING: %s on %s in ~%ds, picked up by %s.", eventTitle(next.event.Name()), strikeNames(g.strikes(next)), ceilSeconds(left), sensor.Name))
+	}
+}
+
+// strikes names the systems next will strike.
+func (g *Game) strikes(next *incomingEvent) []string {
+	target := g.Systems[next.target]
+	names, listed := eventStrikes[next.event.Name()]
+	if crisis, ok := next.event.(*crisisEvent); ok {
+		names, listed = crisis.victims, true
+	}
+	if !listed {
+		return []string{target.Name}
+	}
+	var struck []string
+	for _, name := range names {
+		if sys := g.systemNamed(name, target); !slices.Contains(struck, sys.Name) {
+			struck = append(struck, sys.Name)
+		}
+	}
+	return struck
+}
+
+// strikeNames lists the systems an event strikes for the player.
+func strikeNames(systems []string) string {
+	if len(systems) == 0 {
+		return tr("the whole plant")
+	}
+	names := make([]string, len(systems))
+	for i, name := range systems {
+		names[i] = tr(name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// eventTitle names an event for the player.
+func eventTitle(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// incomingSnapshotLocked copies the early warning, or returns nil while no
+// event has been picked up. The caller must hold g.mu.
+func (g *Game) incomingSnapshotLocked(now time.Time) *IncomingState {
+	next := g.incoming
+	if next == nil || !next.announced {
+		return nil
+	}
+	return &IncomingState{Event: next.event.Name(), Systems: g.strikes(next), Left: max(0, g.nextEvent.Sub(now))}
+}
+
+// incomingBanner renders the early warning for the dashboard, or "".
+func (s GameState) incomingBanner() string {
+	v := s.Incoming
+	if v == nil {
+		return ""
+	}
+	return theme.Warning.With(color.Bold).Sprint(tr(">>> INCOMING: %s on %s in ~%ds <<<", eventTitle(v.Event), strikeNames(v.Systems), ceilSeconds(v.Left)))
+}
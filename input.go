@@ -0,0 +1,100 @@
+package main
+
+import "strings"
+
+// Player input. A session's lines are read on one goroutine and carried out
+// on another, in the order typed, while the dashboard is drawn on its own:
+// a slow frame or a busy game loop holds up neither the typing nor the
+// drawing, and no line is dropped on the way. The reader stops reading when
+// InputBacklog lines are waiting, and goes on once they have run.
+
+const InputBacklog = 64
+
+// playerInput carries a session's lines from its reader to the plant.
+type playerInput struct {
+	lines  chan string
+	done   chan struct{} // Closed once the input has ended and every line has run
+	quit   chan struct{} // Closed once the player quits
+	redraw chan struct{} // Receives once a line has run, for the dashboard to show it
+}
+
+// readInput starts reading s's lines, and carrying them out on p, until the
+// input ends, the player quits or stop is closed.
+func (p *Plant) readInput(s Session, stop <-chan struct{}) *playerInput {
+	in := &playerInput{
+		lines:  make(chan string, InputBacklog),
+		done:   make(chan struct{}),
+		quit:   make(chan struct{}),
+		redraw: make(chan struct{}, 1),
+	}
+	go func() {
+		defer close(in.lines) // The input has ended, or the reader failed
+		defer func() {
+			recover() // A reader that panics, as on stdin closed abruptly, ends the input
+		}()
+		for {
+			line, err := s.ReadLine()
+			if err != nil {
+				return
+			}
+			select {
+			case in.lines <- line:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(in.done)
+		for {
+			select {
+			case line, ok := <-in.lines:
+				if !ok {
+					return
+				}
+				if p.dispatch(line) {
+					close(in.quit)
+					return
+				}
+				select {
+				case in.redraw <- struct{}{}:
+				default: // A redraw is already due
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return in
+}
+
+// dispatch carries out a line of the player's input on the focused unit, and
+// reports whether it quits. Once the run has ended only quit is taken.
+func (p *Plant) dispatch(rawInput string) (quit bool) {
+	game := p.Focused()
+	input := strings.TrimSpace(rawInput)
+	parts := strings.Fields(strings.ToLower(input))
+	if len(parts) == 0 {
+		return false
+	}
+	command := game.resolveVerb(parts[0])
+	if command == "quit" { // Allow quit anytime
+		game.AddLog(LogInfo, tr("Exiting simulation..."))
+		return true
+	}
+	if over, won := p.Ended(); over || won {
+		game.AddLog(LogInfo, tr("Game ended. Only 'quit' is available."))
+		return false
+	}
+	switch {
+	case p.interceptCode(input): // Authorization codes bypass command parsing
+	case command == "reactor" && len(p.Units) > 1:
+		p.handleFocus(parts[1:])
+	case game.ended():
+		game.AddLog(LogInfo, tr("Unit %d is shut down. Switch units with 'reactor <n>'.", game.Unit))
+	default:
+		game.Submit(input)
+		game.call(func() {}) // Wait for it to run, so the redraw shows it
+	}
+	return false
+}
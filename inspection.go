@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Regulatory inspections. Every so often the regulators announce a visit
+// Notice ahead. Once they arrive every system must stay above the warning
+// threshold for Window: holding earns repair kits and a score bonus, while
+// the first system to slip ends the inspection failed, and the regulators
+// order the Penalty event on it. Inspections come with random events, so a
+// scenario without them has none, and nor does the tutorial.
+type InspectionConfig struct {
+	Every   time.Duration `toml:"every"` // Between visits, the first one after it too; 0 disables
+	Notice  time.Duration `toml:"notice"`
+	Window  time.Duration `toml:"window"`
+	Kits    int           `toml:"kits"`
+	Bonus   int           `toml:"bonus"`
+	Penalty string        `toml:"penalty"` // Event ordered on failure
+}
+
+func defaultInspections() InspectionConfig {
+	return InspectionConfig{
+		Every:   70 * time.Second,
+		Notice:  30 * time.Second,
+		Window:  20 * time.Second,
+		Kits:    1,
+		Bonus:   250,
+		Penalty: "partial_scram",
+	}
+}
+
+func (c InspectionConfig) validate() error {
+	switch {
+	case c.Every == 0:
+		return nil
+	case c.Every < 0 || c.Notice < 0 || c.Window <= 0:
+		return fmt.Errorf("inspections: every and notice cannot be negative, and window must be positive")
+	case c.Notice+c.Window > c.Every:
+		return fmt.Errorf("inspections: notice and window must fit within every")
+	case c.Kits < 0 || c.Bonus < 0:
+		return fmt.Errorf("inspections: kits and bonus cannot be negative")
+	}
+	if _, ok := lookupEvent(c.Penalty); !ok {
+		return fmt.Errorf("inspections: unknown penalty event %q", c.Penalty)
+	}
+	return nil
+}
+
+// Where the next inspection stands.
+const (
+	inspectionDue       = iota // Not announced yet
+	inspectionAnnounced        // The regulators are on their way
+	inspectionUnderWay         // The regulators are on the floor
+)
+
+// InspectionState is the next inspection, as the dashboard shows it.
+type InspectionState struct {
+	UnderWay bool          `json:"under_way"`
+	In       time.Duration `json:"in_ns"` // Until the regulators arrive, or leave once they have
+}
+
+// nextInspectionLocked returns when the next inspection opens, and whether
+// there is one before the run is over. The caller must hold g.mu.
+func (g *Game) nextInspectionLocked() (time.Duration, bool) {
+	cfg := g.Config.Inspections
+	if cfg.Every <= 0 || g.tutorial != nil || (g.Scenario != nil && g.Scenario.NoRandomEvents) {
+		return 0, false
+	}
+	open := time.Duration(g.inspectionsDone+1) * cfg.Every
+	if g.Mode != ModeEndless && open+cfg.Window > g.Config.GameDuration {
+		return 0, false
+	}
+	return open, true
+}
+
+// updateInspection announces, opens and concludes the inspections as they
+// come due. It runs on the game loop.
+func (g *Game) updateInspection() {
+	cfg := g.Config.Inspections
+	elapsed := g.Elapsed()
+	var open time.Duration
+	var ok bool
+	var stage int
+	g.locked(func() {
+		open, ok = g.nextInspectionLocked()
+		stage = g.inspectionStage
+	})
+	if !ok || elapsed < open-cfg.Notice {
+		return
+	}
+	if elapsed < open {
+		if stage == inspectionDue {
+			g.setInspectionStage(inspectionAnnounced)
+			g.AddLog(LogWarning, tr("INSPECTION: Regulators arrive in %s. Hold every system above WARNING for %s to pass.", formatDuration(open-elapsed), formatDuration(cfg.Window)))
+		}
+		return
+	}
+	if stage != inspectionUnderWay {
+		g.setInspectionStage(inspectionUnderWay)
+		g.AddLog(LogWarning, tr("INSPECTION: The regulators are on the floor. Keep every system above WARNING for %s.", formatDuration(cfg.Window)))
+	}
+	if slipped := g.belowWarning(); slipped != nil {
+		g.failInspection(slipped)
+	} else if elapsed >= open+cfg.Window {
+		g.passInspection()
+	}
+}
+
+func (g *Game) setInspectionStage(stage int) {
+	g.mu.Lock()
+	g.inspectionStage = stage
+	g.mu.Unlock()
+}
+
+// belowWarning returns the system furthest at or below its warning
+// threshold, offline ones included, or nil if every system is above it.
+func (g *Game) belowWarning() *System {
+	var low *System
+	lowest := 1 // Of low, how far it is above its threshold
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		value := sys.Value
+		sys.mu.Unlock()
+		if margin := value - sys.WarningThreshold; margin < lowest {
+			low, lowest = sys, margin
+		}
+	}
+	return low
+}
+
+// passInspection rewards an inspection every system came through.
+func (g *Game) passInspection() {
+	cfg := g.Config.Inspections
+	g.locked(func() {
+		g.inspectionsDone++
+		g.inspectionStage = inspectionDue
+		g.Score.Inspections++
+		g.Inventory[ItemRepairKit] += cfg.Kits
+	})
+	g.AddLog(LogSuccess, tr("INSPECTION PASSED: The regulators sign off. +%d repair kits, +%d points.", cfg.Kits, cfg.Bonus))
+}
+
+// failInspection ends the inspection on the system that slipped, and the
+// regulators order the penalty on it.
+func (g *Game) failInspection(slipped *System) {
+	g.mu.Lock()
+	g.inspectionsDone++
+	g.inspectionStage = inspectionDue
+	g.mu.Unlock()
+	slipped.mu.Lock()
+	value := slipped.Value
+	slipped.mu.Unlock()
+	g.AddLog(LogCritical, tr("INSPECTION FAILED: %s (%d) fell to %d in front of the regulators.", slipped.Name, slipped.ID, value))
+	if ev, ok := g.Events.Lookup(g.Config.Inspections.Penalty); ok {
+		g.triggerEvent(ev, slipped.ID)
+	}
+}
+
+// inspectionLocked returns the next inspection once it has been announced,
+// or nil. The caller must hold g.mu.
+func (g *Game) inspectionLocked(elapsed time.Duration) *InspectionState {
+	open, ok := g.nextInspectionLocked()
+	if !ok || g.inspectionStage == inspectionDue {
+		return nil
+	}
+	s := &InspectionState{In: max(0, open-elapsed)}
+	if g.inspectionStage == inspectionUnderWay {
+		s.UnderWay, s.In = true, max(0, open+g.Config.Inspections.Window-elapsed)
+	}
+	return s
+}
+
+// inspectionBanner renders the inspection header line, or "" while none
+// is announced.
+func (s GameState) inspectionBanner() string {
+	i := s.Inspection
+	switch {
+	case i == nil:
+		return ""
+	case i.UnderWay:
+		return theme.Warning.Sprint(tr("INSPECTION UNDER WAY: Keep every system above WARNING for %s more.", formatDuration(i.In)))
+	}
+	return theme.Notice.Sprint(tr("INSPECTION: Regulators arrive in %s.", formatDuration(i.In)))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Inventory item names, as typed after `use` and stored in saves.
+const (
+	ItemRepairKit = "repair_kit"
+	ItemCoolant   = "coolant"
+	ItemFuse      = "fuse"
+	ItemScrubber  = "scrubber"
+)
+
+const CoolantCanisterBoost = 30 // Integrity restored by one coolant canister
+
+// Item is a consumable the player carries. use is nil for items consumed by
+// other commands, like repair kits by stabilize.
+type Item struct {
+	Name          string
+	Label         string
+	Help          string
+	DefaultTarget string // System used when `use` is given no ID; empty if none is needed
+	NeedsTarget   bool
+	use           func(g *Game, target *System) bool // Reports whether the item was consumed
+}
+
+// itemCatalog lists every item in inventory panel order.
+var itemCatalog = []Item{
+	{Name: ItemRepairKit, Label: "Repair Kits", Help: "consumed by stabilize"},
+	{Name: ItemCoolant, Label: "Coolant Canisters", Help: fmt.Sprintf("instant +%d to Coolant Flow", CoolantCanisterBoost),
+		DefaultTarget: "Coolant Flow", NeedsTarget: true, use: useCoolant},
+	{Name: ItemFuse, Label: "Fuse Packs", Help: "blocks the next power surge", use: useFuse},
+	{Name: ItemScrubber, Label: "Radiation Scrubbers", Help: "removes 1 permanent degradation from a system",
+		NeedsTarget: true, use: useScrubber},
+}
+
+func lookupItem(name string) (Item, bool) {
+	for _, item := range itemCatalog {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+func itemNames() []string {
+	names := make([]string, 0, len(itemCatalog))
+	for _, item := range itemCatalog {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// itemCount returns how many of the named item the player carries.
+func (g *Game) itemCount(name string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.Inventory[name]
+}
+
+func (g *Game) addItem(name string, n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.Inventory[name] += n
+}
+
+// inventoryPanel renders the carried items for the dashboard.
+func (s GameState) inventoryPanel() string {
+	parts := make([]string, 0, len(itemCatalog))
+	for _, item := range itemCatalog {
+		entry := fmt.Sprintf("%s x%d", tr(item.Label), s.Inventory[item.Name])
+		if item.Name == ItemFuse && s.FuseArmed {
+			entry += " (armed)"
+		}
+		parts = append(parts, entry)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// handleUse consumes one item, optionally on the system given by args[0].
+func (g *Game) handleUse(name string, args []string) {
+	item, ok := lookupItem(name)
+	if !ok {
+		g.AddLog(LogWarning, tr("Error: Unknown item %q (choose %s).", name, strings.Join(itemNames(), "|")))
+		return
+	}
+	if item.use == nil {
+		g.AddLog(LogWarning, tr("%s cannot be used directly (%s).", item.Label, item.Help))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot use items: Player busy with another action."))
+		return
+	}
+	if g.itemCount(item.Name) <= 0 {
+		g.AddLog(LogWarning, tr("Cannot use: No %s left!", strings.ToLower(item.Label)))
+		return
+	}
+
+	var target *System
+	if item.NeedsTarget {
+		if len(args) > 0 {
+			sysID, err := strconv.Atoi(args[0])
+			if err != nil || sysID < 0 || sysID >= len(g.Systems) {
+				g.AddLog(LogWarning, tr("Error: Invalid system ID for use."))
+				return
+			}
+			target = g.Systems[sysID]
+		} else if target = g.systemNamed(item.DefaultTarget, nil); target == nil {
+			g.AddLog(LogInfo, tr("Usage: use %s <system_id>", item.Name))
+			return
+		}
+		if target.IsOffline() {
+			g.AddLog(LogWarning, tr("Cannot use %s: %s (%d) is OFFLINE. Reboot it first.", item.Name, target.Name, target.ID))
+			return
+		}
+	}
+
+	if item.use(g, target) {
+		g.addItem(item.Name, -1)
+	}
+}
+
+func useCoolant(g *Game, target *System) bool {
+	target.Boost(CoolantCanisterBoost)
+	g.AddLog(LogSuccess, tr("Coolant canister purged into %s (%d). Value increased by %d.", target.Name, target.ID, CoolantCanisterBoost))
+	return true
+}
+
+func useFuse(g *Game, _ *System) bool {
+	g.mu.Lock()
+	armed := g.fuseArmed
+	g.fuseArmed = true
+	g.mu.Unlock()
+	if armed {
+		g.AddLog(LogWarning, tr("A fuse pack is already installed."))
+		return false
+	}
+	g.AddLog(LogSuccess, tr("Fuse pack installed. The next power surge will be absorbed."))
+	return true
+}
+
+func useScrubber(g *Game, target *System) bool {
+	target.mu.Lock()
+	scrubbed := target.DegradationRate > 1
+	if scrubbed {
+		target.DegradationRate--
+	}
+	target.mu.Unlock()
+	if !scrubbed {
+		g.AddLog(LogWarning, tr("%s (%d) is already clean. Scrubber not used.", target.Name, target.ID))
+		return false
+	}
+	g.AddLog(LogSuccess, tr("Radiation scrubber cycled on %s (%d). Degradation reduced.", target.Name, target.ID))
+	return true
+}
+
+// blowFuse consumes an armed fuse pack, reporting whether one was armed.
+func (g *Game) blowFuse() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	armed := g.fuseArmed
+	g.fuseArmed = false
+	return armed
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Ironman. An --ironman run has one save, the IronmanSlot of the profile,
+// which it writes every IronmanInterval and on quitting, and which is
+// removed when the run ends: there is no saving by hand and no going back.
+// The run under way is noted in the profile with a key of its own, and every
+// save is sealed with an HMAC-SHA256 under that key, so a save edited, copied
+// in from another run, or kept past the end of its own is refused. The key
+// sits in the profile's JSON beside the run, so the seal is tamper-evident
+// against casual edits to a save, not against a player who reads the key out
+// of the profile. A run whose save has gone missing or been tampered with is
+// forfeit, as a loss.
+
+const (
+	IronmanSlot     = "ironman"
+	IronmanInterval = time.Second
+)
+
+// IronmanRun is the ironman run under way, as the profile keeps it.
+type IronmanRun struct {
+	ID  string `json:"id"`
+	Key string `json:"key"` // Seals the run's saves, hex
+}
+
+// newIronmanRun starts a run with a fresh ID and key.
+func newIronmanRun() (*IronmanRun, error) {
+	b := make([]byte, 40)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return &IronmanRun{ID: hex.EncodeToString(b[:8]), Key: hex.EncodeToString(b[8:])}, nil
+}
+
+// seal returns the seal of st, whose own Seal is left out.
+func (r *IronmanRun) seal(st SaveState) string {
+	st.Seal = ""
+	data, _ := json.Marshal(st) // Plain fields always marshal
+	mac := hmac.New(sha256.New, []byte(r.Key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readIronmanSave reads the save of run from path. It returns nil and no
+// error if there is none.
+func readIronmanSave(path string, run *IronmanRun) (*SaveState, error) {
+	st, err := ReadSaveFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case st.Ironman != run.ID:
+		return nil, fmt.Errorf("the save is not of the run under way")
+	case !hmac.Equal([]byte(st.Seal), []byte(run.seal(st))):
+		return nil, fmt.Errorf("the save has been tampered with")
+	}
+	return &st, nil
+}
+
+// startIronman returns the save to resume the profile's ironman run from,
+// nil for a new run, and a note for the player if the last run was forfeit.
+func startIronman(prof *Profile, path string) (*SaveState, string, error) {
+	if prof.Ironman != nil {
+		st, err := readIronmanSave(path, prof.Ironman)
+		if err == nil && st != nil {
+			return st, "", nil
+		}
+		reason := tr("its save is missing")
+		if err != nil {
+			reason = err.Error()
+		}
+		prof.Runs++
+		prof.Losses++
+		prof.Ironman = nil
+		note := tr("The last ironman run is forfeit, as a loss: %s.", reason)
+		if err := prof.startIronman(); err != nil {
+			return nil, "", err
+		}
+		_ = os.Remove(path) // A new run starts clean
+		return nil, note, nil
+	}
+	return nil, "", prof.startIronman()
+}
+
+// startIronman notes a new ironman run under way, and saves the profile so
+// the run cannot be dodged by quitting.
+func (p *Profile) startIronman() error {
+	run, err := newIronmanRun()
+	if err != nil {
+		return err
+	}
+	p.Ironman = run
+	return p.Save()
+}
+
+// ironmanBanner renders the ironman header line, or "" for another run.
+func (s GameState) ironmanBanner() string {
+	if !s.Ironman {
+		return ""
+	}
+	return theme.Accent.Sprint(tr("IRONMAN: One save, kept for you. It is gone when the run ends."))
+}
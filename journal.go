@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal types. A journal is a JSON-lines file of everything that happens
+// in a run, kept apart from the log on screen, which only holds the latest
+// entries. Every change to a system's integrity is recorded with its cause:
+// the event or command that made it, the degradation tick, or a timer such as
+// a stabilization completing.
+const (
+	JournalStart   = "start"   // The run began, or resumed from a save
+	JournalEvent   = "event"   // An event struck, aimed at System
+	JournalCommand = "command" // A line of input ran
+	JournalChange  = "change"  // A system's integrity moved by Delta to Value
+	JournalOffline = "offline" // A system went offline
+	JournalOnline  = "online"  // An offline system came back
+	JournalLog     = "log"     // An entry of the on-screen log
+	JournalEnd     = "end"     // The run ended
+
+	CauseTick       = "degradation"
+	CauseTimer      = "timer"
+	CauseConditions = "conditions"
+)
+
+// JournalEntry is one line of the journal.
+type JournalEntry struct {
+	Time     time.Time     `json:"time"`
+	Elapsed  time.Duration `json:"elapsed_ns"`
+	Unit     int           `json:"unit,omitempty"` // In a multi-reactor plant
+	Type     string        `json:"type"`
+	Name     string        `json:"name,omitempty"` // The event, or the command line
+	Operator string        `json:"operator,omitempty"`
+	SystemID *int          `json:"system_id,omitempty"`
+	System   string        `json:"system,omitempty"`
+	Delta    int           `json:"delta,omitempty"`
+	Value    *int          `json:"value,omitempty"` // After the change
+	Cause    string        `json:"cause,omitempty"`
+	Message  string        `json:"message,omitempty"`  // Log text, the settings at the start, the outcome at the end
+	Severity string        `json:"severity,omitempty"` // Of a log entry
+	Score    *int          `json:"score,omitempty"`    // At the end
+}
+
+// Journal writes a run's entries to a file. Every unit of a plant shares one.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// OpenJournal creates or truncates the journal at path.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (j *Journal) write(e JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.enc.Encode(e) // A full disk must not stop the game
+}
+
+// Close flushes the journal to disk.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return err
+	}
+	return j.file.Close()
+}
+
+// journalState is what a game's journal last recorded, to tell what changed.
+type journalState struct {
+	values  []int
+	offline []bool
+	ended   bool
+}
+
+// startJournal records the game from now on to j. It must be called before
+// the game loop starts.
+func (g *Game) startJournal(j *Journal) {
+	g.journal = j
+	g.journalCause = CauseTimer
+	g.journalWrite(JournalEntry{Type: JournalStart, Message: fmt.Sprintf("%s, %s, seed %d", g.Difficulty.Name, g.Mode, g.Seed)})
+	g.journalSettle("")
+}
+
+// journalWrite stamps and records e.
+func (g *Game) journalWrite(e JournalEntry) {
+	if g.journal == nil {
+		return
+	}
+	g.locked(func() {
+		g.stampJournalLocked(&e)
+	})
+	g.journal.write(e)
+}
+
+// stampJournalLocked fills in the time and unit of e. The caller must hold g.mu.
+func (g *Game) stampJournalLocked(e *JournalEntry) {
+	now := g.clock.Now()
+	e.Time, e.Unit = now, g.Unit
+	if !g.EndTime.IsZero() {
+		now = g.EndTime
+	}
+	e.Elapsed = g.elapsedAtLocked(now)
+}
+
+// journalSettle records every change since the last settle as caused by
+// cause, and the end of the run once it has ended. It must run on the game
+// loop.
+func (g *Game) journalSettle(cause string) {
+	if g.journal == nil {
+		return
+	}
+	s := &g.journalSeen
+	if s.values == nil {
+		s.values, s.offline = make([]int, len(g.Systems)), make([]bool, len(g.Systems))
+		for i := range s.values {
+			s.values[i] = -1 // Anything, so the first settle records the start values
+		}
+	}
+	for i, sys := range g.Systems {
+		sys.mu.Lock()
+		value, offline, name := sys.Value, sys.Offline, sys.Name
+		sys.mu.Unlock()
+		id := i
+		if value != s.values[i] {
+			e := JournalEntry{Type: JournalChange, SystemID: &id, System: name, Value: &value, Cause: cause}
+			if s.values[i] >= 0 {
+				e.Delta = value - s.values[i]
+			}
+			g.journalWrite(e)
+			s.values[i] = value
+		}
+		if offline != s.offline[i] {
+			kind := JournalOffline
+			if !offline {
+				kind = JournalOnline
+			}
+			g.journalWrite(JournalEntry{Type: kind, SystemID: &id, System: name, Cause: cause})
+			s.offline[i] = offline
+		}
+	}
+	if g.ended() && !s.ended {
+		s.ended = true
+		score := g.CurrentScore().Total
+		g.journalWrite(JournalEntry{Type: JournalEnd, Message: g.outcomeText(), Score: &score})
+	}
+}
+
+// journalCaused records e, and then attributes the changes made until the
+// returned func is called to cause. Changes made before are settled first,
+// under the cause in force until now.
+func (g *Game) journalCaused(e JournalEntry, cause string) (done func()) {
+	if g.journal == nil {
+		return func() {}
+	}
+	outer := g.journalCause
+	g.journalSettle(outer)
+	g.journalWrite(e)
+	g.journalCause = cause
+	return func() {
+		g.journalSettle(cause)
+		g.journalCause = outer
+	}
+}
+
+// journalEvent records ev striking target; see journalCaused.
+func (g *Game) journalEvent(ev Event, target *System) (done func()) {
+	id := target.ID
+	return g.journalCaused(JournalEntry{Type: JournalEvent, Name: ev.Name(), SystemID: &id, System: target.Name}, ev.Name())
+}
+
+// journalCommand records a line of input about to run; see journalCaused.
+func (g *Game) journalCommand(input string) (done func()) {
+	if g.journal == nil {
+		return func() {}
+	}
+	g.mu.Lock()
+	operator := g.Operator.Name
+	g.mu.Unlock()
+	return g.journalCaused(JournalEntry{Type: JournalCommand, Name: input, Operator: operator}, input)
+}
+
+// journalTurn settles the changes of a turn of the game loop that no event or
+// command accounts for, and readies the next turn.
+func (g *Game) journalTurn() {
+	g.journalSettle(g.journalCause)
+	g.journalCause = CauseTimer
+}
+
+// journalLogLocked records an entry of the on-screen log. The caller must
+// hold g.mu.
+func (g *Game) journalLogLocked(severity Severity, entry string) {
+	if g.journal == nil {
+		return
+	}
+	e := JournalEntry{Type: JournalLog, Message: entry, Severity: severity.String()}
+	g.stampJournalLocked(&e)
+	g.journal.write(e)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Key map. The config's [keys] table remaps the hotkeys, and its
+// [keys.cooldowns] table how long each command with a cooldown is locked
+// out after use; the help pages and the command list show the lengths in
+// effect. `keys` shows the whole map: hotkeys, cooldowns, aliases and verbs.
+
+// KeysTopic is the help topic of the key map.
+const KeysTopic = "keys"
+
+// KeysConfig is the key map: one key for each hotkey action, and cooldowns
+// that replace the built-in ones.
+type KeysConfig struct {
+	Stabilize string                   `toml:"stabilize"`
+	Vent      string                   `toml:"vent"`
+	Override  string                   `toml:"override"`
+	Reboot    string                   `toml:"reboot"`
+	Inspect   string                   `toml:"inspect"`
+	Divert    string                   `toml:"divert"`    // Opens the divert dialog
+	Command   string                   `toml:"command"`   // Opens a line to type a command on
+	Cooldowns map[string]time.Duration `toml:"cooldowns"` // By command: divert, vent, override
+}
+
+func defaultKeys() KeysConfig {
+	return KeysConfig{Stabilize: "s", Vent: "v", Override: "o", Reboot: "r", Inspect: "i", Divert: "d", Command: ":"}
+}
+
+// hotkeyBinding is a key and what it does.
+type hotkeyBinding struct {
+	key    string
+	action string // The command it types, or "divert" or "command"
+}
+
+// bindings lists the keys in the order the prompt and the keys screen show
+// them.
+func (c KeysConfig) bindings() []hotkeyBinding {
+	return []hotkeyBinding{
+		{c.Stabilize, "stabilize"}, {c.Vent, "vent"}, {c.Override, "override"},
+		{c.Reboot, "reboot"}, {c.Inspect, "inspect"}, {c.Divert, "divert"}, {c.Command, "command"},
+	}
+}
+
+// actions maps the keys that act on the selected system to their commands.
+func (c KeysConfig) actions() map[byte]string {
+	actions := map[byte]string{}
+	for _, b := range c.bindings()[:5] {
+		actions[b.key[0]] = b.action
+	}
+	return actions
+}
+
+func (c KeysConfig) validate() error {
+	seen := map[string]string{}
+	for _, b := range c.bindings() {
+		r, size := utf8.DecodeRuneInString(b.key)
+		switch {
+		case size != len(b.key) || r <= ' ' || r >= keyBackspace:
+			return fmt.Errorf("keys: %s must be one printable ASCII character, got %q", b.action, b.key)
+		case r >= '0' && r <= '9':
+			return fmt.Errorf("keys: %s cannot be %q: digits select systems", b.action, b.key)
+		case seen[b.key] != "":
+			return fmt.Errorf("keys: %s and %s are both %q", seen[b.key], b.action, b.key)
+		}
+		seen[b.key] = b.action
+	}
+	for command, d := range c.Cooldowns {
+		if _, ok := commandCooldowns[command]; !ok {
+			return fmt.Errorf("keys.cooldowns: %q has no cooldown (choose %s)", command, strings.Join(cooldownCommands(), "|"))
+		}
+		if d < 0 {
+			return fmt.Errorf("keys.cooldowns: %s cannot be negative", command)
+		}
+	}
+	return nil
+}
+
+// cooldown is how long command is locked out after use.
+func (c KeysConfig) cooldown(command string) time.Duration {
+	if d, ok := c.Cooldowns[command]; ok {
+		return d
+	}
+	return commandCooldowns[command]
+}
+
+// cooldownLengths returns the cooldown of every command that has one.
+func (c KeysConfig) cooldownLengths() map[string]time.Duration {
+	lengths := make(map[string]time.Duration, len(commandCooldowns))
+	for command := range commandCooldowns {
+		lengths[command] = c.cooldown(command)
+	}
+	return lengths
+}
+
+func cooldownCommands() []string {
+	commands := make([]string, 0, len(commandCooldowns))
+	for command := range commandCooldowns {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// keysPage is the keys screen: every binding and command word in effect.
+func (g *Game) keysPage() []string {
+	key := func(k, does string) string { return fmt.Sprintf("  %-8s %s", k, does) }
+	lines := []string{tr("HOTKEYS (with --hotkeys):"), key("0-9", tr("select a system"))}
+	for _, b := range g.Config.Keys.bindings() {
+		switch b.action {
+		case "divert":
+			lines = append(lines, key(b.key, tr("divert from the selected system")))
+		case "command":
+			lines = append(lines, key(b.key, tr("type a command")))
+		default:
+			lines = append(lines, key(b.key, tr("%s the selected system", b.action)))
+		}
+	}
+	lines = append(lines, key("Esc", tr("clear the selection")), key("Ctrl+C", tr("quit")), "", tr("COOLDOWNS:"))
+	for _, command := range cooldownCommands() {
+		lines = append(lines, fmt.Sprintf("  %-12s %s", command, g.Config.Keys.cooldown(command)))
+	}
+	if len(g.Config.Aliases) > 0 {
+		lines = append(lines, "", tr("ALIASES:"))
+		for _, name := range g.aliasNames() {
+			lines = append(lines, fmt.Sprintf("  %-12s %s", name, g.Config.Aliases[name]))
+		}
+	}
+	if verbs := g.verbNames(); len(verbs) > 0 {
+		lines = append(lines, "", tr("VERBS:"))
+		for _, verb := range verbs {
+			lines = append(lines, fmt.Sprintf("  %-12s %s", verb, g.resolveVerb(verb)))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ScreenSize is the terminal the dashboard is laid out for, in columns and
+// rows.
+type ScreenSize struct {
+	Width, Height int
+}
+
+// Screen sizes. Below ScreenWidth bars shrink and the sparklines go; from
+// WideScreen the log moves beside the status panel. Whatever the size, no
+// line is drawn past the last column, so the terminal never wraps one.
+const (
+	ScreenWidth  = 80 // Columns the dashboard is laid out for
+	ScreenHeight = 24
+	LogColumnMin = 40 // The narrowest log column of the wide layout
+	WideScreen   = ScreenWidth + 2 + LogColumnMin
+	MinLogLines  = 3 // The fewest log entries kept on a short screen
+
+	BarWidth       = 20 // The status bars at full width
+	BarMin         = 10
+	SparklineMin   = 4
+	statusColumns  = 4 + 18 + 2 + 7 + 1 + 2 // "[n] <name>: nnn/nnn [" and "]"
+	screenNarrowed = "~"                    // Ends a line cut to fit
+)
+
+// DefaultScreen is assumed where the terminal cannot be asked.
+var DefaultScreen = ScreenSize{ScreenWidth, ScreenHeight}
+
+// terminalSize returns the size of the terminal on standard output.
+func terminalSize() ScreenSize {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return DefaultScreen
+	}
+	return ScreenSize{width, height}
+}
+
+func (size ScreenSize) wide() bool {
+	return size.Width >= WideScreen
+}
+
+// column is the width the status panel is laid out for.
+func (size ScreenSize) column() int {
+	if size.wide() {
+		return ScreenWidth
+	}
+	return size.Width
+}
+
+// statusWidths returns how wide to draw the status bars, and how much room a
+// status line whose tags take extra columns has left for its sparkline, 0
+// for none. tagged is the longest tags of any line, which all bars make room
+// for.
+func statusWidths(column, tagWidth, tagged, extra int) (bar, spark int) {
+	room := column - 1 - statusColumns - tagWidth
+	bar = min(BarWidth, max(BarMin, room-tagged))
+	if spark = min(SparklineWidth, room-bar-1-extra); spark < SparklineMin {
+		spark = 0
+	}
+	return bar, spark
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// visibleLen is how many columns s takes on screen, leaving out its escape
+// codes.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// fitLine cuts line to width columns, keeping its escape codes whole.
+func fitLine(line string, width int) string {
+	if width < 1 || visibleLen(line) <= width {
+		return line
+	}
+	var b strings.Builder
+	shown, styled := 0, false
+	for len(line) > 0 && shown < width-1 {
+		if loc := ansiEscape.FindStringIndex(line); loc != nil && loc[0] == 0 {
+			b.WriteString(line[:loc[1]])
+			line, styled = line[loc[1]:], true
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(line)
+		b.WriteRune(r)
+		line, shown = line[n:], shown+1
+	}
+	b.WriteString(screenNarrowed)
+	if styled {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// padLine fills line out to width columns.
+func padLine(line string, width int) string {
+	return line + strings.Repeat(" ", max(0, width-visibleLen(line)))
+}
+
+// writeFitted writes text cut line by line to width.
+func writeFitted(w io.Writer, text string, width int) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = fitLine(line, width)
+	}
+	fmt.Fprint(w, strings.Join(lines, "\n"))
+}
+
+// dashboard collects the sections of the dashboard as they are drawn, to be
+// laid out for the screen at the end.
+type dashboard struct {
+	status     bytes.Buffer // Everything above the log
+	logHeading string
+	log        []string     // The entries, colored
+	spectators []string     // The spectators' chat, under the log; the first line of the panel is blank
+	commands   bytes.Buffer // The command list, ending with the prompt
+}
+
+func splitLines(b *bytes.Buffer) []string {
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+var commandNames = regexp.MustCompile(`(?:^|\|)\s*([a-z]+)`)
+
+// compactCommands turns the command list into the names of the commands,
+// wrapped to width, for a screen too short for the full list. The heading
+// and the prompt stay.
+func compactCommands(lines []string, width int) []string {
+	if len(lines) < 3 {
+		return lines
+	}
+	out := []string{lines[0], lines[1]}
+	prompt := lines[len(lines)-1]
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range lines[2 : len(lines)-1] {
+		usage := strings.TrimSpace(ansiEscape.ReplaceAllString(line, ""))
+		if i := strings.Index(usage, "("); i >= 0 {
+			usage = usage[:i]
+		}
+		for _, m := range commandNames.FindAllStringSubmatch(usage, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				names = append(names, m[1])
+			}
+		}
+	}
+	line := " "
+	for _, name := range names {
+		if len(line)+1+len(name) > width-1 && line != " " {
+			out = append(out, line)
+			line = " "
+		}
+		line += " " + name
+	}
+	return append(out, line, prompt)
+}
+
+// render lays the dashboard out for size: the log and the spectators' chat
+// beside the status panel on a wide screen and below it otherwise, the
+// command list cut down to names, the chat to its latest messages or away,
+// and the log to its latest entries if the screen is too short for them.
+func (d *dashboard) render(w io.Writer, size ScreenSize) {
+	status, commands := splitLines(&d.status), splitLines(&d.commands)
+	logHeading := []string{"", d.logHeading}
+	rows := func() int {
+		if size.wide() {
+			return max(len(status), 1+len(d.log)+len(d.spectators)) + len(commands)
+		}
+		return len(status) + len(logHeading) + len(d.log) + len(d.spectators) + len(commands)
+	}
+	if rows() > size.Height {
+		commands = compactCommands(commands, size.Width)
+	}
+	if extra := rows() - size.Height; extra > 0 && len(d.spectators) > 0 {
+		if messages := len(d.spectators) - 2; extra < messages {
+			d.spectators = append(d.spectators[:2:2], d.spectators[2+extra:]...)
+		} else {
+			d.spectators = nil
+		}
+	}
+	if extra := rows() - size.Height; extra > 0 {
+		d.log = d.log[min(extra, max(0, len(d.log)-MinLogLines)):]
+	}
+
+	var lines []string
+	if size.wide() {
+		logLines := append(append(logHeading[1:], d.log...), d.spectators...)
+		logWidth := size.Width - ScreenWidth - 2
+		for i := 0; i < max(len(status), len(logLines)); i++ {
+			left, right := "", ""
+			if i < len(status) {
+				left = fitLine(status[i], ScreenWidth)
+			}
+			if i < len(logLines) {
+				right = fitLine(logLines[i], logWidth)
+			}
+			lines = append(lines, padLine(left, ScreenWidth)+"  "+right)
+		}
+	} else {
+		lines = append(append(append(status, logHeading...), d.log...), d.spectators...)
+	}
+	lines = append(lines, commands...)
+	for i, line := range lines {
+		lines[i] = fitLine(line, size.Width)
+	}
+	fmt.Fprint(w, strings.Join(lines, "\n"))
+}
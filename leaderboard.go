@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Global leaderboard. Finished runs are posted as JSON to <url>/scores,
+// signed with an HMAC-SHA256 of the body under a key shared with the server:
+//
+//	POST /scores
+//	X-Reactor-Signature: sha256=<hex digest>
+//	{"name": "kim", "score": 1234, "seed": 42, ..., "replay_hash": "<hex>"}
+//
+// The server may answer with the run's rank, {"rank": 4}. GET /scores?limit=20
+// returns the best runs, highest score first, in the same form.
+const (
+	LeaderboardKeyEnv  = "REACTOR_LEADERBOARD_KEY" // The signing key, kept off the command line
+	LeaderboardSize    = 20
+	LeaderboardTimeout = 10 * time.Second
+	SignatureHeader    = "X-Reactor-Signature"
+)
+
+// Leaderboard is a remote score server.
+type Leaderboard struct {
+	URL  string // Base URL
+	Key  string // Signs submissions; without it runs are not submitted
+	Name string // The player's name on the board
+}
+
+// LeaderboardEntry is one run on the board.
+type LeaderboardEntry struct {
+	Name       string        `json:"name"`
+	Score      int           `json:"score"`
+	Seed       int64         `json:"seed"`
+	Difficulty string        `json:"difficulty"`
+	Mode       string        `json:"mode"`
+	Mutators   []string      `json:"mutators,omitempty"`
+	Scenario   string        `json:"scenario,omitempty"`
+	Reactors   int           `json:"reactors,omitempty"` // Units played; 0 means one
+	Won        bool          `json:"won"`
+	Scram      bool          `json:"scram,omitempty"`
+	Ironman    bool          `json:"ironman,omitempty"`
+	Duration   time.Duration `json:"duration_ns"`
+	ConfigHash string        `json:"config_hash"`
+	Challenge  string        `json:"challenge,omitempty"` // Replays the run's seed and settings with --challenge
+	ReplayHash string        `json:"replay_hash"`         // Identifies the run's seed, settings and commands
+	Date       time.Time     `json:"date"`
+}
+
+var leaderboardClient = &http.Client{Timeout: LeaderboardTimeout}
+
+// Submit posts entry and returns its rank, or 0 if the server gave none.
+func (lb *Leaderboard) Submit(entry LeaderboardEntry) (int, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, lb.endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	mac := hmac.New(sha256.New, []byte(lb.Key))
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := leaderboardClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return 0, fmt.Errorf("server answered %s", resp.Status)
+	}
+	var result struct {
+		Rank int `json:"rank"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result) // The rank is optional
+	return result.Rank, nil
+}
+
+// Top fetches the best n runs.
+func (lb *Leaderboard) Top(n int) ([]LeaderboardEntry, error) {
+	resp, err := leaderboardClient.Get(fmt.Sprintf("%s?limit=%d", lb.endpoint(), n))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server answered %s", resp.Status)
+	}
+	var entries []LeaderboardEntry
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid leaderboard: %w", err)
+	}
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
+
+func (lb *Leaderboard) endpoint() string {
+	return strings.TrimSuffix(lb.URL, "/") + "/scores"
+}
+
+// PrintLeaderboard fetches and prints the global table for `leaderboard`.
+func PrintLeaderboard(lb *Leaderboard) error {
+	entries, err := lb.Top(LeaderboardSize)
+	if err != nil {
+		return err
+	}
+	w := color.Output
+	fmt.Fprintln(w, theme.Heading.Sprint(tr("GLOBAL TOP %d:", LeaderboardSize)))
+	if len(entries) == 0 {
+		fmt.Fprintln(w, tr("  No runs submitted yet."))
+		return nil
+	}
+	fmt.Fprintf(w, "  %-4s %-16s %8s  %-6s  %-10s %-15s %-6s %-20s  %-16s  %s\n", "#", "NAME", "SCORE", "TIME", "DIFFICULTY", "MODE", "RESULT", "SEED", "DATE", "CHALLENGE")
+	for i, e := range entries {
+		result := "LOSS"
+		if e.Won {
+			result = "WIN"
+		} else if e.Scram {
+			result = "SCRAM"
+		}
+		mode := e.Mode
+		if e.Reactors > 1 {
+			mode = fmt.Sprintf("%s x%d", e.Mode, e.Reactors)
+		}
+		if e.Ironman {
+			mode += " ironman"
+		}
+		line := fmt.Sprintf("  %-4d %-16s %8d  %-6s  %-10s %-15s %-6s %-20d  %-16s  %s", i+1, e.Name, e.Score, formatDuration(e.Duration),
+			strings.ToUpper(e.Difficulty), mode, result, e.Seed, e.Date.Format("2006-01-02 15:04"), e.Challenge)
+		if strings.EqualFold(e.Name, lb.Name) {
+			line = theme.Success.Sprintf(line)
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// submitScore posts the finished run to lb and notes the result on the
+// summary screen.
+func (p *Plant) submitScore(lb *Leaderboard) {
+	first := p.Units[0]
+	note := ""
+	if lb.Key == "" {
+		note = theme.Warning.Sprint(tr("LEADERBOARD: Not submitted: set %s to sign runs.", LeaderboardKeyEnv))
+	} else {
+		p.noteLeaderboard(theme.Info.Sprint(tr("LEADERBOARD: Submitting as %s...", lb.Name)))
+		hs := p.highScoreEntry()
+		entry := LeaderboardEntry{
+			Name:       lb.Name,
+			Score:      hs.Score,
+			Seed:       hs.Seed,
+			Difficulty: hs.Difficulty,
+			Mode:       hs.Mode,
+			Mutators:   mutatorList(first.Mutators),
+			Reactors:   hs.Reactors,
+			Won:        hs.Won,
+			Scram:      hs.Scram,
+			Ironman:    first.ironman != nil,
+			Duration:   hs.Duration,
+			ConfigHash: first.configHash,
+			Challenge:  hs.Challenge,
+			ReplayHash: p.replayHash(),
+			Date:       hs.Date,
+		}
+		if first.Scenario != nil {
+			entry.Scenario = first.Scenario.Name
+		}
+		switch rank, err := lb.Submit(entry); {
+		case err != nil:
+			note = theme.Critical.Sprint(tr("LEADERBOARD: Failed to submit: %v", err))
+		case rank > 0:
+			note = theme.Info.Sprint(tr("LEADERBOARD: Submitted as %s, global rank #%d.", lb.Name, rank))
+		default:
+			note = theme.Info.Sprint(tr("LEADERBOARD: Submitted as %s.", lb.Name))
+		}
+	}
+	p.noteLeaderboard(note)
+}
+
+func (p *Plant) noteLeaderboard(note string) {
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		unit.leaderboardNote = note
+		unit.mu.Unlock()
+	}
+}
+
+// recordReplay adds a command to the digest of the run. It must run on the
+// game loop.
+func (g *Game) recordReplay(input string) {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.replay == nil {
+		g.replay = sha256.New()
+	}
+	fmt.Fprintf(g.replay, "%d %s\n", elapsed.Milliseconds(), strings.Join(strings.Fields(strings.ToLower(input)), " "))
+}
+
+// replayStateLocked returns the digest in progress, for a save file. The
+// caller must hold g.mu.
+func (g *Game) replayStateLocked() []byte {
+	if g.replay == nil {
+		return nil
+	}
+	state, _ := g.replay.(encoding.BinaryMarshaler).MarshalBinary() // sha256 digests always marshal
+	return state
+}
+
+// resumeReplay rebuilds the digest saved by replayStateLocked.
+func resumeReplay(state []byte) (hash.Hash, error) {
+	if len(state) == 0 {
+		return nil, nil
+	}
+	replay := sha256.New()
+	if err := replay.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, fmt.Errorf("save has invalid replay digest: %w", err)
+	}
+	return replay, nil
+}
+
+// replayHash identifies the run: the seed and settings of every unit and the
+// commands run on it, with their timing.
+func (p *Plant) replayHash() string {
+	sum := sha256.New()
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		var commands []byte
+		if unit.replay != nil {
+			commands = unit.replay.Sum(nil)
+		}
+		fmt.Fprintf(sum, "%d|%s|%s|%s|%x\n", unit.Seed, unit.Difficulty.Name, unit.Mode, strings.Join(mutatorList(unit.Mutators), ","), commands)
+		unit.mu.Unlock()
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Severity classifies a log entry, and picks its color on screen.
+type Severity int
+
+const (
+	LogInfo         Severity = iota // Routine feedback
+	LogWarning                      // Needs attention, or a command was refused
+	LogCritical                     // The reactor is in danger, or something failed
+	LogSuccess                      // An action or event helped
+	LogEvent                        // Something happened outside the player's hands: an event, the crew, chat, a rival, a rule
+	LogTransmission                 // A story transmission, or the reply to one
+)
+
+var severityNames = []string{"info", "warning", "critical", "success", "event", "transmission"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText encodes the severity by name for save files and the APIs.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name written by MarshalText.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if string(text) == name {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log severity %q", text)
+}
+
+// color renders text in the severity's color, tagged with it if the theme
+// tags severities.
+func (s Severity) color(text string) string {
+	switch s {
+	case LogWarning:
+		return theme.tag(TagWarning) + theme.Warning.Sprint(text)
+	case LogCritical:
+		return theme.tag(TagCritical) + theme.Alert.Sprint(text)
+	case LogSuccess:
+		return theme.tag(TagOK) + theme.Success.Sprint(text)
+	case LogEvent:
+		return theme.tag("") + theme.Info.Sprint(text)
+	case LogTransmission:
+		return theme.tag("") + theme.Highlight.Sprint(text)
+	}
+	return theme.tag("") + text
+}
+
+// LogEntry is one line of the event log.
+type LogEntry struct {
+	Text     string   `json:"text"` // Starts with the time it was logged
+	Severity Severity `json:"severity"`
+}
+
+// UnmarshalJSON also reads the plain strings saves held before entries had a
+// severity. Those kept their colors in the text, so they stay LogInfo.
+func (e *LogEntry) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = LogEntry{}
+		return json.Unmarshal(data, &e.Text)
+	}
+	type entry LogEntry // Without this method
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// colorLogEntry colors a log entry by its severity.
+func colorLogEntry(entry LogEntry) string {
+	return entry.Severity.color(entry.Text)
+}
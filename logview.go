@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LogPageLines is how many entries the log viewer shows at once.
+const LogPageLines = 20
+
+// LogViewState is the page of the full log an operator is reading.
+type LogViewState struct {
+	Entries []LogEntry `json:"entries"`
+	First   int        `json:"first"` // 1-based number of Entries[0] in the history
+	Total   int        `json:"total"`
+}
+
+// openLogView shows the newest page of the full log to the current operator
+// in place of the dashboard.
+func (g *Game) openLogView() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.logOpen, g.logTop, g.trendOpen, g.helpOpen = true, -1, false, false
+}
+
+// handleLogView runs input as a log viewer key if the current operator is
+// reading the log, and reports whether it did. Any other command closes the
+// viewer and runs as usual. It must run on the game loop.
+func (g *Game) handleLogView(input string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.logOpen {
+		return false
+	}
+	last := max(0, len(g.History)-LogPageLines) // The top of the newest page
+	top := g.logTop
+	if top < 0 {
+		top = last
+	}
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "u", "up", "older":
+		g.logTop = max(0, top-LogPageLines)
+	case "d", "down", "newer":
+		if g.logTop = top + LogPageLines; g.logTop >= last {
+			g.logTop = -1
+		}
+	case "top":
+		g.logTop = 0
+	case "end":
+		g.logTop = -1
+	case "q", "close", "log":
+		g.logOpen = false
+	default:
+		g.logOpen = false
+		return false
+	}
+	return true
+}
+
+// logViewLocked returns the page op is reading, or nil if op is at the
+// dashboard. The caller must hold g.mu.
+func (g *Game) logViewLocked(op *Operator) *LogViewState {
+	if !op.logOpen {
+		return nil
+	}
+	start := op.logTop
+	if start < 0 {
+		start = max(0, len(g.History)-LogPageLines)
+	}
+	end := min(len(g.History), start+LogPageLines)
+	return &LogViewState{Entries: append([]LogEntry(nil), g.History[start:end]...), First: start + 1, Total: len(g.History)}
+}
+
+// drawLogView renders the log viewer in place of the dashboard.
+func (s GameState) drawLogView(w io.Writer) {
+	v := s.LogView
+	fmt.Fprintln(w, theme.Title.Sprint(tr("--- EVENT LOG: entries %d-%d of %d ---", v.First, v.First+len(v.Entries)-1, v.Total)))
+	fmt.Fprintln(w, theme.Dim.Sprint(tr("The reactor keeps running while you read.")))
+	fmt.Fprintln(w)
+	for _, entry := range v.Entries {
+		fmt.Fprintln(w, colorLogEntry(entry))
+	}
+	fmt.Fprintln(w, theme.Title.Sprint(tr("\n  u: older  d: newer  top  end  q: back to the dashboard")))
+	fmt.Fprint(w, theme.Title.Sprint(tr("Enter command: ")))
+}
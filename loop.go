@@ -0,0 +1,207 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// run is the game loop. It owns the game state: ticks, timer completions
+// and player input are all applied here, one at a time, so no two changes
+// to the reactor ever interleave. The UI only reads, under the locks.
+func (g *Game) run(wg *sync.WaitGroup, quit <-chan struct{}) {
+	defer wg.Done()
+	defer close(g.done)
+	step := g.clock.NewTicker(ScriptPollInterval)
+	defer step.Stop()
+	autosave := g.clock.NewTicker(g.autosaveInterval())
+	defer autosave.Stop()
+	pilot := g.clock.NewTicker(BotReaction)
+	defer pilot.Stop()
+	g.scheduleRandomEvent()
+	due := g.newSchedule()
+
+	for {
+		select {
+		case <-step.Chan():
+			g.tick(g.clock.Now(), &due)
+			continue
+		case <-autosave.Chan():
+			g.writeAutosave()
+		case <-pilot.Chan():
+			g.fly()
+		case f := <-g.inbox:
+			f()
+		case <-quit:
+			return
+		}
+		g.endTurn()
+	}
+}
+
+// schedule holds when the periodic ticks of a run next fall due.
+type schedule struct {
+	degrade, conditions time.Time
+}
+
+// newSchedule starts the periodic ticks from now.
+func (g *Game) newSchedule() schedule {
+	now := g.clock.Now()
+	return schedule{
+		degrade:    now.Add(g.wallTime(g.degradationTick())),
+		conditions: now.Add(g.wallTime(g.Config.Conditions.Tick)),
+	}
+}
+
+// tick is one step of the run at now: the degradation and conditions ticks
+// due by then, the event poll, escalation and the end check. The game loop
+// takes one every ScriptPollInterval, and the simulator one every SimStep
+// of its clock, so both play by the same rules.
+func (g *Game) tick(now time.Time, due *schedule) {
+	for !now.Before(due.degrade) {
+		if !g.ended() && !g.isPaused() {
+			g.journalCause = CauseTick
+			g.degradeTick()
+			g.journalTurn()
+		}
+		due.degrade = due.degrade.Add(g.wallTime(g.degradationTick())) // The run's phase or the sandbox timescale may have changed
+	}
+	for g.conditions != nil && !now.Before(due.conditions) {
+		if !g.ended() && !g.isPaused() {
+			g.journalCause = CauseConditions
+			g.conditionsTick()
+			g.journalTurn()
+		}
+		due.conditions = due.conditions.Add(g.wallTime(g.Config.Conditions.Tick))
+	}
+	if !g.ended() {
+		g.pollEvents()
+		if g.Mode == ModeEndless {
+			g.checkEscalation()
+		}
+	}
+	g.endTurn()
+}
+
+// endTurn closes a turn of the game loop: it checks whether the run has
+// ended, snapshots a practice run and settles the journal.
+func (g *Game) endTurn() {
+	g.checkEnd()
+	g.practiceTick()
+	g.journalTurn()
+}
+
+// post hands f to the game loop. Without a loop, as in the headless
+// simulator, which drives the game from its own goroutine, f runs at once.
+// Work posted after the loop has stopped is dropped.
+func (g *Game) post(f func()) {
+	if g.inline {
+		f()
+		return
+	}
+	select {
+	case g.inbox <- f:
+	case <-g.done:
+	}
+}
+
+// call runs f on the game loop and waits for it to finish.
+func (g *Game) call(f func()) {
+	finished := make(chan struct{})
+	g.post(func() {
+		f()
+		close(finished)
+	})
+	select {
+	case <-finished:
+	case <-g.done:
+	}
+}
+
+// after runs f on the game loop once d has passed on the game's clock,
+// unless the run has been restored since, as a rewind or load does.
+func (g *Game) after(d time.Duration, f func()) {
+	epoch := g.restoreEpoch()
+	g.clock.AfterFunc(d, func() {
+		g.post(func() {
+			if g.restoreEpoch() == epoch {
+				f()
+			}
+		})
+	})
+}
+
+// Submit passes a line of player input to the game loop. Timed actions
+// entered while the player is busy wait in the queue.
+func (g *Game) Submit(input string) {
+	g.post(func() {
+		g.actAs(g.crew()[0])
+		g.handleInput(input)
+	})
+}
+
+// SubmitAs passes a line of input typed by op, a member of a co-op crew.
+func (g *Game) SubmitAs(op *Operator, input string) {
+	g.post(func() {
+		g.actAs(op)
+		g.handleInput(input)
+	})
+}
+
+// actAs makes op the operator whose command or timer is being handled, so
+// the action state and cooldowns it touches are op's. It must run on the
+// game loop, which hands control to whoever's work comes next.
+func (g *Game) actAs(op *Operator) {
+	g.mu.Lock()
+	g.Operator = op
+	g.mu.Unlock()
+}
+
+// crew returns the operators at the console, the host first.
+func (g *Game) crew() []*Operator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*Operator(nil), g.operators...)
+}
+
+// localPlayer reports whether the command running was typed by the host at
+// this machine: commands that write files to its disk run for them alone,
+// not for a player over SSH, a co-op member or a bot. It must run on the
+// game loop.
+func (g *Game) localPlayer() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.local && !g.botCommand && g.Operator == g.operators[0]
+}
+
+// handleInput runs or queues a line of player input. It must run on the
+// game loop.
+func (g *Game) handleInput(input string) {
+	if g.ended() {
+		return // Ended while the input was on its way
+	}
+	if g.handleLogView(input) || g.handleTrendView(input) || g.handleHelpView(input) {
+		return
+	}
+	if g.tutorialBlocks(input) {
+		return
+	}
+	if line, consumed := g.interceptConfirm(input); consumed {
+		if line != "" {
+			g.execute(line)
+		}
+		return
+	}
+	input = g.resolveInputVerb(g.expandAlias(input))
+	command := ""
+	if parts := strings.Fields(strings.ToLower(input)); len(parts) > 0 {
+		command = parts[0]
+	}
+	if queueableCommands[command] && g.IsPlayerBusy() {
+		g.queueCommand(input)
+		return
+	}
+	g.typed = input
+	g.execute(input)
+	g.typed = ""
+}
@@ -2,218 +2,653 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
-	"math/rand"
+	"hash"
+	"io"
+	"net/url"
 	"os"
-	"os/exec"
-	"runtime"
-	"strconv"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fatih/color"
+	"golang.org/x/term"
 )
 
-const (
-	NumSystems         = 5
-	MaxSystemValue     = 100
-	MinSystemValue     = 0
-	CriticalThreshold  = 20
-	WarningThreshold   = 50
-	StabilizeTime      = 5 * time.Second
-	GameDuration       = 3 * time.Minute // 3 minutes to survive
-	EventIntervalMin   = 8 * time.Second
-	EventIntervalMax   = 15 * time.Second
-	DegradationTick    = 750 * time.Millisecond
-	InitialRepairKits  = 3
-)
-
-var systemNames = []string{"Coolant Flow", "Pressure Ctrl", "Core Temp", "Shield Integrity", "Power Output"}
-
 // System struct
 type System struct {
-	ID              int
-	Name            string
-	Value           int
-	DegradationRate int // How much it degrades per tick
-	mu              sync.Mutex
-	IsStable        bool // True if player action made it temporarily stable (during stabilization process)
+	ID                int
+	Name              string
+	Value             int
+	MinValue          int
+	MaxValue          int
+	WarningThreshold  int
+	CriticalThreshold int
+	DegradationRate   int // How much it degrades per tick
+	WarningCurve      int // Percent of DegradationRate at or below WarningThreshold, 0 for 100
+	CriticalCurve     int // Percent of DegradationRate at or below CriticalThreshold, 0 for 100
+	mu                sync.Mutex
+	IsStable          bool      // True if player action made it temporarily stable (during stabilization process)
+	GlitchBoost       int       // Extra degradation from timed effects (rod jams, xenon, ...)
+	GlitchEnd         time.Time // When the latest timed effect wears off
+	Offline           bool      // Hit MinValue; stays down until rebooted
+	Overcharge        int       // Raised ceiling while overcharged, 0 otherwise
+	Reported          int       // Noisy reading shown while SensorFaultEnd is ahead
+	SensorFaultEnd    time.Time // When a sensor glitch stops corrupting readings
+	Damage            int       // Total integrity lost to Harm, for the run report
+	Stabilized        int       // Stabilizations completed; each restores less than the last
+	offlineNotified   bool
+	clock             Clock // The owning game's clock
+}
+
+// restoreLocked is the value a stabilization restores s to: its maximum the
+// first time, and StabilizeFalloff percent of it less every time after, down
+// to floor. The caller must hold s.mu.
+func (s *System) restoreLocked(floor int) int {
+	return max(floor, s.MaxValue-s.Stabilized*s.MaxValue*StabilizeFalloff/100)
+}
+
+// clampLocked keeps Value in bounds and takes the system offline when it
+// bottoms out. The caller must hold s.mu.
+func (s *System) clampLocked() {
+	if ceiling := s.ceilingLocked(); s.Value > ceiling {
+		s.Value = ceiling
+	}
+	if s.Value <= s.MaxValue {
+		s.Overcharge = 0 // Overcharge wears off once the surplus is spent
+	}
+	if s.Value <= s.MinValue {
+		s.Value = s.MinValue
+		if !s.IsStable {
+			s.Offline = true
+		}
+	}
 }
 
 func (s *System) Degrade() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.IsStable { // If being stabilized, degradation is paused for this system
+	if s.IsStable || s.Offline { // If being stabilized, degradation is paused for this system
 		return
 	}
-	s.Value -= s.DegradationRate
-	if s.Value < MinSystemValue {
-		s.Value = MinSystemValue
+	s.Value -= s.rateLocked()
+	if s.overchargedLocked() {
+		s.Value -= OverchargeDrain
 	}
+	s.clampLocked()
 }
 
 func (s *System) Boost(amount int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Value += amount
-	if s.Value > MaxSystemValue {
-		s.Value = MaxSystemValue
+	if s.Offline { // Offline systems only come back through a reboot
+		return
 	}
+	s.Value += amount
+	s.clampLocked()
 }
 
 func (s *System) Harm(amount int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.Offline {
+		return
+	}
+	before := s.Value
 	s.Value -= amount
-	if s.Value < MinSystemValue {
-		s.Value = MinSystemValue
+	s.clampLocked()
+	if before > s.Value {
+		s.Damage += before - s.Value
 	}
 }
 
-// Game state
-type Game struct {
-	Systems       []*System
-	EventLog      []string
-	LogCapacity   int
+// IsOffline reports whether the system is down and needs a reboot.
+func (s *System) IsOffline() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Offline
+}
+
+// Timed player actions, recorded in Game.ActionKind
+const (
+	ActionStabilize = "stabilize"
+	ActionReboot    = "reboot"
+	ActionScram     = "scram"
+	ActionFabricate = "fabricate"
+	ActionInspect   = "inspect"
+	ActionSweep     = "sweep"
+	ActionRally     = "rally"
+)
+
+const ScramHoldTime = 10 * time.Second // Confirmation hold before an emergency shutdown completes
+
+const OverrideDelay = 500 * time.Millisecond // Before a manual override's outcome is known
+
+const StabilizeFalloff = 15 // Percent of its maximum each stabilization of a system restores less
+
+// Odds of the risky commands, in percent.
+const (
+	VentBackflowChance    = 35 // A vent damages another system
+	OverrideSuccessChance = 10 // An override fully stabilizes its system
+	OverrideNeutralChance = 30 // An override has no effect; the rest fail
+)
+
+// Operator is one player at the console: their timed action, queued command
+// and cooldowns. A game has a single operator unless a co-op crew shares it.
+type Operator struct {
+	Name          string // Shown in the crew panel; "" when playing alone
 	PlayerAction  string // e.g., "Stabilizing Core Temp..."
 	ActionEndTime time.Time
-	RepairKits    int
-	GameOver      bool
-	GameWon       bool
-	StartTime     time.Time
-	mu            sync.Mutex // For game-wide states like GameOver, EventLog, PlayerAction
+	ActionSystem  int                  // System targeted by the current action, -1 if none
+	ActionKind    string               // One of the Action* kinds while busy
+	QueuedCommand string               // Follow-up command to run once the current action ends
+	actionCancel  chan struct{}        // Closed by cancel to abort the current timed action
+	cooldownUntil map[string]time.Time // Per command, when it may next be used
+	logOpen       bool                 // Reading the full log instead of the dashboard
+	logTop        int                  // First entry on the page read, -1 to follow the newest
+	trendOpen     bool                 // Reading a system's trend graph instead of the dashboard
+	trendSystem   int                  // The system graphed
+	helpOpen      bool                 // Reading a help page instead of the dashboard
+	helpTopic     string               // The page read: a command, ManualTopic, or "" for the index
+	savesPage     []string             // The slots listed when saves last ran
+	schematicOpen bool                 // Showing the schematic instead of the status table
+	view          string               // How the status table draws the systems: one of the View* names, "" for bars
+	advisorOpen   bool                 // Showing the advisor's recommendation on the dashboard
+	confirm       *pendingConfirm      // Dangerous command awaiting CONFIRM, nil if none
+	lastDivert    *divertUndo          // Their divert undo would take back, nil if none
+}
+
+// Game state
+type Game struct {
+	*Operator                   // Whose command or timer is being handled; see actAs
+	operators       []*Operator // The crew, the host first
+	Systems         []*System
+	EventLog        []LogEntry
+	History         []LogEntry // Every entry logged; EventLog keeps the latest LogCapacity
+	LogCapacity     int
+	Inventory       map[string]int // Carried items by name, repair kits included
+	fuseArmed       bool           // A fuse pack will absorb the next power surge
+	GameOver        bool
+	GameWon         bool
+	Scrammed        bool // Ended by an emergency shutdown: a partial victory
+	StartTime       time.Time
+	Config          Config
+	EndTime         time.Time // Set when the game is won or lost
+	Difficulty      Difficulty
+	Mutators        []Mutator // Rule variants chosen for this run
+	Mode            string    // ModeClassic or ModeEndless
+	Seed            int64
+	rng             *Rand // World events and initial layout
+	actionRng       *Rand // Outcomes of player actions
+	weatherRng      *Rand // External conditions
+	Events          *EventRegistry
+	dependencies    []dependencyLink
+	cascadeActive   []bool // Per dependency, whether its source is currently failing
+	Level           int    // Endless mode escalation level
+	Score           ScoreStats
+	Stats           RunStats
+	matrix          ActionMatrix
+	topScores       []HighScore // Filled in once the run is recorded
+	lastScore       *HighScore
+	Outcome         string    // Why the game was lost, when not a meltdown
+	Scenario        *Scenario // Campaign level being played, nil for free play
+	CampaignLevel   int       // 1-based campaign level, 0 for free play
+	Upgrades        []string  // IDs of the profile's campaign upgrades, on a campaign level
+	ScenarioPath    string    // File the scenario was loaded from, if not a campaign level
+	configPath      string    // The --config file, which reload re-reads; "" for none
+	configHash      string    // Of the config the run started on, for its challenge string
+	scriptFired     int       // Scenario timed events fired so far
+	triggerState    []TriggerState
+	objective       ObjectiveChecker // Victory condition, survival unless a scenario sets one
+	objectiveHeld   time.Duration
+	objectiveMark   time.Duration  // Elapsed time of the last objective check
+	campaignNote    string         // Unlock message shown on the summary screen
+	reportNote      string         // Report export result shown on the summary screen
+	profileNote     string         // Profile update shown on the summary screen
+	rules           []*Rule        // Player automation, at most MaxRules
+	thermal         *thermalModel  // Drives Core Temp, nil when disabled
+	pressure        float64        // Set by the adaptive mutator; 1 is neutral
+	Radiation       float64        // Station radiation level, 0-RadiationMax
+	radiationHigh   time.Duration  // Consecutive time spent above RadiationLimit
+	meltdownDue     time.Duration  // Elapsed game time the imminent meltdown completes, 0 while none looms
+	Morale          int            // Crew morale, 0-MoraleMax
+	moraleCalm      time.Duration  // Of the calm stretch so far
+	undosUsed       int            // Of UndoLimit
+	Unit            int            // 1-based reactor unit in a multi-reactor plant, 0 when alone
+	plant           *Plant         // Owning plant when there are several units
+	crisis          *Crisis        // Major incident in progress, nil if none
+	code            *emergencyCode // Authorization code awaiting input, nil if none
+	minigame        *stabilizeGame // Sequence of the stabilize under way, nil if none
+	minigames       bool           // Stabilize may play the mini-game: a player's own run, not a headless one
+	saboteur        *saboteur      // Intruder at large, nil if none
+	conditions      *conditions    // Weather and grid demand, nil when disabled
+	Sandbox         bool           // Lose conditions are off and the debug console is available
+	practice        *practice      // Snapshots of a --practice run, nil otherwise
+	restores        int            // Restores so far; the timers set before the last one are dropped
+	debugConsole    bool           // Cheats unlocked with the debug command
+	timescale       float64        // Sandbox clock speed, 0 for real time
+	paused          bool           // The game clock stands still, for a tutorial step
+	tutorial        *tutorial      // The guide of a --tutorial run, nil otherwise
+	story           *story         // Transmissions of a --story run, nil otherwise
+	scaleBase       time.Duration  // Elapsed game time when the timescale last changed
+	scaleMark       time.Time      // Wall time when the timescale last changed
+	clock           Clock          // Wall clock, or a simClock in headless runs
+	inbox           chan func()    // Work for the game loop
+	done            chan struct{}  // Closed once the game loop has stopped
+	inline          bool           // No game loop: posted work runs at once
+	savesDir        string         // The profile's save slots, "" outside the player's own run
+	local           bool           // The host sits at this machine, so their commands may write files to its disk
+	botCommand      bool           // The command running was sent by a bot, the autopilot's or a --remote client's
+	autosavePath    string         // Where the player's own run is autosaved, "" if it is not
+	ironman         *IronmanRun    // The run is an --ironman run, nil otherwise
+	policy          Policy         // The bot the autopilot turns over to
+	autopilot       *autopilot     // Flying the reactor, nil while off
+	assisted        bool           // The autopilot has flown the run, which keeps it off the record
+	typed           string         // The command line running, if typed rather than queued or run by a rule
+	rule            int            // The number of the automation rule running a command, 0 if none
+	nextEvent       time.Time      // When the next random event is due
+	incoming        *incomingEvent // The next random event, drawn ahead of time; nil if none
+	phase           *Phase         // Of Config.Phases the run is in; nil in endless mode
+	recentEvents    []recentEvent  // Events of the last EventMemory
+	eventHits       map[string]int // Per event, the times it hit, for --analyze
+	inspectionsDone int            // Inspections over, passed or failed
+	inspectionStage int            // Of the next one: inspectionDue, inspectionAnnounced or inspectionUnderWay
+	logCount        int            // Entries ever logged, so watchers can tell which are new
+	rival           *Game          // The other player's reactor in a versus match, nil otherwise
+	sabotageSpent   int            // Sabotage points spent on the rival
+	chat            *ChatPoll      // Viewers' vote on the next random event, nil without chat voting
+	spectators      *spectatorChat // Messages from the spectators of the status API
+	replay          hash.Hash      // Digest of the commands run, for the leaderboard; nil before the first
+	leaderboardNote string         // Leaderboard submission result shown on the summary screen
+	journal         *Journal       // Records the run to --logfile, nil without one
+	journalCause    string         // What the changes being made are journaled as
+	journalSeen     journalState   // What the journal last recorded
+	Alarms          []Alarm        // On the annunciator until acknowledged
+	alarmLevels     []int          // Per system, the alarm level at the last tick
+	nextAlarm       int            // ID of the last alarm raised
+	cues            int            // Sound cues raised
+	lastCue         Cue
+	countdownMark   int        // Seconds left at the last countdown cue
+	trend           [][]int    // Per system, the reading at every degradation tick
+	mu              sync.Mutex // For game-wide states like GameOver, EventLog, PlayerAction
 }
 
-func NewGame() *Game {
+func NewGame(cfg Config, diff Difficulty, seed int64, mutators []Mutator) *Game {
 	g := &Game{
-		Systems:     make([]*System, NumSystems),
-		EventLog:    make([]string, 0, 10),
+		Operator:    &Operator{ActionSystem: -1},
+		Systems:     make([]*System, cfg.NumSystems),
+		EventLog:    make([]LogEntry, 0, 10),
 		LogCapacity: 10,
-		RepairKits:  InitialRepairKits,
+		Inventory:   map[string]int{ItemRepairKit: diff.startingKits(cfg.InitialRepairKits)},
 		StartTime:   time.Now(),
+		clock:       wallClock{},
+		inbox:       make(chan func()),
+		done:        make(chan struct{}),
+		Config:      cfg,
+		Difficulty:  diff,
+		Mutators:    mutators,
+		Mode:        ModeClassic,
+		Seed:        seed,
+		rng:         NewRand(seed),
+		actionRng:   NewRand(seed ^ 0x5eed),
+		weatherRng:  NewRand(seed ^ 0xc11a7e),
+		Events:      NewEventRegistry(eventCatalog),
+		objective:   surviveObjective{},
+		pressure:    1,
+		Morale:      MoraleMax,
+		policy:      greedyPolicy{},
+		configHash:  configHash(cfg),
+		spectators:  newSpectatorChat(cfg.Spectators),
+	}
+	g.operators = []*Operator{g.Operator}
+	for name, weight := range cfg.EventWeights {
+		_ = g.Events.SetWeight(name, weight) // Validated when the config was loaded
 	}
-	for i := 0; i < NumSystems; i++ {
+	g.applyMutators()
+	if cfg.Conditions.Tick > 0 {
+		g.conditions = &conditions{}
+	}
+	for i := 0; i < cfg.NumSystems; i++ {
+		def := cfg.systemDef(i)
+		maxValue, warning, critical := cfg.systemLimits(def)
 		g.Systems[i] = &System{
-			ID:              i,
-			Name:            systemNames[i],
-			Value:           MaxSystemValue - rand.Intn(20), // Start mostly stable
-			DegradationRate: rand.Intn(3) + 2,             // Random degradation between 2-4
+			ID:                i,
+			Name:              cfg.SystemNames[i],
+			Value:             maxValue - g.rng.Intn(20), // Start mostly stable
+			MinValue:          cfg.MinSystemValue,
+			MaxValue:          maxValue,
+			WarningThreshold:  warning,
+			CriticalThreshold: critical,
+			DegradationRate:   g.rng.Intn(3) + 2, // Random degradation between 2-4
+			WarningCurve:      def.WarningCurve,
+			CriticalCurve:     def.CriticalCurve,
+			clock:             g.clock,
+		}
+		if def.DegradationRate > 0 {
+			g.Systems[i].DegradationRate = def.DegradationRate
 		}
 	}
+	g.resolveDependencies()
+	g.resolveThermal()
+	g.resolveActions()
 	return g
 }
 
-func (g *Game) AddLog(event string) {
+func (g *Game) AddLog(severity Severity, event string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	timestampedEvent := fmt.Sprintf("%s %s", time.Now().Format("15:04:05"), event)
-	g.EventLog = append(g.EventLog, timestampedEvent)
+	entry := LogEntry{Text: fmt.Sprintf("%s %s", g.clock.Now().Format("15:04:05"), event), Severity: severity}
+	g.EventLog = append(g.EventLog, entry)
+	g.History = append(g.History, entry)
+	g.logCount++
 	if len(g.EventLog) > g.LogCapacity {
 		g.EventLog = g.EventLog[len(g.EventLog)-g.LogCapacity:] // Keep last N entries
 	}
+	g.journalLogLocked(severity, event)
 }
 
 func (g *Game) SetPlayerAction(action string, duration time.Duration) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.PlayerAction = action
-	g.ActionEndTime = time.Now().Add(duration)
+	g.ActionEndTime = g.clock.Now().Add(duration)
 }
 
 func (g *Game) ClearPlayerAction() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.PlayerAction = ""
+	g.ActionSystem = -1
+	g.ActionKind = ""
+	g.actionCancel = nil
+	g.minigame = nil
+}
+
+// newActionCancel arms cancellation for the timed action being started.
+func (g *Game) newActionCancel() chan struct{} {
+	cancel := make(chan struct{})
+	g.mu.Lock()
+	g.actionCancel = cancel
+	g.mu.Unlock()
+	return cancel
+}
+
+// afterAction runs done once the timed action armed with cancel has run for
+// duration. A cancelled action never completes; handleCancel cleans it up.
+func (g *Game) afterAction(cancel chan struct{}, duration time.Duration, done func()) {
+	op := g.Operator
+	g.after(duration, func() {
+		g.actAs(op) // The action completes for whoever started it
+		g.finishAction(cancel, done)
+	})
+}
+
+// finishAction runs done for the timed action armed with cancel now, unless
+// it has been cancelled or has completed already.
+func (g *Game) finishAction(cancel chan struct{}, done func()) {
+	g.mu.Lock()
+	current := g.actionCancel == cancel
+	if current {
+		g.actionCancel = nil
+	}
+	g.mu.Unlock()
+	if current {
+		done()
+		g.cue(CueAction)
+	}
+}
+
+// queueableCommands are the actions that can wait for the current one to end.
+var queueableCommands = map[string]bool{
+	"stabilize": true, "divert": true, "vent": true, "override": true,
+	"reboot": true, "overcharge": true, "scram": true, "fabricate": true, "use": true,
+	"inspect": true, "sweep": true, "rally": true, CrisisSuppress: true, CrisisIsolate: true, CrisisRestart: true,
+}
+
+// queueCommand holds input until the current action finishes. Only one
+// command can be queued at a time.
+func (g *Game) queueCommand(input string) {
+	g.mu.Lock()
+	queued := g.QueuedCommand
+	if queued == "" {
+		g.QueuedCommand = input
+	}
+	g.mu.Unlock()
+	if queued != "" {
+		g.AddLog(LogWarning, tr("Cannot queue '%s': '%s' is already queued.", input, queued))
+		return
+	}
+	g.AddLog(LogInfo, tr("Queued '%s' to run when the current action ends.", input))
+}
+
+// takeQueuedCommand returns and clears the queued command once the current
+// action has been cleared by its goroutine.
+func (g *Game) takeQueuedCommand() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.PlayerAction != "" {
+		return ""
+	}
+	queued := g.QueuedCommand
+	g.QueuedCommand = ""
+	return queued
 }
 
 func (g *Game) IsPlayerBusy() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	return g.PlayerAction != "" && time.Now().Before(g.ActionEndTime)
+	return g.PlayerAction != "" && g.clock.Now().Before(g.ActionEndTime)
 }
 
 // --- UI Functions ---
-func clearScreen() {
-	if runtime.GOOS == "windows" {
-		cmd := exec.Command("cmd", "/c", "cls")
-		cmd.Stdout = os.Stdout
-		_ = cmd.Run() // Error ignored for simplicity
-	} else {
-		cmd := exec.Command("clear")
-		cmd.Stdout = os.Stdout
-		_ = cmd.Run() // Error ignored for simplicity
-	}
-}
-
-func (g *Game) Display() {
-	clearScreen()
-	fmt.Println(color.CyanString("--- REACTOR CONTROL TERMINAL ---"))
-	g.mu.Lock() // Lock for game state relevant to display
-	elapsed := time.Since(g.StartTime)
-	kits := g.RepairKits
-	playerAction := g.PlayerAction
-	actionEndTime := g.ActionEndTime
-	eventLogCopy := make([]string, len(g.EventLog))
-	copy(eventLogCopy, g.EventLog)
-	g.mu.Unlock()
 
-	fmt.Printf("Time Elapsed: %s / %s\n", formatDuration(elapsed), formatDuration(GameDuration))
-	fmt.Printf("Repair Kits: %d\n\n", kits)
+// ANSI escapes the dashboard is drawn with.
+const (
+	ansiClear      = "\x1b[H\x1b[2J" // Cursor home, then clear the screen
+	ansiAltScreen  = "\x1b[?1049h"   // Switch to the alternate screen
+	ansiMainScreen = "\x1b[?1049l"   // Back to the main screen and its scrollback
+)
 
-	color.Yellow("SYSTEM STATUS:")
-	for _, sys := range g.Systems {
-		sys.mu.Lock()
-		val := sys.Value
-		name := sys.Name
-		id := sys.ID
-		sys.mu.Unlock()
+func clearScreen(w io.Writer) {
+	fmt.Fprint(w, ansiClear)
+}
 
-		bar := renderBar(val, MaxSystemValue)
-		var statusColorFormat string
-		if val <= CriticalThreshold {
-			statusColorFormat = color.New(color.FgRed, color.Bold).Sprintf("%3d/%3d", val, MaxSystemValue)
-		} else if val <= WarningThreshold {
-			statusColorFormat = color.New(color.FgYellow).Sprintf("%3d/%3d", val, MaxSystemValue)
-		} else {
-			statusColorFormat = color.New(color.FgGreen).Sprintf("%3d/%3d", val, MaxSystemValue)
+// Display renders the dashboard from a single snapshot, so every panel shows
+// the same instant.
+func (g *Game) Display(w io.Writer) {
+	g.DisplayFor(w, nil, DefaultScreen)
+}
+
+// DisplayFor draws the dashboard as op, a member of a co-op crew, sees it on
+// a screen of the given size.
+func (g *Game) DisplayFor(out io.Writer, op *Operator, size ScreenSize) {
+	s := g.SnapshotFor(op)
+	clearScreen(out)
+	if s.LogView != nil || s.TrendView != nil || s.HelpView != nil {
+		var view bytes.Buffer
+		switch {
+		case s.LogView != nil:
+			s.drawLogView(&view)
+		case s.TrendView != nil:
+			s.drawTrendView(&view, size.Width)
+		default:
+			s.drawHelpView(&view)
 		}
-		fmt.Printf("[%d] %-18s: %s %s\n", id, name, statusColorFormat, bar)
+		writeFitted(out, view.String(), size.Width)
+		return
+	}
+	d := dashboard{logHeading: s.spotlight(PanelLog, theme.Heading.Sprint(tr("EVENT LOG:")))}
+	w := &d.status
+	col := size.column()
+	fmt.Fprintln(w, s.meltdownTitle())
+	if g.plant != nil {
+		fmt.Fprintln(w, g.plant.tabBar())
+	}
+	if alarms := s.annunciator(); alarms != "" {
+		fmt.Fprintln(w, alarms)
+	}
+	if tutorial := s.tutorialPanel(); tutorial != "" {
+		fmt.Fprintf(w, "%s\n\n", tutorial)
+	}
+	if transmission := s.transmissionPanel(); transmission != "" {
+		fmt.Fprintf(w, "%s\n\n", transmission)
 	}
 
-	if playerAction != "" {
-		timeLeft := actionEndTime.Sub(time.Now())
-		if timeLeft < 0 {
-			timeLeft = 0
-		}
-		color.Magenta("\nCURRENT ACTION: %s (%.1fs left)", playerAction, timeLeft.Seconds())
+	if s.Mode == ModeEndless {
+		fmt.Fprint(w, tr("Time Survived: %s (Escalation Level %d)\n", formatDuration(s.Elapsed), s.Level))
+	} else {
+		fmt.Fprint(w, tr("Time Elapsed: %s / %s%s\n", formatDuration(s.Elapsed), formatDuration(s.Duration), s.phaseTag()))
+	}
+	fmt.Fprint(w, tr("Difficulty: %s  Seed: %d\n", strings.ToUpper(s.Difficulty), s.Seed))
+	if sandbox := s.sandboxBanner(); sandbox != "" {
+		fmt.Fprintln(w, sandbox)
+	}
+	if practice := s.practiceBanner(); practice != "" {
+		fmt.Fprintln(w, practice)
+	}
+	if autopilot := s.autopilotBanner(); autopilot != "" {
+		fmt.Fprintln(w, autopilot)
+	}
+	if ironman := s.ironmanBanner(); ironman != "" {
+		fmt.Fprintln(w, ironman)
+	}
+	if inspection := s.inspectionBanner(); inspection != "" {
+		fmt.Fprintln(w, inspection)
+	}
+	if len(s.Mutators) > 0 {
+		fmt.Fprint(w, tr("Mutators: %s%s\n", strings.Join(s.Mutators, ", "), s.pressureTag()))
+	}
+	if s.CampaignLevel > 0 {
+		fmt.Fprint(w, tr("Level %d: %s  Objective: %s\n", s.CampaignLevel, s.Scenario, s.Objective))
+	} else if s.Scenario != "" {
+		fmt.Fprint(w, tr("Scenario: %s  Objective: %s\n", s.Scenario, s.Objective))
+	}
+	fmt.Fprint(w, tr("Score: %d  %s\n", s.Score.Total, s.streakMeter()))
+	fmt.Fprint(w, tr("INVENTORY: %s\n", s.inventoryPanel()))
+	fmt.Fprintln(w, s.spotlight(PanelRadiation, s.radiationGauge()))
+	fmt.Fprintln(w, s.moraleGauge())
+	if forecast := s.forecastWidget(); forecast != "" {
+		fmt.Fprintln(w, forecast)
+	}
+	fmt.Fprintln(w)
+	if crisis := s.crisisPanel(); crisis != "" {
+		fmt.Fprintf(w, "%s\n\n", crisis)
+	}
+	if saboteur := s.saboteurPanel(); saboteur != "" {
+		fmt.Fprintf(w, "%s\n\n", saboteur)
+	}
+	if code := s.codePrompt(); code != "" {
+		fmt.Fprintf(w, "%s\n\n", code)
+	}
+	if sequence := s.minigamePrompt(); sequence != "" {
+		fmt.Fprintf(w, "%s\n\n", sequence)
+	}
+	if advice := s.advisorPanel(); advice != "" {
+		fmt.Fprintf(w, "%s\n\n", advice)
 	}
 
-	fmt.Println(color.YellowString("\nEVENT LOG:"))
-	for _, entry := range eventLogCopy { // Use the copied log
-		lowerEntry := strings.ToLower(entry)
-		if strings.Contains(lowerEntry, "critical") || strings.Contains(lowerEntry, "failed") || strings.Contains(lowerEntry, "catastrophic") {
-			color.Red(entry)
-		} else if strings.Contains(lowerEntry, "warning") || strings.Contains(lowerEntry, "event:") || strings.Contains(lowerEntry, "glitch") {
-			color.Yellow(entry)
-		} else if strings.Contains(lowerEntry, "success") || strings.Contains(lowerEntry, "complete") || strings.Contains(lowerEntry, "boost") {
-			color.Green(entry)
-		} else {
-			fmt.Println(entry)
+	if banner := s.incomingBanner(); banner != "" {
+		fmt.Fprintln(w, banner)
+	}
+	if banner := s.imminentBanner(col); banner != "" {
+		fmt.Fprintln(w, banner)
+	} else if banner := s.meltdownBanner(col); banner != "" {
+		fmt.Fprintln(w, banner)
+	}
+	if s.Schematic {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("SCHEMATIC:")))
+		s.drawSchematic(w, col)
+	} else {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("SYSTEM STATUS:")))
+		s.drawStatus(w, col)
+	}
+
+	if s.Action != "" {
+		fmt.Fprintln(w, theme.Action.Sprint(tr("\nCURRENT ACTION: %s (%.1fs left)", s.Action, s.ActionLeft.Seconds())))
+		if s.Queued != "" {
+			fmt.Fprintln(w, theme.Action.Sprint(tr("  QUEUED: %s", s.Queued)))
 		}
 	}
+	if crew := s.crewPanel(); crew != "" {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("\nCREW:")))
+		fmt.Fprintln(w, crew)
+	}
+	if versus := s.versusPanel(); versus != "" {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("\nVERSUS:")))
+		fmt.Fprintln(w, versus)
+	}
+
+	if vote := s.votePanel(); vote != "" {
+		fmt.Fprintf(w, "\n%s\n", vote)
+	}
+	if rules := s.rulesPanel(); rules != "" {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("\nAUTOMATION:")))
+		fmt.Fprintln(w, rules)
+	}
 
-	fmt.Println(color.CyanString("\n--- AVAILABLE COMMANDS ---"))
-	fmt.Println("  stabilize <id>          (Uses 1 Repair Kit, takes time)")
-	fmt.Println("  divert <from_id> <to_id> <amount (10-30)>")
-	fmt.Println("  vent <id>               (Risky, instant effect)")
-	fmt.Println("  override <id>           (VERY Risky, instant effect)")
-	fmt.Println("  quit")
-	fmt.Print(color.CyanString("Enter command: "))
+	for _, entry := range s.Log {
+		d.log = append(d.log, colorLogEntry(entry))
+	}
+	d.spectators = s.spectatorPanel()
+
+	w = &d.commands
+	fmt.Fprintln(w, "\n"+s.spotlight(PanelCommands, theme.Title.Sprint(tr("--- AVAILABLE COMMANDS ---"))))
+	fmt.Fprintln(w, tr("  stabilize <id>          (Uses 1 Repair Kit, takes time)"))
+	fmt.Fprint(w, tr("  divert <from_id> <to_id> <amount (10-30)> (%s)%s\n", s.describeCosts(CommandDivert), s.cooldownTag(CommandDivert)))
+	fmt.Fprint(w, tr("  vent <id>               (Risky, instant effect, %s)%s\n", s.describeCosts(CommandVent), s.cooldownTag(CommandVent)))
+	fmt.Fprint(w, tr("  override <id>           (VERY Risky, instant effect, %s)%s\n", s.describeCosts(CommandOverride), s.cooldownTag(CommandOverride)))
+	fmt.Fprintln(w, tr("  overcharge <id>         (90%+ systems only, up to 130%, may blow out)"))
+	fmt.Fprintln(w, tr("  reboot <id>             (OFFLINE systems only, takes time)"))
+	fmt.Fprintln(w, tr("  inspect <id>            (Read the true value, takes time)"))
+	fmt.Fprintln(w, tr("  use <item> [id]         (Consume an item from the inventory)"))
+	fmt.Fprintln(w, tr("  fabricate               (Drains 15 Power Output into a repair kit, takes time)"))
+	fmt.Fprintln(w, tr("  rally                   (Lift the crew's morale, takes time)"))
+	fmt.Fprintln(w, tr("  scram                   (Emergency shutdown, halves score)"))
+	fmt.Fprintln(w, tr("  cancel                  (Abort the current timed action)"))
+	fmt.Fprint(w, tr("  undo                    (Take back the last divert within %.0fs, %d left)\n", UndoWindow.Seconds(), s.Undos))
+	fmt.Fprintln(w, tr("  suppress | isolate <id> | restart <id> (Work through a major incident)"))
+	fmt.Fprintln(w, tr("  rule add <cond> <action> | rule list | rule del <n>"))
+	fmt.Fprintln(w, tr("  ack <id> | ack all      (Acknowledge alarms on the annunciator)"))
+	fmt.Fprintln(w, tr("  log                     (Browse the full event log)"))
+	fmt.Fprintln(w, tr("  trend <id>              (Graph a system's history over the whole run)"))
+	fmt.Fprintln(w, tr("  schematic               (Toggle the plant diagram in place of the status table)"))
+	fmt.Fprintln(w, tr("  view compact|bars|gauges (Draw the systems as short lines, bars or tall gauges)"))
+	fmt.Fprintln(w, tr("  advise [on|off]         (Recommend what to do next, or keep it on the dashboard)"))
+	fmt.Fprintln(w, tr("  autopilot [on|off]      (Let the bot play for you; the run is not recorded)"))
+	fmt.Fprintln(w, tr("  keys                    (Show the hotkeys, cooldowns, aliases and verbs in effect)"))
+	if s.SpectatorChat != "" {
+		fmt.Fprintln(w, tr("  spectators on|off|mute <name> (Let the spectators chat, or silence one)"))
+	}
+	if g.plant != nil {
+		fmt.Fprint(w, tr("  reactor <1-%d>           (Switch focus to another unit)\n", len(g.plant.Units)))
+	} else {
+		fmt.Fprintln(w, tr("  save <slot|file>        (Write game state to a save slot or a JSON file)"))
+		fmt.Fprintln(w, tr("  saves                   (List your profile's save slots)"))
+	}
+	if s.Versus != nil {
+		fmt.Fprintln(w, tr("  sabotage <player> <event> (Spend sabotage points on your rival's reactor)"))
+	}
+	if s.Saboteur != nil {
+		fmt.Fprintln(w, tr("  sweep <id>              (Search a system for the saboteur, takes time)"))
+	}
+	if s.Story {
+		fmt.Fprintln(w, tr("  respond yes|no          (Answer a transmission that asks for a decision)"))
+	}
+	for _, line := range s.debugHelp() {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w, tr("  help [command] | manual (Explain a command in full, or the rules of the plant)"))
+	fmt.Fprintln(w, tr("  quit"))
+	fmt.Fprint(w, theme.Title.Sprint(tr("Enter command: ")))
+	d.render(out, size)
 }
 
-func renderBar(current, max int) string {
-	barLength := 20
+func (sys SystemState) renderBar(barLength int) string {
+	current, max := sys.Reading, sys.MaxValue
 	fillLength := (current * barLength) / max
 	if fillLength < 0 {
 		fillLength = 0
@@ -223,12 +658,16 @@ func renderBar(current, max int) string {
 	}
 	barStr := strings.Repeat("=", fillLength) + strings.Repeat("-", barLength-fillLength)
 
-	if current <= CriticalThreshold {
-		return color.RedString("[%s]", barStr)
-	} else if current <= WarningThreshold {
-		return color.YellowString("[%s]", barStr)
+	if current > max { // Overcharge spills past the end of the bar
+		surplus := ((current - max) * barLength) / max
+		return theme.OK.Sprintf("[%s]", barStr) + theme.Info.Sprintf(strings.Repeat("+", surplus))
 	}
-	return color.GreenString("[%s]", barStr)
+	if current <= sys.Critical {
+		return theme.Critical.Sprintf("[%s]", barStr)
+	} else if current <= sys.Warning {
+		return theme.Warning.Sprintf("[%s]", barStr)
+	}
+	return theme.OK.Sprintf("[%s]", barStr)
 }
 
 func formatDuration(d time.Duration) string {
@@ -239,458 +678,1363 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d", m, s)
 }
 
-// --- Game Logic Goroutines ---
-func (g *Game) manageSystemDegradation(wg *sync.WaitGroup, quit <-chan struct{}) {
-	defer wg.Done()
-	ticker := time.NewTicker(DegradationTick)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			g.mu.Lock()
-			gameOver := g.GameOver
-			gameWon := g.GameWon
-			g.mu.Unlock()
-			if gameOver || gameWon {
-				return
-			}
-			for _, sys := range g.Systems {
-				sys.Degrade() // Degrade handles its own lock
-				sys.mu.Lock()
-				val := sys.Value
-				name := sys.Name
-				id := sys.ID
-				isStable := sys.IsStable
-				sys.mu.Unlock()
-				if val == MinSystemValue && !isStable {
-					g.AddLog(color.RedString("CRITICAL: System %s (%d) at ZERO integrity!", name, id))
-				}
-			}
-		case <-quit:
-			return
+// --- Game Logic ---
+// degradeTick advances the reactor by one degradation tick.
+func (g *Game) degradeTick() {
+	g.sabotageTick()
+	values := make([]int, len(g.Systems))
+	for i, sys := range g.Systems {
+		sys.Degrade() // Degrade handles its own lock
+		g.checkBlowout(sys)
+		g.updateSensor(sys)
+		sys.mu.Lock()
+		val := sys.Value
+		name := sys.Name
+		id := sys.ID
+		isStable := sys.IsStable
+		sys.mu.Unlock()
+		values[i] = val
+		sys.mu.Lock()
+		announce := sys.Offline && !sys.offlineNotified
+		sys.offlineNotified = sys.Offline
+		sys.mu.Unlock()
+		if announce && !isStable {
+			g.AddLog(LogCritical, tr("CRITICAL: System %s (%d) at ZERO integrity! OFFLINE. Use 'reboot %d' to restore it.", name, id, id))
 		}
 	}
+	g.applyThermal(values)
+	g.applyCascades(values)
+	g.recordScoreTick(values)
+	g.adaptPressure(values)
+	g.updateAlarms(values)
+	g.updateMorale(values)
+	g.recordTrend()
+	g.recordStatsTick(values)
+	g.updateRadiation(values)
+	g.runRules(values)
 }
 
-func (g *Game) generateRandomEvents(wg *sync.WaitGroup, quit <-chan struct{}) {
-	defer wg.Done()
-	for {
-		g.mu.Lock()
-		gameOver := g.GameOver
-		gameWon := g.GameWon
-		g.mu.Unlock()
-		if gameOver || gameWon {
-			return // Exit if game has ended
-		}
-
-		sleepDuration := time.Duration(rand.Intn(int(EventIntervalMax-EventIntervalMin)) + int(EventIntervalMin))
-		
-		// Select with timeout for quit signal
-		select {
-		case <-time.After(sleepDuration):
-			// Continue to trigger event
-		case <-quit:
-			return // Exit if quit signal received during sleep
-		}
-
-		g.mu.Lock()
-		gameOver = g.GameOver // Re-check after sleep
-		gameWon = g.GameWon
-		g.mu.Unlock()
-		if gameOver || gameWon {
-			return
-		}
+// pollEvents fires the scripted and random events that have come due and
+// moves any incident or authorization code along.
+func (g *Game) pollEvents() {
+	if g.updateTutorial() {
+		return // Nothing happens while the tutorial is paused
+	}
+	g.countdownCue()
+	g.checkPhase()
+	g.triggerScriptedEvents()
+	g.updateStory()
+	g.updateCrisis()
+	g.updateEmergencyCode()
+	g.updateInspection()
+	if g.randomEventsEnabled() {
+		g.detectIncoming()
+	}
+	g.mu.Lock()
+	due := !g.clock.Now().Before(g.nextEvent)
+	g.mu.Unlock()
+	if g.randomEventsEnabled() && due {
 		g.triggerRandomEvent()
+		g.scheduleRandomEvent()
 	}
 }
 
-
-func (g *Game) triggerRandomEvent() {
-	eventID := rand.Intn(5)
-	sysID := rand.Intn(NumSystems)
-	targetSystem := g.Systems[sysID]
-
-	switch eventID {
-	case 0:
-		damage := rand.Intn(20) + 10
-		targetSystem.Harm(damage)
-		g.AddLog(color.YellowString("EVENT: Power surge in %s (%d)! Damage: %d", targetSystem.Name, sysID, damage))
-	case 1:
-		damage := rand.Intn(15) + 10
-		targetSystem.Harm(damage)
-		g.AddLog(color.YellowString("EVENT: Coolant leak detected near %s (%d)! Damage: %d", targetSystem.Name, sysID, damage))
-		if targetSystem.ID == 0 && NumSystems > 2 && g.Systems[2].Name == "Core Temp" { // Assuming Coolant Flow is ID 0, Core Temp is ID 2
-			coreTempSys := g.Systems[2]
-			coreTempSys.mu.Lock()
-			coreTempSys.DegradationRate += 1
-			coreTempSys.mu.Unlock()
-			g.AddLog(color.YellowString("INFO: Core Temp (%d) degradation increased due to coolant issue.", coreTempSys.ID))
-		}
-	case 2:
-		g.AddLog(color.HiWhiteString("EVENT: Sensor glitch on %s (%d). Readings may be unreliable.", targetSystem.Name, sysID))
-		targetSystem.mu.Lock()
-		originalRate := targetSystem.DegradationRate
-		targetSystem.DegradationRate += 2
-		targetSystem.mu.Unlock()
-		go func(sys *System, origRate int) {
-			time.Sleep(15 * time.Second)
-			sys.mu.Lock()
-			sys.DegradationRate = origRate
-			sys.mu.Unlock()
-			g.AddLog(color.HiWhiteString("INFO: Sensor for %s (%d) recalibrated.", sys.Name, sys.ID))
-		}(targetSystem, originalRate)
-	case 3:
-		boost := rand.Intn(10) + 5
-		targetSystem.Boost(boost)
-		g.AddLog(color.GreenString("EVENT: Unexpected efficiency boost in %s (%d)! Value +%d", targetSystem.Name, sysID, boost))
-	case 4:
-		numAffected := rand.Intn(NumSystems-1) + 1
-		g.AddLog(color.YellowString("EVENT: Cosmic ray shower detected! Multiple systems affected."))
-		affectedIndices := make(map[int]bool)
-		for i := 0; i < numAffected; {
-			idx := rand.Intn(NumSystems)
-			if !affectedIndices[idx] {
-				affectedIndices[idx] = true
-				affectedSys := g.Systems[idx]
-				damage := rand.Intn(5) + 5
-				affectedSys.Harm(damage)
-				g.AddLog(fmt.Sprintf("  - %s (%d) took %d damage.", affectedSys.Name, idx, damage))
-				i++
-			}
-		}
+// scheduleRandomEvent picks when the next random event strikes.
+func (g *Game) scheduleRandomEvent() {
+	delay := g.wallTime(g.nextEventDelay())
+	g.locked(func() {
+		g.nextEvent = g.clock.Now().Add(delay)
+	})
+	g.drawIncoming()
+	if g.chat != nil {
+		g.openChatVote()
 	}
 }
 
 // --- Player Actions ---
 func (g *Game) handleStabilize(sysID int) {
-	if sysID < 0 || sysID >= NumSystems {
-		g.AddLog(color.RedString("Error: Invalid system ID for stabilize."))
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for stabilize."))
 		return
 	}
 	if g.IsPlayerBusy() {
-		g.AddLog(color.YellowString("Cannot start new action: Player busy."))
+		g.AddLog(LogWarning, tr("Cannot start new action: Player busy."))
 		return
 	}
-	g.mu.Lock()
-	if g.RepairKits <= 0 {
-		g.mu.Unlock()
-		g.AddLog(color.RedString("Cannot stabilize: No repair kits left!"))
+	targetSystem := g.Systems[sysID]
+	refused := ""
+	g.locked(func() {
+		switch {
+		case g.Inventory[ItemRepairKit] <= 0:
+			refused = tr("Cannot stabilize: No repair kits left!")
+		case targetSystem.IsOffline():
+			refused = tr("Cannot stabilize: %s (%d) is OFFLINE. Reboot it first.", targetSystem.Name, sysID)
+		default:
+			g.Inventory[ItemRepairKit]--
+			g.Stats.KitsUsed++
+		}
+	})
+	if refused != "" {
+		g.AddLog(LogWarning, refused)
 		return
 	}
-	g.RepairKits--
-	g.mu.Unlock()
 
+	duration := g.stabilizeDuration()
+	g.AddLog(LogInfo, tr("Commencing stabilization for %s (%d). This will take time.", targetSystem.Name, sysID))
+	if duration > g.Config.StabilizeTime {
+		g.AddLog(LogWarning, tr("WARNING: Power shortfall. Stabilization will take %.0fs.", duration.Seconds()))
+	}
+	if slowed := g.moraleDuration(duration); slowed > duration {
+		duration = slowed
+		g.AddLog(LogWarning, tr("WARNING: The tired crew is slow. Stabilization will take %.0fs.", duration.Seconds()))
+	}
+	g.startStabilization(targetSystem, duration, g.minigameEnabled())
+}
+
+// startStabilization occupies the player with stabilizing sys for duration,
+// playing the mini-game with play. It is shared by handleStabilize and by
+// resuming a saved game.
+func (g *Game) startStabilization(sys *System, duration time.Duration, play bool) {
+	g.SetPlayerAction(tr("Stabilizing %s (%d)...", sys.Name, sys.ID), duration)
+	g.locked(func() {
+		g.ActionSystem = sys.ID
+		g.ActionKind = ActionStabilize
+	})
+
+	sys.mu.Lock()
+	sys.IsStable = true
+	sys.mu.Unlock()
+
+	cancel := g.newActionCancel()
+	finish := func() {
+		percent := g.endMinigame()
+		var restored, next int
+		sys.locked(func() {
+			sys.Value = max(sys.restoreLocked(sys.WarningThreshold)*percent/100, min(sys.Value, sys.MaxValue))
+			sys.Overcharge = 0
+			sys.IsStable = false
+			sys.Stabilized++
+			restored, next = sys.Value, sys.restoreLocked(sys.WarningThreshold)
+		})
+
+		g.ClearPlayerAction() // This goroutine is responsible for clearing its action
+		g.AddLog(LogSuccess, tr("System %s (%d) stabilization complete. Value restored to %d.", sys.Name, sys.ID, restored))
+		g.AddLog(LogInfo, tr("Each repair of %s holds less: the next restores it to %d.", sys.Name, next))
+	}
+	if play {
+		g.startMinigame(duration, cancel, finish)
+	}
+	g.afterAction(cancel, duration, finish)
+}
+
+func (g *Game) handleReboot(sysID int) {
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for reboot."))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot reboot: Player busy with another action."))
+		return
+	}
 	targetSystem := g.Systems[sysID]
-	g.SetPlayerAction(fmt.Sprintf("Stabilizing %s (%d)...", targetSystem.Name, sysID), StabilizeTime)
-	g.AddLog(fmt.Sprintf("Commencing stabilization for %s (%d). This will take time.", targetSystem.Name, sysID))
+	if !targetSystem.IsOffline() {
+		g.AddLog(LogWarning, tr("Cannot reboot: %s (%d) is still online.", targetSystem.Name, sysID))
+		return
+	}
+	g.AddLog(LogInfo, tr("Commencing reboot of %s (%d). This will take time.", targetSystem.Name, sysID))
+	g.startReboot(targetSystem, g.Config.RebootTime)
+}
 
-	targetSystem.mu.Lock()
-	targetSystem.IsStable = true
-	targetSystem.mu.Unlock()
+// startReboot occupies the player with bringing an offline system back up.
+// It is shared by handleReboot and by resuming a saved game.
+func (g *Game) startReboot(sys *System, duration time.Duration) {
+	g.SetPlayerAction(tr("Rebooting %s (%d)...", sys.Name, sys.ID), duration)
+	g.locked(func() {
+		g.ActionSystem = sys.ID
+		g.ActionKind = ActionReboot
+	})
+
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		value := sys.MaxValue * g.Config.RebootPercent / 100
+		sys.mu.Lock()
+		sys.Offline = false
+		sys.offlineNotified = false
+		sys.Value = value
+		sys.mu.Unlock()
+
+		g.ClearPlayerAction()
+		g.AddLog(LogSuccess, tr("System %s (%d) reboot complete. Back online at %d.", sys.Name, sys.ID, value))
+	})
+}
+
+func (g *Game) handleScram() {
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot scram: Player busy with another action."))
+		return
+	}
+	if g.offlineCount() >= 2 && !g.meltdownImminent() {
+		g.AddLog(LogWarning, tr("Cannot scram: Too many systems offline for a safe shutdown."))
+		return
+	}
+	if !g.confirmed(tr("SCRAM shuts the reactor down and ends the run with the score halved.")) {
+		return
+	}
+	g.AddLog(LogWarning, tr("WARNING: SCRAM initiated. Shutdown completes in %.0fs if the reactor holds.", ScramHoldTime.Seconds()))
+	g.startScram(ScramHoldTime)
+}
+
+// startScram holds the emergency shutdown for duration, then ends the run as
+// a partial victory. It is shared by handleScram and by resuming a saved game.
+func (g *Game) startScram(duration time.Duration) {
+	g.SetPlayerAction(tr("SCRAM: Holding for safe shutdown..."), duration)
+	g.mu.Lock()
+	g.ActionSystem = -1
+	g.ActionKind = ActionScram
+	g.mu.Unlock()
 
-	go func(sys *System) {
-		time.Sleep(StabilizeTime)
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		g.mu.Lock()
+		ended := g.GameOver || g.GameWon
+		g.mu.Unlock()
+		if ended { // The reactor melted down during the hold
+			return
+		}
+		for _, sys := range g.Systems {
+			sys.mu.Lock()
+			sys.IsStable = true // Halts degradation
+			sys.mu.Unlock()
+		}
+		g.ClearPlayerAction()
+		g.locked(func() {
+			g.GameOver = true
+			g.Scrammed = true
+			g.EndTime = g.clock.Now()
+		})
+		g.AddLog(LogSuccess, tr("SCRAM COMPLETE: All systems shut down safely. No meltdown."))
+	})
+}
 
+// handleCancel aborts the current timed action. Resources it consumed, like
+// a stabilization's repair kit, are not refunded.
+func (g *Game) handleCancel() {
+	g.mu.Lock()
+	cancel := g.actionCancel
+	kind := g.ActionKind
+	sysID := g.ActionSystem
+	g.actionCancel = nil
+	g.mu.Unlock()
+	if cancel == nil {
+		g.AddLog(LogWarning, tr("Nothing to cancel."))
+		return
+	}
+	close(cancel)
+
+	switch kind {
+	case ActionStabilize:
+		sys := g.Systems[sysID]
 		sys.mu.Lock()
-		sys.Value = MaxSystemValue
 		sys.IsStable = false
 		sys.mu.Unlock()
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Stabilization of %s (%d) aborted. The repair kit is lost.", sys.Name, sysID))
+	case ActionReboot:
+		sys := g.Systems[sysID]
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Reboot of %s (%d) aborted. It remains OFFLINE.", sys.Name, sysID))
+	case ActionInspect:
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Inspection of %s (%d) aborted.", g.Systems[sysID].Name, sysID))
+	case ActionSweep:
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Sweep of %s (%d) aborted.", g.Systems[sysID].Name, sysID))
+	case ActionRally:
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Rally aborted."))
+	case ActionFabricate:
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: Fabrication aborted. The drained power is lost."))
+	default:
+		g.ClearPlayerAction()
+		g.AddLog(LogWarning, tr("WARNING: SCRAM aborted. Reactor returning to normal operation."))
+	}
+}
 
-		g.ClearPlayerAction() // This goroutine is responsible for clearing its action
-		g.AddLog(color.GreenString("System %s (%d) stabilization complete. Value restored to %d.", sys.Name, sys.ID, MaxSystemValue))
-	}(targetSystem)
+// offlineCount returns how many systems are currently offline.
+func (g *Game) offlineCount() int {
+	count := 0
+	for _, sys := range g.Systems {
+		if sys.IsOffline() {
+			count++
+		}
+	}
+	return count
 }
 
 func (g *Game) handleDivert(fromSysID, toSysID, amount int) {
-	if fromSysID < 0 || fromSysID >= NumSystems || toSysID < 0 || toSysID >= NumSystems || fromSysID == toSysID {
-		g.AddLog(color.RedString("Error: Invalid system IDs for divert."))
+	numSystems := len(g.Systems)
+	if fromSysID < 0 || fromSysID >= numSystems || toSysID < 0 || toSysID >= numSystems || fromSysID == toSysID {
+		g.AddLog(LogWarning, tr("Error: Invalid system IDs for divert."))
 		return
 	}
 	if amount < 10 || amount > 30 {
-		g.AddLog(color.RedString("Error: Divert amount must be between 10 and 30."))
+		g.AddLog(LogWarning, tr("Error: Divert amount must be between 10 and 30."))
 		return
 	}
 	if g.IsPlayerBusy() {
-		g.AddLog(color.YellowString("Cannot divert: Player busy with another action."))
+		g.AddLog(LogWarning, tr("Cannot divert: Player busy with another action."))
 		return
 	}
 
 	fromSys := g.Systems[fromSysID]
 	toSys := g.Systems[toSysID]
+	if fromSys.IsOffline() || toSys.IsOffline() {
+		g.AddLog(LogWarning, tr("Error: Cannot divert through an OFFLINE system."))
+		return
+	}
+	if g.onCooldown(CommandDivert) {
+		return
+	}
 
 	fromSys.mu.Lock()
-	canDivert := fromSys.Value >= amount+CriticalThreshold/2 // Less strict, can go into warning
+	canDivert := fromSys.Value >= amount+fromSys.CriticalThreshold/2 // Less strict, can go into warning
+	fromSys.mu.Unlock()
 	if !canDivert {
-		fromSys.mu.Unlock()
-		g.AddLog(color.RedString("Error: Not enough capacity in %s (%d) to divert %d.", fromSys.Name, fromSysID, amount))
+		g.AddLog(LogWarning, tr("Error: Not enough capacity in %s (%d) to divert %d.", fromSys.Name, fromSysID, amount))
+		return
+	}
+	if !g.divertConfirmed(fromSysID, toSysID, amount) || !g.drawPower(CommandDivert) {
 		return
 	}
+	fromSys.mu.Lock()
 	fromSys.Value -= amount
 	fromSys.mu.Unlock()
+	g.startCooldown(CommandDivert)
 
-	toSys.Boost(amount)
-	g.AddLog(fmt.Sprintf("Diverted %d from %s (%d) to %s (%d).", amount, fromSys.Name, fromSysID, toSys.Name, toSysID))
+	delivered := g.divertDelivery(fromSysID, toSysID, amount)
+	g.recordDivert(fromSys, toSys, amount, toSys.boostGained(delivered))
+	g.AddLog(LogInfo, tr("Diverted %d from %s (%d) to %s (%d): %d arrived.", amount, fromSys.Name, fromSysID, toSys.Name, toSysID, delivered))
 }
 
 func (g *Game) handleVent(sysID int) {
-	if sysID < 0 || sysID >= NumSystems {
-		g.AddLog(color.RedString("Error: Invalid system ID for vent."))
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for vent."))
 		return
 	}
 	if g.IsPlayerBusy() {
-		g.AddLog(color.YellowString("Cannot vent: Player busy with another action."))
+		g.AddLog(LogWarning, tr("Cannot vent: Player busy with another action."))
 		return
 	}
 
 	targetSystem := g.Systems[sysID]
+	if targetSystem.IsOffline() {
+		g.AddLog(LogWarning, tr("Cannot vent: %s (%d) is OFFLINE. Reboot it first.", targetSystem.Name, sysID))
+		return
+	}
+	if g.onCooldown(CommandVent) {
+		return
+	}
 	targetSystem.mu.Lock()
 	currentValue := targetSystem.Value
+	maxValue := targetSystem.MaxValue
 	targetSystem.mu.Unlock()
 
-	boostAmount := (MaxSystemValue - currentValue) / 2
+	boostAmount := (maxValue - currentValue) / 2
 	if boostAmount < 10 {
 		boostAmount = 10
 	}
-	if boostAmount == 0 && currentValue == MaxSystemValue { // No point venting if already max
-	    g.AddLog(fmt.Sprintf("System %s (%d) is already optimal. Venting had no effect.", targetSystem.Name, sysID))
+	if boostAmount == 0 && currentValue == maxValue { // No point venting if already max
+	    g.AddLog(LogInfo, tr("System %s (%d) is already optimal. Venting had no effect.", targetSystem.Name, sysID))
         return
     }
+	if !g.drawPower(CommandVent) {
+		return
+	}
 	targetSystem.Boost(boostAmount)
-	g.AddLog(fmt.Sprintf("Emergency vent on %s (%d). Value increased by %d.", targetSystem.Name, sysID, boostAmount))
+	g.startCooldown(CommandVent)
+	g.addRadiation(RadiationPerVent)
+	g.AddLog(LogInfo, tr("Emergency vent on %s (%d). Value increased by %d. Radiation released.", targetSystem.Name, sysID, boostAmount))
 
-	if rand.Intn(100) < 35 {
-		secondarySysID := rand.Intn(NumSystems)
+	if g.actionRng.Intn(100) < VentBackflowChance {
+		numSystems := len(g.Systems)
+		secondarySysID := g.actionRng.Intn(numSystems)
 		// Ensure secondary is not the same as vented, if possible and more than 1 system
-		if NumSystems > 1 {
+		if numSystems > 1 {
 			for secondarySysID == sysID {
-				secondarySysID = rand.Intn(NumSystems)
+				secondarySysID = g.actionRng.Intn(numSystems)
 			}
 		}
-		secondaryDamage := rand.Intn(15) + 5
+		secondaryDamage := g.scaleDamage(g.actionRng.Intn(15) + 5)
 		g.Systems[secondarySysID].Harm(secondaryDamage)
-		g.AddLog(color.RedString("WARNING: Vent caused backflow! System %s (%d) damaged by %d.", g.Systems[secondarySysID].Name, secondarySysID, secondaryDamage))
+		g.AddLog(LogCritical, tr("WARNING: Vent caused backflow! System %s (%d) damaged by %d.", g.Systems[secondarySysID].Name, secondarySysID, secondaryDamage))
 	}
 }
 
 func (g *Game) handleOverride(sysID int) {
-	if sysID < 0 || sysID >= NumSystems {
-		g.AddLog(color.RedString("Error: Invalid system ID for override."))
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for override."))
 		return
 	}
 	if g.IsPlayerBusy() {
-		g.AddLog(color.YellowString("Cannot override: Player busy with another action."))
+		g.AddLog(LogWarning, tr("Cannot override: Player busy with another action."))
+		return
+	}
+	if g.pacifistBlocks() {
 		return
 	}
 
 	targetSystem := g.Systems[sysID]
-	g.AddLog(color.HiRedString("Attempting DANGEROUS manual override on %s (%d)...", targetSystem.Name, sysID))
-	time.Sleep(500 * time.Millisecond)
+	if targetSystem.IsOffline() {
+		g.AddLog(LogWarning, tr("Cannot override: %s (%d) is OFFLINE. Reboot it first.", targetSystem.Name, sysID))
+		return
+	}
+	if g.onCooldown(CommandOverride) {
+		return
+	}
+	risk := 100 - OverrideSuccessChance - OverrideNeutralChance
+	if !g.confirmed(tr("OVERRIDE has a %d%% chance of critical damage to %s (%d).", risk, targetSystem.Name, sysID)) {
+		return
+	}
+	g.writeAutosave() // The last save before the gamble
+	if !g.drawPower(CommandOverride) {
+		return
+	}
+	g.startCooldown(CommandOverride)
+	g.AddLog(LogWarning, tr("Attempting DANGEROUS manual override on %s (%d)...", targetSystem.Name, sysID))
+	g.after(OverrideDelay, func() { // The outcome comes through after a tense moment
+		outcome := g.actionRng.Intn(100)
+		damage := 0
+		failed := outcome >= OverrideSuccessChance+OverrideNeutralChance
+		if failed { // Scaled before locking: an overcharged shield is checked under its own lock
+			damage = g.scaleDamage(g.actionRng.Intn(40) + 30)
+		}
+		targetSystem.locked(func() {
+			name := targetSystem.Name // Store before potential nil dereference if game ends abruptly
+			id := targetSystem.ID
+			if outcome < OverrideSuccessChance {
+				targetSystem.Value = targetSystem.MaxValue
+				targetSystem.clampLocked() // Ends any overcharge
+				g.AddLog(LogSuccess, tr("OVERRIDE SUCCESS: %s (%d) fully stabilized!", name, id))
+			} else if !failed {
+				g.AddLog(LogWarning, tr("OVERRIDE NEUTRAL: %s (%d) override had no significant effect.", name, id))
+			} else {
+				targetSystem.Value -= damage
+				targetSystem.clampLocked()
+				g.AddLog(LogCritical, tr("OVERRIDE FAILED: %s (%d) CRITICAL DAMAGE! Value -%d", name, id, damage))
+			}
+		})
+		if failed {
+			g.recordFailedOverride()
+			g.changeMorale(-MoraleOverrideLoss, tr("The failed override shakes the crew."))
+		}
+	})
+}
 
-	outcome := rand.Intn(100)
-	targetSystem.mu.Lock()
-	name := targetSystem.Name // Store before potential nil dereference if game ends abruptly
-	id := targetSystem.ID
-	if outcome < 10 { // 10% success
-		targetSystem.Value = MaxSystemValue
-		g.AddLog(color.GreenString("OVERRIDE SUCCESS: %s (%d) fully stabilized!", name, id))
-	} else if outcome < 40 { // 30% neutral
-		g.AddLog(color.YellowString("OVERRIDE NEUTRAL: %s (%d) override had no significant effect.", name, id))
-	} else { // 60% failure
-		damage := rand.Intn(40) + 30
-		targetSystem.Value -= damage
-		if targetSystem.Value < MinSystemValue {
-			targetSystem.Value = MinSystemValue
-		}
-		g.AddLog(color.RedString("OVERRIDE FAILED: %s (%d) CRITICAL DAMAGE! Value -%d", name, id, damage))
+// execute parses and runs one in-game command other than quit.
+func (g *Game) execute(input string) {
+	rawParts := splitCommand(input) // Original case, for arguments like file names
+	parts := make([]string, len(rawParts))
+	for i, part := range rawParts {
+		parts[i] = strings.ToLower(part)
+	}
+	if len(parts) == 0 {
+		return
+	}
+	defer g.journalCommand(input)()
+	defer g.auditCommand(parts)()
+	command := g.resolveVerb(parts[0])
+	run, known := commandTable[command]
+	if !known {
+		g.AddLog(LogWarning, tr("Unknown command: %s", parts[0]))
+		return
+	}
+	parts[0], rawParts[0] = command, command
+	if !g.resolveSystemArgs(command, parts, rawParts) {
+		return
+	}
+	g.fumble(command, parts, rawParts)
+	if g.actionForbidden(command, parts) {
+		return
+	}
+	run(g, parts, rawParts)
+	g.countCommand(command)
+	g.recordReplay(input)
+}
+
+// checkEnd ends the game once its objective is met or it is lost, and runs
+// the queued command when the player is free. The game loop calls it after
+// every turn. A sandbox run can only be won.
+func (g *Game) checkEnd() {
+	if g.ended() {
+		return
+	}
+	won, failure := g.checkObjective()
+	if failure == "" {
+		failure = g.radiationFailure()
+	}
+	if g.Sandbox {
+		failure = ""
+	}
+	if won {
+		g.locked(func() {
+			g.GameWon = true
+			g.EndTime = g.clock.Now()
+		})
+		g.AddLog(LogSuccess, tr("OBJECTIVE COMPLETE: Survived the critical period! You win!"))
+		return
+	}
+	g.updateMeltdown()
+	if failure == "" && g.meltdownExpired() {
+		g.locked(func() {
+			g.GameOver = true
+			g.EndTime = g.clock.Now()
+		})
+		g.AddLog(LogCritical, tr("CATASTROPHIC FAILURE: Multiple systems stayed offline. The reactor has melted down. GAME OVER."))
+		return
+	}
+	if failure != "" {
+		g.locked(func() {
+			g.GameOver = true
+			g.EndTime = g.clock.Now()
+			g.Outcome = failure
+		})
+		g.AddLog(LogCritical, failure)
+		return
+	}
+
+	for _, op := range g.crew() {
+		g.actAs(op)
+		if queued := g.takeQueuedCommand(); queued != "" {
+			g.AddLog(LogInfo, tr("Executing queued command: %s", queued))
+			g.execute(queued)
+		}
 	}
-	targetSystem.mu.Unlock()
 }
 
 // --- Main Game Loop ---
 func main() {
-	rand.Seed(time.Now().UnixNano())
-	game := NewGame()
-	quitSignal := make(chan struct{})
-	var wg sync.WaitGroup
+	configPath := flag.String("config", "", "TOML file overriding the built-in reactor tunables")
+	loadPath := flag.String("load", "", "resume a game previously written with the save command")
+	difficultyName := flag.String("difficulty", "normal", "difficulty level: "+strings.Join(difficultyNames(), "|"))
+	modeName := flag.String("mode", ModeClassic, "game mode: "+ModeClassic+"|"+ModeEndless)
+	seed := flag.Int64("seed", 0, "random seed for a reproducible run (0 picks one from the clock)")
+	showScores := flag.Bool("scores", false, "print the local high-score table and exit")
+	campaign := flag.Bool("campaign", false, "play the campaign, starting at the furthest unlocked level")
+	levelNum := flag.Int("level", 0, "campaign level to play (implies --campaign)")
+	scenarioPath := flag.String("scenario", "", "play a custom scenario file (TOML)")
+	reactors := flag.Int("reactors", 1, fmt.Sprintf("reactor units to run at once (1-%d)", MaxReactors))
+	var mutatorFlags mutatorFlag
+	flag.Var(&mutatorFlags, "mutator", "rule variant to play with, repeatable or comma-separated: "+strings.Join(mutatorNames(), "|"))
+	reportPath := flag.String("report", "", "export the post-game statistics report to a .json or .md file")
+	profileName := flag.String("profile", DefaultProfile, "player profile to record this run under")
+	sandbox := flag.Bool("sandbox", false, "disable lose conditions and allow the debug console; the run is not recorded")
+	silent := flag.Bool("silent", false, "mute the terminal bell and sound cues")
+	simulate := flag.Int("simulate", 0, "play this many games headless with a bot and print aggregate statistics")
+	policyName := flag.String("policy", "greedy", "bot policy for --simulate and the autopilot: "+strings.Join(policyNames(), "|")+", plugin:<file.so> or remote:<addr>")
+	analyze := flag.Bool("analyze", false, "with --simulate, add balance analytics: what events and commands are worth, when losses come and dominant strategies")
+	scriptPath := flag.String("script", "", "play one game headless on a script of timed commands (a file, or - for stdin) and exit with its outcome")
+	httpAddr := flag.String("http", "", "serve the live game state as JSON on this address (e.g. :8080) for external dashboards")
+	remoteAddr := flag.String("remote", "", "accept remote control connections for bots on this TCP address (e.g. :9000)")
+	sshAddr := flag.String("ssh", "", "host the game over SSH on this address (e.g. :2222), one game per connection")
+	hostAddr := flag.String("host", "", fmt.Sprintf("host a co-op game on this TCP address (e.g. :7777) for up to %d players", MaxCrew))
+	joinAddr := flag.String("join", "", "join the co-op game hosted at this address (e.g. host:7777)")
+	crewName := flag.String("name", "engineer", "your name in a co-op crew, a versus match or on the leaderboard")
+	versus := flag.Bool("versus", false, "with --host, play a versus match against one rival instead of co-op")
+	logPath := flag.String("logfile", "", "write every event, command and change to the systems to this file as JSON lines")
+	castPath := flag.String("cast", "", "record the session as drawn to this asciinema .cast file, for sharing")
+	castLast := flag.Duration("cast-last", 0, "with --cast, keep only this much of the end of the session (e.g. 60s)")
+	leaderboardURL := flag.String("leaderboard", "", "submit finished runs to the leaderboard server at this URL, signed with $"+LeaderboardKeyEnv)
+	themeName := flag.String("theme", classicTheme.Name, "color theme: "+strings.Join(themeNames(), "|"))
+	noColor := flag.Bool("no-color", false, "draw without color, tagging severities as [CRIT], [WARN] and [OK]")
+	ascii := flag.Bool("ascii", false, "draw with plain ASCII characters only, tagging severities as with --no-color")
+	hotkeyMode := flag.Bool("hotkeys", false, "read single keys without Enter: 0-9 select a system, s/v/o/r/i/d act on it, : types a command")
+	mouseMode := flag.Bool("mouse", false, "with --hotkeys, which it turns on: click a system to select it, click the buttons on the prompt line, drag one system onto another to divert")
+	tutorialMode := flag.Bool("tutorial", false, "learn to play: a guided run that pauses to teach each command; it is not recorded")
+	storyMode := flag.Bool("story", false, "play with transmissions from the Plant Director and emergency services, some asking for a decision")
+	langName := flag.String("lang", "en", "language of the game: "+strings.Join(languageNames(), "|"))
+	ironman := flag.Bool("ironman", false, "play with one save, kept for you and gone when the run ends; wins are recorded as ironman wins")
+	practiceMode := flag.Bool("practice", false, "snapshot the run every 5 seconds so rewind <seconds> can take it back; the run is not recorded")
+	challenge := flag.String("challenge", "", "play the run of a challenge string from the summary screen: its seed, difficulty, mode, mutators and reactors")
+	flag.Parse()
+	ansi := enableANSI() // Before anything is drawn
+
+	if err := setLanguage(*langName); err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --lang: %v", err)))
+		os.Exit(2)
+	}
 
-	wg.Add(1)
-	go game.manageSystemDegradation(&wg, quitSignal)
-	wg.Add(1)
-	go game.generateRandomEvents(&wg, quitSignal)
+	th, err := ThemeByName(*themeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --theme: %v", err)))
+		os.Exit(2)
+	}
+	th.Symbols = th.Symbols || *noColor || *ascii
+	th.ASCII = *ascii
+	theme = th
+	if *noColor {
+		color.NoColor = true
+	}
 
-	reader := bufio.NewReader(os.Stdin)
-	uiTicker := time.NewTicker(200 * time.Millisecond) // UI refresh rate
-	defer uiTicker.Stop()
+	var leaderboard *Leaderboard
+	if *leaderboardURL != "" {
+		if u, err := url.Parse(*leaderboardURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --leaderboard %q (an http or https URL)", *leaderboardURL)))
+			os.Exit(2)
+		}
+		if !profileNamePattern.MatchString(*crewName) {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --name %q (letters, digits, - and _ only)", *crewName)))
+			os.Exit(2)
+		}
+		leaderboard = &Leaderboard{URL: *leaderboardURL, Key: os.Getenv(LeaderboardKeyEnv), Name: *crewName}
+	}
 
-	game.AddLog("SYSTEM BOOT: Reactor control online. Good luck, engineer.")
+	if *showScores {
+		if err := PrintHighScoreTable(); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to read high scores: %v", err)))
+			os.Exit(1)
+		}
+		return
+	}
 
-	inputChan := make(chan string)
-	go func() { // Goroutine for blocking input read
-		defer func() {
-			// If ReadString panics (e.g. stdin closed abruptly), recover
-			if r := recover(); r != nil {
-				// Optionally log, but mainly prevent crash of this goroutine
-			}
-		}()
-		for {
-			rawInput, err := reader.ReadString('\n')
-			if err != nil {
-				// Likely EOF or other error, stop trying to read
-				close(inputChan) // Signal main loop that input is done
-				return
-			}
-			
-			game.mu.Lock()
-			isGameOverOrWon := game.GameOver || game.GameWon
-			game.mu.Unlock()
-
-			// Only send input if game is running, or if it's "quit" when game is over
-			if !isGameOverOrWon || (isGameOverOrWon && strings.TrimSpace(strings.ToLower(rawInput)) == "quit") {
-				select {
-				case inputChan <- rawInput:
-				case <-quitSignal: // If game is quitting, stop sending
-					close(inputChan)
-					return
-				}
-			}
+	if *joinAddr != "" { // The host's flags decide the game
+		if err := joinCoop(*joinAddr, *crewName); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to join %s: %v", *joinAddr, err)))
+			os.Exit(1)
 		}
-	}()
+		return
+	}
 
-	running := true
-	for running {
-		game.Display()
-
-		game.mu.Lock()
-		isGameOver := game.GameOver
-		isGameWon := game.GameWon
-		game.mu.Unlock()
-
-		if !isGameOver && !isGameWon {
-			if time.Since(game.StartTime) >= GameDuration {
-				game.mu.Lock()
-				game.GameWon = true
-				isGameWon = true // Update local var
-				game.mu.Unlock()
-				game.AddLog(color.HiGreenString("OBJECTIVE COMPLETE: Survived the critical period! You win!"))
+	profile, err := LoadProfile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load profile: %v", err)))
+		os.Exit(1)
+	}
+	savesDir, err := profileSavesDir(profile.Name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to open the saves: %v", err)))
+		os.Exit(1)
+	}
+	switch flag.Arg(0) {
+	case "":
+	case "profile":
+		profile.Print()
+		return
+	case "upgrade":
+		if err := profile.runUpgrade(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+			os.Exit(2)
+		}
+		return
+	case "ssh-key":
+		if err := profile.runSSHKey(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+			os.Exit(2)
+		}
+		return
+	case SavesTopic:
+		if err := PrintSaves(profile.Name, DefaultConfig()); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to list the saves: %v", err)))
+			os.Exit(1)
+		}
+		return
+	case "leaderboard":
+		if leaderboard == nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("leaderboard needs the server's URL: --leaderboard <url> leaderboard")))
+			os.Exit(2)
+		}
+		if err := PrintLeaderboard(leaderboard); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to fetch the leaderboard: %v", err)))
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Unknown command %q (choose 'profile', 'upgrade', 'ssh-key', 'saves' or 'leaderboard')", flag.Arg(0))))
+		os.Exit(2)
+	}
+
+	var stamp *RunStamp
+	if *challenge != "" {
+		set := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["seed"] || set["difficulty"] || set["mode"] || set["mutator"] || set["reactors"] || *loadPath != "" || *campaign || *levelNum > 0 || *tutorialMode || *simulate > 0 {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--challenge sets the seed, difficulty, mode, mutators and reactors: it cannot be combined with them, --load, --tutorial, --simulate or the campaign")))
+			os.Exit(2)
+		}
+		st, err := ParseChallenge(*challenge)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --challenge: %v", err)))
+			os.Exit(2)
+		}
+		*seed, *difficultyName, *modeName, *reactors = st.Seed, st.Difficulty, st.Mode, st.Reactors
+		for _, name := range st.Mutators {
+			_ = mutatorFlags.Set(name) // Checked by ParseChallenge
+		}
+		stamp = &st
+	}
+
+	difficulty, err := DifficultyByName(*difficultyName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+		os.Exit(2)
+	}
+	if *loadPath == "" && !*sandbox && !*tutorialMode && *simulate == 0 && *scriptPath == "" && *sshAddr == "" { // A saved game keeps the difficulty it was started on; SSH players have their own profiles
+		if err := profile.checkDifficulty(difficulty.Name); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+			os.Exit(2)
+		}
+	}
+	mode, err := ParseMode(*modeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+		os.Exit(2)
+	}
+
+	if *reactors < 1 || *reactors > MaxReactors {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--reactors must be between 1 and %d", MaxReactors)))
+		os.Exit(2)
+	}
+	if *reactors > 1 && (*loadPath != "" || *scenarioPath != "" || *campaign || *levelNum > 0) {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--reactors cannot be combined with --load, --scenario or the campaign")))
+		os.Exit(2)
+	}
+
+	if *simulate < 0 {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--simulate must be a positive number of games")))
+		os.Exit(2)
+	}
+	if *simulate > 0 {
+		if *loadPath != "" || *reactors > 1 || *sandbox || *reportPath != "" || *httpAddr != "" || *remoteAddr != "" || *campaign || *levelNum > 0 {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--simulate cannot be combined with --load, --reactors, --sandbox, --report, --http, --remote or the campaign")))
+			os.Exit(2)
+		}
+	}
+	if *analyze && *simulate == 0 {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--analyze needs --simulate")))
+		os.Exit(2)
+	}
+	policy, err := PolicyByName(*policyName) // For the autopilot too
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+		os.Exit(2)
+	}
+
+	var script *Script
+	if *scriptPath != "" {
+		if *simulate > 0 || *loadPath != "" || *reactors > 1 || *sandbox || *reportPath != "" || *httpAddr != "" || *remoteAddr != "" || *campaign || *levelNum > 0 ||
+			*sshAddr != "" || *hostAddr != "" || *tutorialMode || *storyMode || *logPath != "" || *castPath != "" {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--script plays a game of its own: it cannot be combined with --simulate, --load, --reactors, --sandbox, --report, --http, --remote, --ssh, --host, --tutorial, --story, --logfile, --cast or the campaign")))
+			os.Exit(2)
+		}
+		if script, err = LoadScript(*scriptPath); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load script %s: %v", *scriptPath, err)))
+			os.Exit(2)
+		}
+	}
+
+	if *sshAddr != "" && (*loadPath != "" || *reportPath != "" || *simulate > 0 || *httpAddr != "" || *remoteAddr != "" || *campaign || *levelNum > 0) {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--ssh cannot be combined with --load, --report, --simulate, --http, --remote or the campaign")))
+		os.Exit(2)
+	}
+
+	if *tutorialMode && (*loadPath != "" || *scenarioPath != "" || *campaign || *levelNum > 0 || *reactors > 1 || *simulate > 0 || *sshAddr != "" || *hostAddr != "" || mode != ModeClassic) {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--tutorial cannot be combined with --load, --scenario, --reactors, --simulate, --ssh, --host, --mode or the campaign")))
+		os.Exit(2)
+	}
+
+	if *storyMode && (*tutorialMode || *reactors > 1 || *simulate > 0 || *sshAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--story cannot be combined with --tutorial, --reactors, --simulate or --ssh")))
+		os.Exit(2)
+	}
+
+	if *logPath != "" && (*simulate > 0 || *sshAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--logfile cannot be combined with --simulate or --ssh")))
+		os.Exit(2)
+	}
+	if *castPath != "" && (*simulate > 0 || *sshAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--cast cannot be combined with --simulate or --ssh")))
+		os.Exit(2)
+	}
+	if *castLast < 0 || (*castLast > 0 && *castPath == "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--cast-last must be positive, and needs --cast")))
+		os.Exit(2)
+	}
+	if *hostAddr != "" && (*reactors > 1 || *simulate > 0 || *sshAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--host cannot be combined with --reactors, --simulate or --ssh")))
+		os.Exit(2)
+	}
+	if *versus && (*hostAddr == "" || *loadPath != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--versus needs --host and cannot be combined with --load")))
+		os.Exit(2)
+	}
+	if *ironman && (*loadPath != "" || *sandbox || *tutorialMode || *reactors > 1 || *simulate > 0 || *scriptPath != "" || *sshAddr != "" || *versus) {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--ironman cannot be combined with --load, --sandbox, --tutorial, --reactors, --simulate, --script, --ssh or --versus")))
+		os.Exit(2)
+	}
+	if *practiceMode && (*loadPath != "" || *sandbox || *tutorialMode || *ironman || *reactors > 1 || *simulate > 0 || *scriptPath != "" || *sshAddr != "" || *hostAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--practice cannot be combined with --load, --sandbox, --tutorial, --ironman, --reactors, --simulate, --script, --ssh or --host")))
+		os.Exit(2)
+	}
+	if *hostAddr != "" && !profileNamePattern.MatchString(*crewName) {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid --name %q (letters, digits, - and _ only)", *crewName)))
+		os.Exit(2)
+	}
+
+	if *reportPath != "" {
+		if _, err := reportFormat(*reportPath); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+			os.Exit(2)
+		}
+	}
+
+	cfg := DefaultConfig()
+	if *configPath != "" {
+		if cfg, err = LoadConfig(*configPath); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load config %s: %v", *configPath, err)))
+			os.Exit(2)
+		}
+	}
+	if cfg.Chat.enabled() && (*reactors > 1 || *sshAddr != "") {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Chat voting runs on a single reactor: it cannot be combined with --reactors or --ssh")))
+		os.Exit(2)
+	}
+
+	pruneAutosaves(savesDir)
+	left := leftAutosave(savesDir)
+	ironmanPath, forfeit := slotPath(savesDir, IronmanSlot), ""
+	if *ironman {
+		var st *SaveState
+		if st, forfeit, err = startIronman(profile, ironmanPath); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to start the ironman run: %v", err)))
+			os.Exit(1)
+		}
+		if st != nil {
+			*loadPath = ironmanPath
+		}
+	}
+	if !*ironman && *loadPath == "" && *scenarioPath == "" && !*campaign && *levelNum == 0 && *reactors == 1 && !*sandbox && !*tutorialMode && !*practiceMode && *simulate == 0 && *scriptPath == "" && *sshAddr == "" && !*versus && *challenge == "" && offerAutosave(left) {
+		*loadPath = left
+	}
+	var saved *SaveState
+	if *loadPath != "" {
+		*loadPath = resolveSavePath(savesDir, *loadPath)
+		st, err := ReadSaveFile(*loadPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load %s: %v", *loadPath, err)))
+			os.Exit(1)
+		}
+		if st.Ironman != "" && !*ironman {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("%s is an ironman save: resume its run with --ironman", *loadPath)))
+			os.Exit(2)
+		}
+		saved = &st
+		if st.CampaignLevel > 0 {
+			*levelNum = st.CampaignLevel // Resume the saved level; it was unlocked when played
+		}
+		if st.ScenarioPath != "" {
+			*scenarioPath = st.ScenarioPath
+		}
+	}
+
+	var scenario *Scenario
+	if *scenarioPath != "" {
+		if *campaign || *levelNum > 0 {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--scenario cannot be combined with the campaign")))
+			os.Exit(2)
+		}
+		if scenario, err = LoadScenarioFile(*scenarioPath); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load scenario %s: %v", *scenarioPath, err)))
+			os.Exit(2)
+		}
+		cfg = scenario.Config(cfg)
+		mode = ModeClassic
+	}
+
+	var levels []*Scenario
+	if *campaign || *levelNum > 0 {
+		if levels, err = LoadCampaign(); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load campaign: %v", err)))
+			os.Exit(1)
+		}
+		if saved == nil {
+			if *levelNum, err = selectCampaignLevel(levels, *levelNum); err != nil {
+				fmt.Fprintln(os.Stderr, theme.Critical.Sprintf("%v", err))
+				os.Exit(2)
 			}
+			offerUpgrade(profile)
+		} else if *levelNum > len(levels) {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Save refers to unknown campaign level %d", *levelNum)))
+			os.Exit(1)
+		}
+		cfg = levels[*levelNum-1].Config(cfg)
+		mode = ModeClassic
+	}
 
-			criticalFailures := 0
-			for _, sys := range game.Systems {
-				sys.mu.Lock()
-				val := sys.Value
-				sys.mu.Unlock()
-				if val <= MinSystemValue {
-					criticalFailures++
-				}
+	var upgradeIDs []string
+	if levels != nil {
+		scenario = levels[*levelNum-1]
+		upgradeIDs = profile.Upgrades
+		if saved != nil {
+			upgradeIDs = saved.Upgrades // Those the run was started with
+		}
+	}
+	if stamp != nil {
+		if hash := configHash(cfg); hash != stamp.ConfigHash {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("The challenge was played on config %s, and this run's is %s: use the same --config and --scenario files", stamp.ConfigHash, hash)))
+			os.Exit(2)
+		}
+	}
+	newPlant := func(seed int64) (*Plant, error) {
+		plant := NewPlant(*reactors, cfg, difficulty, seed, mutatorFlags)
+		for _, unit := range plant.Units {
+			unit.Mode = mode
+			unit.Sandbox = *sandbox
+			if *practiceMode {
+				unit.practice = &practice{}
+			}
+			unit.policy = policy
+			unit.minigames = true
+			unit.configPath = *configPath
+		}
+		if scenario != nil {
+			game := plant.Units[0]
+			if err := game.applyScenario(scenario, *levelNum); err != nil {
+				return nil, err
 			}
-			if criticalFailures >= 2 && !isGameOver { // Check against local isGameOver to prevent re-triggering
-				game.mu.Lock()
-				game.GameOver = true
-				isGameOver = true // Update local var
-				game.mu.Unlock()
-				game.AddLog(color.HiRedString("CATASTROPHIC FAILURE: Multiple systems offline. Meltdown imminent. GAME OVER."))
+			if levels != nil {
+				game.applyUpgrades(upgradeIDs)
 			}
+			game.ScenarioPath = *scenarioPath
 		}
-		
-		if isGameOver || isGameWon {
-			game.Display() // One final display for win/loss message
-			fmt.Println(color.CyanString("Game has ended. Type 'quit' or press Ctrl+C to exit."))
-			// Wait for quit command via inputChan
+		return plant, nil
+	}
+	if *sshAddr != "" {
+		if os.Getenv("NO_COLOR") == "" && !*noColor {
+			color.NoColor = false // The players' terminals, not the host's, show the colors
 		}
-
-		var input string
-		select {
-		case <-uiTicker.C:
-			// UI tick happened, just loop to Display again
-			// Player action timeout is handled by the stabilize goroutine itself by calling ClearPlayerAction
-			continue
-		case rawInput, ok := <-inputChan:
-			if !ok { // inputChan was closed
-				running = false // End the game loop if input source is gone
-				continue
-			}
-			input = strings.TrimSpace(rawInput)
-		case <-quitSignal: // If the main quit signal is fired (e.g. future admin command)
-		    running = false
-			continue
+		fmt.Println(theme.Title.Sprint(tr("Hosting Reactor Meltdown over SSH on %s. Press Ctrl+C to stop.", *sshAddr)))
+		srv := SSHServer{NewPlant: newPlant, Seed: *seed, Sandbox: *sandbox, Leaderboard: leaderboard, Silent: *silent}
+		if err := srv.ListenAndServe(*sshAddr); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("SSH server failed: %v", err)))
+			os.Exit(1)
 		}
+		return
+	}
 
-		parts := strings.Fields(strings.ToLower(input))
-		if len(parts) == 0 {
-			if isGameOver || isGameWon { // If game ended and user just presses Enter
-				game.Display() // Keep displaying the end message
-				fmt.Println(color.CyanString("Game has ended. Type 'quit' or press Ctrl+C to exit."))
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if *simulate > 0 {
+		sim := Simulation{
+			Games:      *simulate,
+			Policy:     policy,
+			Seed:       *seed,
+			Config:     cfg,
+			Difficulty: difficulty,
+			Mode:       mode,
+			Mutators:   mutatorFlags,
+			Scenario:   scenario,
+			Analyze:    *analyze,
+		}
+		summary, err := sim.Run()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Simulation failed: %v", err)))
+			os.Exit(1)
+		}
+		summary.Print()
+		summary.PrintAnalysis()
+		return
+	}
+	if script != nil {
+		if err := script.check(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load script %s: %v", *scriptPath, err)))
+			os.Exit(2)
+		}
+		sim := Simulation{
+			Games:      1,
+			Script:     script,
+			Seed:       *seed,
+			Config:     cfg,
+			Difficulty: difficulty,
+			Mode:       mode,
+			Mutators:   mutatorFlags,
+			Scenario:   scenario,
+		}
+		summary, err := sim.Run()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Simulation failed: %v", err)))
+			os.Exit(1)
+		}
+		os.Exit(scriptExit(summary))
+	}
+	var seat *rivalSeat
+	if *versus { // Before the reactors are built, so neither runs while waiting
+		fmt.Println(theme.Title.Sprint(tr("Waiting for a rival to join on %s...", *hostAddr)))
+		if seat, err = awaitRival(*hostAddr); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to host the versus match: %v", err)))
+			os.Exit(1)
+		}
+	}
+	plant, err := newPlant(*seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Invalid scenario: %v", err)))
+		os.Exit(2)
+	}
+	game := plant.Units[0]
+	if saved != nil {
+		if err := game.Restore(*saved); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to load %s: %v", *loadPath, err)))
+			os.Exit(1)
+		}
+	}
+	game.savesDir = savesDir
+	switch {
+	case *ironman:
+		game.ironman, game.autosavePath = profile.Ironman, ironmanPath
+		if forfeit != "" {
+			game.AddLog(LogWarning, forfeit)
+		}
+	case *loadPath != "" && *loadPath == left: // Resumed: the run goes on autosaving to the same file
+		game.autosavePath = left
+	default:
+		game.autosavePath = newAutosavePath(savesDir, time.Now())
+	}
+	if *tutorialMode {
+		if err := game.startTutorial(); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to start the tutorial: %v", err)))
+			os.Exit(2)
+		}
+	}
+	if *storyMode && game.story == nil { // A story save goes on with its own
+		game.startStory()
+	}
+	if *logPath != "" {
+		journal, err := OpenJournal(*logPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to open the log file: %v", err)))
+			os.Exit(1)
+		}
+		defer journal.Close()
+		for _, unit := range plant.Units {
+			unit.startJournal(journal)
+		}
+	}
+	if *httpAddr != "" {
+		srv, err := plant.serveStatus(*httpAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to start the status API: %v", err)))
+			os.Exit(1)
+		}
+		defer srv.Close()
+	}
+	if *remoteAddr != "" {
+		ln, err := plant.serveRemote(*remoteAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to start remote control: %v", err)))
+			os.Exit(1)
+		}
+		defer ln.Close()
+	}
+	if seat != nil {
+		rivalPlant, _ := newPlant(*seed) // The same seed, so both reactors start alike
+		game.Name = *crewName
+		stop := game.startMatch(rivalPlant.Units[0], seat)
+		defer stop()
+	} else if *hostAddr != "" {
+		game.Name = *crewName
+		ln, err := game.serveCoop(*hostAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to host the co-op game: %v", err)))
+			os.Exit(1)
+		}
+		defer ln.Close()
+		game.AddLog(LogEvent, tr("CREW: Hosting on %s. Up to %d more players can join with --join.", *hostAddr, MaxCrew-1))
+	}
+	if cfg.Chat.enabled() {
+		stop := game.startChat(cfg.Chat)
+		defer stop()
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	reader := bufio.NewReader(os.Stdin)
+	readLine := func() (string, error) { return reader.ReadString('\n') }
+	out := io.Writer(color.Output)
+	if ansi {
+		out = os.Stdout // The terminal draws the escapes itself
+	}
+	var prompt func() string
+	size := terminalSize
+	redraw := make(chan struct{}, 1)
+	notifyResize(redraw)
+	if *hotkeyMode || *mouseMode {
+		stdin := int(os.Stdin.Fd())
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("--hotkeys needs an interactive terminal: %v", err)))
+			os.Exit(2)
+		}
+		defer term.Restore(stdin, state)
+		names := make([]string, len(game.Systems))
+		for i, sys := range game.Systems {
+			names[i] = sys.Name
+		}
+		keys := newHotkeys(names, game.Config.Keys, func() bool { return plant.Focused().minigamePending() }, redraw)
+		go keys.run(interruptReader{os.Stdin, interrupt})
+		readLine, prompt, out = keys.ReadLine, keys.prompt, crlfWriter{color.Output}
+		if *mouseMode {
+			out = keys.enableMouse(out)
+			defer fmt.Fprint(out, ansiMouseOff)
+		}
+	} else if stdin := int(os.Stdin.Fd()); term.IsTerminal(stdin) {
+		if state, err := term.MakeRaw(stdin); err == nil {
+			defer term.Restore(stdin, state)
+			t := term.NewTerminal(struct {
+				io.Reader
+				io.Writer
+			}{interruptReader{os.Stdin, interrupt}, color.Output}, "")
+			t.AutoCompleteCallback = plant.complete
+			readLine, out = t.ReadLine, bufio.NewWriterSize(t, SSHFrameBuffer)
+			size = func() ScreenSize {
+				s := terminalSize()
+				t.SetSize(s.Width, s.Height)
+				return s
 			}
-			continue
 		}
-		command := parts[0]
+	}
+	if *castPath != "" {
+		rec, err := newCastRecorder(out, *castPath, size(), *castLast)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to record to %s: %v", *castPath, err)))
+			os.Exit(1)
+		}
+		defer func() {
+			if err := rec.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to record to %s: %v", *castPath, err)))
+			}
+		}()
+		out = rec
+	}
+	plant.Play(Session{
+		ReadLine:    readLine,
+		Out:         out,
+		Sandbox:     *sandbox || *tutorialMode || *practiceMode, // Tutorial and practice runs go unrecorded too
+		LoadPath:    *loadPath,
+		ReportPath:  *reportPath,
+		Levels:      levels,
+		Profile:     profile,
+		Leaderboard: leaderboard,
+		Silent:      *silent,
+		PlaySounds:  true,
+		Size:        size,
+		Redraw:      redraw,
+		Prompt:      prompt,
+		Interrupt:   interrupt,
+		Reload:      reload,
+		LocalSaves:  true,
+		AltScreen:   ansi && term.IsTerminal(int(os.Stdout.Fd())),
+	})
+}
 
-		if command == "quit" { // Allow quit anytime
-			running = false
-			game.AddLog("Exiting simulation...")
-			continue
+// Session is one player at the controls of a plant: where their commands come
+// from, where the dashboard is drawn, and how the run is recorded.
+type Session struct {
+	ReadLine    func() (string, error) // Returns an error once the input has ended
+	Out         io.Writer              // Flushed after every frame if it has a Flush method
+	Sandbox     bool
+	LoadPath    string            // The save the plant was restored from, if any
+	ReportPath  string            // Where to export the post-game report, if anywhere
+	Levels      []*Scenario       // The campaign, when playing one of its levels
+	Profile     *Profile          // Records the run under this profile; nil records none
+	Leaderboard *Leaderboard      // Submits the run to this server; nil submits none
+	Silent      bool              // No bell or sounds
+	PlaySounds  bool              // Run the sound command, on this machine: false where the player sits elsewhere
+	Size        func() ScreenSize // The player's terminal; nil for DefaultScreen
+	Redraw      <-chan struct{}   // Receives when the dashboard is due at once: the terminal was resized, or a hotkey pressed
+	Prompt      func() string     // Replaces the command prompt, for input that echoes itself; nil keeps it
+	Interrupt   <-chan os.Signal  // Receives on Ctrl+C or SIGTERM, to shut down and save the run; nil for none
+	Reload      <-chan os.Signal  // Receives on SIGHUP, to re-read the event tables; nil for none
+	LocalSaves  bool              // Let the player's commands, and a run cut short, write files on this machine: false where they sit elsewhere
+	AltScreen   bool              // Play on the terminal's alternate screen, leaving the last frame on the main one
+}
+
+// screen returns the size of the player's terminal.
+func (s Session) screen() ScreenSize {
+	if s.Size == nil {
+		return DefaultScreen
+	}
+	return s.Size()
+}
+
+// recordMu serializes the writes to the high-score table and the profiles,
+// which several sessions of an SSH server may finish at once.
+var recordMu sync.Mutex
+
+// Play runs the plant's game loops and the dashboard for s until the player
+// quits or the input ends.
+func (p *Plant) Play(s Session) {
+	quitSignal := make(chan struct{})
+	var wg sync.WaitGroup
+
+	game := p.Units[0]
+	crashed := make(chan string, len(p.Units))
+	for _, unit := range p.Units {
+		unit.local = s.LocalSaves
+		wg.Add(1)
+		go func() {
+			defer unit.recoverCrash(crashed)
+			unit.run(&wg, quitSignal)
+		}()
+	}
+
+	uiTicker := time.NewTicker(200 * time.Millisecond) // UI refresh rate
+	defer uiTicker.Stop()
+	if s.AltScreen {
+		fmt.Fprint(s.Out, ansiAltScreen)
+	}
+
+	if s.LoadPath != "" {
+		game.AddLog(LogInfo, tr("SYSTEM RESUME: Restored session from %s.", s.LoadPath))
+	} else {
+		for _, unit := range p.Units {
+			unit.AddLog(LogInfo, tr("SYSTEM BOOT: Reactor control online. Good luck, engineer."))
+		}
+		if game.Scenario != nil {
+			game.AddLog(LogInfo, tr("BRIEFING: %s", game.Scenario.Briefing))
+		}
+		if len(game.Upgrades) > 0 {
+			game.AddLog(LogInfo, tr("UPGRADES: %s.", game.upgradeNames()))
 		}
+		if len(p.Units) > 1 {
+			game.AddLog(LogInfo, tr("PLANT: %d units online. Any meltdown evacuates the plant. Switch units with 'reactor <n>'.", len(p.Units)))
+		}
+	}
+
+	input := p.readInput(s, quitSignal)
+
+	running := true
+	interrupted := false
+	crash := "" // Why a game loop failed, if one did
+	scoreRecorded := false
+	var end *ending // The animation of the run's end, until it has played out
+	rung := 0       // Cues sounded so far
+	for running {
+		p.propagateMeltdown()
+		game = p.Focused()
+		isGameOver, isGameWon := p.Ended()
 		
-		game.mu.Lock()
-		isGameOver = game.GameOver // Re-check before processing non-quit command
-		isGameWon = game.GameWon
-		game.mu.Unlock()
-
-		if isGameOver || isGameWon { // If game ended, only "quit" is processed above
-			game.AddLog(color.WhiteString("Game ended. Only 'quit' is available."))
-			continue
-		}
-
-		switch command {
-		case "stabilize":
-			if len(parts) < 2 {
-				game.AddLog("Usage: stabilize <system_id>")
-			} else if sysID, err := strconv.Atoi(parts[1]); err != nil {
-				game.AddLog("Error: Invalid system ID format.")
-			} else {
-				game.handleStabilize(sysID)
+		if (isGameOver || isGameWon) && !scoreRecorded {
+			recordMu.Lock()
+			offRecord := s.Sandbox || p.assisted() // Cheated and autopiloted runs stay off the record
+			if !offRecord {
+				p.recordHighScore()
 			}
-		case "divert":
-			if len(parts) < 4 {
-				game.AddLog("Usage: divert <from_id> <to_id> <amount>")
-			} else {
-				fromID, err1 := strconv.Atoi(parts[1])
-				toID, err2 := strconv.Atoi(parts[2])
-				amount, err3 := strconv.Atoi(parts[3])
-				if err1 != nil || err2 != nil || err3 != nil {
-					game.AddLog("Error: Invalid ID or amount format for divert.")
-				} else {
-					game.handleDivert(fromID, toID, amount)
+			if s.ReportPath != "" {
+				p.writeReport(s.ReportPath)
+			}
+			if s.Profile != nil && !offRecord {
+				if fresh, err := LoadProfile(s.Profile.Name); err == nil {
+					s.Profile = fresh // Another session may have recorded a run since it was loaded
 				}
+				p.recordProfile(s.Profile)
 			}
-		case "vent":
-			if len(parts) < 2 {
-				game.AddLog("Usage: vent <system_id>")
-			} else if sysID, err := strconv.Atoi(parts[1]); err != nil {
-				game.AddLog("Error: Invalid system ID format.")
-			} else {
-				game.handleVent(sysID)
+			if !offRecord && isGameWon && game.CampaignLevel > 0 {
+				game.recordCampaignWin(s.Levels, s.Profile)
 			}
-		case "override":
-			if len(parts) < 2 {
-				game.AddLog("Usage: override <system_id>")
-			} else if sysID, err := strconv.Atoi(parts[1]); err != nil {
-				game.AddLog("Error: Invalid system ID format.")
-			} else {
-				game.handleOverride(sysID)
+			recordMu.Unlock()
+			if s.Leaderboard != nil && !offRecord {
+				wg.Add(1)
+				go func() { // Over the network: the summary screen shows the result when it comes
+					defer wg.Done()
+					p.submitScore(s.Leaderboard)
+				}()
 			}
-		default:
-			game.AddLog(color.RedString("Unknown command: %s", command))
+			scoreRecorded = true
+			end = newEnding(game.Snapshot(), time.Now())
+		}
+
+		var frame <-chan time.Time
+		if end != nil && !end.over(time.Now()) {
+			end.draw(s.Out, s.screen(), time.Now())
+			frame = time.After(EndingFrame)
+		} else if isGameOver || isGameWon {
+			game.DisplaySummary(s.Out) // Scored summary screen replaces the dashboard
+			fmt.Fprintln(s.Out, theme.Title.Sprint(tr("Game has ended. Type 'quit' or press Ctrl+C to exit.")))
+		} else {
+			game.DisplayFor(s.Out, nil, s.screen())
+			if s.Prompt != nil {
+				fmt.Fprint(s.Out, "\r\x1b[K", fitLine(s.Prompt(), s.screen().Width))
+			}
+		}
+		if !s.Silent {
+			rung = p.soundCues(s.Out, rung, s.PlaySounds)
+		}
+		s.flush()
+
+		select {
+		case <-uiTicker.C:
+			// UI tick happened, just loop to Display again
+			// Player action timeout is handled by the stabilize goroutine itself by calling ClearPlayerAction
+		case <-s.Redraw:
+		case <-input.redraw: // A line has run
+		case <-frame:
+		case <-input.quit:
+			running = false
+		case <-input.done: // The input source is gone
+			running = false
+		case <-s.Reload:
+			for _, unit := range p.Units {
+				unit.Submit("reload")
+			}
+		case <-s.Interrupt:
+			running, interrupted = false, true
+			game.AddLog(LogWarning, tr("INTERRUPTED: Shutting down."))
+		case crash = <-crashed:
+			running = false
 		}
 	}
 
-	close(quitSignal) // Signal all goroutines to stop
-	// Input goroutine will also see quitSignal and close inputChan or exit.
+	select {
+	case <-s.Interrupt: // Ctrl+C ended the input of a raw terminal first
+		interrupted = true
+	default:
+	}
+	if s.AltScreen {
+		fmt.Fprint(s.Out, ansiMainScreen) // The final frame stays in the scrollback
+	}
+	close(quitSignal) // Signal all goroutines to stop, the input's among them
 	
-	game.AddLog("Shutting down auxiliary systems...")
-	game.Display() // Final display before exit
-	fmt.Println(color.CyanString("Waiting for systems to power down..."))
+	game.AddLog(LogInfo, tr("Shutting down auxiliary systems..."))
+	if over, won := p.Ended(); over || won {
+		game.DisplaySummary(s.Out)
+	} else {
+		game.DisplayFor(s.Out, nil, s.screen()) // Final display before exit
+	}
+	fmt.Fprintln(s.Out, theme.Title.Sprint(tr("Waiting for systems to power down...")))
+	s.flush()
 	wg.Wait() // Wait for degradation and event goroutines
-	fmt.Println(color.CyanString("All systems offline. Exiting."))
+	game.clearAutosave()
+	switch {
+	case game.ironman != nil && !game.ended(): // Its own save is its emergency save
+		fmt.Fprintln(s.Out, theme.Warning.Sprint(tr("The ironman run is saved. Go on with it with --ironman.")))
+	case (interrupted || crash != "") && s.LocalSaves:
+		if note := game.emergencySave(); note != "" {
+			fmt.Fprintln(s.Out, theme.Warning.Sprint(note))
+		}
+	}
+	if crash != "" {
+		fmt.Fprintln(s.Out, theme.Critical.Sprint(crash))
+	}
+	fmt.Fprintln(s.Out, theme.Title.Sprint(tr("All systems offline. Exiting.")))
+	s.flush()
+}
+
+// flush sends the frame drawn so far when Out is buffered.
+func (s Session) flush() {
+	if f, ok := s.Out.(interface{ Flush() error }); ok {
+		_ = f.Flush() // A dropped connection ends the input too
+	}
 }
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Action matrix. Not every command works on every system: vent only works
+// on the systems with something to blow off, override cannot force the
+// shields, and divert only moves integrity between systems that are linked,
+// losing some of it on the longer routes.
+// The config's and a scenario's [actions.<command>] tables replace the rules
+// of a command, naming systems so they carry over between layouts; a rule
+// that names none of the layout's systems is ignored. `help <command>` shows
+// the rules in effect.
+
+// matrixCommands are the commands the matrix can limit.
+var matrixCommands = []string{"stabilize", "divert", "vent", "reboot", "override", "overcharge", "inspect"}
+
+// ActionRule limits which systems a command works on.
+type ActionRule struct {
+	Only   []string    `toml:"only"`   // The systems it works on; empty for every one
+	Except []string    `toml:"except"` // Systems it never works on
+	Pairs  [][2]string `toml:"pairs"`  // For divert: the from and to systems it may link; empty for any
+	Losses []RouteLoss `toml:"losses"` // For divert: the routes that lose more than the rest
+}
+
+// ActionRules holds the rule of each command the matrix limits.
+type ActionRules map[string]ActionRule
+
+func defaultActions() ActionRules {
+	return ActionRules{
+		"vent":     {Only: []string{"Pressure Ctrl", "Core Temp"}},
+		"override": {Except: []string{"Shield Integrity"}},
+		"divert": {Pairs: [][2]string{
+			{"Power Output", "Coolant Flow"}, {"Power Output", "Pressure Ctrl"},
+			{"Power Output", "Core Temp"}, {"Power Output", "Shield Integrity"},
+			{"Coolant Flow", "Core Temp"}, {"Coolant Flow", "Pressure Ctrl"},
+			{"Pressure Ctrl", "Coolant Flow"}, {"Pressure Ctrl", "Core Temp"},
+			{"Core Temp", "Power Output"},
+		}, Losses: []RouteLoss{
+			{From: "Power Output", To: "Shield Integrity", Loss: 10},
+			{From: "Core Temp", To: "Power Output", Loss: 10},
+		}},
+	}
+}
+
+func validateActions(rules ActionRules) error {
+	for command, rule := range rules {
+		known := false
+		for _, c := range matrixCommands {
+			known = known || c == command
+		}
+		switch {
+		case !known:
+			return fmt.Errorf("actions: %q cannot be limited (choose %s)", command, strings.Join(matrixCommands, "|"))
+		case (len(rule.Pairs) > 0 || len(rule.Losses) > 0) && command != "divert":
+			return fmt.Errorf("actions.%s: only divert takes pairs and losses", command)
+		case command == "divert" && (len(rule.Only) > 0 || len(rule.Except) > 0):
+			return fmt.Errorf("actions.divert: divert takes pairs, not only or except")
+		}
+		for _, route := range rule.Losses {
+			if route.Loss < 0 || route.Loss > 100 {
+				return fmt.Errorf("actions.divert: the loss from %s to %s must be within 0-100", route.From, route.To)
+			}
+		}
+	}
+	return nil
+}
+
+// ActionMatrix is the action rules resolved against the current layout, by
+// system ID. A command it has no entry for works everywhere.
+type ActionMatrix struct {
+	Allowed map[string][]bool `json:"allowed,omitempty"` // By command, then system
+	Links   [][]bool          `json:"links,omitempty"`   // Divert, by from, then to system; nil for any
+	Loss    [][]int           `json:"loss,omitempty"`    // Percent a divert loses, by from, then to system
+}
+
+// resolveActions links the configured action rules to this game's systems.
+func (g *Game) resolveActions() {
+	g.matrix = ActionMatrix{}
+	n := len(g.Systems)
+	for _, command := range matrixCommands {
+		rule, ok := g.Config.Actions[command]
+		if !ok {
+			continue
+		}
+		if command == "divert" {
+			links, named := make([][]bool, n), false
+			for i := range links {
+				links[i] = make([]bool, n)
+			}
+			for _, pair := range rule.Pairs {
+				from, to := g.systemNamed(pair[0], nil), g.systemNamed(pair[1], nil)
+				named = named || from != nil || to != nil
+				if from != nil && to != nil {
+					links[from.ID][to.ID] = true
+				}
+			}
+			if named {
+				g.matrix.Links = links
+			}
+			g.matrix.Loss = g.resolveLosses(rule.Losses)
+			continue
+		}
+		allowed, named := make([]bool, n), false
+		for i := range allowed {
+			allowed[i] = len(rule.Only) == 0
+		}
+		for _, name := range rule.Only {
+			if sys := g.systemNamed(name, nil); sys != nil {
+				allowed[sys.ID], named = true, true
+			}
+		}
+		for _, name := range rule.Except {
+			if sys := g.systemNamed(name, nil); sys != nil {
+				allowed[sys.ID], named = false, true
+			}
+		}
+		if named {
+			if g.matrix.Allowed == nil {
+				g.matrix.Allowed = map[string][]bool{}
+			}
+			g.matrix.Allowed[command] = allowed
+		}
+	}
+	g.applySystemActions()
+}
+
+// resolveLosses links route losses to this game's systems, or returns nil
+// when they name none of its routes.
+func (g *Game) resolveLosses(routes []RouteLoss) [][]int {
+	var loss [][]int
+	for _, route := range routes {
+		from, to := g.systemNamed(route.From, nil), g.systemNamed(route.To, nil)
+		if from == nil || to == nil {
+			continue
+		}
+		if loss == nil {
+			loss = make([][]int, len(g.Systems))
+			for i := range loss {
+				loss[i] = make([]int, len(g.Systems))
+			}
+		}
+		loss[from.ID][to.ID] = route.Loss
+	}
+	return loss
+}
+
+// allows reports whether command works on system id.
+func (m ActionMatrix) allows(command string, id int) bool {
+	allowed, ok := m.Allowed[command]
+	return !ok || id < 0 || id >= len(allowed) || allowed[id]
+}
+
+// allowsDivert reports whether divert may move integrity from one system to
+// another.
+func (m ActionMatrix) allowsDivert(from, to int) bool {
+	return m.Links == nil || from < 0 || from >= len(m.Links) || to < 0 || to >= len(m.Links[from]) || m.Links[from][to]
+}
+
+// loss is the percent a divert from one system to another loses on its
+// route.
+func (m ActionMatrix) loss(from, to int) int {
+	if from < 0 || from >= len(m.Loss) || to < 0 || to >= len(m.Loss[from]) {
+		return 0
+	}
+	return m.Loss[from][to]
+}
+
+// actionForbidden logs a refusal and returns true when the matrix rules out
+// command on the systems parts names. parts has its system names already
+// turned into IDs.
+func (g *Game) actionForbidden(command string, parts []string) bool {
+	ids := make([]int, 0, 2)
+	for _, i := range systemArgs[command] {
+		if i >= len(parts) {
+			return false // Left to the command's own usage message
+		}
+		id, err := strconv.Atoi(parts[i])
+		if err != nil || id < 0 || id >= len(g.Systems) {
+			return false
+		}
+		ids = append(ids, id)
+	}
+	switch {
+	case command == "divert" && len(ids) == 2:
+		if g.matrix.allowsDivert(ids[0], ids[1]) {
+			return false
+		}
+		from, to := g.Systems[ids[0]], g.Systems[ids[1]]
+		g.AddLog(LogWarning, tr("Cannot divert from %s (%d) to %s (%d): They are not linked. See help divert.", from.Name, from.ID, to.Name, to.ID))
+		return true
+	case len(ids) == 1 && !g.matrix.allows(command, ids[0]):
+		sys := g.Systems[ids[0]]
+		g.AddLog(LogWarning, tr("Cannot %s %s (%d): %s only works on %s.", command, sys.Name, sys.ID, command, strings.Join(g.matrixSystems(command), ", ")))
+		return true
+	}
+	return false
+}
+
+// matrixSystems names the systems command works on, in ID order.
+func (g *Game) matrixSystems(command string) []string {
+	var names []string
+	for _, sys := range g.Systems {
+		if g.matrix.allows(command, sys.ID) {
+			names = append(names, fmt.Sprintf("%s (%d)", tr(sys.Name), sys.ID))
+		}
+	}
+	return names
+}
+
+// matrixHelp is what help <command> says about the systems command works
+// on, or nil when it works on all of them.
+func (g *Game) matrixHelp(command string) []string {
+	if command == "divert" {
+		if g.matrix.Links == nil && g.matrix.Loss == nil {
+			return nil
+		}
+		lines := []string{"", tr("Links divert may use, from each system to:")}
+		for _, from := range g.Systems {
+			var to []string
+			for _, sys := range g.Systems {
+				if sys == from || !g.matrix.allowsDivert(from.ID, sys.ID) {
+					continue
+				}
+				if loss := g.matrix.loss(from.ID, sys.ID); loss > 0 {
+					to = append(to, tr("%d (-%d%%)", sys.ID, loss))
+				} else {
+					to = append(to, strconv.Itoa(sys.ID))
+				}
+			}
+			if len(to) == 0 {
+				to = []string{tr("none")}
+			}
+			lines = append(lines, fmt.Sprintf("  %-22s %s", fmt.Sprintf("%s (%d)", tr(from.Name), from.ID), strings.Join(to, ", ")))
+		}
+		return lines
+	}
+	if _, ok := g.matrix.Allowed[command]; !ok {
+		return nil
+	}
+	names := g.matrixSystems(command)
+	if len(names) == 0 {
+		names = []string{tr("none")}
+	}
+	return []string{"", tr("Works on: %s", strings.Join(names, ", "))}
+}
+
+// mergeActions returns base with the rules of over replacing its own, by
+// command.
+func mergeActions(base, over ActionRules) ActionRules {
+	merged := make(ActionRules, len(base)+len(over))
+	for command, rule := range base {
+		merged[command] = rule
+	}
+	for command, rule := range over {
+		merged[command] = rule
+	}
+	return merged
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Meltdown. Losing a second system does not end the run at once: the
+// reactor goes MELTDOWN IMMINENT for MeltdownGrace, while the player can
+// still scram or reboot one of the systems to bring the count back under
+// two. Only if the clock runs out does the reactor melt down. A sandbox
+// reactor never does.
+const MeltdownGrace = 30 * time.Second
+
+// updateMeltdown starts the meltdown clock once two systems are offline and
+// stops it once fewer are. checkEnd calls it on the game loop.
+func (g *Game) updateMeltdown() {
+	down := !g.Sandbox && g.offlineCount() >= 2
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	due := g.meltdownDue
+	switch {
+	case down && due == 0:
+		g.meltdownDue = elapsed + MeltdownGrace
+	case !down && due != 0:
+		g.meltdownDue = 0
+	}
+	g.mu.Unlock()
+	switch {
+	case down && due == 0:
+		g.AddLog(LogCritical, tr("MELTDOWN IMMINENT: Multiple systems offline. Scram or bring a system back online within %.0fs!", MeltdownGrace.Seconds()))
+	case !down && due != 0:
+		g.AddLog(LogSuccess, tr("MELTDOWN AVERTED: Fewer than two systems offline. The reactor holds."))
+	}
+}
+
+// meltdownExpired reports whether the meltdown clock has run out.
+func (g *Game) meltdownExpired() bool {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.meltdownDue != 0 && elapsed >= g.meltdownDue
+}
+
+// meltdownImminent reports whether the meltdown clock is running.
+func (g *Game) meltdownImminent() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.meltdownDue != 0
+}
+
+// meltdownLeftLocked returns the time left before the meltdown, or 0 while
+// none looms. The caller must hold g.mu.
+func (g *Game) meltdownLeftLocked(elapsed time.Duration) time.Duration {
+	if g.meltdownDue == 0 {
+		return 0
+	}
+	return max(time.Nanosecond, g.meltdownDue-elapsed)
+}
+
+// meltdownTitle is the dashboard title, in red while a meltdown looms.
+func (s GameState) meltdownTitle() string {
+	if s.Meltdown == 0 || s.GameOver || s.GameWon {
+		return theme.Title.Sprint(tr("--- REACTOR CONTROL TERMINAL ---"))
+	}
+	return theme.Alert.With(color.Bold).Sprint(tr("--- REACTOR CONTROL TERMINAL: MELTDOWN IMMINENT ---"))
+}
+
+// imminentBanner returns the countdown banner centered in width, or "" while
+// no meltdown looms. It flashes like meltdownBanner, in inverse red.
+func (s GameState) imminentBanner(width int) string {
+	if s.Meltdown == 0 || s.GameOver || s.GameWon {
+		return ""
+	}
+	text := tr("!!! MELTDOWN IN %ds: SCRAM OR REBOOT A SYSTEM !!!", ceilSeconds(s.Meltdown))
+	if pad := width - 1 - len(text); pad > 0 {
+		text = strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+	}
+	style := theme.Critical.With(color.Bold)
+	if s.Elapsed/BannerFlash%2 == 0 {
+		style = style.With(color.ReverseVideo)
+	}
+	return style.Sprint(text)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Stabilize mini-game. With stabilize_minigame = true in the config,
+// stabilize at the terminal is not a passive wait: the crew calls out a short
+// sequence of WASD keys to repeat, and how fast and clean it comes back
+// decides how much of the usual restore the repair holds, from MinigameFloor
+// to 100 percent. Typing it right finishes the
+// repair at once, and every wrong sequence costs MinigameMissCost percent.
+// Left untyped, the repair completes when the stabilize time runs out and
+// holds MinigameFloor percent. The config's default keeps the passive wait,
+// and headless runs, the autopilot, co-op crews and the tutorial always do.
+// With --hotkeys the sequence's keys go to it while it awaits them.
+
+const (
+	MinigameKeys     = "wasd"
+	MinigameLength   = 4
+	MinigameFloor    = 70 // Percent of the restore a slow or untyped sequence holds
+	MinigameMissCost = 10 // Percent lost per wrong sequence
+	MinigameFast     = 40 // Percent of the stabilize time within which a sequence holds it all
+)
+
+// stabilizeGame is a sequence awaiting input while a stabilize runs.
+type stabilizeGame struct {
+	Sequence string
+	start    time.Time
+	deadline time.Time
+	misses   int
+	percent  int           // Of the restore the repair holds, once typed; 0 before
+	cancel   chan struct{} // Of the stabilize it finishes
+	finish   func()
+}
+
+// minigameEnabled reports whether the next stabilize plays the mini-game.
+func (g *Game) minigameEnabled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.minigames && g.Config.StabilizeMinigame && g.autopilot == nil && len(g.operators) == 1 && g.tutorial == nil
+}
+
+// startMinigame calls out a sequence for the stabilize armed with cancel,
+// which finish completes.
+func (g *Game) startMinigame(duration time.Duration, cancel chan struct{}, finish func()) {
+	keys := make([]byte, MinigameLength)
+	for i := range keys {
+		keys[i] = MinigameKeys[g.actionRng.Intn(len(MinigameKeys))]
+	}
+	now := g.clock.Now()
+	mg := &stabilizeGame{Sequence: string(keys), start: now, deadline: now.Add(duration), cancel: cancel, finish: finish}
+	g.mu.Lock()
+	g.minigame = mg
+	g.mu.Unlock()
+	g.AddLog(LogInfo, tr("STABILIZE: Repeat the sequence %s, the faster the better.", spacedKeys(mg.Sequence)))
+}
+
+// interceptMinigame checks input against the sequence of the mini-game under
+// way. It reports whether the input was consumed: the sequence, or a wrong
+// attempt at one.
+func (g *Game) interceptMinigame(input string) bool {
+	typed := strings.ToLower(strings.ReplaceAll(input, " ", ""))
+	var mg *stabilizeGame
+	solved := false
+	g.locked(func() {
+		if g.minigame == nil || len(typed) != MinigameLength || strings.Trim(typed, MinigameKeys) != "" {
+			return
+		}
+		mg = g.minigame
+		if typed != mg.Sequence {
+			mg.misses++
+			return
+		}
+		mg.percent = mg.score(g.clock.Now())
+		solved = true
+	})
+	switch {
+	case mg == nil:
+		return false
+	case !solved:
+		g.AddLog(LogWarning, tr("STABILIZE: Wrong sequence. Repeat %s.", spacedKeys(mg.Sequence)))
+	default:
+		g.finishAction(mg.cancel, mg.finish)
+	}
+	return true
+}
+
+// minigamePending reports whether a sequence awaits the player's keys.
+func (g *Game) minigamePending() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.minigame != nil
+}
+
+// score is the percent of the restore a sequence typed at now holds.
+func (mg *stabilizeGame) score(now time.Time) int {
+	percent := 100
+	total, taken := mg.deadline.Sub(mg.start), now.Sub(mg.start)
+	if fast := total * MinigameFast / 100; total > 0 && taken > fast {
+		percent -= int(int64(100-MinigameFloor) * int64(taken-fast) / int64(total-fast))
+	}
+	return max(MinigameFloor, percent-mg.misses*MinigameMissCost)
+}
+
+// endMinigame returns the percent of the restore the finishing stabilize
+// holds, logging how the sequence went: 100 when no mini-game was played.
+func (g *Game) endMinigame() int {
+	g.mu.Lock()
+	mg := g.minigame
+	g.minigame = nil
+	g.mu.Unlock()
+	switch {
+	case mg == nil:
+		return 100
+	case mg.percent == 0:
+		g.AddLog(LogWarning, tr("STABILIZE: The sequence went untyped. The repair holds %d%%.", MinigameFloor))
+		return MinigameFloor
+	}
+	g.AddLog(LogInfo, tr("STABILIZE: Sequence repeated. The repair holds %d%%.", mg.percent))
+	return mg.percent
+}
+
+// spacedKeys spells out a sequence for the player, as in "W A S D".
+func spacedKeys(sequence string) string {
+	return strings.Join(strings.Split(strings.ToUpper(sequence), ""), " ")
+}
+
+// MinigameState is a snapshot of the sequence awaiting input.
+type MinigameState struct {
+	Sequence string        `json:"sequence"`
+	Left     time.Duration `json:"left_ns"`
+}
+
+// minigameSnapshotLocked copies the mini-game under way at now, or returns
+// nil if there is none. The caller must hold g.mu.
+func (g *Game) minigameSnapshotLocked(now time.Time) *MinigameState {
+	if g.minigame == nil {
+		return nil
+	}
+	return &MinigameState{Sequence: g.minigame.Sequence, Left: g.minigame.deadline.Sub(now)}
+}
+
+// minigamePrompt renders the sequence awaiting input for the dashboard, or "".
+func (s GameState) minigamePrompt() string {
+	if s.Minigame == nil {
+		return ""
+	}
+	return theme.Notice.With(color.Bold).Sprint(tr(" STABILIZE: TYPE %s within %ds ", spacedKeys(s.Minigame.Sequence), ceilSeconds(s.Minigame.Left)))
+}
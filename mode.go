@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ModeClassic = "classic" // Survive Config.GameDuration to win
+	ModeEndless = "endless" // No win condition; survive as long as possible
+
+	EscalationInterval = 60 * time.Second // Endless mode ramps difficulty this often
+	EscalationSeverity = 0.25             // Extra damage multiplier per escalation level
+)
+
+// ParseMode validates a --mode value.
+func ParseMode(mode string) (string, error) {
+	switch mode {
+	case ModeClassic, ModeEndless:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown mode %q (choose %s|%s)", mode, ModeClassic, ModeEndless)
+}
+
+// checkEscalation escalates once another EscalationInterval has been survived.
+func (g *Game) checkEscalation() {
+	g.mu.Lock()
+	due := int(g.elapsedAtLocked(g.clock.Now())/EscalationInterval) > g.Level
+	g.mu.Unlock()
+	if due {
+		g.escalate()
+	}
+}
+
+// escalate bumps every system's degradation rate and the event severity.
+func (g *Game) escalate() {
+	g.mu.Lock()
+	g.Level++
+	level := g.Level
+	g.mu.Unlock()
+
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		sys.DegradationRate++
+		sys.mu.Unlock()
+	}
+	g.AddLog(LogWarning, tr("WARNING: Reactor instability escalating! Level %d: degradation and event severity increased.", level))
+}
+
+// severityScale is the extra damage multiplier from endless escalation.
+func (g *Game) severityScale() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return 1 + EscalationSeverity*float64(g.Level)
+}
+
+// Elapsed returns the time played so far, frozen once the game has ended.
+func (g *Game) Elapsed() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.clock.Now()
+	if !g.EndTime.IsZero() {
+		now = g.EndTime
+	}
+	return g.elapsedAtLocked(now)
+}
+
+// elapsedAtLocked returns the game time at wall time t, allowing for a
+// sandbox timescale. The caller must hold g.mu.
+func (g *Game) elapsedAtLocked(t time.Time) time.Duration {
+	if g.paused {
+		return g.scaleBase
+	}
+	if g.scaleMark.IsZero() {
+		return t.Sub(g.StartTime)
+	}
+	return g.scaleBase + time.Duration(float64(t.Sub(g.scaleMark))*g.timescale)
+}
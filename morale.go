@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Crew morale. Every critical alarm and failed override wears the crew
+// down, and every calm stretch without a critical system lifts them. Below
+// MoraleLow a tired crew stabilizes slowly and may fumble a command onto the
+// system next to the one it names; `rally` spends the player's time to
+// lift them.
+
+const (
+	MoraleMax           = 100
+	MoraleLow           = 40               // Below this the crew slows down and fumbles
+	MoraleAlarmLoss     = 8                // Lost to every critical alarm
+	MoraleOverrideLoss  = 12               // Lost to every failed override
+	MoraleCalmTime      = 30 * time.Second // A stretch without a critical system
+	MoraleCalmGain      = 10               // Gained for every calm stretch
+	MoraleSlowdown      = 0.5              // Stabilization is this much slower at zero morale
+	MoraleFumbleChance  = 25               // Percent chance of a fumble at zero morale
+	RallyTime           = 6 * time.Second  // Rallying the crew
+	RallyGain           = 30
+	moraleGaugeSegments = 20
+)
+
+// updateMorale lifts morale after every calm stretch. It runs once per
+// degradation tick; values holds each system's integrity, indexed by ID.
+func (g *Game) updateMorale(values []int) {
+	calm := true
+	for i, val := range values {
+		if val <= g.Systems[i].CriticalThreshold {
+			calm = false
+		}
+	}
+	tick := g.degradationTick()
+	g.mu.Lock()
+	lifted := false
+	if !calm {
+		g.moraleCalm = 0
+	} else if g.moraleCalm += tick; g.moraleCalm >= MoraleCalmTime {
+		g.moraleCalm = 0
+		lifted = g.Morale < MoraleMax
+	}
+	g.mu.Unlock()
+	if lifted {
+		g.changeMorale(MoraleCalmGain, tr("A calm stretch lifts the crew's spirits."))
+	}
+}
+
+// changeMorale moves morale by delta, logging why, and announces when it
+// crosses MoraleLow.
+func (g *Game) changeMorale(delta int, why string) {
+	g.mu.Lock()
+	before := g.Morale
+	g.Morale = min(MoraleMax, max(0, g.Morale+delta))
+	after := g.Morale
+	g.mu.Unlock()
+	if after == before {
+		return
+	}
+	g.AddLog(LogInfo, tr("MORALE: %s (%d)", why, after))
+	switch {
+	case before >= MoraleLow && after < MoraleLow:
+		g.AddLog(LogWarning, tr("WARNING: Crew morale is low. Stabilization slows down and commands may be fumbled."))
+	case before < MoraleLow && after >= MoraleLow:
+		g.AddLog(LogSuccess, tr("Crew morale restored."))
+	}
+}
+
+// moraleShortfall is how far below MoraleLow morale stands, from 0 to 1.
+func (g *Game) moraleShortfall() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return moraleShortfall(g.Morale)
+}
+
+func moraleShortfall(morale int) float64 {
+	if morale >= MoraleLow {
+		return 0
+	}
+	return float64(MoraleLow-morale) / MoraleLow
+}
+
+// moraleDuration stretches a stabilization of duration for low morale.
+func (g *Game) moraleDuration(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration) * (1 + MoraleSlowdown*g.moraleShortfall()))
+}
+
+// fumbleChance is the percent chance that a command is fumbled.
+func fumbleChance(morale int) int {
+	return int(MoraleFumbleChance * moraleShortfall(morale))
+}
+
+// fumble may turn an action onto the system next to the one it names,
+// while morale is low. parts and rawParts are the command line, its system
+// names already turned into IDs.
+func (g *Game) fumble(command string, parts, rawParts []string) {
+	args := systemArgs[command]
+	if !queueableCommands[command] || len(args) == 0 || args[0] >= len(parts) || len(g.Systems) < 2 {
+		return
+	}
+	id, err := strconv.Atoi(parts[args[0]])
+	if err != nil || id < 0 || id >= len(g.Systems) {
+		return
+	}
+	g.mu.Lock()
+	chance := fumbleChance(g.Morale)
+	g.mu.Unlock()
+	if chance == 0 || g.actionRng.Intn(100) >= chance {
+		return
+	}
+	wrong := id + 1 - 2*g.actionRng.Intn(2)
+	if wrong < 0 || wrong >= len(g.Systems) {
+		wrong = 2*id - wrong // The only neighbour there is
+	}
+	parts[args[0]] = strconv.Itoa(wrong)
+	rawParts[args[0]] = parts[args[0]]
+	g.AddLog(LogWarning, tr("FUMBLE: The tired crew worked %s (%d) instead of %s (%d)!", g.Systems[wrong].Name, wrong, g.Systems[id].Name, id))
+}
+
+func (g *Game) handleRally() {
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot rally: Player busy with another action."))
+		return
+	}
+	g.mu.Lock()
+	full := g.Morale >= MoraleMax
+	g.mu.Unlock()
+	if full {
+		g.AddLog(LogWarning, tr("Cannot rally: The crew's morale is already full."))
+		return
+	}
+	g.AddLog(LogInfo, tr("Rallying the crew. This will take time."))
+	g.startRally(RallyTime)
+}
+
+// startRally occupies the player with rallying the crew. It is shared by
+// handleRally and by resuming a saved game.
+func (g *Game) startRally(duration time.Duration) {
+	g.SetPlayerAction(tr("Rallying the crew..."), duration)
+	g.mu.Lock()
+	g.ActionSystem = -1
+	g.ActionKind = ActionRally
+	g.mu.Unlock()
+
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		g.ClearPlayerAction()
+		g.changeMorale(RallyGain, tr("The crew is rallied."))
+	})
+}
+
+// moraleGauge renders the morale line for the dashboard.
+func (s GameState) moraleGauge() string {
+	fill := s.Morale * moraleGaugeSegments / MoraleMax
+	bar := fmt.Sprintf("[%s%s]", strings.Repeat("#", fill), strings.Repeat("-", moraleGaugeSegments-fill))
+	line := tr("MORALE:    %5d %s", s.Morale, bar)
+	if s.Morale < MoraleLow {
+		slower := MoraleSlowdown * moraleShortfall(s.Morale) * 100
+		return theme.Warning.With(color.Bold).Sprint(tr("%s LOW: stabilize +%.0f%%, %d%% fumbles", line, slower, fumbleChance(s.Morale)))
+	}
+	return theme.OK.Sprint(line)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mouse. With --mouse, which turns on --hotkeys, the terminal reports clicks
+// as well as keys: clicking a system's row selects it, the prompt line turns
+// into a toolbar of buttons for the hotkeys, and dragging from one system's
+// row to another's opens the divert dialog between them, with buttons for
+// the amount. A click is matched against the frame last drawn.
+
+const (
+	ansiMouseOn  = "\x1b[?1000h\x1b[?1006h" // Report presses and releases, in SGR form
+	ansiMouseOff = "\x1b[?1000l\x1b[?1006l"
+
+	mouseReportStart = "\x1b[<"
+	mouseReportMax   = 24 // Bytes of a report, at most
+	mouseLeft        = 0  // Button code of the left button; wheels, modifiers and others add to it
+)
+
+// mouseEvent is a press or release of a mouse button at column x of row y of
+// the screen, both counted from 0.
+type mouseEvent struct {
+	button int
+	x, y   int
+	press  bool
+}
+
+// mouseReport parses the SGR mouse report that data starts with. size is the
+// bytes it takes, 0 if data does not start with one; a report cut off by the
+// end of data is not whole. A report's start followed by anything else is
+// skipped, as an event of no button.
+func mouseReport(data []byte) (ev mouseEvent, size int, whole bool) {
+	if !bytes.HasPrefix(data, []byte(mouseReportStart)) {
+		return mouseEvent{}, 0, false
+	}
+	ev.button = -1
+	for end := len(mouseReportStart); end < len(data); end++ {
+		switch c := data[end]; {
+		case c == 'M' || c == 'm':
+			fields := strings.Split(string(data[len(mouseReportStart):end]), ";")
+			if len(fields) == 3 {
+				button, err1 := strconv.Atoi(fields[0])
+				x, err2 := strconv.Atoi(fields[1])
+				y, err3 := strconv.Atoi(fields[2])
+				if err1 == nil && err2 == nil && err3 == nil {
+					ev = mouseEvent{button: button, x: x - 1, y: y - 1}
+				}
+			}
+			ev.press = c == 'M'
+			return ev, end + 1, true
+		case c != ';' && (c < '0' || c > '9') || end >= mouseReportMax:
+			return ev, len(mouseReportStart), true
+		}
+	}
+	return ev, len(data), false
+}
+
+// promptButton is a button of the toolbar the prompt line turns into: its
+// columns, from inclusive to exclusive, and the key it presses.
+type promptButton struct {
+	from, to int
+	key      byte
+}
+
+// button appends a button reading label that presses key to the prompt text.
+// The caller must hold h.mu.
+func (h *hotkeys) button(text *string, label string, key byte) {
+	*text += " "
+	from := visibleLen(*text)
+	*text += theme.Inverse.Sprint(" " + label + " ")
+	h.buttons = append(h.buttons, promptButton{from: from, to: visibleLen(*text), key: key})
+}
+
+// click handles a mouse press or release, and returns the command line it
+// completes, if any. The caller must hold h.mu.
+func (h *hotkeys) click(ev mouseEvent) (string, bool) {
+	if h.screen == nil || h.typing || ev.button != mouseLeft {
+		return "", false
+	}
+	line, bottom := h.screen.line(ev.y)
+	if bottom {
+		if !ev.press {
+			return "", false
+		}
+		for _, b := range h.buttons {
+			if ev.x >= b.from && ev.x < b.to {
+				return h.key(b.key)
+			}
+		}
+		return "", false
+	}
+	id := h.systemAt(line, ev.x)
+	if ev.press {
+		h.pressed = id
+		return "", false
+	}
+	from := h.pressed
+	h.pressed = -1
+	switch {
+	case id < 0:
+	case len(h.divert) == 2:
+		h.note = tr("Click 10, 20 or 30, or Esc.")
+	case from >= 0 && from != id && h.divert == nil:
+		h.selected, h.divert, h.note = from, []int{from, id}, ""
+	case from == id:
+		return h.systemKey(id)
+	}
+	return "", false
+}
+
+var systemTag = regexp.MustCompile(`\[(\d+)\]`)
+
+// systemAt returns the system at column x of line, or -1: the one whose row
+// it is, when it starts with the system's ID, or on the row of IDs under the
+// gauges the ID nearest x.
+func (h *hotkeys) systemAt(line string, x int) int {
+	tags := systemTag.FindAllStringSubmatchIndex(line, -1)
+	tag := -1
+	switch {
+	case len(tags) == 0:
+	case tags[0][0] == 0:
+		tag = 0
+	case strings.TrimSpace(systemTag.ReplaceAllString(line, "")) == "":
+		best := gaugeWidth/2 + 1
+		for i, t := range tags {
+			if d := max(t[0]-x, x-t[1]+1, 0); d < best {
+				tag, best = i, d
+			}
+		}
+	}
+	if tag < 0 {
+		return -1
+	}
+	id, err := strconv.Atoi(line[tags[tag][2]:tags[tag][3]])
+	if err != nil || id >= len(h.names) {
+		return -1
+	}
+	return id
+}
+
+// screenTracker passes what is drawn on to w, and keeps the frame on screen,
+// from the last clear on, to match clicks against.
+type screenTracker struct {
+	w     io.Writer
+	mu    sync.Mutex
+	frame []byte
+}
+
+func (t *screenTracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	if i := bytes.LastIndex(p, []byte(ansiClear)); i >= 0 {
+		t.frame = append(t.frame[:0], p[i+len(ansiClear):]...)
+	} else {
+		t.frame = append(t.frame, p...)
+	}
+	t.mu.Unlock()
+	return t.w.Write(p)
+}
+
+// line returns row of the frame on screen, without its colors, and whether
+// it is the bottom row, where the prompt is.
+func (t *screenTracker) line(row int) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := strings.Split(string(t.frame), "\n")
+	if row < 0 || row >= len(lines) {
+		return "", false
+	}
+	line := ansiEscape.ReplaceAllString(lines[row], "")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:] // The prompt, drawn over the line
+	}
+	return line, row == len(lines)-1
+}
+
+// enableMouse turns on the terminal's mouse reports, and returns the writer
+// to draw on for clicks to be matched against what is drawn.
+func (h *hotkeys) enableMouse(w io.Writer) io.Writer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.screen = &screenTracker{w: w}
+	io.WriteString(w, ansiMouseOn)
+	return h.screen
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mutator names, as given to --mutator and stored in saves.
+const (
+	MutatorNoKits       = "nokits"
+	MutatorDoubleEvents = "doubleevents"
+	MutatorFragile      = "fragile"
+	MutatorPacifist     = "pacifist"
+	MutatorAdaptive     = "adaptive"
+)
+
+const (
+	DoubleEventsScale = 0.5 // Multiplies the event interval
+	FragileDamage     = 1.5 // Multiplies all damage
+)
+
+// Mutator is an optional rule variant. Mutators compose; each one multiplies
+// the final score by its bonus.
+type Mutator struct {
+	Name            string
+	Desc            string
+	ScoreMultiplier float64
+}
+
+var mutators = map[string]Mutator{
+	MutatorNoKits:       {MutatorNoKits, "start without repair kits; no supply drops", 1.5},
+	MutatorDoubleEvents: {MutatorDoubleEvents, "events strike twice as often", 1.5},
+	MutatorFragile:      {MutatorFragile, "all damage x1.5", 1.4},
+	MutatorPacifist:     {MutatorPacifist, "override is not allowed", 1.2},
+	MutatorAdaptive:     {MutatorAdaptive, "events ease off while you struggle and press harder while you cope", 1},
+}
+
+func mutatorNames() []string {
+	names := make([]string, 0, len(mutators))
+	for name := range mutators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MutatorByName looks up a mutator, case-insensitively.
+func MutatorByName(name string) (Mutator, error) {
+	m, ok := mutators[strings.ToLower(name)]
+	if !ok {
+		return Mutator{}, fmt.Errorf("unknown mutator %q (choose %s)", name, strings.Join(mutatorNames(), "|"))
+	}
+	return m, nil
+}
+
+// mutatorFlag collects --mutator values. The flag may be repeated or given a
+// comma-separated list.
+type mutatorFlag []Mutator
+
+func (f *mutatorFlag) String() string {
+	return strings.Join(mutatorList(*f), ",")
+}
+
+func (f *mutatorFlag) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		m, err := MutatorByName(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		if !hasMutator(*f, m.Name) {
+			*f = append(*f, m)
+		}
+	}
+	return nil
+}
+
+func hasMutator(list []Mutator, name string) bool {
+	for _, m := range list {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mutatorList returns the names of the given mutators.
+func mutatorList(list []Mutator) []string {
+	names := make([]string, len(list))
+	for i, m := range list {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// applyMutators sets up a new game for its mutators.
+func (g *Game) applyMutators() {
+	if hasMutator(g.Mutators, MutatorNoKits) {
+		g.Inventory[ItemRepairKit] = 0
+	}
+	g.applyMutatorWeights()
+}
+
+// applyMutatorWeights keeps supply drops out of the pool under nokits and at
+// their configured weight otherwise, so a restored game matches its save.
+func (g *Game) applyMutatorWeights() {
+	weight := 0
+	if !hasMutator(g.Mutators, MutatorNoKits) {
+		ev, _ := lookupEvent("supply_drop")
+		weight = ev.Weight()
+		if w, ok := g.Config.EventWeights["supply_drop"]; ok {
+			weight = w
+		}
+	}
+	_ = g.Events.SetWeight("supply_drop", weight) // Built in, so always known
+}
+
+// mutatorMultiplier is the combined score bonus of the active mutators.
+func (g *Game) mutatorMultiplier() float64 {
+	scale := 1.0
+	for _, m := range g.Mutators {
+		scale *= m.ScoreMultiplier
+	}
+	return scale
+}
+
+// mutatorDamageScale is the damage multiplier from the active mutators.
+func (g *Game) mutatorDamageScale() float64 {
+	scale := g.adaptivePressure()
+	if hasMutator(g.Mutators, MutatorFragile) {
+		scale *= FragileDamage
+	}
+	return scale
+}
+
+// mutatorEventScale is the event interval multiplier from the active mutators.
+func (g *Game) mutatorEventScale() float64 {
+	scale := 1 / g.adaptivePressure()
+	if hasMutator(g.Mutators, MutatorDoubleEvents) {
+		scale *= DoubleEventsScale
+	}
+	return scale
+}
+
+// pacifistBlocks reports, and logs, whether the pacifist mutator forbids an override.
+func (g *Game) pacifistBlocks() bool {
+	if !hasMutator(g.Mutators, MutatorPacifist) {
+		return false
+	}
+	g.AddLog(LogWarning, tr("Cannot override: Forbidden by the pacifist mutator."))
+	return true
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// ObjectiveChecker is a run's victory condition. Check reports whether the
+// run has been won, or why it has been lost on objective; meltdowns are
+// checked separately. Describe renders the objective for the header.
+type ObjectiveChecker interface {
+	Check(g *Game, elapsed time.Duration) (won bool, failure string)
+	Describe(g *Game) string
+}
+
+// newObjectiveChecker builds the checker for a validated scenario objective.
+func newObjectiveChecker(obj Objective) ObjectiveChecker {
+	switch obj.Kind {
+	case ObjectiveHoldAbove:
+		return holdAboveObjective{obj}
+	case ObjectiveAllAbove:
+		return allAboveObjective{obj}
+	case ObjectiveKeepKits:
+		return keepKitsObjective{obj}
+	case ObjectiveHoldBand:
+		return holdBandObjective{obj}
+	}
+	return surviveObjective{}
+}
+
+// surviveObjective wins once the run's duration has elapsed.
+type surviveObjective struct{}
+
+func (surviveObjective) Check(g *Game, elapsed time.Duration) (bool, string) {
+	return elapsed >= g.Config.GameDuration, ""
+}
+
+func (surviveObjective) Describe(g *Game) string {
+	return tr("Survive %s", formatDuration(g.Config.GameDuration))
+}
+
+// holdAboveObjective wins once one system has stayed above a value for a
+// continuous stretch.
+type holdAboveObjective struct{ Objective }
+
+func (o holdAboveObjective) Check(g *Game, elapsed time.Duration) (bool, string) {
+	sys := g.Systems[o.System]
+	sys.mu.Lock()
+	above := sys.Value > o.Value
+	sys.mu.Unlock()
+
+	if g.trackHeld(elapsed, above, true) >= o.Hold {
+		return true, ""
+	}
+	if elapsed >= g.Config.GameDuration {
+		return false, tr("OBJECTIVE FAILED: %s was not held above %d for %s in time.", sys.Name, o.Value, formatDuration(o.Hold))
+	}
+	return false, ""
+}
+
+func (o holdAboveObjective) Describe(g *Game) string {
+	return tr("Keep %s above %d for %s (held %s)", g.Systems[o.System].Name, o.Value, formatDuration(o.Hold), formatDuration(g.heldTime()))
+}
+
+// allAboveObjective wins the moment every system is at or above a value.
+type allAboveObjective struct{ Objective }
+
+func (o allAboveObjective) Check(g *Game, elapsed time.Duration) (bool, string) {
+	lowest := g.lowestValue()
+	if lowest >= o.Value {
+		return true, ""
+	}
+	if elapsed >= g.Config.GameDuration {
+		return false, tr("OBJECTIVE FAILED: Systems never all reached %d in time.", o.Value)
+	}
+	return false, ""
+}
+
+func (o allAboveObjective) Describe(g *Game) string {
+	return tr("Bring every system to %d+ at once within %s (lowest %d)", o.Value, formatDuration(g.Config.GameDuration), g.lowestValue())
+}
+
+// keepKitsObjective wins by surviving the duration with enough repair kits.
+type keepKitsObjective struct{ Objective }
+
+func (o keepKitsObjective) Check(g *Game, elapsed time.Duration) (bool, string) {
+	if elapsed < g.Config.GameDuration {
+		return false, ""
+	}
+	if kits := g.itemCount(ItemRepairKit); kits < o.Kits {
+		return false, tr("OBJECTIVE FAILED: Survived with %d repair kits; %d were required.", kits, o.Kits)
+	}
+	return true, ""
+}
+
+func (o keepKitsObjective) Describe(g *Game) string {
+	return tr("Survive %s with %d+ repair kits left (have %d)", formatDuration(g.Config.GameDuration), o.Kits, g.itemCount(ItemRepairKit))
+}
+
+// holdBandObjective wins once one system has spent a cumulative stretch
+// within a band; time outside the band pauses the count without resetting it.
+type holdBandObjective struct{ Objective }
+
+func (o holdBandObjective) Check(g *Game, elapsed time.Duration) (bool, string) {
+	sys := g.Systems[o.System]
+	sys.mu.Lock()
+	inBand := sys.Value >= o.Min && sys.Value <= o.Max
+	sys.mu.Unlock()
+
+	if g.trackHeld(elapsed, inBand, false) >= o.Hold {
+		return true, ""
+	}
+	if elapsed >= g.Config.GameDuration {
+		return false, tr("OBJECTIVE FAILED: %s spent under %s between %d and %d.", sys.Name, formatDuration(o.Hold), o.Min, o.Max)
+	}
+	return false, ""
+}
+
+func (o holdBandObjective) Describe(g *Game) string {
+	return tr("Keep %s within %d-%d for %s in total (held %s)", g.Systems[o.System].Name, o.Min, o.Max, formatDuration(o.Hold), formatDuration(g.heldTime()))
+}
+
+// trackHeld adds the time since the last check to the held total while held
+// is true and returns the total. With reset, losing the condition starts the
+// count over.
+func (g *Game) trackHeld(elapsed time.Duration, held, reset bool) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if held {
+		g.objectiveHeld += elapsed - g.objectiveMark
+	} else if reset {
+		g.objectiveHeld = 0
+	}
+	g.objectiveMark = elapsed
+	return g.objectiveHeld
+}
+
+func (g *Game) heldTime() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.objectiveHeld
+}
+
+// lowestValue returns the lowest true integrity among the systems.
+func (g *Game) lowestValue() int {
+	lowest := math.MaxInt
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		if sys.Value < lowest {
+			lowest = sys.Value
+		}
+		sys.mu.Unlock()
+	}
+	return lowest
+}
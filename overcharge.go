@@ -0,0 +1,97 @@
+package main
+
+const (
+	OverchargePercent = 130 // Ceiling of an overcharged system, as a share of its max value
+	OverchargeReady   = 90  // Share of max value a system needs before it can be overcharged
+	OverchargeDrain   = 2   // Extra degradation per tick while overcharged
+	BlowoutChance     = 5   // Percent chance per tick that an overcharged system blows out
+	BlowoutPercent    = 10  // Share of max value left after a blowout
+
+	ShieldAbsorb      = 0.5  // Damage multiplier while the shields are overcharged
+	PowerStabilizeCut = 0.75 // Stabilize time multiplier while power is overcharged
+)
+
+// Systems with a special buff while overcharged, by name so custom layouts
+// reusing the default names get them too.
+const (
+	OverchargeShieldSystem = "Shield Integrity"
+	OverchargePowerSystem  = "Power Output"
+)
+
+// IsOvercharged reports whether the system is running above its max value.
+func (s *System) IsOvercharged() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overchargedLocked()
+}
+
+func (s *System) overchargedLocked() bool {
+	return s.Overcharge > 0 && s.Value > s.MaxValue
+}
+
+// ceilingLocked is the highest value the system may currently hold.
+func (s *System) ceilingLocked() int {
+	if s.Overcharge > s.MaxValue {
+		return s.Overcharge
+	}
+	return s.MaxValue
+}
+
+// overchargeBuffActive reports whether the named system exists and is overcharged.
+func (g *Game) overchargeBuffActive(name string) bool {
+	sys := g.systemNamed(name, nil)
+	return sys != nil && sys.IsOvercharged()
+}
+
+func (g *Game) handleOvercharge(sysID int) {
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for overcharge."))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot overcharge: Player busy with another action."))
+		return
+	}
+
+	sys := g.Systems[sysID]
+	sys.mu.Lock()
+	switch {
+	case sys.Offline:
+		sys.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot overcharge: %s (%d) is OFFLINE. Reboot it first.", sys.Name, sysID))
+		return
+	case sys.overchargedLocked():
+		sys.mu.Unlock()
+		g.AddLog(LogWarning, tr("System %s (%d) is already overcharged.", sys.Name, sysID))
+		return
+	case sys.Value < sys.MaxValue*OverchargeReady/100:
+		sys.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot overcharge: %s (%d) must be at %d%% integrity or more.", sys.Name, sysID, OverchargeReady))
+		return
+	}
+	sys.Overcharge = sys.MaxValue * OverchargePercent / 100
+	sys.Value = sys.Overcharge
+	value := sys.Value
+	sys.mu.Unlock()
+
+	g.AddLog(LogWarning, tr("OVERCHARGE: %s (%d) pushed to %d. Expect faster decay and a risk of blowout.", sys.Name, sysID, value))
+	switch sys.Name {
+	case OverchargeShieldSystem:
+		g.AddLog(LogInfo, tr("  - Overcharged shields absorb %.0f%% of incoming damage.", (1-ShieldAbsorb)*100))
+	case OverchargePowerSystem:
+		g.AddLog(LogInfo, tr("  - Surplus power speeds up stabilization by %.0f%%.", (1-PowerStabilizeCut)*100))
+	}
+}
+
+// checkBlowout rolls the per-tick blowout chance for an overcharged system.
+func (g *Game) checkBlowout(sys *System) {
+	if !sys.IsOvercharged() || g.rng.Intn(100) >= BlowoutChance {
+		return
+	}
+	sys.mu.Lock()
+	sys.Overcharge = 0
+	sys.Value = sys.MaxValue * BlowoutPercent / 100
+	value := sys.Value
+	sys.mu.Unlock()
+	g.AddLog(LogCritical, tr("EVENT: BLOWOUT! Overcharged %s (%d) failed violently. Integrity dropped to %d.", sys.Name, sys.ID, value))
+}
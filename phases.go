@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Run phases. A timed run passes through phases with a pace of their own:
+// the startup is gentle, the steady state runs as configured, and the final
+// crunch wears the plant down faster and deals out the nastier events. A
+// phase lasts until its share of the run, speeds the degradation tick up by
+// its multiplier and draws random events by its own weights for the events
+// it names. Endless runs have no end to divide, and escalate instead.
+
+// Phase is one stretch of a timed run.
+type Phase struct {
+	Name         string         `toml:"name"`
+	Until        int            `toml:"until"`         // Percent of the run it lasts until
+	Degradation  float64        `toml:"degradation"`   // Degradation speed; 1 as configured
+	EventWeights map[string]int `toml:"event_weights"` // Replace the weights of the events named
+}
+
+func defaultPhases() []Phase {
+	return []Phase{
+		{Name: "startup", Until: 20, Degradation: 0.8, EventWeights: map[string]int{
+			"earthquake": 0, "electrical_fire": 1, "turbine_hall_fire": 0, "primary_loop_rupture": 0, "saboteur": 0,
+		}},
+		{Name: "steady state", Until: 75, Degradation: 1},
+		{Name: "final crunch", Until: 100, Degradation: 1.3, EventWeights: map[string]int{
+			"earthquake": 3, "electrical_fire": 4, "steam_void": 5, "turbine_hall_fire": 2, "primary_loop_rupture": 2,
+			"efficiency_boost": 2, "maintenance_crew": 1, "spare_parts": 1,
+		}},
+	}
+}
+
+func validatePhases(phases []Phase) error {
+	until := 0
+	for i, p := range phases {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("phases: phase %d needs a name", i+1)
+		case p.Until <= until || p.Until > 100:
+			return fmt.Errorf("phases.%s: until must grow from phase to phase, up to 100", p.Name)
+		case p.Degradation <= 0:
+			return fmt.Errorf("phases.%s: degradation must be positive", p.Name)
+		}
+		until = p.Until
+		for name, weight := range p.EventWeights {
+			if _, ok := lookupEvent(name); !ok {
+				return fmt.Errorf("phases.%s: unknown event %q", p.Name, name)
+			}
+			if weight < 0 {
+				return fmt.Errorf("phases.%s: %q weight cannot be negative", p.Name, name)
+			}
+		}
+	}
+	if len(phases) > 0 && until != 100 {
+		return fmt.Errorf("phases: the last phase must last until 100")
+	}
+	return nil
+}
+
+// phaseAt returns the phase of the run at game time elapsed, or nil when
+// the run has none.
+func (g *Game) phaseAt(elapsed time.Duration) *Phase {
+	phases := g.Config.Phases
+	if g.Mode == ModeEndless || len(phases) == 0 {
+		return nil
+	}
+	for i := range phases {
+		if elapsed < g.Config.GameDuration*time.Duration(phases[i].Until)/100 {
+			return &phases[i]
+		}
+	}
+	return &phases[len(phases)-1]
+}
+
+// phaseDegradation is the degradation speed of the current phase.
+func (g *Game) phaseDegradation() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.phase == nil {
+		return 1
+	}
+	return g.phase.Degradation
+}
+
+// checkPhase moves the run on to the phase its time has reached, and
+// announces it.
+func (g *Game) checkPhase() {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	prev, next := g.phase, g.phaseAt(elapsed)
+	g.phase = next
+	g.mu.Unlock()
+	if prev == nil || next == prev {
+		return // The first phase begins with the run
+	}
+	level := LogInfo
+	if next.Degradation > prev.Degradation {
+		level = LogWarning
+	}
+	g.AddLog(level, tr("PHASE: %s. The plant degrades at x%.2f.", phaseTitle(next.Name), next.Degradation))
+}
+
+// pickEvent draws a random event by the weights of the phase at game time
+// at.
+func (g *Game) pickEvent(at time.Duration) Event {
+	var weights map[string]int
+	if p := g.phaseAt(at); p != nil {
+		weights = p.EventWeights
+	}
+	return g.Events.PickWith(g.rng, weights)
+}
+
+// phaseSummary lists the phases of the run with their ends and degradation
+// speeds, for the manual.
+func (g *Game) phaseSummary() string {
+	if g.phaseAt(0) == nil {
+		return tr("none")
+	}
+	parts := make([]string, len(g.Config.Phases))
+	for i, p := range g.Config.Phases {
+		parts[i] = tr("%s to %s x%.2f", p.Name, formatDuration(g.Config.GameDuration*time.Duration(p.Until)/100), p.Degradation)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// phaseTitle names a phase for the header and the log.
+func phaseTitle(name string) string {
+	return strings.ToUpper(tr(name))
+}
+
+// phaseTag is the header's note of the current phase, or "".
+func (s GameState) phaseTag() string {
+	if s.Phase == "" {
+		return ""
+	}
+	return tr("  Phase: %s", phaseTitle(s.Phase))
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+const MaxReactors = 3 // Units allowed with --reactors
+
+// Plant groups the reactor units of one run. Every unit is an independent
+// Game with its own systems, events and timers; the player works on the
+// focused unit and switches with the reactor command. A single-reactor run
+// is a plant with one unit.
+type Plant struct {
+	Units []*Game
+	focus int
+	mu    sync.Mutex
+}
+
+// NewPlant builds n units from the same config and mutators. Each unit gets
+// its own seed so they do not fail in lockstep.
+func NewPlant(n int, cfg Config, diff Difficulty, seed int64, mutators []Mutator) *Plant {
+	p := &Plant{}
+	for i := 0; i < n; i++ {
+		unit := NewGame(cfg, diff, seed+int64(i), mutators)
+		if n > 1 {
+			unit.Unit = i + 1
+			unit.plant = p
+		}
+		if i > 0 {
+			unit.spectators = p.Units[0].spectators // The spectators talk to the plant, not a unit
+		}
+		p.Units = append(p.Units, unit)
+	}
+	return p
+}
+
+// Focused returns the unit the player is currently commanding.
+func (p *Plant) Focused() *Game {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.Units[p.focus]
+}
+
+func (p *Plant) handleFocus(args []string) {
+	game := p.Focused()
+	if len(args) == 0 {
+		game.AddLog(LogInfo, tr("Usage: reactor <1-%d>", len(p.Units)))
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(p.Units) {
+		game.AddLog(LogWarning, tr("Error: No reactor unit %s (units 1-%d).", args[0], len(p.Units)))
+		return
+	}
+	p.mu.Lock()
+	p.focus = n - 1
+	p.mu.Unlock()
+	p.Units[n-1].AddLog(LogInfo, tr("Focus switched to reactor unit %d.", n))
+}
+
+// ended reports whether a unit's game is over, won or lost.
+func (g *Game) ended() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.GameOver || g.GameWon
+}
+
+// meltedDown reports whether a unit was lost, as opposed to won or scrammed.
+func (g *Game) meltedDown() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.GameOver && !g.Scrammed
+}
+
+// propagateMeltdown ends every running unit once any unit is lost.
+func (p *Plant) propagateMeltdown() {
+	lost := 0
+	for _, unit := range p.Units {
+		if unit.meltedDown() {
+			lost = unit.Unit
+			break
+		}
+	}
+	if lost == 0 || len(p.Units) == 1 {
+		return
+	}
+	for _, unit := range p.Units {
+		unit.call(func() {
+			running := false
+			unit.locked(func() {
+				running = !unit.GameOver && !unit.GameWon
+				if running {
+					unit.GameOver = true
+					unit.EndTime = unit.clock.Now()
+					unit.Outcome = tr("MELTDOWN: Reactor unit %d was lost. The plant has been evacuated.", lost)
+				}
+			})
+			if running {
+				unit.AddLog(LogCritical, tr("PLANT FAILURE: Reactor unit %d melted down. All units shut down.", lost))
+			}
+		})
+	}
+}
+
+// Ended reports whether the run is over: every unit has ended or been taken
+// down by another unit's meltdown. won is true only when every unit won.
+func (p *Plant) Ended() (over, won bool) {
+	won = true
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		unitWon := unit.GameWon
+		unitEnded := unit.GameOver || unit.GameWon
+		unit.mu.Unlock()
+		if !unitEnded {
+			return false, false
+		}
+		won = won && unitWon
+	}
+	return !won, won
+}
+
+// recordHighScore records the run once it has ended. A multi-reactor run is
+// one entry scored as the sum of its units.
+func (p *Plant) recordHighScore() {
+	if len(p.Units) == 1 {
+		p.Units[0].recordHighScore()
+		return
+	}
+	first := p.Units[0]
+	first.saveHighScore(p.highScoreEntry())
+
+	first.mu.Lock()
+	top, last := first.topScores, first.lastScore
+	first.mu.Unlock()
+	for _, unit := range p.Units[1:] {
+		unit.mu.Lock()
+		unit.topScores, unit.lastScore = top, last
+		unit.mu.Unlock()
+	}
+}
+
+// highScoreEntry describes the finished run as a high-score entry.
+func (p *Plant) highScoreEntry() HighScore {
+	entry := p.Units[0].highScoreEntry()
+	if len(p.Units) == 1 {
+		return entry
+	}
+	entry.Score = p.TotalScore()
+	entry.Won = true
+	entry.Scram = false
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		entry.Won = entry.Won && unit.GameWon
+		unit.mu.Unlock()
+	}
+	entry.Reactors = len(p.Units)
+	return entry
+}
+
+// TotalScore sums the scores of every unit.
+func (p *Plant) TotalScore() int {
+	total := 0
+	for _, unit := range p.Units {
+		total += unit.CurrentScore().Total
+	}
+	return total
+}
+
+// unitStatus summarizes a unit's worst system for the tab bar.
+func (s GameState) unitStatus() string {
+	switch {
+	case s.GameWon:
+		return theme.Success.Sprint(tr("SECURED"))
+	case s.Scrammed:
+		return theme.Notice.Sprint(tr("SCRAMMED"))
+	case s.GameOver:
+		return theme.Alert.Sprint(tr("LOST"))
+	}
+	offline, worst := 0, alarmNone
+	for _, sys := range s.Systems {
+		if sys.Offline {
+			offline++
+		} else {
+			worst = max(worst, sys.level())
+		}
+	}
+	if offline > 0 {
+		return theme.Critical.Sprint(tr("%d OFFLINE", offline))
+	}
+	if s.Crisis != nil {
+		return theme.Alert.Sprint(tr("CRISIS"))
+	}
+	switch {
+	case worst == alarmCritical:
+		return theme.Critical.Sprint(tr("CRITICAL"))
+	case worst == alarmWarning:
+		return theme.Warning.Sprint(tr("WARNING"))
+	}
+	return theme.OK.Sprint(tr("OK"))
+}
+
+// tabBar renders one tab per unit from a snapshot of each, marking the
+// focused one.
+func (p *Plant) tabBar() string {
+	focused := p.Focused()
+	tabs := make([]string, len(p.Units))
+	for i, unit := range p.Units {
+		label := fmt.Sprintf("[%d] %s", unit.Unit, unit.Snapshot().unitStatus())
+		if unit == focused {
+			label = color.New(color.Bold, color.Underline).Sprintf("[%d]", unit.Unit) + strings.TrimPrefix(label, fmt.Sprintf("[%d]", unit.Unit))
+		}
+		tabs[i] = label
+	}
+	return tr("UNITS: %s", strings.Join(tabs, "  "))
+}
+
+// printPlantSummary lists every unit's result on the summary screen.
+func (p *Plant) printPlantSummary(w io.Writer) {
+	fmt.Fprintln(w, theme.Heading.Sprint(tr("\nPLANT UNITS:")))
+	for _, unit := range p.Units {
+		state := unit.Snapshot()
+		fmt.Fprint(w, tr("  Unit %d: %-8s %7d\n", unit.Unit, state.unitStatus(), state.Score.Total))
+	}
+	fmt.Fprintln(w, theme.Success.Sprint(tr("\n  COMBINED PLANT SCORE: %d", p.TotalScore())))
+}
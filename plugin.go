@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"plugin"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// External bot policies. --policy plugin:<file.so> loads a Go plugin that
+// exports
+//
+//	func Decide(state []byte) string
+//
+// and --policy remote:<addr> connects to a bot that answers Decide calls of
+// the remote control protocol over TCP:
+//
+//	{"id": 1, "method": "Decide", "params": {"state": {...}}}
+//	{"id": 1, "result": "stabilize 2"}
+//
+// Either is given the state the built-in policies decide on, policyView's,
+// as JSON, as GetState returns it, and returns the line the operator types,
+// or "" to wait. A bot that fails, or keeps
+// the game waiting past RemotePolicyTimeout, waits from then on.
+const (
+	RemoteDecide        = "Decide"
+	RemotePolicyTimeout = BotReaction
+
+	pluginPrefix = "plugin:"
+	remotePrefix = "remote:"
+)
+
+// externalPolicy looks up a policy given by a plugin: or remote: spec, and
+// reports whether spec was one.
+func externalPolicy(spec string) (Policy, bool, error) {
+	var p Policy
+	var err error
+	switch {
+	case strings.HasPrefix(spec, pluginPrefix):
+		p, err = loadPluginPolicy(strings.TrimPrefix(spec, pluginPrefix))
+	case strings.HasPrefix(spec, remotePrefix):
+		p, err = dialRemotePolicy(strings.TrimPrefix(spec, remotePrefix))
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("policy %s: %v", spec, err)
+	}
+	return p, true, nil
+}
+
+// marshalState is s as an external policy is given it.
+func marshalState(s GameState) []byte {
+	state, _ := json.Marshal(s) // Plain fields always marshal
+	return state
+}
+
+// pluginPolicy plays by the Decide function of a Go plugin.
+type pluginPolicy struct {
+	path   string
+	decide func(state []byte) string
+}
+
+func loadPluginPolicy(path string) (Policy, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Decide")
+	if err != nil {
+		return nil, err
+	}
+	decide, ok := sym.(func([]byte) string)
+	if !ok {
+		return nil, fmt.Errorf("%s: Decide must be a func(state []byte) string, not %T", path, sym)
+	}
+	return pluginPolicy{path: path, decide: decide}, nil
+}
+
+func (p pluginPolicy) Name() string { return pluginPrefix + p.path }
+
+func (p pluginPolicy) Decide(s GameState, rng *Rand) Command {
+	return ParseCommand(p.decide(marshalState(s)))
+}
+
+// remotePolicy plays by the answers of a bot over TCP. Units flown at once
+// take turns on the connection.
+type remotePolicy struct {
+	addr    string
+	conn    net.Conn
+	scanner *bufio.Scanner
+	mu      sync.Mutex
+	calls   int
+	err     error // Why the bot stopped playing, once it has
+}
+
+func dialRemotePolicy(addr string) (Policy, error) {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), RemoteMaxLine)
+	return &remotePolicy{addr: addr, conn: conn, scanner: scanner}, nil
+}
+
+func (p *remotePolicy) Name() string { return remotePrefix + p.addr }
+
+// Decide asks the bot; once it has failed it waits, and Err says why.
+func (p *remotePolicy) Decide(s GameState, rng *Rand) Command {
+	c, _ := p.actOn(marshalState(s))
+	return c
+}
+
+// actOn asks the bot for the command to type in state. It takes as long as
+// the bot does, up to RemotePolicyTimeout, so the autopilot calls it away
+// from the game loop. failed is why the bot stopped playing, when it just
+// has.
+func (p *remotePolicy) actOn(state []byte) (c Command, failed error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return Command{}, nil
+	}
+	input, err := p.call(state)
+	if err != nil {
+		p.err = err
+		p.conn.Close()
+		return Command{}, err
+	}
+	return ParseCommand(input), nil
+}
+
+// Err is why the bot stopped playing, or nil.
+func (p *remotePolicy) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// call makes one Decide call of the bot.
+func (p *remotePolicy) call(state []byte) (string, error) {
+	p.calls++
+	id := strconv.Itoa(p.calls)
+	call := RemoteRequest{ID: json.RawMessage(id), Method: RemoteDecide}
+	call.Params.State = state
+	req, _ := json.Marshal(call) // The state was marshaled already
+	if err := p.conn.SetDeadline(time.Now().Add(RemotePolicyTimeout)); err != nil {
+		return "", err
+	}
+	if _, err := p.conn.Write(append(req, '\n')); err != nil {
+		return "", err
+	}
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("the bot hung up")
+	}
+	var resp struct {
+		ID     json.RawMessage `json:"id"`
+		Result string          `json:"result"`
+		Error  string          `json:"error"`
+	}
+	switch err := json.Unmarshal(p.scanner.Bytes(), &resp); {
+	case err != nil:
+		return "", fmt.Errorf("invalid response: %v", err)
+	case string(resp.ID) != id:
+		return "", fmt.Errorf("response to call %s, not %s", resp.ID, id)
+	case resp.Error != "":
+		return "", fmt.Errorf("%s", resp.Error)
+	}
+	return resp.Result, nil
+}
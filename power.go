@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Power budget. Besides its cooldown, each risky command draws power from
+// Power Output, and is refused when the draw would leave it at the critical
+// threshold or below, or when it is offline. The difficulty scales the
+// built-in costs; the config's [power_costs.<difficulty>] tables set them
+// outright. Layouts without a Power Output system pay nothing.
+
+// PowerSystem is the system commands draw their power from.
+const PowerSystem = "Power Output"
+
+// commandPowerCosts is how much power each command draws at normal
+// difficulty.
+var commandPowerCosts = map[string]int{
+	CommandDivert:   5,
+	CommandVent:     10,
+	CommandOverride: 20,
+}
+
+func powerCommands() []string {
+	commands := make([]string, 0, len(commandPowerCosts))
+	for command := range commandPowerCosts {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
+
+// PowerCostTable holds power costs by difficulty, then command.
+type PowerCostTable map[string]map[string]int
+
+// validatePowerCosts rejects cost tables for unknown difficulties or
+// commands, and negative costs.
+func validatePowerCosts(costs PowerCostTable) error {
+	for difficulty, table := range costs {
+		if _, err := DifficultyByName(difficulty); err != nil {
+			return fmt.Errorf("power_costs: %v", err)
+		}
+		for command, cost := range table {
+			if _, ok := commandPowerCosts[command]; !ok {
+				return fmt.Errorf("power_costs.%s: %q draws no power (choose %s)", difficulty, command, strings.Join(powerCommands(), "|"))
+			}
+			if cost < 0 {
+				return fmt.Errorf("power_costs.%s: %s cannot be negative", difficulty, command)
+			}
+		}
+	}
+	return nil
+}
+
+// powerCost is how much power command draws at the game's difficulty.
+func (g *Game) powerCost(command string) int {
+	if cost, ok := g.Config.PowerCosts[g.Difficulty.Name][command]; ok {
+		return cost
+	}
+	return int(math.Round(float64(commandPowerCosts[command]) * g.Difficulty.PowerScale))
+}
+
+// powerCosts returns the cost of every command that draws power.
+func (g *Game) powerCosts() map[string]int {
+	costs := make(map[string]int, len(commandPowerCosts))
+	for command := range commandPowerCosts {
+		costs[command] = g.powerCost(command)
+	}
+	return costs
+}
+
+// drawPower takes command's cost from Power Output, or logs a refusal and
+// returns false when it cannot be spared.
+func (g *Game) drawPower(command string) bool {
+	cost := g.powerCost(command)
+	power := g.systemNamed(PowerSystem, nil)
+	if cost == 0 || power == nil {
+		return true
+	}
+	power.mu.Lock()
+	switch {
+	case power.Offline:
+		power.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot %s: %s (%d) is OFFLINE and supplies no power.", command, power.Name, power.ID))
+		return false
+	case power.Value-cost <= power.CriticalThreshold:
+		power.mu.Unlock()
+		g.AddLog(LogWarning, tr("Cannot %s: %s (%d) cannot spare the %d power it needs.", command, power.Name, power.ID, cost))
+		return false
+	}
+	power.Value -= cost
+	power.mu.Unlock()
+	return true
+}
+
+// affords reports whether Power Output can spare command's cost, going by
+// the dashboard.
+func (s GameState) affords(command string) bool {
+	cost := s.PowerCosts[command]
+	if cost == 0 {
+		return true
+	}
+	for _, sys := range s.Systems {
+		if sys.Name == PowerSystem {
+			return !sys.Offline && sys.Reading-cost > sys.Critical
+		}
+	}
+	return true
+}
+
+// powerHelp is the help line on what command draws at the game's
+// difficulty.
+func (g *Game) powerHelp(command string) string {
+	return tr("Draws %d power from %s, which must stay above %d (%s).", g.powerCost(command), PowerSystem, g.Config.CriticalThreshold, g.Difficulty.Name)
+}
+
+// describeCosts renders what command costs for the command list: its
+// cooldown, and its power if it draws any.
+func (s GameState) describeCosts(command string) string {
+	cooldown := describeCooldown(s.CooldownLengths[command])
+	if cost := s.PowerCosts[command]; cost > 0 {
+		return tr("%s, %d power", cooldown, cost)
+	}
+	return cooldown
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Practice mode. --practice snapshots the run every PracticeInterval, and
+// rewind <seconds> takes it back to the newest snapshot at least that far
+// back, so a newcomer can try an override or a divert and watch what it does
+// without having to live with it. The systems, inventory, log, score and
+// timed actions go back to the snapshot; the events do not replay, as the
+// RNG carries on from where it was, and timers set before the rewind are
+// dropped. A practice run is not recorded and cannot be saved. The full log
+// and the trends only grow, so a snapshot keeps their lengths, not copies,
+// and a rewind cuts the run's own back to them.
+
+const (
+	PracticeInterval = 5 * time.Second
+	PracticeKeep     = 24 // Snapshots kept: the last two minutes
+	RewindDefault    = 15 * time.Second
+)
+
+// practice keeps the snapshots of a practice run, oldest first.
+type practice struct {
+	snapshots []practiceSnapshot
+}
+
+// practiceSnapshot is the run as it stood at elapsed game time at.
+type practiceSnapshot struct {
+	at      time.Duration
+	state   SaveState // Without its History and Trend
+	history int       // Entries of the full log
+	trend   []int     // Samples of each system's trend
+}
+
+// practiceTick snapshots a practice run once PracticeInterval has passed
+// since the last snapshot. It runs from the game loop.
+func (g *Game) practiceTick() {
+	if g.practice == nil || g.ended() {
+		return
+	}
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	snaps := g.practice.snapshots
+	due := len(snaps) == 0 || elapsed-snaps[len(snaps)-1].at >= PracticeInterval
+	g.mu.Unlock()
+	if !due {
+		return
+	}
+	st := g.State()
+	st.History, st.Trend = nil, nil
+	snap := practiceSnapshot{at: st.Elapsed, state: st}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	snap.history = len(g.History)
+	for _, samples := range g.trend {
+		snap.trend = append(snap.trend, len(samples))
+	}
+	g.practice.snapshots = append(g.practice.snapshots, snap)
+	if len(g.practice.snapshots) > PracticeKeep {
+		g.practice.snapshots = g.practice.snapshots[len(g.practice.snapshots)-PracticeKeep:]
+	}
+}
+
+// handleRewind takes a practice run back by the seconds in args, or by
+// RewindDefault, as far as the oldest snapshot kept.
+func (g *Game) handleRewind(args []string) {
+	if g.practice == nil {
+		g.AddLog(LogWarning, tr("Cannot rewind: Only a --practice run can be taken back."))
+		return
+	}
+	back := RewindDefault
+	if len(args) > 0 {
+		seconds, err := strconv.Atoi(args[0])
+		if err != nil || seconds <= 0 {
+			g.AddLog(LogInfo, tr("Usage: rewind [seconds]"))
+			return
+		}
+		back = time.Duration(seconds) * time.Second
+	}
+	elapsed := g.Elapsed()
+	var snap practiceSnapshot
+	taken := false
+	g.locked(func() {
+		if taken = len(g.practice.snapshots) > 0; !taken {
+			return
+		}
+		i := len(g.practice.snapshots) - 1
+		for i > 0 && g.practice.snapshots[i].at > elapsed-back {
+			i--
+		}
+		snap = g.practice.snapshots[i]
+		g.practice.snapshots = g.practice.snapshots[:i+1]
+		snap.state.History = slices.Clone(g.History[:min(snap.history, len(g.History))])
+		if len(snap.trend) == len(g.trend) {
+			snap.state.Trend = make([][]int, len(g.trend))
+			for id, n := range snap.trend {
+				snap.state.Trend[id] = slices.Clone(g.trend[id][:min(n, len(g.trend[id]))])
+			}
+		}
+	})
+	if !taken {
+		g.AddLog(LogWarning, tr("Cannot rewind: No snapshot has been taken yet."))
+		return
+	}
+	if err := g.Restore(snap.state); err != nil {
+		g.AddLog(LogWarning, tr("Rewind failed: %v", err))
+		return
+	}
+	g.AddLog(LogSuccess, tr("REWIND: Back %.0fs to %s. The events ahead are drawn afresh.", (elapsed-snap.at).Seconds(), formatDuration(snap.at)))
+}
+
+// rewindSpanLocked is how far back the oldest snapshot kept lies at elapsed.
+// The caller must hold g.mu.
+func (g *Game) rewindSpanLocked(elapsed time.Duration) time.Duration {
+	if g.practice == nil || len(g.practice.snapshots) == 0 {
+		return 0
+	}
+	return elapsed - g.practice.snapshots[0].at
+}
+
+// practiceBanner renders the practice mode for the header, or "".
+func (s GameState) practiceBanner() string {
+	if !s.Practice {
+		return ""
+	}
+	return theme.Accent.Sprint(tr("PRACTICE: rewind <seconds> takes the run back, up to %ds. Not recorded.", int(s.RewindSpan.Seconds())))
+}
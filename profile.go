@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ProfileDir     = "profiles"
+	DefaultProfile = "default"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// difficultyUnlocks maps each locked difficulty to the one that must be won
+// to unlock it. Difficulties not listed are always available.
+var difficultyUnlocks = map[string]string{
+	"hard":      "normal",
+	"nightmare": "hard",
+}
+
+// Profile is a named player's lifetime record, persisted between sessions
+// and updated at the end of every run.
+type Profile struct {
+	Name           string               `json:"name"`
+	Runs           int                  `json:"runs"`
+	Wins           int                  `json:"wins"`
+	Losses         int                  `json:"losses"`
+	Scrams         int                  `json:"scrams"`
+	PlayTime       time.Duration        `json:"play_time_ns"`
+	TotalScore     int                  `json:"total_score"`
+	BestScore      int                  `json:"best_score"`
+	Commands       int                  `json:"commands"`
+	KitsUsed       int                  `json:"kits_used"`
+	Events         int                  `json:"events"`
+	KitlessWins    int                  `json:"kitless_wins"`
+	DifficultyWins map[string]int       `json:"difficulty_wins"`
+	Unlocked       []string             `json:"unlocked_difficulties"`
+	Achievements   map[string]time.Time `json:"achievements"` // Earned, by ID
+	IronmanWins    int                  `json:"ironman_wins,omitempty"`
+	Ironman        *IronmanRun          `json:"ironman,omitempty"` // The ironman run under way, nil if none
+	Upgrades       []string             `json:"campaign_upgrades,omitempty"`
+	UpgradePicks   int                  `json:"upgrade_picks,omitempty"` // Earned and not yet chosen
+	SSHKeys        []string             `json:"ssh_keys,omitempty"`      // Public keys that play as the profile over --ssh, in authorized_keys form
+}
+
+// Achievement is a lifetime goal; progress is derived from the profile.
+type Achievement struct {
+	ID       string
+	Name     string
+	Desc     string
+	Target   int
+	progress func(p *Profile) int
+}
+
+var achievements = []Achievement{
+	{"first_win", "First Shift", "Win a run", 1, func(p *Profile) int { return p.Wins }},
+	{"veteran", "Veteran Operator", "Win 10 runs", 10, func(p *Profile) int { return p.Wins }},
+	{"hard_win", "Trial by Fire", "Win on hard", 1, func(p *Profile) int { return p.DifficultyWins["hard"] }},
+	{"nightmare_win", "Nightmare Survivor", "Win on nightmare", 1, func(p *Profile) int { return p.DifficultyWins["nightmare"] }},
+	{"kitless", "No Spare Parts", "Win without using a repair kit", 1, func(p *Profile) int { return p.KitlessWins }},
+	{"marathon", "Marathon Shift", "Play for 60 minutes in total", 60, func(p *Profile) int { return int(p.PlayTime / time.Minute) }},
+	{"storm_chaser", "Storm Chaser", "Survive 500 events", 500, func(p *Profile) int { return p.Events }},
+	{"high_roller", "High Roller", "Score 2000 in one run", 2000, func(p *Profile) int { return p.BestScore }},
+	{"ironman_win", "Iron Operator", "Win an ironman run", 1, func(p *Profile) int { return p.IronmanWins }},
+}
+
+func profilePath(name string) (string, error) {
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q (letters, digits, - and _ only)", name)
+	}
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, ProfileDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// LoadProfile reads the named profile. A new profile starts with the
+// difficulties that need no unlock.
+func LoadProfile(name string) (*Profile, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{Name: name}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	p.fillDefaults()
+	return p, nil
+}
+
+// guestProfile returns a new profile for name, read from no file, for a run
+// that is not to be recorded.
+func guestProfile(name string) *Profile {
+	p := &Profile{Name: name}
+	p.fillDefaults()
+	return p
+}
+
+// fillDefaults sets up what a profile read without them lacks.
+func (p *Profile) fillDefaults() {
+	if p.DifficultyWins == nil {
+		p.DifficultyWins = make(map[string]int)
+	}
+	if p.Achievements == nil {
+		p.Achievements = make(map[string]time.Time)
+	}
+	for _, name := range difficultyNames() {
+		if _, locked := difficultyUnlocks[name]; !locked && !p.hasUnlocked(name) {
+			p.Unlocked = append(p.Unlocked, name)
+		}
+	}
+}
+
+func (p *Profile) Save() error {
+	path, err := profilePath(p.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (p *Profile) hasUnlocked(difficulty string) bool {
+	for _, name := range p.Unlocked {
+		if name == difficulty {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDifficulty reports an error if the profile may not play difficulty yet.
+func (p *Profile) checkDifficulty(difficulty string) error {
+	if p.hasUnlocked(difficulty) {
+		return nil
+	}
+	return fmt.Errorf("difficulty %s is locked for profile %s: win a run on %s to unlock it", difficulty, p.Name, difficultyUnlocks[difficulty])
+}
+
+// recordRun adds a finished run to the profile and returns notes on anything
+// it unlocked.
+func (p *Profile) recordRun(r Report, difficulty string, scrammed, ironman bool) []string {
+	p.Runs++
+	if ironman {
+		p.Ironman = nil // Over: its save goes too
+	}
+	switch {
+	case r.Won:
+		p.Wins++
+		if ironman {
+			p.IronmanWins++
+		}
+		p.DifficultyWins[difficulty]++
+		if r.KitsUsed == 0 {
+			p.KitlessWins++
+		}
+	case scrammed:
+		p.Scrams++
+	default:
+		p.Losses++
+	}
+	p.PlayTime += time.Duration(r.Seconds * float64(time.Second))
+	p.TotalScore += r.Score
+	if r.Score > p.BestScore {
+		p.BestScore = r.Score
+	}
+	p.Commands += r.TotalCommands
+	p.KitsUsed += r.KitsUsed
+	p.Events += r.EventsSurvived
+
+	var notes []string
+	for _, name := range difficultyNames() {
+		if need, locked := difficultyUnlocks[name]; locked && !p.hasUnlocked(name) && p.DifficultyWins[need] > 0 {
+			p.Unlocked = append(p.Unlocked, name)
+			notes = append(notes, tr("DIFFICULTY UNLOCKED: %s", strings.ToUpper(name)))
+		}
+	}
+	for _, a := range achievements {
+		if _, earned := p.Achievements[a.ID]; !earned && a.progress(p) >= a.Target {
+			p.Achievements[a.ID] = time.Now()
+			notes = append(notes, tr("ACHIEVEMENT UNLOCKED: %s (%s)", a.Name, a.Desc))
+		}
+	}
+	return notes
+}
+
+// recordProfile adds the run to prof, saves it and notes the result on the
+// summary screen. A multi-reactor run counts once, with its units combined.
+func (p *Plant) recordProfile(prof *Profile) {
+	var run Report
+	won := true
+	for _, unit := range p.Units {
+		r := unit.Report()
+		won = won && r.Won
+		run.Score += r.Score
+		run.TotalCommands += r.TotalCommands
+		run.KitsUsed += r.KitsUsed
+		run.EventsSurvived += r.EventsSurvived
+		if r.Seconds > run.Seconds {
+			run.Seconds = r.Seconds
+		}
+	}
+	run.Won = won
+	first := p.Units[0]
+	first.mu.Lock()
+	scrammed := first.Scrammed && len(p.Units) == 1
+	first.mu.Unlock()
+
+	notes := prof.recordRun(run, first.Difficulty.Name, scrammed, first.ironman != nil)
+	lines := []string{theme.Info.Sprint(tr("PROFILE %s: %d runs, %d wins.", prof.Name, prof.Runs, prof.Wins))}
+	for _, note := range notes {
+		lines = append(lines, theme.Success.Sprintf(note))
+	}
+	if err := prof.Save(); err != nil {
+		lines = append(lines, theme.Critical.Sprint(tr("Failed to save profile: %v", err)))
+	}
+	note := strings.Join(lines, "\n")
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		unit.profileNote = note
+		unit.mu.Unlock()
+	}
+}
+
+// Print renders the profile for `profile`.
+func (p *Profile) Print() {
+	fmt.Println(theme.Title.Sprint(tr("PROFILE: %s", p.Name)))
+	fmt.Print(tr("  Runs: %d  Wins: %d  Losses: %d  Scrams: %d\n", p.Runs, p.Wins, p.Losses, p.Scrams))
+	fmt.Print(tr("  Time played: %s  Best score: %d  Total score: %d\n", formatDuration(p.PlayTime), p.BestScore, p.TotalScore))
+	fmt.Print(tr("  Commands issued: %d  Repair kits used: %d  Events survived: %d\n", p.Commands, p.KitsUsed, p.Events))
+	var unlocked []string
+	for _, name := range difficultyNames() {
+		if p.hasUnlocked(name) {
+			unlocked = append(unlocked, name)
+		}
+	}
+	fmt.Print(tr("  Difficulties unlocked: %s\n", strings.Join(unlocked, ", ")))
+	fmt.Print(tr("  Ironman wins: %d\n", p.IronmanWins))
+	if p.Ironman != nil {
+		fmt.Println(theme.Warning.Sprint(tr("  An ironman run is under way: resume it with --ironman.")))
+	}
+	fmt.Println()
+	p.printUpgrades()
+
+	fmt.Println(theme.Heading.Sprint(tr("\nACHIEVEMENTS (%d/%d):", len(p.Achievements), len(achievements))))
+	for _, a := range achievements {
+		if at, earned := p.Achievements[a.ID]; earned {
+			fmt.Println(theme.OK.Sprint(tr("  [x] %-20s %-32s earned %s", a.Name, a.Desc, at.Format("2006-01-02"))))
+			continue
+		}
+		progress := a.progress(p)
+		if progress > a.Target {
+			progress = a.Target
+		}
+		fmt.Print(tr("  [ ] %-20s %-32s %d/%d\n", a.Name, a.Desc, progress, a.Target))
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	CodeTime   = 8 * time.Second // Time to type an emergency authorization code
+	CodeDamage = 25              // Hit on the target system when the code is missed
+	CodeBonus  = 10              // Integrity restored to the target on a correct code
+	CodePrefix = "AZ-5-"
+)
+
+// emergencyCode is a pending quick-time authorization the player must type.
+type emergencyCode struct {
+	Code     string
+	target   *System
+	deadline time.Time
+}
+
+func applyEmergencyCode(g *Game, target *System) {
+	g.mu.Lock()
+	pending := g.code != nil
+	g.mu.Unlock()
+	if pending {
+		return // One authorization at a time
+	}
+	g.startEmergencyCode(target, fmt.Sprintf("%s%04d", CodePrefix, g.rng.Intn(10000)), g.tuning("emergency_code").Duration)
+}
+
+// startEmergencyCode asks for code within duration. It is shared by the
+// emergency_code event and by resuming a saved game.
+func (g *Game) startEmergencyCode(target *System, code string, duration time.Duration) {
+	g.locked(func() {
+		g.code = &emergencyCode{Code: code, target: target, deadline: g.clock.Now().Add(duration)}
+	})
+	g.AddLog(LogWarning, tr("EVENT: Emergency authorization required for %s (%d)! TYPE: %s within %.0fs", target.Name, target.ID, code, duration.Seconds()))
+}
+
+// interceptCode checks input against the pending authorization code, and
+// the sequence of a stabilize mini-game, before it reaches normal command
+// parsing. It reports whether the input was consumed: the correct code, or a
+// wrong attempt at one.
+func (g *Game) interceptCode(input string) bool {
+	if g.interceptMinigame(input) {
+		return true
+	}
+	g.mu.Lock()
+	code := g.code
+	matched := code != nil && strings.EqualFold(input, code.Code)
+	if matched {
+		g.code = nil
+	}
+	g.mu.Unlock()
+	if code == nil {
+		return false
+	}
+	if matched {
+		bonus := g.rollRange(g.tuning("emergency_code").Boost)
+		code.target.Boost(bonus)
+		g.AddLog(LogSuccess, tr("AUTHORIZATION ACCEPTED: Emergency procedure on %s (%d) complete. Bonus +%d.", code.target.Name, code.target.ID, bonus))
+		return true
+	}
+	if strings.HasPrefix(strings.ToUpper(input), CodePrefix) {
+		g.AddLog(LogCritical, tr("AUTHORIZATION REJECTED: %s is not the requested code.", input))
+		return true
+	}
+	return false // An ordinary command; the code is still pending
+}
+
+// updateEmergencyCode applies the hit once a code has gone untyped. It runs
+// from the event loop.
+func (g *Game) updateEmergencyCode() {
+	g.mu.Lock()
+	code := g.code
+	expired := code != nil && !g.clock.Now().Before(code.deadline)
+	if expired {
+		g.code = nil
+	}
+	g.mu.Unlock()
+	if !expired {
+		return
+	}
+	g.AddLog(LogCritical, tr("CRITICAL: Authorization %s timed out! Automatic safeguards failed.", code.Code))
+	g.harmLogged(code.target, g.rollRange(g.tuning("emergency_code").Damage))
+}
+
+// pendingCode returns the authorization code awaiting input, or "".
+func (g *Game) pendingCode() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.code == nil {
+		return ""
+	}
+	return g.code.Code
+}
+
+// CodeState is a snapshot of the authorization code awaiting input.
+type CodeState struct {
+	Code string        `json:"code"`
+	Left time.Duration `json:"left_ns"`
+}
+
+// codeSnapshotLocked copies the pending code at now, or returns nil if there is
+// none. The caller must hold g.mu.
+func (g *Game) codeSnapshotLocked(now time.Time) *CodeState {
+	if g.code == nil {
+		return nil
+	}
+	return &CodeState{Code: g.code.Code, Left: g.code.deadline.Sub(now)}
+}
+
+// codePrompt renders the pending authorization for the dashboard, or "".
+func (s GameState) codePrompt() string {
+	if s.Code == nil {
+		return ""
+	}
+	return theme.Caution.With(color.Bold, color.BlinkSlow).Sprint(tr(" EMERGENCY AUTHORIZATION: TYPE %s within %ds ", s.Code.Code, ceilSeconds(s.Code.Left)))
+}
+
+// interceptCode offers input to every unit's pending code, so a code can be
+// answered without switching focus.
+func (p *Plant) interceptCode(input string) bool {
+	for _, unit := range p.Units {
+		consumed := false
+		unit.call(func() { consumed = unit.interceptCode(input) })
+		if consumed {
+			return true
+		}
+	}
+	return false
+}
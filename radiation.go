@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	RadiationMax         = 100.0
+	RadiationLimit       = 75.0             // Level above which the evacuation clock runs
+	RadiationPerCritical = 1.5              // Added per tick for each critical or offline system
+	RadiationPerVent     = 6.0              // Added by every emergency vent
+	RadiationDecay       = 0.5              // Natural fall per tick
+	ShieldScrubRate      = 2.0              // Extra fall per tick at full Shield Integrity
+	EvacuationDelay      = 20 * time.Second // Consecutive time above the limit before the crew leaves
+)
+
+// updateRadiation advances the station radiation level after a degradation
+// tick. values holds each system's integrity, indexed by ID.
+func (g *Game) updateRadiation(values []int) {
+	rise := 0.0
+	for i, val := range values {
+		if val <= g.Systems[i].CriticalThreshold {
+			rise += RadiationPerCritical
+		}
+	}
+	fall := RadiationDecay
+	if shield := g.systemNamed("Shield Integrity", nil); shield != nil {
+		if val := values[shield.ID]; val > shield.WarningThreshold {
+			share := float64(val-shield.WarningThreshold) / float64(shield.MaxValue-shield.WarningThreshold)
+			if share > 1 {
+				share = 1 // Overcharged shields scrub no faster
+			}
+			fall += ShieldScrubRate * share
+		}
+	}
+
+	tick := g.degradationTick()
+	g.mu.Lock()
+	before := g.Radiation
+	g.Radiation = clampRadiation(g.Radiation + rise - fall)
+	crossed := before < RadiationLimit && g.Radiation >= RadiationLimit
+	cleared := before >= RadiationLimit && g.Radiation < RadiationLimit
+	if g.Radiation >= RadiationLimit {
+		g.radiationHigh += tick
+	} else {
+		g.radiationHigh = 0
+	}
+	g.mu.Unlock()
+
+	if crossed {
+		g.AddLog(LogCritical, tr("CRITICAL: Radiation above safe limits! Crew evacuates in %.0fs unless it falls.", EvacuationDelay.Seconds()))
+	} else if cleared {
+		g.AddLog(LogSuccess, tr("Radiation back below safe limits. Evacuation order lifted."))
+	}
+}
+
+// addRadiation raises the radiation level by amount.
+func (g *Game) addRadiation(amount float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.Radiation = clampRadiation(g.Radiation + amount)
+}
+
+func clampRadiation(level float64) float64 {
+	if level < 0 {
+		return 0
+	}
+	if level > RadiationMax {
+		return RadiationMax
+	}
+	return level
+}
+
+// radiationFailure reports the loss message once the crew has evacuated.
+func (g *Game) radiationFailure() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.radiationHigh < EvacuationDelay {
+		return ""
+	}
+	return tr("EVACUATION: Radiation stayed above safe limits for %.0fs. The crew abandoned the station.", EvacuationDelay.Seconds())
+}
+
+// radiationGauge renders the radiation line for the dashboard.
+func (s GameState) radiationGauge() string {
+	level := s.Radiation
+	exposure := s.RadiationExposure
+
+	const barLength = 20
+	fill := int(level / RadiationMax * barLength)
+	bar := fmt.Sprintf("[%s%s]", strings.Repeat("#", fill), strings.Repeat("-", barLength-fill))
+	line := tr("RADIATION: %5.1f %s", level, bar)
+	switch {
+	case level >= RadiationLimit:
+		left := EvacuationDelay - exposure
+		if left < 0 {
+			left = 0
+		}
+		return theme.Critical.With(color.Bold).Sprint(tr("%s EVACUATION IN %ds", line, ceilSeconds(left)))
+	case level >= RadiationLimit*2/3:
+		return theme.Warning.Sprintf(line)
+	}
+	return theme.OK.Sprintf(line)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Remote control protocol. A client connects over TCP and exchanges JSON,
+// one object per line. Requests carry an id that is echoed in the response:
+//
+//	{"id": 1, "method": "ApplyCommand", "params": {"command": "vent 1"}}
+//	{"id": 1, "result": {"log": [...], "state": {...}}}
+//	{"id": 2, "method": "GetState"}
+//	{"id": 2, "error": "..."}
+//
+// Subscribe starts a stream of the same state and log messages as /watch,
+// which carry a type instead of an id. Every method takes an optional
+// "unit" parameter in a multi-reactor plant and defaults to the focused unit.
+const (
+	RemoteApplyCommand = "ApplyCommand"
+	RemoteGetState     = "GetState"
+	RemoteSubscribe    = "Subscribe"
+
+	RemoteMaxLine = 64 << 10 // Longest request accepted
+)
+
+// RemoteRequest is one call from a remote client.
+type RemoteRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		Command string          `json:"command,omitempty"` // ApplyCommand
+		Unit    int             `json:"unit,omitempty"`
+		State   json.RawMessage `json:"state,omitempty"` // Decide, sent to an external policy
+	} `json:"params"`
+}
+
+// RemoteResponse answers a RemoteRequest with either a result or an error.
+type RemoteResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// CommandResult is what ApplyCommand returns: the log entries the command
+// produced, which say whether it worked, and the state right after it.
+type CommandResult struct {
+	Log   []LogEntry `json:"log"`
+	State GameState  `json:"state"`
+}
+
+// serveRemote accepts remote control connections on addr. Each connection
+// plays the same game as the terminal.
+func (p *Plant) serveRemote(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return // Closed as the game exits
+			}
+			go p.remoteSession(conn)
+		}
+	}()
+	return ln, nil
+}
+
+// remoteSession serves one connection until the client hangs up.
+func (p *Plant) remoteSession(conn net.Conn) {
+	defer conn.Close()
+	var mu sync.Mutex // Responses and the subscription share the connection
+	write := func(v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = conn.Write(append(data, '\n'))
+		return err == nil
+	}
+	var stop chan struct{} // Ends the current subscription
+	defer func() {
+		if stop != nil {
+			close(stop)
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), RemoteMaxLine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req RemoteRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			write(RemoteResponse{ID: json.RawMessage("null"), Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+		resp := RemoteResponse{ID: req.ID}
+		if resp.ID == nil {
+			resp.ID = json.RawMessage("null")
+		}
+		unit, err := p.remoteUnit(req.Params.Unit)
+		switch {
+		case err != nil:
+			resp.Error = err.Error()
+		case req.Method == RemoteApplyCommand:
+			resp.Result, err = unit.applyRemote(req.Params.Command)
+		case req.Method == RemoteGetState:
+			s := unit.Snapshot()
+			s.Log = plainLog(s.Log)
+			resp.Result = s
+		case req.Method == RemoteSubscribe:
+			if stop != nil {
+				close(stop) // A new subscription replaces the old one
+			}
+			stop = make(chan struct{})
+			resp.Result = "subscribed"
+			go p.watch(unit, req.Params.Unit == 0, stop, func(msg []byte) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				_, err := conn.Write(append(msg, '\n'))
+				return err == nil
+			})
+		default:
+			err = fmt.Errorf("unknown method %q (choose %s, %s or %s)", req.Method, RemoteApplyCommand, RemoteGetState, RemoteSubscribe)
+		}
+		if err != nil {
+			resp.Error, resp.Result = err.Error(), nil
+		}
+		if !write(resp) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		write(RemoteResponse{ID: json.RawMessage("null"), Error: err.Error()})
+	}
+}
+
+// remoteUnit returns reactor unit n, or the focused unit for 0.
+func (p *Plant) remoteUnit(n int) (*Game, error) {
+	if n == 0 {
+		return p.Focused(), nil
+	}
+	if n < 1 || n > len(p.Units) {
+		return nil, fmt.Errorf("no reactor unit %d (units 1-%d)", n, len(p.Units))
+	}
+	return p.Units[n-1], nil
+}
+
+// applyRemote runs one line of input as though it were typed, and collects
+// the log entries it produced.
+func (g *Game) applyRemote(input string) (CommandResult, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return CommandResult{}, fmt.Errorf("no command given")
+	}
+	var entries []LogEntry
+	ran := false
+	g.call(func() {
+		if g.ended() {
+			return
+		}
+		ran = true
+		g.mu.Lock()
+		before := g.logCount
+		g.mu.Unlock()
+		if !g.interceptCode(input) {
+			g.botCommand = true
+			g.handleInput(input)
+			g.botCommand = false
+		}
+		g.locked(func() {
+			if fresh := g.logCount - before; fresh > 0 {
+				entries = append([]LogEntry(nil), g.EventLog[max(0, len(g.EventLog)-fresh):]...)
+			}
+		})
+	})
+	if !ran {
+		return CommandResult{}, fmt.Errorf("the game has ended")
+	}
+	s := g.Snapshot()
+	s.Log = plainLog(s.Log)
+	return CommandResult{Log: plainLog(entries), State: s}, nil
+}
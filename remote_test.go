@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// remoteReply is a response or a subscription message, as the client reads it.
+type remoteReply struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+	Type   string          `json:"type"`
+}
+
+// dialRemote serves a two-unit plant over TCP and connects to it.
+func dialRemote(t *testing.T) (net.Conn, *bufio.Scanner) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	difficulty, err := DifficultyByName("normal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPlant(2, DefaultConfig(), difficulty, 5, nil)
+	for _, unit := range p.Units {
+		unit.useClock(newSimClock(unit.StartTime))
+		unit.inline = true // No game loop: the session's calls run at once
+	}
+	ln, err := p.serveRemote("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn, bufio.NewScanner(conn)
+}
+
+func readReply(t *testing.T, in *bufio.Scanner) remoteReply {
+	t.Helper()
+	if !in.Scan() {
+		t.Fatalf("no reply: %v", in.Err())
+	}
+	var r remoteReply
+	if err := json.Unmarshal(in.Bytes(), &r); err != nil {
+		t.Fatalf("reply %s: %v", in.Bytes(), err)
+	}
+	return r
+}
+
+func TestRemoteRequests(t *testing.T) {
+	conn, in := dialRemote(t)
+	for _, tc := range []struct {
+		request string
+		id      string
+		err     string // In the error; "" for a result
+		result  string // In the result
+	}{
+		{`{"id": 1, "method": "GetState"}`, `1`, "", `"systems":[`},
+		{`{"id": "two", "method": "GetState", "params": {"unit": 2}}`, `"two"`, "", `"unit":2`},
+		{`{"id": 3, "method": "ApplyCommand", "params": {"command": "stabilize 1"}}`, `3`, "", `"log":[{`},
+		{`{"id": 4, "method": "ApplyCommand", "params": {"command": "  "}}`, `4`, "no command given", ""},
+		{`{"id": 5, "method": "GetState", "params": {"unit": 3}}`, `5`, "no reactor unit 3 (units 1-2)", ""},
+		{`{"id": 6, "method": "Shutdown"}`, `6`, `unknown method "Shutdown"`, ""},
+		{`{"method": "GetState"}`, `null`, "", `"systems":[`},
+		{`{"id": 7, "method": `, `null`, "invalid request", ""},
+	} {
+		if _, err := conn.Write([]byte(tc.request + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		r := readReply(t, in)
+		if string(r.ID) != tc.id {
+			t.Errorf("%s: id %s, want %s", tc.request, r.ID, tc.id)
+		}
+		switch {
+		case tc.err != "" && !strings.Contains(r.Error, tc.err):
+			t.Errorf("%s: error %q, want one with %q", tc.request, r.Error, tc.err)
+		case tc.err == "" && r.Error != "":
+			t.Errorf("%s: error %q", tc.request, r.Error)
+		case tc.err != "" && r.Result != nil:
+			t.Errorf("%s: result %s along with the error", tc.request, r.Result)
+		case !strings.Contains(string(r.Result), tc.result):
+			t.Errorf("%s: result %.200s, want one with %s", tc.request, r.Result, tc.result)
+		}
+	}
+}
+
+func TestRemoteSubscribe(t *testing.T) {
+	conn, in := dialRemote(t)
+	if _, err := conn.Write([]byte(`{"id": 1, "method": "Subscribe"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	var subscribed, state bool // The first state may overtake the response
+	for !subscribed || !state {
+		r := readReply(t, in)
+		switch {
+		case string(r.ID) == "1" && string(r.Result) == `"subscribed"`:
+			subscribed = true
+		case r.Type == "state":
+			state = true
+		default:
+			t.Fatalf("unexpected message: %s", in.Bytes())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Rand is a goroutine-safe wrapper around *rand.Rand. The game keeps one
+// stream for world events and a separate one for player action outcomes, so
+// the event sequence for a given seed does not depend on what the player types.
+type Rand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func NewRand(seed int64) *Rand {
+	return &Rand{r: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Rand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+func (r *Rand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
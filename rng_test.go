@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seededRun plays a headless game for d and returns its full log, without
+// the wall-clock times the entries start with.
+func seededRun(t *testing.T, seed int64, d time.Duration) string {
+	t.Helper()
+	g, clock := headlessGame(t, seed)
+	due := g.newSchedule()
+	playFor(g, clock, &due, d)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var log strings.Builder
+	for _, e := range g.History {
+		_, text, _ := strings.Cut(e.Text, " ")
+		fmt.Fprintf(&log, "%s %s\n", e.Severity, text)
+	}
+	return log.String()
+}
+
+func TestSameSeedSameRun(t *testing.T) {
+	first := seededRun(t, 7, 2*time.Minute)
+	if again := seededRun(t, 7, 2*time.Minute); again != first {
+		t.Errorf("two runs on seed 7 differ:\nfirst  %s\nsecond %s", first, again)
+	}
+	if other := seededRun(t, 8, 2*time.Minute); other == first {
+		t.Error("runs on seeds 7 and 8 are the same")
+	}
+}
+
+func TestSameSeedSameSimulation(t *testing.T) {
+	difficulty, err := DifficultyByName("normal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := Simulation{Games: 3, Policy: greedyPolicy{}, Seed: 11, Config: DefaultConfig(), Difficulty: difficulty, Mode: ModeClassic}
+	first, err := sim.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := sim.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Won != again.Won || first.Scrammed != again.Scrammed || first.TotalScore != again.TotalScore || first.TotalSurvived != again.TotalSurvived {
+		t.Errorf("replayed batch won %d, scrammed %d, scored %d after %v; first %d, %d, %d after %v",
+			again.Won, again.Scrammed, again.TotalScore, again.TotalSurvived, first.Won, first.Scrammed, first.TotalScore, first.TotalSurvived)
+	}
+}
+
+func TestRandStreams(t *testing.T) {
+	a, b := NewRand(5), NewRand(5)
+	for i := 0; i < 100; i++ {
+		if x, y := a.Intn(1000), b.Intn(1000); x != y {
+			t.Fatalf("draw %d: %d and %d from the same seed", i, x, y)
+		}
+	}
+}
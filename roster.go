@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// System roster. The config's and a scenario's [[systems]] tables lay out
+// the reactor one system at a time, up to MaxSystems of them: each may set
+// its own maximum, thresholds and degradation curve, and choose the
+// commands that work on it. What a table leaves out comes from the config-wide
+// settings.
+
+// MaxSystems is the most systems a reactor can have: one per digit key.
+const MaxSystems = 10
+
+// SystemDef customizes one system of the layout. Zero values keep the
+// config-wide defaults.
+type SystemDef struct {
+	Name              string   `toml:"name"`
+	MaxValue          int      `toml:"max_value"`
+	WarningThreshold  int      `toml:"warning_threshold"`
+	CriticalThreshold int      `toml:"critical_threshold"`
+	DegradationRate   int      `toml:"degradation_rate"` // Per tick; random between 2-4 when unset
+	WarningCurve      int      `toml:"warning_curve"`    // Percent of the rate at or below the warning threshold
+	CriticalCurve     int      `toml:"critical_curve"`   // Percent of the rate at or below the critical threshold
+	Actions           []string `toml:"actions"`          // The commands that work on it, over the [actions] rules; empty to keep them
+}
+
+// systemDef returns the definition of system i, or a zero one.
+func (c *Config) systemDef(i int) SystemDef {
+	if i < len(c.Systems) {
+		return c.Systems[i]
+	}
+	return SystemDef{}
+}
+
+// setSystems lays the reactor out as defs, naming the systems defs leaves
+// unnamed after fallback, or "System <id>" past its end.
+func (c *Config) setSystems(defs []SystemDef, fallback []string) {
+	c.NumSystems = len(defs)
+	c.SystemNames = make([]string, len(defs))
+	c.Systems = append([]SystemDef(nil), defs...)
+	for i, def := range defs {
+		switch {
+		case def.Name != "":
+			c.SystemNames[i] = def.Name
+		case i < len(fallback):
+			c.SystemNames[i] = fallback[i]
+		default:
+			c.SystemNames[i] = fmt.Sprintf("System %d", i)
+		}
+	}
+}
+
+// validateSystems checks every system definition against the config-wide
+// settings it falls back on.
+func (c *Config) validateSystems() error {
+	if len(c.Systems) > c.NumSystems {
+		return fmt.Errorf("systems defines %d systems for %d", len(c.Systems), c.NumSystems)
+	}
+	for i, def := range c.Systems {
+		name := c.SystemNames[i]
+		max, warning, critical := c.systemLimits(def)
+		switch {
+		case def.MaxValue < 0 || def.WarningThreshold < 0 || def.CriticalThreshold < 0:
+			return fmt.Errorf("systems: %s: max_value and thresholds cannot be negative", name)
+		case !(c.MinSystemValue < critical && critical < warning && warning < max):
+			return fmt.Errorf("systems: %s: thresholds must satisfy min < critical < warning < max", name)
+		case def.DegradationRate < 0 || def.WarningCurve < 0 || def.CriticalCurve < 0:
+			return fmt.Errorf("systems: %s: degradation_rate and curves cannot be negative", name)
+		}
+		for _, command := range def.Actions {
+			if !slices.Contains(matrixCommands, command) {
+				return fmt.Errorf("systems: %s: %q cannot be limited (choose %s)", name, command, strings.Join(matrixCommands, "|"))
+			}
+		}
+	}
+	return nil
+}
+
+// systemLimits returns the maximum and thresholds def gives its system.
+func (c *Config) systemLimits(def SystemDef) (max, warning, critical int) {
+	max, warning, critical = c.MaxSystemValue, c.WarningThreshold, c.CriticalThreshold
+	if def.MaxValue > 0 {
+		max = def.MaxValue
+	}
+	if def.WarningThreshold > 0 {
+		warning = def.WarningThreshold
+	}
+	if def.CriticalThreshold > 0 {
+		critical = def.CriticalThreshold
+	}
+	return max, warning, critical
+}
+
+// rateLocked is how much s degrades this tick: its rate, bent by the curve
+// of the zone it is in. The caller must hold s.mu.
+func (s *System) rateLocked() int {
+	curve := 100
+	switch {
+	case s.Value <= s.CriticalThreshold && s.CriticalCurve > 0:
+		curve = s.CriticalCurve
+	case s.Value <= s.WarningThreshold && s.WarningCurve > 0:
+		curve = s.WarningCurve
+	}
+	return (s.DegradationRate*curve + 50) / 100
+}
+
+// applySystemActions makes the matrix follow every system definition that
+// lists the commands working on its system: the listed ones do, in place of
+// the rules, and the rest do not. A system listing divert that the rules
+// give no links is linked both ways with every other system. resolveActions
+// calls it once the configured rules are in the matrix.
+func (g *Game) applySystemActions() {
+	n := len(g.Systems)
+	for i := range g.Systems {
+		def := g.Config.systemDef(i)
+		if len(def.Actions) == 0 {
+			continue
+		}
+		for _, command := range matrixCommands {
+			works := slices.Contains(def.Actions, command)
+			if command == "divert" {
+				g.linkSystem(i, works)
+				continue
+			}
+			allowed, ok := g.matrix.Allowed[command]
+			if !ok {
+				if works {
+					continue
+				}
+				allowed = make([]bool, n)
+				for j := range allowed {
+					allowed[j] = true
+				}
+				if g.matrix.Allowed == nil {
+					g.matrix.Allowed = map[string][]bool{}
+				}
+				g.matrix.Allowed[command] = allowed
+			}
+			allowed[i] = works
+		}
+	}
+}
+
+// linkSystem cuts every divert link of system id, or, with linked, links it
+// both ways with every other system if the rules give it no links.
+func (g *Game) linkSystem(id int, linked bool) {
+	links := g.matrix.Links
+	if links == nil {
+		if linked {
+			return // Every system is linked already
+		}
+		links = make([][]bool, len(g.Systems))
+		for from := range links {
+			links[from] = make([]bool, len(g.Systems))
+			for to := range links[from] {
+				links[from][to] = true
+			}
+		}
+		g.matrix.Links = links
+	}
+	if linked && (slices.Contains(links[id], true) || slices.ContainsFunc(links, func(to []bool) bool { return to[id] })) {
+		return
+	}
+	for j := range links {
+		links[id][j], links[j][id] = linked && j != id, linked && j != id
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Saboteur. A rare event lets a saboteur loose in one of the systems. Until
+// they are caught, a random system takes hidden damage every tick. Each
+// `sweep <id>` searches one system; a miss tells whether the trail is warm
+// or cold, then whether each sweep is hotter or colder than the one before,
+// by how far its system ID is from the hiding place.
+
+const (
+	SweepTime      = 3 * time.Second // Searching one system for the saboteur
+	SaboteurDamage = 2               // Hidden damage to a random system every tick
+)
+
+// Clues a sweep that misses can give.
+const (
+	ClueWarm   = "warm"   // First sweep, one system away
+	ClueCold   = "cold"   // First sweep, further away
+	ClueHotter = "hotter" // Closer than the sweep before
+	ClueColder = "colder" // Further than the sweep before
+	ClueSame   = "same"   // As far as the sweep before
+)
+
+// SaboteurSweep is one sweep that missed, and its clue.
+type SaboteurSweep struct {
+	System int    `json:"system"`
+	Clue   string `json:"clue"`
+}
+
+// saboteur is an intruder at large.
+type saboteur struct {
+	location *System
+	sweeps   []SaboteurSweep
+}
+
+func applySaboteur(g *Game, target *System) {
+	g.mu.Lock()
+	loose := g.saboteur != nil
+	if !loose {
+		g.saboteur = &saboteur{location: target}
+	}
+	g.mu.Unlock()
+	if loose {
+		return // One intruder at a time
+	}
+	g.AddLog(LogCritical, tr("EVENT: INTRUDER ALERT! A saboteur is loose in the plant. Find them with sweep <id>."))
+}
+
+// sabotageTick lets the saboteur strike a random system. It runs once per
+// degradation tick; the damage is not logged.
+func (g *Game) sabotageTick() {
+	g.mu.Lock()
+	loose := g.saboteur != nil
+	g.mu.Unlock()
+	if loose {
+		g.Systems[g.rng.Intn(len(g.Systems))].Harm(g.scaleDamage(g.rollRange(g.tuning("saboteur").Damage)))
+	}
+}
+
+func (g *Game) handleSweep(sysID int) {
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for sweep."))
+		return
+	}
+	g.mu.Lock()
+	loose := g.saboteur != nil
+	g.mu.Unlock()
+	if !loose {
+		g.AddLog(LogWarning, tr("Cannot sweep: No intruder is loose."))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot sweep: Player busy with another action."))
+		return
+	}
+	sys := g.Systems[sysID]
+	g.AddLog(LogInfo, tr("Sweeping %s (%d) for the saboteur. This will take time.", sys.Name, sysID))
+	g.startSweep(sys, SweepTime)
+}
+
+// startSweep occupies the player with searching sys. It is shared by
+// handleSweep and by resuming a saved game.
+func (g *Game) startSweep(sys *System, duration time.Duration) {
+	g.SetPlayerAction(tr("Sweeping %s (%d)...", sys.Name, sys.ID), duration)
+	g.locked(func() {
+		g.ActionSystem = sys.ID
+		g.ActionKind = ActionSweep
+	})
+
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		g.ClearPlayerAction()
+		g.finishSweep(sys)
+	})
+}
+
+// finishSweep catches the saboteur in sys, or gives the clue of a miss.
+func (g *Game) finishSweep(sys *System) {
+	severity, note := LogWarning, ""
+	g.locked(func() {
+		s := g.saboteur
+		switch {
+		case s == nil:
+			severity, note = LogInfo, tr("SWEEP: %s (%d) is clear. The intruder is already gone.", sys.Name, sys.ID)
+		case sys == s.location:
+			g.saboteur = nil
+			severity, note = LogSuccess, tr("SWEEP: Saboteur caught in %s (%d)! Security has them in custody.", sys.Name, sys.ID)
+		default:
+			clue := s.clue(sys)
+			s.sweeps = append(s.sweeps, SaboteurSweep{System: sys.ID, Clue: clue})
+			note = tr("SWEEP: No sign of the saboteur in %s (%d). %s", sys.Name, sys.ID, clueText(clue))
+		}
+	})
+	g.AddLog(severity, note)
+}
+
+// clue is what a sweep of sys that misses tells.
+func (s *saboteur) clue(sys *System) string {
+	return sweepClue(sys.ID, s.location.ID, s.sweeps)
+}
+
+// sweepClue is what a sweep of system swept tells, after the sweeps before,
+// with the saboteur hiding in location.
+func sweepClue(swept, location int, before []SaboteurSweep) string {
+	distance := abs(swept - location)
+	if len(before) == 0 {
+		if distance == 1 {
+			return ClueWarm
+		}
+		return ClueCold
+	}
+	last := abs(before[len(before)-1].System - location)
+	switch {
+	case distance < last:
+		return ClueHotter
+	case distance > last:
+		return ClueColder
+	}
+	return ClueSame
+}
+
+func clueText(clue string) string {
+	switch clue {
+	case ClueWarm:
+		return tr("The trail is warm.")
+	case ClueCold:
+		return tr("The trail is cold.")
+	case ClueHotter:
+		return tr("Hotter.")
+	case ClueColder:
+		return tr("Colder.")
+	}
+	return tr("No hotter, no colder.")
+}
+
+// clueLabel is the clue of a sweep on the intruder panel.
+func clueLabel(clue string) string {
+	switch clue {
+	case ClueWarm:
+		return tr("warm")
+	case ClueCold:
+		return tr("cold")
+	case ClueHotter:
+		return tr("hotter")
+	case ClueColder:
+		return tr("colder")
+	}
+	return tr("same")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// SaboteurState is the intruder at large, as the dashboard shows it: the
+// sweeps so far, never the hiding place.
+type SaboteurState struct {
+	Sweeps []SaboteurSweep `json:"sweeps,omitempty"`
+}
+
+// suspects returns the IDs of the systems the saboteur may hide in, going
+// by the clues so far.
+func (v *SaboteurState) suspects(systems int) []int {
+	var ids []int
+	for id := 0; id < systems; id++ {
+		fits := true
+		for i, sweep := range v.Sweeps {
+			if sweep.System == id || sweepClue(sweep.System, id, v.Sweeps[:i]) != sweep.Clue {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// saboteurSnapshotLocked copies the intruder at large, or returns nil if
+// there is none. The caller must hold g.mu.
+func (g *Game) saboteurSnapshotLocked() *SaboteurState {
+	if g.saboteur == nil {
+		return nil
+	}
+	return &SaboteurState{Sweeps: append([]SaboteurSweep(nil), g.saboteur.sweeps...)}
+}
+
+// saboteurPanel renders the intruder at large for the dashboard, or "".
+func (s GameState) saboteurPanel() string {
+	v := s.Saboteur
+	if v == nil {
+		return ""
+	}
+	lines := []string{theme.Inverse.Sprint(tr(" INTRUDER ALERT: A saboteur is damaging the plant "))}
+	swept := make([]string, len(v.Sweeps))
+	for i, sweep := range v.Sweeps {
+		swept[i] = fmt.Sprintf("%d %s", sweep.System, clueLabel(sweep.Clue))
+	}
+	if len(swept) == 0 {
+		lines = append(lines, theme.Notice.With(color.Bold).Sprint(tr("  Search a system with sweep <id> (%.0fs).", SweepTime.Seconds())))
+	} else {
+		lines = append(lines, theme.Notice.With(color.Bold).Sprint(tr("  Swept: %s", strings.Join(swept, ", "))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SavedSaboteur is the serialized form of a saboteur.
+type SavedSaboteur struct {
+	Location int             `json:"location"`
+	Sweeps   []SaboteurSweep `json:"sweeps,omitempty"`
+}
+
+// saboteurStateLocked captures the intruder at large, or returns nil if
+// there is none. The caller must hold g.mu.
+func (g *Game) saboteurStateLocked() *SavedSaboteur {
+	if g.saboteur == nil {
+		return nil
+	}
+	return &SavedSaboteur{Location: g.saboteur.location.ID, Sweeps: append([]SaboteurSweep(nil), g.saboteur.sweeps...)}
+}
+
+// restoreSaboteur rebuilds a saved intruder.
+func (g *Game) restoreSaboteur(st SavedSaboteur) (*saboteur, error) {
+	if st.Location < 0 || st.Location >= len(g.Systems) {
+		return nil, fmt.Errorf("save has saboteur in invalid system %d", st.Location)
+	}
+	for _, sweep := range st.Sweeps {
+		if sweep.System < 0 || sweep.System >= len(g.Systems) {
+			return nil, fmt.Errorf("save has sweep of invalid system %d", sweep.System)
+		}
+	}
+	return &saboteur{location: g.Systems[st.Location], sweeps: st.Sweeps}, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Debug console cheats, available once `debug` is typed in a sandbox run.
+const (
+	DebugSet       = "set"
+	DebugEvent     = "event"
+	DebugKits      = "kits"
+	DebugTimescale = "timescale"
+)
+
+const (
+	MinTimescale = 0.1
+	MaxTimescale = 10.0
+)
+
+// handleDebug toggles the debug console. It only exists in sandbox runs.
+func (g *Game) handleDebug() {
+	if !g.Sandbox {
+		g.AddLog(LogWarning, tr("The debug console is only available with --sandbox."))
+		return
+	}
+	g.mu.Lock()
+	g.debugConsole = !g.debugConsole
+	on := g.debugConsole
+	g.mu.Unlock()
+	if on {
+		g.AddLog(LogInfo, tr("DEBUG: Console unlocked. Cheats: set <id> <value>, event <name> [id], kits <n>, timescale <x>."))
+	} else {
+		g.AddLog(LogInfo, tr("DEBUG: Console locked."))
+	}
+}
+
+func (g *Game) debugUnlocked() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.debugConsole
+}
+
+// handleCheat runs one debug console command.
+func (g *Game) handleCheat(command string, args []string) {
+	if !g.debugUnlocked() {
+		g.AddLog(LogWarning, tr("Unknown command: %s (type 'debug' in a sandbox run to unlock cheats)", command))
+		return
+	}
+	switch command {
+	case DebugSet:
+		if len(args) < 2 {
+			g.AddLog(LogInfo, tr("Usage: set <system_id> <value>"))
+			return
+		}
+		sysID, err1 := strconv.Atoi(args[0])
+		value, err2 := strconv.Atoi(args[1])
+		if err1 != nil || err2 != nil {
+			g.AddLog(LogWarning, tr("Error: Invalid system ID or value format."))
+		} else if sysID < 0 || sysID >= len(g.Systems) {
+			g.AddLog(LogWarning, tr("Error: Invalid system ID for set."))
+		} else {
+			g.cheatSet(g.Systems[sysID], value)
+		}
+	case DebugEvent:
+		if len(args) < 1 {
+			g.AddLog(LogInfo, tr("Usage: event <%s> [system_id]", strings.Join(g.Events.Names(), "|")))
+			return
+		}
+		ev, ok := g.Events.Lookup(args[0])
+		if !ok {
+			g.AddLog(LogWarning, tr("Error: Unknown event %q.", args[0]))
+			return
+		}
+		sysID := g.rng.Intn(len(g.Systems))
+		if len(args) > 1 {
+			id, err := strconv.Atoi(args[1])
+			if err != nil || id < 0 || id >= len(g.Systems) {
+				g.AddLog(LogWarning, tr("Error: Invalid system ID for event."))
+				return
+			}
+			sysID = id
+		}
+		g.AddLog(LogInfo, tr("DEBUG: Triggering %s on %s (%d).", ev.Name(), g.Systems[sysID].Name, sysID))
+		g.triggerEvent(ev, sysID)
+	case DebugKits:
+		if len(args) < 1 {
+			g.AddLog(LogInfo, tr("Usage: kits <n>"))
+			return
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			g.AddLog(LogWarning, tr("Error: Repair kit count must be 0 or more."))
+			return
+		}
+		g.locked(func() {
+			g.Inventory[ItemRepairKit] = n
+		})
+		g.AddLog(LogInfo, tr("DEBUG: Repair kits set to %d.", n))
+	case DebugTimescale:
+		if len(args) < 1 {
+			g.AddLog(LogInfo, tr("Usage: timescale <x>"))
+			return
+		}
+		scale, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || scale < MinTimescale || scale > MaxTimescale {
+			g.AddLog(LogWarning, tr("Error: Timescale must be between %.1f and %.0f.", MinTimescale, MaxTimescale))
+			return
+		}
+		g.setTimescale(scale)
+		g.AddLog(LogInfo, tr("DEBUG: Timescale set to x%.2f.", scale))
+	}
+}
+
+// cheatSet forces sys to value, bringing it back online if value is above
+// the floor. The usual bounds still apply.
+func (g *Game) cheatSet(sys *System, value int) {
+	sys.locked(func() {
+		sys.Value = value
+		if value > sys.MinValue {
+			sys.Offline = false
+			sys.offlineNotified = false
+		}
+		sys.clampLocked()
+		value = sys.Value
+	})
+	g.AddLog(LogInfo, tr("DEBUG: %s (%d) set to %d.", sys.Name, sys.ID, value))
+}
+
+// setTimescale runs the game clock, degradation and random events at scale
+// times real time from now on. Timed actions, cooldowns and incident
+// deadlines keep running in real time.
+func (g *Game) setTimescale(scale float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.clock.Now()
+	g.scaleBase = g.elapsedAtLocked(now)
+	g.scaleMark = now
+	g.timescale = scale
+}
+
+func (g *Game) timeScale() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.timescale == 0 {
+		return 1
+	}
+	return g.timescale
+}
+
+// wallTime converts a span of game time into real time at the current timescale.
+func (g *Game) wallTime(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / g.timeScale())
+}
+
+// sandboxBanner renders the sandbox header line, or "" outside a sandbox.
+func (s GameState) sandboxBanner() string {
+	if !s.Sandbox {
+		return ""
+	}
+	console := "locked"
+	if s.DebugConsole {
+		console = "UNLOCKED"
+	}
+	return theme.Accent.Sprint(tr("SANDBOX: Lose conditions off. Timescale x%.2f. Debug console %s.", s.Timescale, console))
+}
+
+// debugHelp lists the sandbox commands for the dashboard.
+func (s GameState) debugHelp() []string {
+	if !s.Sandbox {
+		return nil
+	}
+	lines := []string{"  debug                   (Lock or unlock the debug console)"}
+	if s.DebugConsole {
+		lines = append(lines,
+			tr("  set <id> <value>        (DEBUG: Force a system's integrity)"),
+			tr("  event <name> [id]       (DEBUG: Trigger an event now)"),
+			tr("  kits <n>                (DEBUG: Set the repair kit count)"),
+			tr("  timescale <x>           (DEBUG: Run the clock at x speed, %.1f-%.0f)", MinTimescale, MaxTimescale),
+		)
+	}
+	return lines
+}
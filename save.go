@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SaveVersion is bumped whenever the save format changes incompatibly.
+const SaveVersion = 1
+
+// SavedSystem is the serialized form of a System.
+type SavedSystem struct {
+	ID              int           `json:"id"`
+	Name            string        `json:"name"`
+	Value           int           `json:"value"`
+	DegradationRate int           `json:"degradation_rate"`
+	IsStable        bool          `json:"is_stable"`
+	Offline         bool          `json:"offline,omitempty"`
+	Overcharge      int           `json:"overcharge,omitempty"`
+	GlitchBoost     int           `json:"glitch_boost,omitempty"`
+	GlitchRemaining time.Duration `json:"glitch_remaining_ns,omitempty"`
+	SensorFault     time.Duration `json:"sensor_fault_ns,omitempty"`
+	Damage          int           `json:"damage,omitempty"`
+	Stabilized      int           `json:"stabilized,omitempty"`
+}
+
+// SaveState is the serialized form of a Game. Times are stored relative to
+// the moment of saving so a loaded game resumes exactly where it left off.
+type SaveState struct {
+	Version         int                      `json:"version"`
+	Difficulty      string                   `json:"difficulty,omitempty"`
+	Mode            string                   `json:"mode,omitempty"`
+	Mutators        []string                 `json:"mutators,omitempty"`
+	Level           int                      `json:"level,omitempty"`
+	Systems         []SavedSystem            `json:"systems"`
+	EventLog        []LogEntry               `json:"event_log"`
+	History         []LogEntry               `json:"history,omitempty"`     // The full log
+	RepairKits      int                      `json:"repair_kits,omitempty"` // Saves from before the inventory
+	Inventory       map[string]int           `json:"inventory,omitempty"`
+	FuseArmed       bool                     `json:"fuse_armed,omitempty"`
+	Elapsed         time.Duration            `json:"elapsed_ns"`
+	PlayerAction    string                   `json:"player_action,omitempty"`
+	ActionSystem    int                      `json:"action_system"`
+	ActionKind      string                   `json:"action_kind,omitempty"`
+	ActionRemaining time.Duration            `json:"action_remaining_ns,omitempty"`
+	QueuedCommand   string                   `json:"queued_command,omitempty"`
+	Score           ScoreStats               `json:"score"`
+	Stats           RunStats                 `json:"stats"`
+	CampaignLevel   int                      `json:"campaign_level,omitempty"`
+	Upgrades        []string                 `json:"upgrades,omitempty"`
+	ScenarioPath    string                   `json:"scenario_path,omitempty"`
+	ScriptFired     int                      `json:"script_fired,omitempty"`
+	Triggers        []TriggerState           `json:"triggers,omitempty"`
+	ObjectiveHeld   time.Duration            `json:"objective_held_ns,omitempty"`
+	Cooldowns       map[string]time.Duration `json:"cooldowns_ns,omitempty"`
+	Rules           []Rule                   `json:"rules,omitempty"`
+	Radiation       float64                  `json:"radiation,omitempty"`
+	RadiationAbove  time.Duration            `json:"radiation_above_ns,omitempty"`
+	MeltdownLeft    time.Duration            `json:"meltdown_left_ns,omitempty"` // Before the imminent meltdown
+	MoraleLost      int                      `json:"morale_lost,omitempty"`      // Below MoraleMax
+	MoraleCalm      time.Duration            `json:"morale_calm_ns,omitempty"`
+	UndosUsed       int                      `json:"undos_used,omitempty"`
+	Inspections     int                      `json:"inspections,omitempty"` // Over, passed or failed
+	Inspecting      int                      `json:"inspecting,omitempty"`  // Where the next one stands
+	Assisted        bool                     `json:"assisted,omitempty"`
+	ThermalRate     float64                  `json:"thermal_rate,omitempty"`
+	Pressure        float64                  `json:"pressure,omitempty"` // Of the adaptive mutator
+	Crisis          *SavedCrisis             `json:"crisis,omitempty"`
+	Story           *SavedStory              `json:"story,omitempty"`
+	Saboteur        *SavedSaboteur           `json:"saboteur,omitempty"`
+	Conditions      *ConditionsState         `json:"conditions,omitempty"`
+	EmergencyCode   string                   `json:"emergency_code,omitempty"`
+	CodeSystem      int                      `json:"code_system,omitempty"`
+	CodeRemaining   time.Duration            `json:"code_remaining_ns,omitempty"`
+	Replay          []byte                   `json:"replay,omitempty"` // Digest of the commands so far
+	Alarms          []Alarm                  `json:"alarms,omitempty"`
+	Trend           [][]int                  `json:"trend,omitempty"` // Per system, every reading so far
+	Ironman         string                   `json:"ironman,omitempty"`
+	Seal            string                   `json:"seal,omitempty"`
+}
+
+// State captures the current game into a SaveState.
+func (g *Game) State() SaveState {
+	now := g.clock.Now()
+	cooldowns := g.cooldownState()
+	var st SaveState
+	g.locked(func() {
+		st = SaveState{
+			Version:        SaveVersion,
+			Difficulty:     g.Difficulty.Name,
+			Mode:           g.Mode,
+			Mutators:       mutatorList(g.Mutators),
+			Level:          g.Level,
+			EventLog:       append([]LogEntry(nil), g.EventLog...),
+			History:        append([]LogEntry(nil), g.History...),
+			Inventory:      make(map[string]int, len(g.Inventory)),
+			FuseArmed:      g.fuseArmed,
+			Elapsed:        now.Sub(g.StartTime),
+			ActionSystem:   -1,
+			Score:          g.Score,
+			Stats:          g.Stats,
+			CampaignLevel:  g.CampaignLevel,
+			Upgrades:       g.Upgrades,
+			ScenarioPath:   g.ScenarioPath,
+			ScriptFired:    g.scriptFired,
+			Triggers:       append([]TriggerState(nil), g.triggerState...),
+			ObjectiveHeld:  g.objectiveHeld,
+			Cooldowns:      cooldowns,
+			Radiation:      g.Radiation,
+			RadiationAbove: g.radiationHigh,
+			MeltdownLeft:   g.meltdownLeftLocked(g.elapsedAtLocked(now)),
+			MoraleLost:     MoraleMax - g.Morale,
+			MoraleCalm:     g.moraleCalm,
+			UndosUsed:      g.undosUsed,
+			Crisis:         g.crisisStateLocked(now),
+			Story:          g.storyStateLocked(g.elapsedAtLocked(now)),
+			Saboteur:       g.saboteurStateLocked(),
+			Conditions:     g.conditionsSnapshotLocked(),
+			Replay:         g.replayStateLocked(),
+			Alarms:         append([]Alarm(nil), g.Alarms...),
+			Trend:          make([][]int, len(g.trend)),
+			Assisted:       g.assisted,
+			Inspections:    g.inspectionsDone,
+			Inspecting:     g.inspectionStage,
+		}
+		for i, samples := range g.trend {
+			st.Trend[i] = append([]int(nil), samples...)
+		}
+		st.Score.InCritical = append([]bool(nil), g.Score.InCritical...)
+		st.Stats.Lowest = append([]int(nil), g.Stats.Lowest...)
+		st.Stats.InCritical = append([]time.Duration(nil), g.Stats.InCritical...)
+		st.Stats.Commands = make(map[string]int, len(g.Stats.Commands))
+		for command, n := range g.Stats.Commands {
+			st.Stats.Commands[command] = n
+		}
+		st.Stats.Audit = append([]AuditEntry(nil), g.Stats.Audit...)
+		if g.thermal != nil {
+			st.ThermalRate = g.thermal.Rate
+		}
+		if hasMutator(g.Mutators, MutatorAdaptive) {
+			st.Pressure = g.pressure
+		}
+		if g.code != nil && now.Before(g.code.deadline) {
+			st.EmergencyCode = g.code.Code
+			st.CodeSystem = g.code.target.ID
+			st.CodeRemaining = g.code.deadline.Sub(now)
+		}
+		for name, n := range g.Inventory {
+			st.Inventory[name] = n
+		}
+		for _, rule := range g.rules {
+			st.Rules = append(st.Rules, Rule{Condition: rule.Condition, Action: rule.Action})
+		}
+		if g.PlayerAction != "" && now.Before(g.ActionEndTime) {
+			st.PlayerAction = g.PlayerAction
+			st.ActionSystem = g.ActionSystem
+			st.ActionKind = g.ActionKind
+			st.ActionRemaining = g.ActionEndTime.Sub(now)
+			st.QueuedCommand = g.QueuedCommand
+		}
+	})
+
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		ss := SavedSystem{
+			ID:              sys.ID,
+			Name:            sys.Name,
+			Value:           sys.Value,
+			DegradationRate: sys.DegradationRate,
+			IsStable:        sys.IsStable,
+			Offline:         sys.Offline,
+			Overcharge:      sys.Overcharge,
+			Damage:          sys.Damage,
+			Stabilized:      sys.Stabilized,
+		}
+		if now.Before(sys.SensorFaultEnd) {
+			ss.SensorFault = sys.SensorFaultEnd.Sub(now)
+		}
+		if sys.GlitchBoost > 0 && now.Before(sys.GlitchEnd) {
+			ss.GlitchBoost = sys.GlitchBoost
+			ss.GlitchRemaining = sys.GlitchEnd.Sub(now)
+		}
+		sys.mu.Unlock()
+		st.Systems = append(st.Systems, ss)
+	}
+	return st
+}
+
+// Restore replaces the game state with st and resumes any pending timers.
+// It is called before the game goroutines are started, or on the game loop
+// of a run under way, as rewind and load do: the run then drops the timers
+// it had set, and its operators their actions, queues, cooldowns and
+// pending confirmations. A save that fails to restore changes nothing.
+func (g *Game) Restore(st SaveState) error {
+	if st.Version != SaveVersion {
+		return fmt.Errorf("unsupported save version %d", st.Version)
+	}
+	var rules []*Rule
+	for _, saved := range st.Rules {
+		rule, err := g.parseRule(saved.Condition, saved.Action)
+		if err != nil {
+			return fmt.Errorf("save has invalid rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if len(st.Systems) != len(g.Systems) {
+		return fmt.Errorf("save has %d systems, expected %d", len(st.Systems), len(g.Systems))
+	}
+	var muts []Mutator
+	for _, name := range st.Mutators {
+		m, err := MutatorByName(name)
+		if err != nil {
+			return err
+		}
+		muts = append(muts, m)
+	}
+	now := g.clock.Now()
+	var crisis *Crisis
+	if st.Crisis != nil {
+		c, err := g.restoreCrisis(*st.Crisis, now)
+		if err != nil {
+			return err
+		}
+		crisis = c
+	}
+	var story *story
+	if st.Story != nil {
+		s, err := restoreStory(*st.Story, st.Elapsed)
+		if err != nil {
+			return err
+		}
+		story = s
+	}
+	var intruder *saboteur
+	if st.Saboteur != nil {
+		s, err := g.restoreSaboteur(*st.Saboteur)
+		if err != nil {
+			return err
+		}
+		intruder = s
+	}
+	weather := g.conditions
+	if st.Conditions != nil && weather != nil {
+		c, err := restoreConditions(*st.Conditions)
+		if err != nil {
+			return err
+		}
+		weather = c
+	}
+	replay, err := resumeReplay(st.Replay)
+	if err != nil {
+		return err
+	}
+	if st.EmergencyCode != "" && (st.CodeSystem < 0 || st.CodeSystem >= len(g.Systems)) {
+		return fmt.Errorf("save has emergency code on invalid system %d", st.CodeSystem)
+	}
+	if st.ActionRemaining > 0 && st.ActionKind != ActionScram && st.ActionKind != ActionRally && (st.ActionSystem < 0 || st.ActionSystem >= len(g.Systems)) {
+		return fmt.Errorf("save has pending action on invalid system %d", st.ActionSystem)
+	}
+	values := make([]int, len(st.Systems))
+	for i, ss := range st.Systems {
+		values[i] = ss.Value
+	}
+	for _, a := range st.Alarms {
+		if a.System < 0 || a.System >= len(g.Systems) {
+			return fmt.Errorf("save has alarm on invalid system %d", a.System)
+		}
+	}
+
+	diff := g.Difficulty
+	if st.Difficulty != "" {
+		d, err := DifficultyByName(st.Difficulty)
+		if err != nil {
+			return err
+		}
+		diff = d
+	}
+	mode := ModeClassic
+	if st.Mode != "" {
+		m, err := ParseMode(st.Mode)
+		if err != nil {
+			return err
+		}
+		mode = m
+	}
+
+	g.locked(func() {
+		g.restores++ // Drops the timers set until now
+		g.code, g.minigame = nil, nil
+		for _, op := range g.operators {
+			op.PlayerAction, op.ActionSystem, op.ActionKind, op.actionCancel = "", -1, "", nil
+			op.QueuedCommand, op.confirm, op.lastDivert, op.cooldownUntil = "", nil, nil, nil
+		}
+		g.Difficulty = diff
+		g.Mode = mode
+		g.Mutators = muts
+		g.applyMutatorWeights()
+		g.Level = st.Level
+		g.Score = st.Score
+		g.Stats = st.Stats
+		g.scriptFired = st.ScriptFired
+		if len(st.Triggers) == len(g.triggerState) {
+			copy(g.triggerState, st.Triggers)
+		}
+		g.objectiveHeld = st.ObjectiveHeld
+		g.objectiveMark = st.Elapsed
+		g.EventLog = append(make([]LogEntry, 0, g.LogCapacity), st.EventLog...)
+		if len(g.EventLog) > g.LogCapacity {
+			g.EventLog = g.EventLog[len(g.EventLog)-g.LogCapacity:]
+		}
+		g.History = append([]LogEntry(nil), st.History...)
+		if len(g.History) == 0 { // Saves from before the full log
+			g.History = append(g.History, g.EventLog...)
+		}
+		g.Inventory = map[string]int{ItemRepairKit: st.RepairKits}
+		for name, n := range st.Inventory {
+			if _, ok := lookupItem(name); ok {
+				g.Inventory[name] = n
+			}
+		}
+		g.fuseArmed = st.FuseArmed
+		g.assisted = st.Assisted || g.autopilot != nil // The autopilot may fly on what was loaded
+		g.rules = rules
+		g.replay = replay
+		g.resumeAlarmsLocked(st.Alarms, values)
+		g.trend = nil
+		if len(st.Trend) == len(g.Systems) {
+			g.trend = st.Trend
+		}
+		g.crisis = crisis
+		g.story = story
+		g.saboteur = intruder
+		g.conditions = weather
+		g.Radiation = clampRadiation(st.Radiation)
+		g.radiationHigh = st.RadiationAbove
+		g.meltdownDue = 0
+		if st.MeltdownLeft > 0 {
+			g.meltdownDue = st.Elapsed + st.MeltdownLeft
+		}
+		g.Morale = min(MoraleMax, max(0, MoraleMax-st.MoraleLost))
+		g.moraleCalm = st.MoraleCalm
+		g.undosUsed = min(UndoLimit, max(0, st.UndosUsed))
+		g.inspectionsDone = max(0, st.Inspections)
+		g.inspectionStage = min(inspectionUnderWay, max(inspectionDue, st.Inspecting))
+		g.phase = g.phaseAt(st.Elapsed)
+		if g.thermal != nil {
+			g.thermal.Rate = st.ThermalRate
+		}
+		if st.Pressure > 0 {
+			g.pressure = st.Pressure
+		}
+		g.QueuedCommand = st.QueuedCommand
+		g.StartTime = now.Add(-st.Elapsed)
+	})
+	for command, left := range st.Cooldowns {
+		if _, ok := commandCooldowns[command]; ok {
+			g.setCooldown(command, left)
+		}
+	}
+
+	for i, ss := range st.Systems {
+		sys := g.Systems[i]
+		sys.locked(func() {
+			sys.ID = ss.ID
+			sys.Name = ss.Name
+			sys.Value = ss.Value
+			sys.DegradationRate = ss.DegradationRate
+			sys.IsStable = false
+			sys.Offline = ss.Offline
+			sys.offlineNotified = ss.Offline
+			sys.Overcharge = ss.Overcharge
+			sys.Damage = ss.Damage
+			sys.Stabilized = max(0, ss.Stabilized)
+			sys.Reported = ss.Value
+			sys.SensorFaultEnd = now.Add(ss.SensorFault)
+			sys.GlitchBoost = 0
+			if ss.GlitchBoost > 0 {
+				sys.GlitchBoost = ss.GlitchBoost
+				sys.GlitchEnd = now.Add(ss.GlitchRemaining)
+			}
+		})
+		if ss.GlitchBoost > 0 {
+			g.expireTimedDegradation(sys, ss.GlitchBoost, ss.GlitchRemaining, "INFO: %s (%d) degradation back to normal.")
+		}
+	}
+
+	if st.EmergencyCode != "" {
+		g.startEmergencyCode(g.Systems[st.CodeSystem], st.EmergencyCode, st.CodeRemaining)
+	}
+	if st.ActionRemaining > 0 {
+		switch st.ActionKind {
+		case ActionScram:
+			g.startScram(st.ActionRemaining)
+		case ActionReboot:
+			g.startReboot(g.Systems[st.ActionSystem], st.ActionRemaining)
+		case ActionInspect:
+			g.startInspection(g.Systems[st.ActionSystem], st.ActionRemaining)
+		case ActionSweep:
+			g.startSweep(g.Systems[st.ActionSystem], st.ActionRemaining)
+		case ActionRally:
+			g.startRally(st.ActionRemaining)
+		case ActionFabricate:
+			g.startFabrication(g.Systems[st.ActionSystem], st.ActionRemaining)
+		default: // Saves from before reboots existed only had stabilizations
+			g.startStabilization(g.Systems[st.ActionSystem], st.ActionRemaining, false)
+		}
+	}
+	return nil
+}
+
+// SaveToFile writes the current game state to path as JSON, sealed if the
+// run is an ironman run.
+func (g *Game) SaveToFile(path string) error {
+	st := g.State()
+	if g.ironman != nil {
+		st.Ironman = g.ironman.ID
+		st.Seal = g.ironman.seal(st)
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// restoreEpoch counts the restores of the run, for the timers set before one
+// to tell that they are stale.
+func (g *Game) restoreEpoch() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.restores
+}
+
+// ReadSaveFile reads a JSON file written by SaveToFile. Pass the result to
+// Restore once a game with the matching layout has been created.
+func ReadSaveFile(path string) (SaveState, error) {
+	var st SaveState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("parse save: %w", err)
+	}
+	return st, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// headlessGame starts a game on a simClock, driven from the test goroutine
+// the way the simulator drives one.
+func headlessGame(t *testing.T, seed int64) (*Game, *simClock) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	difficulty, err := DifficultyByName("normal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame(DefaultConfig(), difficulty, seed, nil)
+	g.Mode = ModeClassic
+	clock := newSimClock(g.StartTime)
+	g.useClock(clock)
+	g.inline = true
+	g.scheduleRandomEvent()
+	return g, clock
+}
+
+// playFor runs g for d of game time, the greedy policy at the controls.
+func playFor(g *Game, clock *simClock, due *schedule, d time.Duration) {
+	rng := NewRand(botSeed)
+	for end := g.Elapsed() + d; !g.ended() && g.Elapsed() < end; {
+		clock.Advance(SimStep)
+		g.tick(clock.Now(), due)
+		if g.Elapsed()%BotReaction == 0 && !g.ended() {
+			if input := (greedyPolicy{}).Decide(policyView(g), rng).String(); input != "" {
+				g.execute(input)
+			}
+		}
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	g, clock := headlessGame(t, 3)
+	due := g.newSchedule()
+	playFor(g, clock, &due, 45*time.Second)
+	if g.ended() {
+		t.Fatal("the run ended before it could be saved")
+	}
+	saved := g.State()
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := headlessGame(t, 99)
+	var st SaveState
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Restore(st); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	again, err := json.Marshal(loaded.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("state changed across a save and load:\nsaved  %s\nloaded %s", data, again)
+	}
+}
+
+func TestRestoreRefusesWithoutChange(t *testing.T) {
+	g, clock := headlessGame(t, 3)
+	due := g.newSchedule()
+	playFor(g, clock, &due, 20*time.Second)
+	before, _ := json.Marshal(g.State())
+
+	for _, tc := range []struct {
+		name  string
+		spoil func(*SaveState)
+	}{
+		{"version", func(st *SaveState) { st.Version = SaveVersion + 1 }},
+		{"systems", func(st *SaveState) { st.Systems = st.Systems[1:] }},
+		{"mutator", func(st *SaveState) { st.Mutators = []string{"no-such-mutator"} }},
+		{"difficulty", func(st *SaveState) { st.Difficulty = "impossible" }},
+		{"alarm system", func(st *SaveState) { st.Alarms = []Alarm{{System: 99}} }},
+	} {
+		st := g.State()
+		st.Level = 7 // Would show if anything were applied
+		tc.spoil(&st)
+		if err := g.Restore(st); err == nil {
+			t.Errorf("%s: Restore accepted a spoiled save", tc.name)
+		}
+		if after, _ := json.Marshal(g.State()); string(after) != string(before) {
+			t.Errorf("%s: a refused save changed the game", tc.name)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Save slots. Each profile keeps its saves in a directory of its own under
+// SavesDir: save <slot> writes one, load <slot> resumes it in place of the
+// run under way, or --load <slot> at launch, and saves lists them with a
+// picture of the bars as they were. The autosaves of the
+// profile's runs go there too, the newest AutosaveKeep of them kept.
+
+const (
+	SavesDir     = "saves"
+	SavesTopic   = "saves"
+	AutosaveKeep = 3
+
+	autosavePrefix = "autosave-"
+	slotExt        = ".json"
+)
+
+// saveSlot is a save of a profile's directory, as listed.
+type saveSlot struct {
+	Name  string
+	Saved time.Time
+	State SaveState
+	Err   error // Why the file could not be read, if it could not
+}
+
+// profileSavesDir returns the directory of the profile's save slots, and
+// creates it if it does not exist.
+func profileSavesDir(profile string) (string, error) {
+	if !profileNamePattern.MatchString(profile) {
+		return "", fmt.Errorf("invalid profile name %q (letters, digits, - and _ only)", profile)
+	}
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, SavesDir, profile)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// isSlotName reports whether name is a slot rather than a file path. Slots
+// take the names profiles do, so "slot1" is a slot and "run.json" a file.
+func isSlotName(name string) bool {
+	return profileNamePattern.MatchString(name)
+}
+
+func slotPath(dir, slot string) string {
+	return filepath.Join(dir, slot+slotExt)
+}
+
+// resolveSavePath returns the file --load reads for name: the slot of that
+// name if dir has one, or name as a path.
+func resolveSavePath(dir, name string) string {
+	if dir == "" || !isSlotName(name) {
+		return name
+	}
+	if path := slotPath(dir, name); fileExists(path) {
+		return path
+	}
+	return name
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// listSlots reads the saves of dir, the newest first.
+func listSlots(dir string) ([]saveSlot, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+slotExt))
+	if err != nil {
+		return nil, err
+	}
+	var slots []saveSlot
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Removed since the listing
+		}
+		slot := saveSlot{Name: strings.TrimSuffix(filepath.Base(path), slotExt), Saved: info.ModTime()}
+		slot.State, slot.Err = ReadSaveFile(path)
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if !slots[i].Saved.Equal(slots[j].Saved) {
+			return slots[i].Saved.After(slots[j].Saved)
+		}
+		return slots[i].Name < slots[j].Name
+	})
+	return slots, nil
+}
+
+// autosaves returns the autosaves among slots, the newest first.
+func autosaves(slots []saveSlot) []saveSlot {
+	var out []saveSlot
+	for _, s := range slots {
+		if strings.HasPrefix(s.Name, autosavePrefix) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// newAutosavePath names the autosave of a run started now.
+func newAutosavePath(dir string, now time.Time) string {
+	return slotPath(dir, autosavePrefix+now.Format("20060102-150405"))
+}
+
+// pruneAutosaves removes all but the newest AutosaveKeep autosaves of dir.
+func pruneAutosaves(dir string) {
+	slots, err := listSlots(dir)
+	if err != nil {
+		return
+	}
+	old := autosaves(slots)
+	for len(old) > AutosaveKeep {
+		_ = os.Remove(slotPath(dir, old[len(old)-1].Name)) // Best effort: it goes next launch otherwise
+		old = old[:len(old)-1]
+	}
+}
+
+// slotLines describes each slot on a line: its name, the time into the run,
+// how the run was set up, the bars of the systems and the worst of them,
+// and when it was saved.
+func slotLines(slots []saveSlot, cfg Config) []string {
+	if len(slots) == 0 {
+		return []string{tr("No saves yet. save <slot> writes one.")}
+	}
+	lines := make([]string, 0, len(slots))
+	for _, s := range slots {
+		saved := s.Saved.Format("2006-01-02 15:04")
+		if s.Err != nil {
+			lines = append(lines, fmt.Sprintf("  %-24s %s  %s", s.Name, saved, tr("unreadable: %v", s.Err)))
+			continue
+		}
+		st := s.State
+		setup := st.Difficulty
+		if st.Mode == ModeEndless {
+			setup += " " + ModeEndless
+		}
+		if st.CampaignLevel > 0 {
+			setup += " " + tr("level %d", st.CampaignLevel)
+		}
+		values := make([]int, len(st.Systems))
+		for i, sys := range st.Systems {
+			values[i] = sys.Value
+		}
+		bars := sparkline(values, cfg.MaxSystemValue, len(values))
+		lines = append(lines, fmt.Sprintf("  %-24s %s  %7s  %-16s %s  %s", s.Name, saved, formatDuration(st.Elapsed), strings.TrimSpace(setup), bars, systemsSummary(st.Systems, cfg)))
+	}
+	return lines
+}
+
+// systemsSummary counts the saved systems in trouble.
+func systemsSummary(systems []SavedSystem, cfg Config) string {
+	var offline, critical, warning int
+	for _, sys := range systems {
+		switch {
+		case sys.Offline || sys.Value <= cfg.MinSystemValue:
+			offline++
+		case sys.Value <= cfg.CriticalThreshold:
+			critical++
+		case sys.Value <= cfg.WarningThreshold:
+			warning++
+		}
+	}
+	var parts []string
+	if offline > 0 {
+		parts = append(parts, tr("%d offline", offline))
+	}
+	if critical > 0 {
+		parts = append(parts, tr("%d critical", critical))
+	}
+	if warning > 0 {
+		parts = append(parts, tr("%d warning", warning))
+	}
+	if len(parts) == 0 {
+		return tr("all healthy")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PrintSaves lists the save slots of a profile, for the saves command line
+// command.
+func PrintSaves(profile string, cfg Config) error {
+	dir, err := profileSavesDir(profile)
+	if err != nil {
+		return err
+	}
+	slots, err := listSlots(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Println(theme.Title.Sprint(tr("SAVES: %s", profile)))
+	for _, line := range slotLines(slots, cfg) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// runSaves opens the list of the profile's slots. The files are read here,
+// on the game loop, and the page shows them as they were then.
+func runSaves(g *Game, _, _ []string) {
+	if g.savesDir == "" {
+		g.AddLog(LogWarning, tr("Save slots are not available in this session."))
+		return
+	}
+	slots, err := listSlots(g.savesDir)
+	if err != nil {
+		g.AddLog(LogWarning, tr("Failed to list the saves: %v", err))
+		return
+	}
+	lines := slotLines(slots, g.Config)
+	g.openHelp(SavesTopic)
+	g.mu.Lock()
+	g.savesPage = lines
+	g.mu.Unlock()
+}
+
+// runLoad resumes a slot of the profile in place of the run under way, on
+// the game loop, as rewind does a snapshot. A save of another scenario or
+// campaign level is built on another reactor, so it resumes at launch, with
+// --load.
+func runLoad(g *Game, _, rawParts []string) {
+	switch {
+	case len(rawParts) < 2:
+		g.AddLog(LogInfo, tr("Usage: load <slot>"))
+		return
+	case !g.localPlayer():
+		g.AddLog(LogWarning, tr("Load failed: Only the host, at the machine the game runs on, can load a save."))
+		return
+	case g.savesDir == "":
+		g.AddLog(LogWarning, tr("Save slots are not available in this session."))
+		return
+	case g.plant != nil:
+		g.AddLog(LogWarning, tr("Load failed: Multi-reactor runs cannot load a save."))
+		return
+	case g.rival != nil:
+		g.AddLog(LogWarning, tr("Load failed: Versus matches cannot load a save."))
+		return
+	case g.tutorial != nil:
+		g.AddLog(LogWarning, tr("Load failed: The tutorial cannot load a save."))
+		return
+	case g.ironman != nil:
+		g.AddLog(LogWarning, tr("An ironman run has one save: its own."))
+		return
+	case !isSlotName(rawParts[1]):
+		g.AddLog(LogWarning, tr("Invalid slot name %q (letters, digits, - and _ only)", rawParts[1]))
+		return
+	}
+	st, err := ReadSaveFile(slotPath(g.savesDir, rawParts[1]))
+	if err != nil {
+		g.AddLog(LogWarning, tr("Cannot load %s: %v", rawParts[1], err))
+		return
+	}
+	switch {
+	case st.Ironman != "":
+		g.AddLog(LogInfo, tr("%s holds an ironman run at %s. It resumes with --ironman.", rawParts[1], formatDuration(st.Elapsed)))
+		return
+	case st.CampaignLevel != g.CampaignLevel || st.ScenarioPath != g.ScenarioPath:
+		g.AddLog(LogInfo, tr("%s holds a run of another scenario or level, at %s: quit, then start with --load %s.", rawParts[1], formatDuration(st.Elapsed), rawParts[1]))
+		return
+	}
+	if err := g.Restore(st); err != nil {
+		g.AddLog(LogWarning, tr("Cannot load %s: %v", rawParts[1], err))
+		return
+	}
+	if g.practice != nil {
+		g.locked(func() {
+			g.practice.snapshots = nil // Of the run loaded over
+		})
+	}
+	g.AddLog(LogSuccess, tr("LOADED: %s, at %s. The events ahead are drawn afresh.", rawParts[1], formatDuration(st.Elapsed)))
+}
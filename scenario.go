@@ -0,0 +1,376 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	ScriptPollInterval = 250 * time.Millisecond // How often scripted events and triggers are checked
+
+	ObjectiveSurvive   = "survive"    // Survive until the scenario duration elapses
+	ObjectiveHoldAbove = "hold_above" // Keep one system above a value for a continuous stretch
+	ObjectiveAllAbove  = "all_above"  // Bring every system to a value at the same time
+	ObjectiveKeepKits  = "keep_kits"  // Survive with a number of repair kits left
+	ObjectiveHoldBand  = "hold_band"  // Keep one system within a band for a cumulative stretch
+)
+
+// Scenario is a scripted run: a reactor layout, a time limit, timed and
+// conditional events and a victory condition. Campaign levels are scenarios
+// too. Unset fields fall back to the base config.
+type Scenario struct {
+	Name           string                 `toml:"name"`
+	Briefing       string                 `toml:"briefing"`
+	Duration       time.Duration          `toml:"duration"`
+	RepairKits     *int                   `toml:"repair_kits"`
+	NoRandomEvents bool                   `toml:"no_random_events"` // Only scripted events fire
+	Systems        []ScenarioSystem       `toml:"systems"`
+	Events         []ScriptedEvent        `toml:"events"`
+	Triggers       []Trigger              `toml:"triggers"`
+	Victory        Objective              `toml:"victory"`
+	Thermal        toml.Primitive         `toml:"thermal"`       // Overrides part of the base thermal model
+	Actions        ActionRules            `toml:"actions"`       // Replaces the base rules of the commands it names
+	EventWeights   map[string]int         `toml:"event_weights"` // Over the base weights of the events it names
+	EventTable     map[string]EventTuning `toml:"event_table"`   // Over the base numbers of the events it names
+
+	meta toml.MetaData
+}
+
+// ScenarioSystem sets up one system: its definition, and what it starts
+// at. Zero values keep the defaults, or the random start.
+type ScenarioSystem struct {
+	SystemDef
+	Value int `toml:"value"`
+}
+
+// ScriptedEvent fires a named event on a system at a fixed game time.
+type ScriptedEvent struct {
+	At     time.Duration `toml:"at"`
+	Event  string        `toml:"event"`
+	System int           `toml:"system"`
+}
+
+// Trigger fires a named event whenever its condition holds, e.g.
+// `if = "Power Output < 30"`. With no cooldown it fires only once.
+type Trigger struct {
+	If       string        `toml:"if"`
+	Event    string        `toml:"event"`
+	System   int           `toml:"system"`
+	Cooldown time.Duration `toml:"cooldown"`
+
+	cond condition
+}
+
+// TriggerState tracks when a trigger last fired, for cooldowns and saves.
+type TriggerState struct {
+	Fired  bool          `json:"fired"`
+	LastAt time.Duration `json:"last_at_ns"`
+}
+
+// condition compares one system's value against a constant.
+type condition struct {
+	System int
+	Op     string
+	Value  int
+}
+
+// Objective is a scenario's victory condition.
+type Objective struct {
+	Kind   string        `toml:"kind"`
+	System int           `toml:"system"`
+	Value  int           `toml:"value"`
+	Hold   time.Duration `toml:"hold"`
+	Min    int           `toml:"min"`  // hold_band lower bound, inclusive
+	Max    int           `toml:"max"`  // hold_band upper bound, inclusive
+	Kits   int           `toml:"kits"` // keep_kits requirement
+}
+
+var conditionPattern = regexp.MustCompile(`^\s*(.+?)\s*(<=|>=|<|>)\s*(-?\d+)\s*$`)
+
+// ParseScenario decodes and validates a scenario definition.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var sc Scenario
+	md, err := toml.Decode(string(data), &sc)
+	if err != nil {
+		return nil, err
+	}
+	sc.meta = md
+	if md.IsDefined("thermal") {
+		thermal := defaultThermal()
+		if err := md.PrimitiveDecode(sc.Thermal, &thermal); err != nil {
+			return nil, fmt.Errorf("thermal: %w", err)
+		}
+		if err := thermal.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown scenario key %q", undecoded[0].String())
+	}
+	completeEventTable(md, sc.EventTable)
+	if sc.Victory.Kind == "" {
+		sc.Victory.Kind = ObjectiveSurvive
+	}
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("scenario %q: %w", sc.Name, err)
+	}
+	sort.SliceStable(sc.Events, func(i, j int) bool { return sc.Events[i].At < sc.Events[j].At })
+	return &sc, nil
+}
+
+// LoadScenarioFile reads a user-authored scenario.
+func LoadScenarioFile(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(data)
+}
+
+// validate checks everything that does not depend on the final system layout;
+// system references are checked by applyScenario.
+func (sc *Scenario) validate() error {
+	if len(sc.Systems) == 1 || len(sc.Systems) > MaxSystems {
+		return fmt.Errorf("needs 2-%d systems, got %d", MaxSystems, len(sc.Systems))
+	}
+	if err := validateActions(sc.Actions); err != nil {
+		return err
+	}
+	if err := validateEventWeights(sc.EventWeights); err != nil {
+		return err
+	}
+	if err := validateEventTable(sc.EventTable); err != nil {
+		return err
+	}
+	for _, ev := range sc.Events {
+		if _, ok := lookupEvent(ev.Event); !ok {
+			return fmt.Errorf("unknown event %q", ev.Event)
+		}
+	}
+	for _, tr := range sc.Triggers {
+		if _, ok := lookupEvent(tr.Event); !ok {
+			return fmt.Errorf("unknown event %q", tr.Event)
+		}
+		if !conditionPattern.MatchString(tr.If) {
+			return fmt.Errorf("trigger condition %q must look like \"<system> < <value>\"", tr.If)
+		}
+	}
+	switch sc.Victory.Kind {
+	case ObjectiveSurvive:
+	case ObjectiveHoldAbove:
+		if sc.Victory.Hold <= 0 {
+			return fmt.Errorf("hold_above victory needs a positive hold time")
+		}
+	case ObjectiveAllAbove:
+		if sc.Victory.Value <= 0 {
+			return fmt.Errorf("all_above victory needs a positive value")
+		}
+	case ObjectiveKeepKits:
+		if sc.Victory.Kits <= 0 {
+			return fmt.Errorf("keep_kits victory needs a positive kit count")
+		}
+	case ObjectiveHoldBand:
+		if sc.Victory.Hold <= 0 {
+			return fmt.Errorf("hold_band victory needs a positive hold time")
+		}
+		if sc.Victory.Min > sc.Victory.Max {
+			return fmt.Errorf("hold_band victory needs min <= max")
+		}
+	default:
+		return fmt.Errorf("unknown victory kind %q", sc.Victory.Kind)
+	}
+	return nil
+}
+
+// Config derives the run config for this scenario from base.
+func (sc *Scenario) Config(base Config) Config {
+	cfg := base
+	if len(sc.Systems) > 0 {
+		defs := make([]SystemDef, len(sc.Systems))
+		for i, sys := range sc.Systems {
+			defs[i] = sys.SystemDef
+		}
+		cfg.setSystems(defs, nil)
+	}
+	if sc.Duration > 0 {
+		cfg.GameDuration = sc.Duration
+	}
+	if sc.RepairKits != nil {
+		cfg.InitialRepairKits = *sc.RepairKits
+	}
+	if sc.meta.IsDefined("thermal") {
+		_ = sc.meta.PrimitiveDecode(sc.Thermal, &cfg.Thermal) // Checked by ParseScenario
+	}
+	if len(sc.Actions) > 0 {
+		cfg.Actions = mergeActions(base.Actions, sc.Actions)
+	}
+	if len(sc.EventWeights) > 0 {
+		cfg.EventWeights = make(map[string]int, len(base.EventWeights)+len(sc.EventWeights))
+		for name, weight := range base.EventWeights {
+			cfg.EventWeights[name] = weight
+		}
+		for name, weight := range sc.EventWeights {
+			cfg.EventWeights[name] = weight
+		}
+	}
+	if len(sc.EventTable) > 0 {
+		cfg.EventTable = mergeEventTable(base.EventTable, sc.EventTable)
+	}
+	return cfg
+}
+
+// findSystem resolves a numeric ID, or a system name as commands take it:
+// "coolant", "coretemp" or "shield". It returns nil if nothing matches, or
+// more than one system does.
+func (g *Game) findSystem(ref string) *System {
+	if id, err := strconv.Atoi(ref); err == nil {
+		if id < 0 || id >= len(g.Systems) {
+			return nil
+		}
+		return g.Systems[id]
+	}
+	if matches := g.matchSystems(ref); len(matches) == 1 {
+		return matches[0]
+	}
+	return nil
+}
+
+// parseCondition resolves "<system> <op> <value>", where the system is
+// anything findSystem accepts.
+func (g *Game) parseCondition(text string) (condition, error) {
+	m := conditionPattern.FindStringSubmatch(text)
+	if m == nil {
+		return condition{}, fmt.Errorf("invalid condition %q", text)
+	}
+	value, _ := strconv.Atoi(m[3])
+	sys := g.findSystem(m[1])
+	if sys == nil {
+		return condition{}, fmt.Errorf("condition %q names an unknown system", text)
+	}
+	return condition{System: sys.ID, Op: m[2], Value: value}, nil
+}
+
+func (c condition) holds(value int) bool {
+	switch c.Op {
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	case ">":
+		return value > c.Value
+	default: // ">="
+		return value >= c.Value
+	}
+}
+
+// applyScenario sets up the systems, triggers and objective for a scenario.
+// level is the 1-based campaign level, or 0 for a standalone scenario.
+func (g *Game) applyScenario(sc *Scenario, level int) error {
+	if err := g.Config.validateSystems(); err != nil {
+		return err
+	}
+	inRange := func(id int) bool { return id >= 0 && id < len(g.Systems) }
+	for _, ev := range sc.Events {
+		if !inRange(ev.System) {
+			return fmt.Errorf("event %q targets invalid system %d", ev.Event, ev.System)
+		}
+	}
+	for i := range sc.Triggers {
+		tr := &sc.Triggers[i]
+		if !inRange(tr.System) {
+			return fmt.Errorf("trigger %q targets invalid system %d", tr.Event, tr.System)
+		}
+		cond, err := g.parseCondition(tr.If)
+		if err != nil {
+			return err
+		}
+		tr.cond = cond
+	}
+	if (sc.Victory.Kind == ObjectiveHoldAbove || sc.Victory.Kind == ObjectiveHoldBand) && !inRange(sc.Victory.System) {
+		return fmt.Errorf("victory targets invalid system %d", sc.Victory.System)
+	}
+
+	g.Scenario = sc
+	g.objective = newObjectiveChecker(sc.Victory)
+	g.CampaignLevel = level
+	g.triggerState = make([]TriggerState, len(sc.Triggers))
+	for i, def := range sc.Systems {
+		sys := g.Systems[i]
+		sys.locked(func() {
+			if def.Value > 0 {
+				sys.Value = def.Value
+			}
+		})
+	}
+	return nil
+}
+
+// triggerScriptedEvents fires every timed event that has come due and every
+// conditional trigger whose condition holds and whose cooldown has passed.
+func (g *Game) triggerScriptedEvents() {
+	if g.Scenario == nil {
+		return
+	}
+	elapsed := g.Elapsed()
+	type firing struct {
+		event  string
+		system int
+	}
+	var due []firing
+
+	g.locked(func() {
+		for g.scriptFired < len(g.Scenario.Events) && g.Scenario.Events[g.scriptFired].At <= elapsed {
+			ev := g.Scenario.Events[g.scriptFired]
+			due = append(due, firing{ev.Event, ev.System})
+			g.scriptFired++
+		}
+	})
+
+	for i, tr := range g.Scenario.Triggers {
+		sys := g.Systems[tr.cond.System]
+		sys.mu.Lock()
+		holds := tr.cond.holds(sys.Value)
+		sys.mu.Unlock()
+
+		g.locked(func() {
+			st := &g.triggerState[i]
+			ready := !st.Fired || (tr.Cooldown > 0 && elapsed-st.LastAt >= tr.Cooldown)
+			if holds && ready {
+				st.Fired = true
+				st.LastAt = elapsed
+				due = append(due, firing{tr.Event, tr.System})
+			}
+		})
+	}
+
+	for _, f := range due {
+		if ev, ok := g.Events.Lookup(f.event); ok {
+			g.triggerEvent(ev, f.system)
+		}
+	}
+}
+
+// randomEventsEnabled reports whether the random event pool is in play.
+func (g *Game) randomEventsEnabled() bool {
+	return (g.Scenario == nil || !g.Scenario.NoRandomEvents) && !g.tutorialRunning()
+}
+
+// checkObjective reports whether the run has been won, or why it has been
+// lost on objective (meltdowns are checked separately).
+func (g *Game) checkObjective() (won bool, failure string) {
+	if g.Mode == ModeEndless {
+		return false, ""
+	}
+	return g.objective.Check(g, g.Elapsed())
+}
+
+// objectiveText describes the current objective for the header.
+func (g *Game) objectiveText() string {
+	return g.objective.Describe(g)
+}
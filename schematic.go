@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Schematic view. `schematic` swaps the status table for a diagram of the
+// plant, each component drawn in the color of its system's health. Coolant
+// and steam flow and the turbine spins once every SchematicFrame of game
+// time, slower as the system weakens, and not at all once it is offline.
+const SchematicFrame = WatchInterval
+
+// schematicArt is the diagram. In schematicMask the same column names the
+// component each character belongs to; see schematicParts. In the art, f
+// and b are pipe flowing forward and back, s is steam, r the turbine rotor
+// and a run of @ the component's reading.
+var schematicArt = []string{
+	"                                                  sssssssssssss",
+	"  ##########################                      sssssssssssss",
+	"  #  +------------------+  #        +-----+      +-------------+",
+	"  #  |  +------------+  |fffffffffff| S G |ffffff|  TURBINE r  |=ffffff> GRID",
+	"  #  |  |    CORE    |  |  #        | @@@@|      |    @@@@     |",
+	"  #  |  |    @@@@    |  |bbbbbbbbbbb|     |      +-------------+",
+	"  #  |  +------------+  |  #        +-----+       POWER OUTPUT",
+	"  #  +----PRESSURE @@@@-+  #         COOLANT",
+	"  ####SHIELDS @@@@##########",
+}
+
+var schematicMask = []string{
+	"                                                  PPPPPPPPPPPPP",
+	"  SSSSSSSSSSSSSSSSSSSSSSSSSS                      PPPPPPPPPPPPP",
+	"  S  PPPPPPPPPPPPPPPPPPPP  S        LLLLLLL      TTTTTTTTTTTTTTT",
+	"  S  P  CCCCCCCCCCCCCC  PLLLLLLLLLLLLLLLLLLPPPPPPTTTTTTTTTTTTTTTTTTTTTTTTTTTT",
+	"  S  P  CCCCCCCCCCCCCC  P  S        LLLLLLL      TTTTTTTTTTTTTTT",
+	"  S  P  CCCCCCCCCCCCCC  PLLLLLLLLLLLLLLLLLL      TTTTTTTTTTTTTTT",
+	"  S  P  CCCCCCCCCCCCCC  P  S        LLLLLLL       TTTTTTTTTTTT",
+	"  S  PPPPPPPPPPPPPPPPPPPP  S         LLLLLLL",
+	"  SSSSSSSSSSSSSSSSSSSSSSSSSS",
+}
+
+var schematicParts = map[byte]string{
+	'C': "Core Temp",
+	'P': "Pressure Ctrl",
+	'L': "Coolant Flow",
+	'T': "Power Output",
+	'S': "Shield Integrity",
+}
+
+const steamPuffs = "  .  o  O   o .   "
+
+// toggleSchematic switches the current operator between the schematic and
+// the status table.
+func (g *Game) toggleSchematic() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.schematicOpen = !g.schematicOpen
+}
+
+// schematicStyle is how a component of sys is drawn; nil when the plant has
+// no such system.
+func (s GameState) schematicStyle(sys *SystemState) Style {
+	switch {
+	case sys == nil:
+		return theme.Dim
+	case sys.Offline:
+		return theme.Inverse
+	case sys.Reading > sys.MaxValue:
+		return theme.Info
+	case sys.Reading <= sys.Critical:
+		return theme.Critical.With(color.Bold)
+	case sys.Reading <= sys.Warning:
+		return theme.Warning
+	}
+	return theme.OK
+}
+
+// schematicPhase is the animation frame of a component of sys: it runs at
+// full speed while healthy, half at the warning threshold, a quarter when
+// critical, and stops offline.
+func (s GameState) schematicPhase(sys *SystemState) int {
+	frame := int(s.Elapsed / SchematicFrame)
+	switch {
+	case sys == nil || sys.Offline:
+		return 0
+	case sys.Reading <= sys.Critical:
+		return frame / 4
+	case sys.Reading <= sys.Warning:
+		return frame / 2
+	}
+	return frame
+}
+
+// schematicChar renders the character of the art at row and x, which
+// belongs to sys, at phase. It returns the text and how many characters of
+// the art it covers.
+func (s GameState) schematicChar(row, x int, sys *SystemState, phase int) (string, int) {
+	line := schematicArt[row]
+	switch line[x] {
+	case 'f':
+		if (x-phase)%3 == 0 {
+			return ">", 1
+		}
+		return "=", 1
+	case 'b':
+		if (x+phase)%3 == 0 {
+			return "<", 1
+		}
+		return "=", 1
+	case 's':
+		if sys == nil || sys.Offline {
+			return " ", 1
+		}
+		return string(steamPuffs[(x+3*row+len(steamPuffs)-phase%len(steamPuffs))%len(steamPuffs)]), 1
+	case 'r':
+		return string(`|/-\`[phase%4]), 1
+	case '@':
+		n := strings.IndexFunc(line[x:], func(r rune) bool { return r != '@' })
+		if n < 0 {
+			n = len(line) - x
+		}
+		switch {
+		case sys == nil:
+			return fmt.Sprintf("%*s", n, "--"), n
+		case sys.Offline:
+			return fmt.Sprintf("%*s", n, "OFF"), n
+		}
+		return fmt.Sprintf("%*d%%", n-1, sys.Reading*100/sys.MaxValue), n
+	}
+	return line[x : x+1], 1
+}
+
+// drawSchematic renders the diagram and a key of the system IDs to command
+// them by, wrapped to width, in place of the status table.
+func (s GameState) drawSchematic(w io.Writer, width int) {
+	parts := make(map[byte]*SystemState, len(schematicParts))
+	for i := range s.Systems {
+		for part, name := range schematicParts {
+			if s.Systems[i].Name == name {
+				parts[part] = &s.Systems[i]
+			}
+		}
+	}
+	for row, line := range schematicArt {
+		mask := schematicMask[row]
+		var b, run strings.Builder
+		owner := byte(' ')
+		flush := func() {
+			if owner != ' ' {
+				b.WriteString(s.schematicStyle(parts[owner]).Sprint(run.String()))
+			} else {
+				b.WriteString(run.String())
+			}
+			run.Reset()
+		}
+		for x := 0; x < len(line); {
+			if mask[x] != owner {
+				flush()
+				owner = mask[x]
+			}
+			sys := parts[owner]
+			text, n := s.schematicChar(row, x, sys, s.schematicPhase(sys))
+			run.WriteString(text)
+			x += n
+		}
+		flush()
+		fmt.Fprintln(w, b.String())
+	}
+
+	line := 0
+	for i := range s.Systems {
+		sys := &s.Systems[i]
+		entry := fmt.Sprintf("[%d] %s", sys.ID, tr(sys.Name))
+		switch {
+		case line == 0:
+		case line+2+len(entry) > width-1:
+			fmt.Fprintln(w)
+			line = 0
+		default:
+			fmt.Fprint(w, "  ")
+			line += 2
+		}
+		fmt.Fprint(w, s.schematicStyle(sys).Sprint(entry))
+		line += len(entry)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	PointsPerSecond       = 10   // Survival points per second played
+	StabilityBonusPerTick = 1    // Per system per degradation tick spent above WarningThreshold
+	CriticalPenalty       = 150  // Each time a system drops to CriticalThreshold or below
+	FailedOverridePenalty = 100  // Each override that causes critical damage
+	UnackedAlarmPenalty   = 50   // Each critical alarm left unacknowledged for AlarmAckTime
+	KitMultiplierStep     = 0.25 // Score multiplier added per unused repair kit
+	ScramMultiplier       = 0.5  // Applied when the run ends in an emergency shutdown
+
+	StreakStep       = 30 * time.Second // Clean play per step of the streak multiplier
+	StreakStepBonus  = 0.25             // Multiplier on survival points added per step
+	StreakMultiplier = 2.0              // The most the streak multiplies survival points by
+
+	streakMeterSegments = 10
+)
+
+// ScoreStats accumulates the raw scoring inputs during a run.
+type ScoreStats struct {
+	StableTicks     int    `json:"stable_ticks"`
+	Criticals       int    `json:"criticals"`
+	FailedOverrides int    `json:"failed_overrides"`
+	UnackedAlarms   int    `json:"unacked_alarms,omitempty"`
+	Shortfalls      int    `json:"shortfalls,omitempty"`  // Conditions ticks with grid demand unmet
+	Inspections     int    `json:"inspections,omitempty"` // Passed
+	InCritical      []bool `json:"in_critical,omitempty"` // Per system, to count each dip only once
+
+	Streak       time.Duration `json:"streak_ns,omitempty"`      // Clean play since the last critical
+	BestStreak   time.Duration `json:"best_streak_ns,omitempty"` // The longest streak of the run
+	StreakPoints float64       `json:"streak_points,omitempty"`  // Survival points the streak added
+}
+
+// ScoreBreakdown is the itemized score shown on the summary screen.
+type ScoreBreakdown struct {
+	Survival          int           `json:"survival"`
+	Stability         int           `json:"stability"`
+	CriticalPenalty   int           `json:"critical_penalty"`
+	OverridePenalty   int           `json:"override_penalty"`
+	AlarmPenalty      int           `json:"alarm_penalty"`
+	DemandPenalty     int           `json:"demand_penalty,omitempty"`
+	InspectionBonus   int           `json:"inspection_bonus,omitempty"`
+	StreakBonus       int           `json:"streak_bonus"`
+	Streak            float64       `json:"streak"` // The multiplier the streak holds now
+	StreakTime        time.Duration `json:"streak_ns"`
+	BestStreak        time.Duration `json:"best_streak_ns"`
+	KitMultiplier     float64       `json:"kit_multiplier"`
+	LevelMultiplier   float64       `json:"level_multiplier"`
+	ScramMultiplier   float64       `json:"scram_multiplier"`
+	MutatorMultiplier float64       `json:"mutator_multiplier"`
+	Total             int           `json:"total"`
+}
+
+// recordScoreTick updates the stability bonus, the streak and critical
+// penalties after a degradation tick. values holds each system's integrity,
+// indexed by ID.
+func (g *Game) recordScoreTick(values []int) {
+	tick := g.degradationTick()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.Score.InCritical) != len(values) {
+		g.Score.InCritical = make([]bool, len(values))
+	}
+	clean, anyCritical := true, false
+	for i, val := range values {
+		if val > g.Systems[i].WarningThreshold {
+			g.Score.StableTicks++
+		} else {
+			clean = false
+		}
+		critical := val <= g.Systems[i].CriticalThreshold
+		if critical && !g.Score.InCritical[i] {
+			g.Score.Criticals++
+		}
+		g.Score.InCritical[i] = critical
+		anyCritical = anyCritical || critical
+	}
+	g.updateStreakLocked(tick, clean, anyCritical)
+}
+
+// updateStreakLocked grows the streak by a tick of clean play, paying out
+// the survival points its multiplier adds, and resets it on a critical. A
+// system in the warning zone holds it where it is. The caller must hold g.mu.
+func (g *Game) updateStreakLocked(tick time.Duration, clean, critical bool) {
+	switch {
+	case critical:
+		g.Score.Streak = 0
+	case clean:
+		g.Score.StreakPoints += (streakMultiplier(g.Score.Streak) - 1) * PointsPerSecond * tick.Seconds()
+		g.Score.Streak += tick
+		g.Score.BestStreak = max(g.Score.BestStreak, g.Score.Streak)
+	}
+}
+
+// streakMultiplier is the multiplier on survival points a streak of clean
+// play holds.
+func streakMultiplier(streak time.Duration) float64 {
+	return min(StreakMultiplier, 1+StreakStepBonus*float64(streak/StreakStep))
+}
+
+func (g *Game) recordFailedOverride() {
+	g.locked(func() {
+		g.Score.FailedOverrides++
+	})
+}
+
+// CurrentScore computes the score from the accumulated stats.
+func (g *Game) CurrentScore() ScoreBreakdown {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.scoreLocked(elapsed)
+}
+
+// scoreLocked computes the score after elapsed. The caller must hold g.mu.
+func (g *Game) scoreLocked(elapsed time.Duration) ScoreBreakdown {
+	b := ScoreBreakdown{
+		Survival:          int(elapsed.Seconds()) * PointsPerSecond,
+		Stability:         g.Score.StableTicks * StabilityBonusPerTick,
+		CriticalPenalty:   g.Score.Criticals * CriticalPenalty,
+		OverridePenalty:   g.Score.FailedOverrides * FailedOverridePenalty,
+		AlarmPenalty:      g.Score.UnackedAlarms * UnackedAlarmPenalty,
+		DemandPenalty:     g.Score.Shortfalls * g.Config.Conditions.DemandPenalty,
+		InspectionBonus:   g.Score.Inspections * g.Config.Inspections.Bonus,
+		StreakBonus:       int(g.Score.StreakPoints),
+		Streak:            streakMultiplier(g.Score.Streak),
+		StreakTime:        g.Score.Streak,
+		BestStreak:        g.Score.BestStreak,
+		KitMultiplier:     1 + KitMultiplierStep*float64(g.Inventory[ItemRepairKit]),
+		LevelMultiplier:   1,
+		ScramMultiplier:   1,
+		MutatorMultiplier: g.mutatorMultiplier(),
+	}
+	if g.Mode == ModeEndless {
+		b.LevelMultiplier = float64(g.Level + 1)
+	}
+	if g.Scrammed {
+		b.ScramMultiplier = ScramMultiplier
+	}
+	subtotal := b.Survival + b.StreakBonus + b.Stability + b.InspectionBonus - b.CriticalPenalty - b.OverridePenalty - b.AlarmPenalty - b.DemandPenalty
+	if subtotal < 0 {
+		subtotal = 0
+	}
+	b.Total = int(float64(subtotal) * b.KitMultiplier * b.LevelMultiplier * b.ScramMultiplier * b.MutatorMultiplier)
+	return b
+}
+
+// streakMeter renders the streak for the header: the multiplier it holds,
+// the progress to its next step and how long it has run.
+func (s GameState) streakMeter() string {
+	b := s.Score
+	fill := streakMeterSegments
+	if b.Streak < StreakMultiplier {
+		fill = int(b.StreakTime % StreakStep * streakMeterSegments / StreakStep)
+	}
+	line := tr("STREAK x%.2f [%s%s] %s", b.Streak, strings.Repeat("#", fill), strings.Repeat("-", streakMeterSegments-fill), formatDuration(b.StreakTime))
+	if b.Streak > 1 {
+		return theme.Success.Sprint(line)
+	}
+	return theme.Dim.Sprint(line)
+}
+
+// outcomeText describes how the run ended.
+func (g *Game) outcomeText() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case g.GameWon:
+		return "REACTOR SECURED. You survived the critical period!"
+	case g.Scrammed:
+		return "SCRAM. Emergency shutdown completed safely. Partial victory."
+	case g.Outcome != "":
+		return g.Outcome
+	case g.Mode == ModeEndless:
+		return "MELTDOWN. The reactor finally gave out."
+	}
+	return "MELTDOWN. Multiple systems offline."
+}
+
+// DisplaySummary renders the end-of-game screen with the score breakdown.
+func (g *Game) DisplaySummary(w io.Writer) {
+	clearScreen(w)
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	won := g.GameWon
+	scrammed := g.Scrammed
+	kits := g.Inventory[ItemRepairKit]
+	level := g.Level
+	topScores := g.topScores
+	lastScore := g.lastScore
+	campaignNote := g.campaignNote
+	reportNote := g.reportNote
+	profileNote := g.profileNote
+	leaderboardNote := g.leaderboardNote
+	g.mu.Unlock()
+	b := g.CurrentScore()
+
+	fmt.Fprintln(w, theme.Title.Sprint(tr("--- REACTOR CONTROL TERMINAL: SHIFT REPORT ---")))
+	switch outcome := g.outcomeText(); {
+	case won:
+		fmt.Fprintln(w, theme.Success.Sprint(tr("\nOUTCOME: %s", outcome)))
+	case scrammed:
+		fmt.Fprintln(w, theme.Notice.Sprint(tr("\nOUTCOME: %s", outcome)))
+	default:
+		fmt.Fprintln(w, theme.Alert.Sprint(tr("\nOUTCOME: %s", outcome)))
+	}
+	if match := g.matchLine(); match != "" {
+		fmt.Fprintln(w, match)
+	}
+
+	fmt.Fprint(w, tr("\nDifficulty: %s  Mode: %s  Seed: %d\n", strings.ToUpper(g.Difficulty.Name), g.Mode, g.Seed))
+	fmt.Fprint(w, tr("Config: %s\n", g.configHash))
+	if challenge := g.challenge(); challenge != "" {
+		fmt.Fprintln(w, theme.Accent.Sprint(tr("CHALLENGE A FRIEND: --challenge %s", challenge)))
+	}
+	if g.CampaignLevel > 0 {
+		fmt.Fprint(w, tr("Campaign Level %d: %s\n", g.CampaignLevel, g.Scenario.Name))
+		if len(g.Upgrades) > 0 {
+			fmt.Fprint(w, tr("Upgrades: %s\n", g.upgradeNames()))
+		}
+	} else if g.Scenario != nil {
+		fmt.Fprint(w, tr("Scenario: %s\n", g.Scenario.Name))
+	}
+	fmt.Fprint(w, tr("Time Survived: %s\n", formatDuration(elapsed)))
+	if g.Mode == ModeEndless {
+		fmt.Fprint(w, tr("Escalation Level Reached: %d\n", level))
+	}
+
+	fmt.Fprintln(w, theme.Heading.Sprint(tr("\nSCORE BREAKDOWN:")))
+	fmt.Fprintf(w, "  %-34s %+7d\n", tr("Survival time"), b.Survival)
+	fmt.Fprintf(w, "  %-34s %+7d\n", tr("Clean streak (best %s)", formatDuration(b.BestStreak)), b.StreakBonus)
+	fmt.Fprintf(w, "  %-34s %+7d\n", tr("Systems held above warning"), b.Stability)
+	if b.InspectionBonus > 0 {
+		fmt.Fprintf(w, "  %-34s %+7d\n", tr("Inspections passed"), b.InspectionBonus)
+	}
+	fmt.Fprintf(w, "  %-34s %7d\n", tr("Critical failures"), -b.CriticalPenalty)
+	fmt.Fprintf(w, "  %-34s %7d\n", tr("Failed overrides"), -b.OverridePenalty)
+	fmt.Fprintf(w, "  %-34s %7d\n", tr("Unacknowledged alarms"), -b.AlarmPenalty)
+	if b.DemandPenalty > 0 {
+		fmt.Fprintf(w, "  %-34s %7d\n", tr("Grid demand unmet"), -b.DemandPenalty)
+	}
+	fmt.Fprintf(w, "  %-34s   x%.2f\n", tr("Unused repair kits (%d)", kits), b.KitMultiplier)
+	if g.Mode == ModeEndless {
+		fmt.Fprintf(w, "  %-34s   x%.2f\n", tr("Escalation level"), b.LevelMultiplier)
+	}
+	if scrammed {
+		fmt.Fprintf(w, "  %-34s   x%.2f\n", tr("Emergency shutdown"), b.ScramMultiplier)
+	}
+	if len(g.Mutators) > 0 {
+		fmt.Fprintf(w, "  %-34s   x%.2f\n", tr("Mutators (%s)", strings.Join(mutatorList(g.Mutators), ", ")), b.MutatorMultiplier)
+	}
+	fmt.Fprintln(w, theme.Success.Sprint(tr("\n  FINAL SCORE: %d", b.Total)))
+	if g.plant != nil {
+		g.plant.printPlantSummary(w)
+	}
+	fmt.Fprintln(w)
+	g.printStats(w)
+	if reportNote != "" {
+		fmt.Fprintln(w, reportNote)
+		fmt.Fprintln(w)
+	}
+	if profileNote != "" {
+		fmt.Fprintln(w, profileNote)
+		fmt.Fprintln(w)
+	}
+	if leaderboardNote != "" {
+		fmt.Fprintln(w, leaderboardNote)
+		fmt.Fprintln(w)
+	}
+	if campaignNote != "" {
+		fmt.Fprintln(w, theme.Info.Sprintf(campaignNote))
+		fmt.Fprintln(w)
+	}
+	if g.Sandbox {
+		fmt.Fprintln(w, theme.Accent.Sprint(tr("SANDBOX RUN: Not recorded in the high scores, profile or campaign.")))
+		fmt.Fprintln(w)
+	} else if g.isAssisted() {
+		fmt.Fprintln(w, theme.Accent.Sprint(tr("AUTOPILOT RUN: Not recorded in the high scores, profile or campaign.")))
+		fmt.Fprintln(w)
+	}
+	if topScores != nil {
+		printHighScores(w, topScores, lastScore)
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scripted playthroughs. --script plays one headless game on the simulated
+// clock, typing each line of a script when the game reaches its time, and
+// exits with a status telling a win from a loss, so recorded scripts can
+// guard the balance against a change:
+//
+//	# Hold the core through the first wave
+//	@00:15 stabilize 2
+//	@00:21.5 divert 4 2 20
+//	vent 1
+//
+// A line without a time is typed right after the one before it. Dangerous
+// commands run without waiting for CONFIRM, as they do for a bot.
+
+// Exit statuses of a script's run; a scrammed run is neither won nor lost.
+const (
+	ExitWon      = 0
+	ExitLost     = 3
+	ExitScrammed = 4
+)
+
+// ScriptLine is one command of a script and the game time it is typed at.
+type ScriptLine struct {
+	At    time.Duration
+	Input string
+	Line  int // In the script file, for errors
+}
+
+// Script is a timed list of commands for a headless game.
+type Script struct {
+	Name  string
+	Lines []ScriptLine
+}
+
+// LoadScript reads the script at path, or from stdin for "-".
+func LoadScript(path string) (*Script, error) {
+	if path == "-" {
+		return ParseScript(os.Stdin, "stdin")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseScript(f, path)
+}
+
+// ParseScript reads a script. Times must not go back.
+func ParseScript(r io.Reader, name string) (*Script, error) {
+	s := &Script{Name: name}
+	var at time.Duration
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			stamp, rest, _ := strings.Cut(line[1:], " ")
+			next, err := parseScriptTime(stamp)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			if next < at {
+				return nil, fmt.Errorf("line %d: @%s comes before the line above", n, stamp)
+			}
+			at, line = next, strings.TrimSpace(rest)
+		}
+		switch fields := strings.Fields(strings.ToLower(line)); {
+		case len(fields) == 0:
+			return nil, fmt.Errorf("line %d: no command after the time", n)
+		case fields[0] == "quit":
+			return nil, fmt.Errorf("line %d: quit is not needed; the run plays out to its end", n)
+		}
+		s.Lines = append(s.Lines, ScriptLine{At: at, Input: line, Line: n})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// check reports the first line whose command the game would not know, by
+// the verbs of cfg. Authorization codes are drawn by the run, and pass.
+func (s *Script) check(cfg Config) error {
+	for _, line := range s.Lines {
+		verb := strings.Fields(line.Input)[0]
+		if strings.HasPrefix(strings.ToUpper(verb), CodePrefix) {
+			continue
+		}
+		if _, known := commandTable[resolveVerbWith(cfg.Verbs, verb)]; !known {
+			return fmt.Errorf("line %d: unknown command %s", line.Line, verb)
+		}
+	}
+	return nil
+}
+
+// parseScriptTime reads a script time: minutes and seconds, as the
+// dashboard shows them (02:15, 2:15.5), or a Go duration (135s).
+func parseScriptTime(stamp string) (time.Duration, error) {
+	minutes, seconds, ok := strings.Cut(stamp, ":")
+	if !ok {
+		d, err := time.ParseDuration(stamp)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid time @%s (use @mm:ss or @90s)", stamp)
+		}
+		return d, nil
+	}
+	m, err := strconv.Atoi(minutes)
+	s, err2 := strconv.ParseFloat(seconds, 64)
+	if err != nil || err2 != nil || m < 0 || s < 0 || s >= 60 {
+		return 0, fmt.Errorf("invalid time @%s (use @mm:ss or @90s)", stamp)
+	}
+	return time.Duration(m)*time.Minute + time.Duration(s*float64(time.Second)), nil
+}
+
+// scriptExit prints the outcome of a scripted run and returns its exit
+// status.
+func scriptExit(sum SimSummary) int {
+	fmt.Println(theme.Title.Sprint(tr("SCRIPT: %s, %d commands", sum.Script.Name, len(sum.Script.Lines))))
+	fmt.Print(tr("  Difficulty: %s  Mode: %s  Seed: %d\n", strings.ToUpper(sum.Difficulty.Name), sum.Mode, sum.Seed))
+	if len(sum.Mutators) > 0 {
+		fmt.Print(tr("  Mutators: %s\n", strings.Join(mutatorList(sum.Mutators), ", ")))
+	}
+	if sum.Scenario != nil {
+		fmt.Print(tr("  Scenario: %s\n", sum.Scenario.Name))
+	}
+	outcome, status := tr("LOST"), ExitLost
+	switch {
+	case sum.Won > 0:
+		outcome, status = tr("WON"), ExitWon
+	case sum.Scrammed > 0:
+		outcome, status = tr("SCRAMMED"), ExitScrammed
+	}
+	fmt.Print(tr("  Outcome: %s after %s  Score: %d\n", outcome, formatDuration(sum.TotalSurvived), sum.TotalScore))
+	if status == ExitLost && len(sum.LossEvents) > 0 {
+		names := make([]string, 0, len(sum.LossEvents))
+		for name := range sum.LossEvents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Print(tr("  Events preceding the loss: %s\n", strings.Join(names, ", ")))
+	}
+	return status
+}
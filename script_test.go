@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func runScript(t *testing.T, text string, seed int64) SimSummary {
+	t.Helper()
+	script, err := ParseScript(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatalf("ParseScript: %v", err)
+	}
+	cfg := DefaultConfig()
+	if err := script.check(cfg); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	difficulty, err := DifficultyByName("normal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := Simulation{Games: 1, Script: script, Seed: seed, Config: cfg, Difficulty: difficulty, Mode: ModeClassic}.Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return sum
+}
+
+func TestScriptScram(t *testing.T) {
+	const script = `
+# Hold on, then shut down
+@00:12 stabilize 2
+@00:20 scram
+`
+	sum := runScript(t, script, 3)
+	if sum.Scrammed != 1 || sum.Won != 0 || sum.Lost != 0 {
+		t.Fatalf("won %d, scrammed %d, lost %d; want the run scrammed", sum.Won, sum.Scrammed, sum.Lost)
+	}
+	if got := formatDuration(sum.TotalSurvived); got != "00:30" {
+		t.Errorf("survived %s, want 00:30", got)
+	}
+	again := runScript(t, script, 3)
+	if again.TotalScore != sum.TotalScore || again.TotalSurvived != sum.TotalSurvived {
+		t.Errorf("replay scored %d after %v, first run %d after %v", again.TotalScore, again.TotalSurvived, sum.TotalScore, sum.TotalSurvived)
+	}
+}
+
+func TestScriptIdleLoses(t *testing.T) {
+	sum := runScript(t, "", 3)
+	if sum.Lost != 1 {
+		t.Fatalf("won %d, scrammed %d, lost %d; want an idle run lost", sum.Won, sum.Scrammed, sum.Lost)
+	}
+	if len(sum.LossEvents) == 0 {
+		t.Error("no events recorded before the loss")
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	for _, text := range []string{
+		"@00:20 vent 1\n@00:10 vent 2",
+		"@1:75 vent 1",
+		"@00:10",
+		"quit",
+	} {
+		if _, err := ParseScript(strings.NewReader(text), "test"); err == nil {
+			t.Errorf("ParseScript(%q) accepted", text)
+		}
+	}
+}
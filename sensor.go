@@ -0,0 +1,90 @@
+package main
+
+import "time"
+
+const (
+	SensorFaultTime = 15 * time.Second // How long a sensor glitch corrupts readings
+	SensorNoise     = 15               // Readings drift up to this far from the true value
+	InspectTime     = 3 * time.Second  // Manual inspection of one system
+)
+
+// Reading is the value the dashboard shows: the true value, or a noisy one
+// while the system's sensor is faulty.
+func (s *System) Reading() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sensorFaultLocked() {
+		return s.Reported
+	}
+	return s.Value
+}
+
+func (s *System) sensorFaultLocked() bool {
+	return s.clock.Now().Before(s.SensorFaultEnd)
+}
+
+// faultSensor corrupts the readings of sys for duration.
+func (g *Game) faultSensor(sys *System, duration time.Duration) {
+	sys.locked(func() {
+		sys.SensorFaultEnd = g.clock.Now().Add(duration)
+	})
+	g.updateSensor(sys)
+}
+
+// updateSensor draws a fresh noisy reading for a system with a faulty
+// sensor. It runs once per degradation tick.
+func (g *Game) updateSensor(sys *System) {
+	sys.mu.Lock()
+	defer sys.mu.Unlock()
+	if !sys.sensorFaultLocked() {
+		sys.Reported = sys.Value
+		return
+	}
+	reading := sys.Value + g.rng.Intn(2*SensorNoise+1) - SensorNoise
+	if reading < sys.MinValue {
+		reading = sys.MinValue + 1 // A faulty sensor never reads exactly zero
+	}
+	if ceiling := sys.ceilingLocked(); reading > ceiling {
+		reading = ceiling
+	}
+	sys.Reported = reading
+}
+
+func (g *Game) handleInspect(sysID int) {
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for inspect."))
+		return
+	}
+	if g.IsPlayerBusy() {
+		g.AddLog(LogWarning, tr("Cannot inspect: Player busy with another action."))
+		return
+	}
+	sys := g.Systems[sysID]
+	g.AddLog(LogInfo, tr("Inspecting %s (%d) by hand. This will take time.", sys.Name, sysID))
+	g.startInspection(sys, InspectTime)
+}
+
+// startInspection occupies the player with reading sys's true value. It is
+// shared by handleInspect and by resuming a saved game.
+func (g *Game) startInspection(sys *System, duration time.Duration) {
+	g.SetPlayerAction(tr("Inspecting %s (%d)...", sys.Name, sys.ID), duration)
+	g.locked(func() {
+		g.ActionSystem = sys.ID
+		g.ActionKind = ActionInspect
+	})
+
+	cancel := g.newActionCancel()
+	g.afterAction(cancel, duration, func() {
+		sys.mu.Lock()
+		value := sys.Value
+		faulty := sys.sensorFaultLocked()
+		sys.mu.Unlock()
+
+		g.ClearPlayerAction()
+		if faulty {
+			g.AddLog(LogInfo, tr("INSPECT: %s (%d) true value is %d. Its sensor is still faulty.", sys.Name, sys.ID, value))
+		} else {
+			g.AddLog(LogInfo, tr("INSPECT: %s (%d) true value is %d. Sensor reads correctly.", sys.Name, sys.ID, value))
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SimStep        = ScriptPollInterval // Simulated time per step
+	SimMaxDuration = time.Hour          // Endless runs still going by then count as survived
+	botSeed        = 0xb07              // Mixed into each game's seed for the policy's choices
+)
+
+// Simulation is a batch of headless games played by a bot policy. Game i
+// uses seed Seed+i, so a batch can be replayed exactly.
+type Simulation struct {
+	Games      int
+	Policy     Policy
+	Seed       int64
+	Config     Config
+	Difficulty Difficulty
+	Mode       string
+	Mutators   []Mutator
+	Scenario   *Scenario
+	Script     *Script // Typed in place of the policy's commands
+	Analyze    bool    // Gather the balance figures of --analyze
+}
+
+// SimResult is the outcome of one simulated game.
+type SimResult struct {
+	Won       bool
+	Scrammed  bool
+	Survived  time.Duration
+	Score     int
+	Preceding []string // Events in the EventMemory before a loss
+}
+
+// SimSummary aggregates a batch of simulated games.
+type SimSummary struct {
+	Simulation
+	Won, Scrammed, Lost int
+	TotalSurvived       time.Duration
+	TotalScore          int
+	LossEvents          map[string]int // Losses each event preceded
+	Analysis            *Analysis      // With Analyze, nil otherwise
+}
+
+// Run plays every game of the batch to the end.
+func (s Simulation) Run() (SimSummary, error) {
+	sum := SimSummary{Simulation: s, LossEvents: make(map[string]int)}
+	if s.Analyze {
+		sum.Analysis = newAnalysis()
+	}
+	for i := 0; i < s.Games; i++ {
+		r, err := s.play(s.Seed+int64(i), sum.Analysis.recorder())
+		if err != nil {
+			return sum, err
+		}
+		if failed, ok := s.Policy.(interface{ Err() error }); ok && failed.Err() != nil {
+			return sum, fmt.Errorf("policy %s: %v", s.Policy.Name(), failed.Err())
+		}
+		switch {
+		case r.Won:
+			sum.Won++
+		case r.Scrammed:
+			sum.Scrammed++
+		default:
+			sum.Lost++
+			for _, name := range r.Preceding {
+				sum.LossEvents[name]++
+			}
+		}
+		sum.TotalSurvived += r.Survived
+		sum.TotalScore += r.Score
+	}
+	return sum, nil
+}
+
+// play runs one game on a simClock. Each step takes the game loop's tick,
+// with the policy or the script standing in for stdin. rec, unless nil,
+// samples the game for --analyze.
+func (s Simulation) play(seed int64, rec *analysisRecorder) (SimResult, error) {
+	g := NewGame(s.Config, s.Difficulty, seed, s.Mutators)
+	g.Mode = s.Mode
+	if s.Scenario != nil {
+		if err := g.applyScenario(s.Scenario, 0); err != nil {
+			return SimResult{}, fmt.Errorf("invalid scenario: %v", err)
+		}
+	}
+	clock := newSimClock(g.StartTime)
+	g.useClock(clock)
+	g.inline = true
+	rng := NewRand(seed ^ botSeed)
+
+	g.scheduleRandomEvent()
+	due := g.newSchedule()
+	nextAct := clock.Now()
+	next := 0 // The script's next line
+	for !g.ended() && g.Elapsed() < SimMaxDuration {
+		clock.Advance(SimStep)
+		now := clock.Now()
+		g.tick(now, &due)
+		rec.step(g)
+		for s.Script != nil && next < len(s.Script.Lines) && !g.ended() && g.Elapsed() >= s.Script.Lines[next].At {
+			if input := s.Script.Lines[next].Input; !g.interceptCode(input) {
+				g.execute(input)
+			}
+			next++
+		}
+		if s.Script == nil && !g.ended() && !now.Before(nextAct) {
+			input := s.Policy.Decide(policyView(g), rng).String()
+			rec.act(g, input)
+			if input != "" && !g.interceptCode(input) {
+				g.execute(input)
+			}
+			nextAct = now.Add(BotReaction)
+		}
+	}
+
+	ended := g.ended()
+	g.mu.Lock()
+	r := SimResult{Won: g.GameWon || !ended, Scrammed: g.Scrammed} // An endless run that was cut off counts as won
+	g.mu.Unlock()
+	r.Survived = g.Elapsed()
+	r.Score = g.CurrentScore().Total
+	if !r.Won && !r.Scrammed {
+		r.Preceding = g.precedingEvents()
+	}
+	rec.finish(g, r)
+	return r, nil
+}
+
+// Print renders the aggregate statistics.
+func (sum SimSummary) Print() {
+	n := sum.Games
+	fmt.Println(theme.Title.Sprint(tr("SIMULATION: %d games, policy %s", n, sum.Policy.Name())))
+	fmt.Print(tr("  Difficulty: %s  Mode: %s  Seeds: %d-%d\n", strings.ToUpper(sum.Difficulty.Name), sum.Mode, sum.Seed, sum.Seed+int64(n)-1))
+	if len(sum.Mutators) > 0 {
+		fmt.Print(tr("  Mutators: %s\n", strings.Join(mutatorList(sum.Mutators), ", ")))
+	}
+	if sum.Scenario != nil {
+		fmt.Print(tr("  Scenario: %s\n", sum.Scenario.Name))
+	}
+	fmt.Print(tr("  Win rate: %.1f%% (%d won, %d scrammed, %d lost)\n", percent(sum.Won, n), sum.Won, sum.Scrammed, sum.Lost))
+	fmt.Print(tr("  Mean survival: %s  Mean score: %d\n", formatDuration(sum.TotalSurvived/time.Duration(n)), sum.TotalScore/n))
+
+	if sum.Lost == 0 {
+		return
+	}
+	fmt.Println(theme.Heading.Sprint(tr("\nEVENTS PRECEDING A LOSS (within %s):", formatDuration(EventMemory))))
+	names := make([]string, 0, len(sum.LossEvents))
+	for name := range sum.LossEvents {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if sum.LossEvents[names[i]] != sum.LossEvents[names[j]] {
+			return sum.LossEvents[names[i]] > sum.LossEvents[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) == 0 {
+		fmt.Println(tr("  No events hit in the run-up to any loss."))
+	}
+	for _, name := range names {
+		fmt.Print(tr("  %-22s %4d of %d losses (%.0f%%)\n", name, sum.LossEvents[name], sum.Lost, percent(sum.LossEvents[name], sum.Lost)))
+	}
+}
+
+func percent(part, whole int) float64 {
+	return 100 * float64(part) / float64(whole)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"time"
+)
+
+// GameState is a point-in-time copy of everything the dashboard shows. It
+// shares nothing with the live game, so it can be read at leisure.
+type GameState struct {
+	Unit              int                      `json:"unit,omitempty"` // 0 when alone
+	Mode              string                   `json:"mode"`
+	Difficulty        string                   `json:"difficulty"`
+	Seed              int64                    `json:"seed"`
+	Mutators          []string                 `json:"mutators,omitempty"`
+	Pressure          float64                  `json:"pressure,omitempty"` // Of the adaptive mutator, when active
+	Scenario          string                   `json:"scenario,omitempty"`
+	CampaignLevel     int                      `json:"campaign_level,omitempty"`
+	Objective         string                   `json:"objective"`
+	Elapsed           time.Duration            `json:"elapsed_ns"`
+	Duration          time.Duration            `json:"duration_ns"`
+	Tick              time.Duration            `json:"tick_ns"` // Between degradation ticks, and trend samples
+	Level             int                      `json:"level"`
+	Phase             string                   `json:"phase,omitempty"` // Of a timed run
+	GameOver          bool                     `json:"game_over"`
+	GameWon           bool                     `json:"game_won"`
+	Scrammed          bool                     `json:"scrammed"`
+	Score             ScoreBreakdown           `json:"score"`
+	MaxValue          int                      `json:"max_value"`
+	MinValue          int                      `json:"min_value"`
+	WarningThreshold  int                      `json:"warning_threshold"`
+	CriticalThreshold int                      `json:"critical_threshold"`
+	Systems           []SystemState            `json:"systems"`
+	Log               []LogEntry               `json:"log"`
+	LogCount          int                      `json:"log_count"` // Entries ever logged; Log holds the latest
+	Action            string                   `json:"action,omitempty"`
+	ActionLeft        time.Duration            `json:"action_left_ns,omitempty"`
+	Queued            string                   `json:"queued,omitempty"`
+	Cooldowns         map[string]time.Duration `json:"cooldowns_ns,omitempty"` // Remaining, for commands cooling down
+	CooldownLengths   map[string]time.Duration `json:"cooldown_lengths_ns"`    // In full, for every command with a cooldown
+	PowerCosts        map[string]int           `json:"power_costs"`            // For every command that draws power
+	Matrix            ActionMatrix             `json:"matrix"`                 // Which systems each command works on
+	Inventory         map[string]int           `json:"inventory"`
+	FuseArmed         bool                     `json:"fuse_armed,omitempty"`
+	Radiation         float64                  `json:"radiation"`
+	RadiationExposure time.Duration            `json:"radiation_exposure_ns"` // Consecutive time above RadiationLimit
+	Meltdown          time.Duration            `json:"meltdown_ns,omitempty"` // Left before the imminent meltdown, 0 while none looms
+	Morale            int                      `json:"morale"`
+	Undos             int                      `json:"undos"` // Left of UndoLimit
+	Crisis            *CrisisState             `json:"crisis,omitempty"`
+	Code              *CodeState               `json:"code,omitempty"`
+	Minigame          *MinigameState           `json:"minigame,omitempty"`
+	Saboteur          *SaboteurState           `json:"saboteur,omitempty"`
+	Conditions        *ConditionsState         `json:"conditions,omitempty"`
+	Transmission      *TransmissionState       `json:"transmission,omitempty"`
+	Rules             []Rule                   `json:"rules,omitempty"`
+	Crew              []CrewState              `json:"crew,omitempty"` // Every operator, in a co-op game
+	Versus            *VersusState             `json:"versus,omitempty"`
+	Vote              *VoteState               `json:"vote,omitempty"`       // The chat's vote on the next event
+	Incoming          *IncomingState           `json:"incoming,omitempty"`   // The next event, once picked up ahead
+	Alarms            []Alarm                  `json:"alarms,omitempty"`     // Unacknowledged, oldest first
+	LogView           *LogViewState            `json:"log_view,omitempty"`   // The page of the full log being read, instead of the dashboard
+	TrendView         *TrendViewState          `json:"trend_view,omitempty"` // The trend graph being read, instead of the dashboard
+	HelpView          *HelpViewState           `json:"help_view,omitempty"`  // The help page being read, instead of the dashboard
+	Tutorial          *TutorialState           `json:"tutorial,omitempty"`   // The tutorial step waiting for the player
+	Inspection        *InspectionState         `json:"inspection,omitempty"` // Once announced
+	Story             bool                     `json:"story,omitempty"`      // Story transmissions are on
+	Schematic         bool                     `json:"schematic,omitempty"`  // The schematic is shown instead of the status table
+	Advisor           bool                     `json:"advisor,omitempty"`    // The advisor's recommendation is shown
+	View              string                   `json:"view,omitempty"`       // How the status table draws the systems, "" for bars
+	Sandbox           bool                     `json:"sandbox,omitempty"`
+	Ironman           bool                     `json:"ironman,omitempty"`
+	Practice          bool                     `json:"practice,omitempty"`
+	RewindSpan        time.Duration            `json:"rewind_span_ns,omitempty"` // How far back a practice run can rewind
+	Autopilot         string                   `json:"autopilot,omitempty"`      // The policy flying the reactor, while the autopilot is on
+	Assisted          bool                     `json:"assisted,omitempty"`       // The autopilot has flown the run
+	Spectators        []SpectatorMessage       `json:"spectators,omitempty"`     // The spectators' latest messages, oldest first
+	SpectatorChat     string                   `json:"spectator_chat,omitempty"` // "on" or "off" with the status API up, "" without
+	DebugConsole      bool                     `json:"debug_console,omitempty"`
+	Timescale         float64                  `json:"timescale"`
+}
+
+// SystemState is one system as the player sees it: Reading is the sensor
+// value, which is noisy during a sensor fault.
+type SystemState struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Reading     int     `json:"reading"`
+	MaxValue    int     `json:"max_value"`
+	Warning     int     `json:"warning_threshold"`
+	Critical    int     `json:"critical_threshold"`
+	Offline     bool    `json:"offline"`
+	SensorFault bool    `json:"sensor_fault"`
+	Cascading   bool    `json:"cascading"`              // Dragged down by a failing dependency
+	Thermal     bool    `json:"thermal,omitempty"`      // Driven by the thermal model
+	ThermalRate float64 `json:"thermal_rate,omitempty"` // Current trend per tick
+	Trend       []int   `json:"trend,omitempty"`        // The latest readings, oldest first, for the sparkline
+}
+
+// Snapshot copies the game state in one turn of the game loop, so nothing
+// changes halfway through and the copy is internally consistent. Once the
+// loop has stopped the state is frozen and is copied directly.
+func (g *Game) Snapshot() GameState {
+	return g.SnapshotFor(nil)
+}
+
+// SnapshotFor is the game state as seen by op, a member of a co-op crew:
+// the current action, queue and cooldowns are op's. nil stands for the host.
+func (g *Game) SnapshotFor(op *Operator) GameState {
+	var s GameState
+	taken := false
+	g.call(func() {
+		s = g.snapshot(op)
+		taken = true
+	})
+	if !taken {
+		s = g.snapshot(op)
+	}
+	return s
+}
+
+func (g *Game) snapshot(op *Operator) GameState {
+	s := GameState{
+		Unit:              g.Unit,
+		Mode:              g.Mode,
+		Difficulty:        g.Difficulty.Name,
+		Seed:              g.Seed,
+		Mutators:          mutatorList(g.Mutators),
+		CampaignLevel:     g.CampaignLevel,
+		Objective:         g.objectiveText(),
+		Duration:          g.Config.GameDuration,
+		Tick:              g.degradationTick(),
+		MaxValue:          g.Config.MaxSystemValue,
+		MinValue:          g.Config.MinSystemValue,
+		WarningThreshold:  g.Config.WarningThreshold,
+		CriticalThreshold: g.Config.CriticalThreshold,
+		CooldownLengths:   g.Config.Keys.cooldownLengths(),
+		PowerCosts:        g.powerCosts(),
+		Matrix:            g.matrix,
+		Sandbox:           g.Sandbox,
+		Systems:           make([]SystemState, len(g.Systems)),
+	}
+	if g.Scenario != nil {
+		s.Scenario = g.Scenario.Name
+	}
+	for i, sys := range g.Systems { // Before g.mu: systems are locked first, as everywhere else
+		sys.locked(func() {
+			s.Systems[i] = SystemState{
+				ID: sys.ID, Name: sys.Name, Reading: sys.Value, MaxValue: sys.MaxValue, Warning: sys.WarningThreshold, Critical: sys.CriticalThreshold,
+				Offline: sys.Offline, SensorFault: sys.sensorFaultLocked(),
+			}
+			if s.Systems[i].SensorFault {
+				s.Systems[i].Reading = sys.Reported
+			}
+		})
+	}
+
+	var rival matchSide
+	if g.rival != nil { // Before g.mu: the two games never hold each other's lock
+		rival = g.rival.matchSide()
+	}
+
+	now := g.clock.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	end := now
+	if !g.EndTime.IsZero() {
+		end = g.EndTime
+	}
+	s.Elapsed = g.elapsedAtLocked(end)
+	s.Score = g.scoreLocked(s.Elapsed)
+	s.Level = g.Level
+	if g.phase != nil {
+		s.Phase = g.phase.Name
+	}
+	if hasMutator(g.Mutators, MutatorAdaptive) {
+		s.Pressure = g.pressure
+	}
+	s.GameOver, s.GameWon, s.Scrammed = g.GameOver, g.GameWon, g.Scrammed
+	s.Log = append([]LogEntry(nil), g.EventLog...)
+	s.LogCount = g.logCount
+	s.Alarms = append([]Alarm(nil), g.Alarms...)
+	if op == nil {
+		op = g.operators[0]
+	}
+	s.Action, s.Queued = op.PlayerAction, op.QueuedCommand
+	s.LogView = g.logViewLocked(op)
+	s.TrendView = g.trendViewLocked(op)
+	s.HelpView = g.helpViewLocked(op, s.Objective)
+	s.Tutorial = g.tutorialLocked()
+	s.Inspection = g.inspectionLocked(s.Elapsed)
+	s.Story, s.Transmission = g.story != nil, g.transmissionLocked(s.Elapsed)
+	s.Schematic, s.Advisor, s.View = op.schematicOpen, op.advisorOpen, op.view
+	s.Spectators, s.SpectatorChat = g.spectators.state()
+	if g.autopilot != nil {
+		s.Autopilot = g.autopilot.policy.Name()
+	}
+	s.Assisted = g.assisted
+	s.Ironman = g.ironman != nil
+	s.Practice, s.RewindSpan = g.practice != nil, g.rewindSpanLocked(s.Elapsed)
+	for i := range s.Systems {
+		s.Systems[i].Trend = g.sparklineLocked(i)
+	}
+	if s.Action != "" && op.ActionEndTime.After(now) {
+		s.ActionLeft = op.ActionEndTime.Sub(now)
+	}
+	for command, until := range op.cooldownUntil {
+		if until.After(now) {
+			if s.Cooldowns == nil {
+				s.Cooldowns = make(map[string]time.Duration)
+			}
+			s.Cooldowns[command] = until.Sub(now)
+		}
+	}
+	s.Inventory = make(map[string]int, len(g.Inventory))
+	for name, n := range g.Inventory {
+		s.Inventory[name] = n
+	}
+	s.FuseArmed = g.fuseArmed
+	s.Radiation, s.RadiationExposure = g.Radiation, g.radiationHigh
+	s.Meltdown = g.meltdownLeftLocked(s.Elapsed)
+	s.Morale = g.Morale
+	s.Undos = UndoLimit - g.undosUsed
+	s.Crisis = g.crisisSnapshotLocked(now)
+	s.Code = g.codeSnapshotLocked(now)
+	s.Minigame = g.minigameSnapshotLocked(now)
+	s.Saboteur = g.saboteurSnapshotLocked()
+	s.Conditions = g.conditionsSnapshotLocked()
+	s.Vote = g.voteSnapshotLocked(now)
+	s.Incoming = g.incomingSnapshotLocked(now)
+	for _, rule := range g.rules {
+		s.Rules = append(s.Rules, Rule{Condition: rule.Condition, Action: rule.Action})
+	}
+	if len(g.operators) > 1 {
+		for _, member := range g.operators {
+			c := CrewState{Name: member.Name, Action: member.PlayerAction}
+			if c.Action != "" && member.ActionEndTime.After(now) {
+				c.ActionLeft = member.ActionEndTime.Sub(now)
+			}
+			s.Crew = append(s.Crew, c)
+		}
+	}
+	for i, link := range g.dependencies {
+		if g.cascadeActive[i] && link.target != nil && link.ExtraDegradation > 0 {
+			s.Systems[link.target.ID].Cascading = true
+		}
+	}
+	if g.thermal != nil {
+		s.Systems[g.thermal.core.ID].Thermal = true
+		s.Systems[g.thermal.core.ID].ThermalRate = g.thermal.Rate
+	}
+	if g.rival != nil {
+		me := matchSide{Name: g.operators[0].Name, Score: s.Score.Total, Ended: g.GameOver || g.GameWon, MeltedDown: g.GameOver && !g.Scrammed}
+		s.Versus = &VersusState{
+			Rival:           rival.Name,
+			RivalScore:      rival.Score,
+			RivalEnded:      rival.Ended,
+			RivalMeltedDown: rival.MeltedDown,
+			Points:          g.sabotagePointsLocked(s.Score.Total),
+			Result:          matchResult(me, rival),
+		}
+	}
+	s.DebugConsole = g.debugConsole
+	s.Timescale = g.timescale
+	if s.Timescale == 0 {
+		s.Timescale = 1
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strings"
+)
+
+// Sound cues. A game counts the cues it raises; each session at the
+// controls rings the terminal bell when the count moves on, and can also run
+// a command to play a sound. --silent turns both off.
+type Cue string
+
+const (
+	CueAlarm     Cue = "alarm"     // A critical alarm was raised
+	CueEvent     Cue = "event"     // An event struck
+	CueAction    Cue = "action"    // A timed action completed
+	CueCountdown Cue = "countdown" // Each of the last CountdownCues seconds of the run
+)
+
+// CountdownCues is how many seconds before the end the countdown starts.
+const CountdownCues = 10
+
+// SoundConfig picks the cues that sound, and how.
+type SoundConfig struct {
+	Bell      bool     `toml:"bell"`    // Ring the terminal bell
+	Command   []string `toml:"command"` // Plays a sound; "{cue}" in an argument becomes the cue's name
+	Alarms    bool     `toml:"alarms"`
+	Events    bool     `toml:"events"`
+	Actions   bool     `toml:"actions"`
+	Countdown bool     `toml:"countdown"`
+}
+
+func defaultSound() SoundConfig {
+	return SoundConfig{Bell: true, Alarms: true, Events: true, Actions: true, Countdown: true}
+}
+
+func (c SoundConfig) wants(cue Cue) bool {
+	switch cue {
+	case CueAlarm:
+		return c.Alarms
+	case CueEvent:
+		return c.Events
+	case CueAction:
+		return c.Actions
+	case CueCountdown:
+		return c.Countdown
+	}
+	return false
+}
+
+// cue raises a sound cue, if the config wants it.
+func (g *Game) cue(c Cue) {
+	if !g.Config.Sound.wants(c) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cues++
+	g.lastCue = c
+}
+
+// countdownCue raises a cue for each of the last CountdownCues seconds of a
+// timed run. It must run on the game loop.
+func (g *Game) countdownCue() {
+	if g.Mode == ModeEndless || g.ended() {
+		return
+	}
+	left := int(math.Ceil((g.Config.GameDuration - g.Elapsed()).Seconds()))
+	if left < 1 || left > CountdownCues || left == g.countdownMark {
+		return
+	}
+	g.countdownMark = left
+	g.cue(CueCountdown)
+}
+
+// cueState returns how many cues the game has raised, and the latest.
+func (g *Game) cueState() (int, Cue) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cues, g.lastCue
+}
+
+// soundCues sounds the cues the plant's units have raised since the count
+// seen, and returns the new count. play runs the configured sound command
+// as well as ringing the bell in w.
+func (p *Plant) soundCues(w io.Writer, seen int, play bool) int {
+	total, last := 0, Cue("")
+	for _, unit := range p.Units {
+		n, c := unit.cueState()
+		total += n
+		if c != "" {
+			last = c
+		}
+	}
+	if total == seen {
+		return total
+	}
+	cfg := p.Units[0].Config.Sound
+	if cfg.Bell {
+		fmt.Fprint(w, "\a")
+	}
+	if play && len(cfg.Command) > 0 {
+		playSound(cfg.Command, last)
+	}
+	return total
+}
+
+// playSound runs command for cue in the background. A missing player is not
+// worth interrupting the game for.
+func playSound(command []string, cue Cue) {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = strings.ReplaceAll(arg, "{cue}", string(cue))
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+}
+
+func (c SoundConfig) validate() error {
+	if len(c.Command) > 0 && c.Command[0] == "" {
+		return fmt.Errorf("sound command must start with a program")
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Spectator chat. The spectators of the status API (--http) can send short
+// messages from the spectator page; they show in a SPECTATORS panel beside
+// the log, for the player and every other spectator. Spectators only talk:
+// nothing they send reaches the reactor. Each address may send a message
+// every interval, however many pages it has open, cut to max_length, with
+// the words of the filter masked. The player turns the chat off with
+// `spectators`, or mutes a spectator by the name they last spoke under:
+// the mute holds the address they spoke from, whatever name it gives next.
+// Sessions over SSH have no spectators, as --http serves only the host's
+// own game.
+
+const (
+	SpectatorLines     = 5   // Messages the panel keeps, the latest
+	SpectatorNameMax   = 16  // Runes of a spectator's name
+	SpectatorMaxLength = 200 // Largest max_length a config may set
+)
+
+// SpectatorConfig sets up the spectators' chat.
+type SpectatorConfig struct {
+	Chat      bool          `toml:"chat"`       // Spectators may send messages, until the player turns it off
+	Interval  time.Duration `toml:"interval"`   // Between two messages of one spectator, at least
+	MaxLength int           `toml:"max_length"` // Runes of a message; longer ones are cut
+	Filter    []string      `toml:"filter"`     // Words masked in messages and names, in any case
+}
+
+func defaultSpectators() SpectatorConfig {
+	return SpectatorConfig{Chat: true, Interval: 5 * time.Second, MaxLength: 80}
+}
+
+func (c SpectatorConfig) validate() error {
+	switch {
+	case c.Interval <= 0:
+		return fmt.Errorf("spectators interval must be positive")
+	case c.MaxLength < 1 || c.MaxLength > SpectatorMaxLength:
+		return fmt.Errorf("spectators max_length must be within 1-%d", SpectatorMaxLength)
+	}
+	for _, word := range c.Filter {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("spectators filter cannot hold an empty word")
+		}
+	}
+	return nil
+}
+
+// SpectatorMessage is one message of the spectators' chat.
+type SpectatorMessage struct {
+	At   time.Duration `json:"at_ns"` // Elapsed game time when it was sent
+	Name string        `json:"name"`
+	Text string        `json:"text"`
+	from string        // The address it came from
+}
+
+// spectatorChat holds the spectators' messages. The units of a plant share
+// one, and it is written from the status API's goroutines, so it has a lock
+// of its own, taken after g.mu when both are.
+type spectatorChat struct {
+	mu       sync.Mutex
+	hosted   bool // The status API is up, so there are spectators to hear from
+	off      bool // The player, or the config, has turned the chat off
+	messages []SpectatorMessage
+	said     map[string]time.Time // When each address last sent a message, within the interval
+	speakers map[string]string    // The address each name last spoke from, by lowercase name
+	muted    map[string]string    // The name each muted address was muted under, by address
+}
+
+func newSpectatorChat(cfg SpectatorConfig) *spectatorChat {
+	return &spectatorChat{off: !cfg.Chat, said: make(map[string]time.Time), speakers: make(map[string]string), muted: make(map[string]string)}
+}
+
+// host marks the chat as open to the spectators of the status API.
+func (c *spectatorChat) host() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hosted = true
+}
+
+// state returns the messages to show and the chat's state: "on" or "off"
+// once hosted, "" before.
+func (c *spectatorChat) state() ([]SpectatorMessage, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case !c.hosted:
+		return nil, ""
+	case c.off:
+		return nil, "off"
+	}
+	return slices.Clone(c.messages), "on"
+}
+
+// spectatorSay posts a spectator's message from the address from, which the
+// rate limit and mutes go by; the error is for the spectator. A muted
+// spectator's messages are dropped without a word.
+func (g *Game) spectatorSay(from, name, text string) error {
+	cfg := g.Config.Spectators
+	name = maskWords(cleanChatText(name, SpectatorNameMax), cfg.Filter)
+	if name == "" {
+		name = "spectator"
+	}
+	text = maskWords(cleanChatText(text, cfg.MaxLength), cfg.Filter)
+	if text == "" {
+		return errors.New("the message is empty")
+	}
+	elapsed := g.Elapsed()
+	c := g.spectators
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.off {
+		return errors.New("the player has turned the spectator chat off")
+	}
+	now := time.Now()
+	for addr, at := range c.said {
+		if now.Sub(at) >= cfg.Interval {
+			delete(c.said, addr)
+		}
+	}
+	if _, ok := c.said[from]; ok {
+		return fmt.Errorf("slow down: one message every %.0fs", cfg.Interval.Seconds())
+	}
+	c.said[from] = now
+	if _, ok := c.muted[from]; ok {
+		return nil
+	}
+	c.speakers[strings.ToLower(name)] = from
+	c.messages = append(c.messages, SpectatorMessage{At: elapsed, Name: name, Text: text, from: from})
+	if len(c.messages) > SpectatorLines {
+		c.messages = c.messages[len(c.messages)-SpectatorLines:]
+	}
+	return nil
+}
+
+// cleanChatText trims text to a single line of at most width runes, with
+// control characters, escape sequences among them, taken out: a spectator
+// does not get to write to the player's terminal.
+func cleanChatText(text string, width int) string {
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || !unicode.IsPrint(r) && r != ' ':
+			return -1
+		}
+		return r
+	}, text)
+	return strings.TrimSpace(shorten(strings.Join(strings.Fields(text), " "), width))
+}
+
+// maskWords stars out the words of filter in text, in any case.
+func maskWords(text string, filter []string) string {
+	for _, word := range filter {
+		word = strings.TrimSpace(word)
+		re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+		text = re.ReplaceAllStringFunc(text, func(m string) string {
+			return strings.Repeat("*", len([]rune(m)))
+		})
+	}
+	return text
+}
+
+// handleSpectators turns the spectators' chat on or off, mutes or unmutes a
+// spectator, or logs its state.
+func (g *Game) handleSpectators(args []string) {
+	g.AddLog(g.spectators.command(args))
+}
+
+// command carries out the spectators command with args and returns what to
+// log.
+func (c *spectatorChat) command(args []string) (Severity, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	usage := tr("Usage: spectators [on|off|mute <name>|unmute <name>]")
+	if !c.hosted {
+		return LogInfo, tr("No spectators: Start the game with --http to let them watch and chat.")
+	}
+	if len(args) == 0 {
+		state := tr("on")
+		if c.off {
+			state = tr("off")
+		}
+		if len(c.muted) == 0 {
+			return LogInfo, tr("SPECTATORS: Chat %s. %s", state, usage)
+		}
+		muted := slices.Compact(slices.Sorted(maps.Values(c.muted)))
+		return LogInfo, tr("SPECTATORS: Chat %s, muted: %s. %s", state, strings.Join(muted, ", "), usage)
+	}
+	verb, name := strings.ToLower(args[0]), strings.Join(args[1:], " ")
+	switch {
+	case verb == "on" && name == "":
+		c.off = false
+		return LogSuccess, tr("SPECTATORS: Chat on. Spectators can send messages again.")
+	case verb == "off" && name == "":
+		c.off, c.messages = true, nil
+		return LogSuccess, tr("SPECTATORS: Chat off. The panel is cleared and new messages are refused.")
+	case verb == "mute" && name != "":
+		from, ok := c.speakers[strings.ToLower(name)]
+		if !ok {
+			return LogWarning, tr("SPECTATORS: No spectator has spoken as %s.", name)
+		}
+		c.muted[from] = name
+		c.messages = slices.DeleteFunc(c.messages, func(m SpectatorMessage) bool { return m.from == from })
+		return LogSuccess, tr("SPECTATORS: %s is muted.", name)
+	case verb == "unmute" && name != "":
+		unmuted := false
+		for from, muted := range c.muted {
+			if strings.EqualFold(muted, name) {
+				delete(c.muted, from)
+				unmuted = true
+			}
+		}
+		if !unmuted {
+			return LogWarning, tr("SPECTATORS: %s is not muted.", name)
+		}
+		return LogSuccess, tr("SPECTATORS: %s may talk again.", name)
+	}
+	return LogInfo, usage
+}
+
+// spectatorPanel renders the spectators' latest messages, or nothing for none.
+func (s GameState) spectatorPanel() []string {
+	if len(s.Spectators) == 0 {
+		return nil
+	}
+	lines := []string{"", theme.Heading.Sprint(tr("SPECTATORS:"))}
+	for _, m := range s.Spectators {
+		lines = append(lines, fmt.Sprintf("%s %s %s", theme.Dim.Sprint(formatDuration(m.At)), theme.Accent.Sprint(m.Name+":"), m.Text))
+	}
+	return lines
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	SSHHostKeyFile = "ssh_host_ed25519_key" // In the app data directory, generated on first use
+	SSHFrameBuffer = 64 << 10               // Room for a whole dashboard frame
+)
+
+// SSHServer hosts the game over SSH. Every connection plays its own plant in
+// the client's terminal; the runs are recorded in the host's high-score table.
+// A client that signs in with one of the keys a profile lists under ssh_keys
+// plays as that profile, named by the SSH user, and its runs are recorded
+// there and on the leaderboard under its name. Anyone else plays as a guest: the profile's
+// difficulty locks of a new player apply, and nothing is recorded under it.
+type SSHServer struct {
+	NewPlant    func(seed int64) (*Plant, error) // Builds the plant for a new connection
+	Seed        int64                            // Every connection plays this seed; 0 picks a fresh one each time
+	Sandbox     bool
+	Leaderboard *Leaderboard // Submits the runs recorded under a profile, under its name
+	Silent      bool         // No bell for the players
+}
+
+// ListenAndServe accepts connections on addr until the server fails.
+func (s *SSHServer) ListenAndServe(addr string) error {
+	if _, err := s.NewPlant(1); err != nil { // Report a broken scenario before anyone connects
+		return fmt.Errorf("invalid scenario: %w", err)
+	}
+	signer, err := sshHostKey()
+	if err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	srv := &ssh.Server{
+		Addr:             addr,
+		Handler:          s.handle,
+		PublicKeyHandler: func(ctx ssh.Context, key ssh.PublicKey) bool { return profileKeyAuthorized(ctx.User(), key) },
+		KeyboardInteractiveHandler: func(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
+			ctx.SetValue(ssh.ContextKeyPublicKey, nil) // A key offered earlier, and not signed with, signs no one in
+			return true                                // As a guest
+		},
+	}
+	srv.AddHostKey(signer)
+	return srv.ListenAndServe()
+}
+
+// handle plays one game over an SSH session.
+func (s *SSHServer) handle(sess ssh.Session) {
+	pty, resize, ok := sess.Pty()
+	if !ok {
+		fmt.Fprintln(sess, tr("Reactor Meltdown needs an interactive terminal: connect with ssh -t."))
+		sess.Exit(1)
+		return
+	}
+	t := term.NewTerminal(sess, "") // Echoes and edits the line being typed while the dashboard redraws
+	t.SetSize(pty.Window.Width, pty.Window.Height)
+	var sizeMu sync.Mutex
+	size := ScreenSize{pty.Window.Width, pty.Window.Height}
+	resized := make(chan struct{}, 1)
+	go func() {
+		for {
+			select {
+			case win := <-resize:
+				t.SetSize(win.Width, win.Height)
+				sizeMu.Lock()
+				size = ScreenSize{win.Width, win.Height}
+				sizeMu.Unlock()
+				select {
+				case resized <- struct{}{}:
+				default: // A redraw is already due
+				}
+			case <-sess.Context().Done():
+				return
+			}
+		}
+	}()
+
+	seed := s.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	plant, err := s.NewPlant(seed)
+	if err != nil {
+		fmt.Fprintln(t, theme.Critical.Sprint(tr("Invalid scenario: %v", err)))
+		sess.Exit(1)
+		return
+	}
+	t.AutoCompleteCallback = plant.complete
+	signedIn := sess.PublicKey() != nil && profileKeyAuthorized(sess.User(), sess.PublicKey())
+	profile, err := s.profile(sess.User(), plant.Units[0].Difficulty.Name, signedIn)
+	if err != nil {
+		fmt.Fprintln(t, theme.Critical.Sprintf("%v", err))
+		sess.Exit(1)
+		return
+	}
+	if profile == nil && !s.Sandbox {
+		fmt.Fprintln(t, theme.Warning.Sprint(tr("Playing as a guest: the run is not recorded under a profile. The host can let your key in with ssh-key.")))
+	}
+
+	var leaderboard *Leaderboard
+	if s.Leaderboard != nil && signedIn && profile != nil { // A guest's run would be signed with the host's key under a name of their choosing
+		lb := *s.Leaderboard
+		lb.Name = profile.Name
+		leaderboard = &lb
+	}
+
+	fmt.Println(theme.Title.Sprint(tr("SSH: %s connected from %s (seed %d).", sess.User(), sess.RemoteAddr(), seed)))
+	plant.Play(Session{
+		ReadLine:    t.ReadLine,
+		Out:         bufio.NewWriterSize(t, SSHFrameBuffer), // Redraw in one write, not line by line under the player's typing
+		AltScreen:   true,
+		Sandbox:     s.Sandbox,
+		Profile:     profile,
+		Leaderboard: leaderboard,
+		Silent:      s.Silent,
+		Size: func() ScreenSize {
+			sizeMu.Lock()
+			defer sizeMu.Unlock()
+			return size
+		},
+		Redraw: resized,
+	})
+	fmt.Println(theme.Title.Sprint(tr("SSH: %s from %s disconnected.", sess.User(), sess.RemoteAddr())))
+	sess.Exit(0)
+}
+
+// profile loads the profile for an SSH user, or returns nil when the runs go
+// unrecorded: in the sandbox, or for a guest, who has not signed in with one
+// of the profile's keys. A guest plays under the locks of a new profile.
+func (s *SSHServer) profile(user, difficulty string, signedIn bool) (*Profile, error) {
+	if s.Sandbox {
+		return nil, nil
+	}
+	if !signedIn {
+		return nil, guestProfile(user).checkDifficulty(difficulty)
+	}
+	profile, err := LoadProfile(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+	if err := profile.checkDifficulty(difficulty); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// profileKeyAuthorized reports whether key is one of the ssh_keys of the
+// profile named user.
+func profileKeyAuthorized(user string, key ssh.PublicKey) bool {
+	if !profileNamePattern.MatchString(user) {
+		return false
+	}
+	profile, err := LoadProfile(user)
+	if err != nil {
+		return false
+	}
+	for _, line := range profile.SSHKeys {
+		if authorized, _, _, _, err := gossh.ParseAuthorizedKey([]byte(line)); err == nil && ssh.KeysEqual(authorized, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// runSSHKey lets the public key in the file at path, as ssh-keygen writes it,
+// play as the profile over --ssh.
+func (p *Profile) runSSHKey(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: ssh-key <file.pub>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	key, comment, _, _, err := gossh.ParseAuthorizedKey(data)
+	if err != nil {
+		return fmt.Errorf("%s holds no public key: %w", args[0], err)
+	}
+	line := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
+	if comment != "" {
+		line += " " + comment
+	}
+	for _, known := range p.SSHKeys {
+		if k, _, _, _, err := gossh.ParseAuthorizedKey([]byte(known)); err == nil && ssh.KeysEqual(k, key) {
+			fmt.Println(theme.Success.Sprint(tr("SSH: The key %s already plays as %s.", gossh.FingerprintSHA256(key), p.Name)))
+			return nil
+		}
+	}
+	p.SSHKeys = append(p.SSHKeys, line)
+	if err := p.Save(); err != nil {
+		return err
+	}
+	fmt.Println(theme.Success.Sprint(tr("SSH: The key %s now plays as %s: connect with ssh %s@<host>.", gossh.FingerprintSHA256(key), p.Name, p.Name)))
+	return nil
+}
+
+// sshHostKey reads the server's host key, generating it on first use so
+// players' clients recognize the host from one run to the next.
+func sshHostKey() (gossh.Signer, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, SSHHostKeyFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := gossh.MarshalPrivateKey(key, "")
+		if err != nil {
+			return nil, err
+		}
+		data = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return gossh.ParsePrivateKey(data)
+}
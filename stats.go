@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventMemory is how far back the events leading up to the end are kept.
+const EventMemory = 30 * time.Second
+
+// RunStats accumulates the figures for the post-game report.
+type RunStats struct {
+	Commands   map[string]int  `json:"commands,omitempty"` // Per command, including those run by rules
+	KitsUsed   int             `json:"kits_used"`
+	Events     int             `json:"events"`                   // Events that hit the reactor
+	Lowest     []int           `json:"lowest,omitempty"`         // Per system, lowest value at a tick
+	InCritical []time.Duration `json:"in_critical_ns,omitempty"` // Per system, time spent critical
+	Audit      []AuditEntry    `json:"audit,omitempty"`          // Every command run, in order
+}
+
+// recordStatsTick updates the per-system figures after a degradation tick.
+// values holds each system's integrity, indexed by ID.
+func (g *Game) recordStatsTick(values []int) {
+	tick := g.degradationTick()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.Stats.Lowest) != len(values) {
+		g.Stats.Lowest = append([]int(nil), values...)
+		g.Stats.InCritical = make([]time.Duration, len(values))
+	}
+	for i, val := range values {
+		if val < g.Stats.Lowest[i] {
+			g.Stats.Lowest[i] = val
+		}
+		if val <= g.Systems[i].CriticalThreshold {
+			g.Stats.InCritical[i] += tick
+		}
+	}
+}
+
+func (g *Game) countCommand(command string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.Stats.Commands == nil {
+		g.Stats.Commands = make(map[string]int)
+	}
+	g.Stats.Commands[command]++
+}
+
+// countEvent records an event that hit the reactor, remembering those of
+// the last EventMemory for the simulation's loss analysis.
+func (g *Game) countEvent(name string) {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.Stats.Events++
+	if g.eventHits == nil {
+		g.eventHits = make(map[string]int)
+	}
+	g.eventHits[name]++
+	g.recentEvents = append(g.recentEvents, recentEvent{name, elapsed})
+	for elapsed-g.recentEvents[0].at > EventMemory {
+		g.recentEvents = g.recentEvents[1:]
+	}
+}
+
+// recentEvent is an event that hit the reactor, stamped with the elapsed time.
+type recentEvent struct {
+	name string
+	at   time.Duration
+}
+
+// precedingEvents returns, once, each event that hit in the EventMemory
+// before the run ended.
+func (g *Game) precedingEvents() []string {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	seen := make(map[string]bool)
+	var names []string
+	for _, ev := range g.recentEvents {
+		if elapsed-ev.at <= EventMemory && !seen[ev.name] {
+			seen[ev.name] = true
+			names = append(names, ev.name)
+		}
+	}
+	return names
+}
+
+// Report is the exported post-game report of one reactor unit.
+type Report struct {
+	Unit           int            `json:"unit,omitempty"`
+	Outcome        string         `json:"outcome"`
+	Won            bool           `json:"won"`
+	Score          int            `json:"score"`
+	Seconds        float64        `json:"seconds_survived"`
+	Difficulty     string         `json:"difficulty"`
+	Mode           string         `json:"mode"`
+	Seed           int64          `json:"seed"`
+	Mutators       []string       `json:"mutators,omitempty"`
+	Commands       map[string]int `json:"commands"`
+	TotalCommands  int            `json:"total_commands"`
+	KitsUsed       int            `json:"kits_used"`
+	EventsSurvived int            `json:"events_survived"`
+	Systems        []SystemReport `json:"systems"`
+	Audit          []AuditEntry   `json:"audit"` // Every command run, in order
+}
+
+// SystemReport is one system's line of the report.
+type SystemReport struct {
+	Name            string  `json:"name"`
+	DamageTaken     int     `json:"damage_taken"`
+	Lowest          int     `json:"lowest"`
+	CriticalSeconds float64 `json:"critical_seconds"`
+}
+
+// Report compiles the run statistics.
+func (g *Game) Report() Report {
+	elapsed := g.Elapsed()
+	score := g.CurrentScore().Total
+	outcome := g.outcomeText()
+	g.mu.Lock()
+	r := Report{
+		Unit:           g.Unit,
+		Outcome:        outcome,
+		Won:            g.GameWon,
+		Score:          score,
+		Seconds:        elapsed.Seconds(),
+		Difficulty:     g.Difficulty.Name,
+		Mode:           g.Mode,
+		Seed:           g.Seed,
+		Mutators:       mutatorList(g.Mutators),
+		Commands:       make(map[string]int, len(g.Stats.Commands)),
+		KitsUsed:       g.Stats.KitsUsed,
+		EventsSurvived: g.Stats.Events,
+		Audit:          append([]AuditEntry(nil), g.Stats.Audit...),
+	}
+	for command, n := range g.Stats.Commands {
+		r.Commands[command] = n
+		r.TotalCommands += n
+	}
+	lowest := append([]int(nil), g.Stats.Lowest...)
+	inCritical := append([]time.Duration(nil), g.Stats.InCritical...)
+	g.mu.Unlock()
+
+	for i, sys := range g.Systems {
+		sys.mu.Lock()
+		sr := SystemReport{Name: sys.Name, DamageTaken: sys.Damage, Lowest: sys.Value}
+		sys.mu.Unlock()
+		if i < len(lowest) && lowest[i] < sr.Lowest {
+			sr.Lowest = lowest[i]
+		}
+		if i < len(inCritical) {
+			sr.CriticalSeconds = inCritical[i].Seconds()
+		}
+		r.Systems = append(r.Systems, sr)
+	}
+	return r
+}
+
+// sortedCommands lists the report's commands, most used first.
+func (r Report) sortedCommands() []string {
+	names := make([]string, 0, len(r.Commands))
+	for name := range r.Commands {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.Commands[names[i]] != r.Commands[names[j]] {
+			return r.Commands[names[i]] > r.Commands[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// printStats renders the statistics section of the summary screen.
+func (g *Game) printStats(w io.Writer) {
+	r := g.Report()
+	fmt.Fprintln(w, theme.Heading.Sprint(tr("RUN STATISTICS:")))
+	used := make([]string, 0, len(r.Commands))
+	for _, name := range r.sortedCommands() {
+		used = append(used, fmt.Sprintf("%s %d", name, r.Commands[name]))
+	}
+	fmt.Fprint(w, tr("  Commands issued: %d", r.TotalCommands))
+	if len(used) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(used, ", "))
+	}
+	fmt.Fprint(w, tr("\n  Repair kits used: %d  Events survived: %d\n", r.KitsUsed, r.EventsSurvived))
+	fmt.Fprintf(w, "  %-18s %7s %7s %9s\n", "SYSTEM", "DAMAGE", "LOWEST", "CRITICAL")
+	for _, sr := range r.Systems {
+		fmt.Fprintf(w, "  %-18s %7d %7d %9s\n", tr(sr.Name), sr.DamageTaken, sr.Lowest, formatDuration(time.Duration(sr.CriticalSeconds*float64(time.Second))))
+	}
+	if len(r.Audit) > 0 {
+		fmt.Fprintln(w, theme.Heading.Sprint(tr("LAST COMMANDS:")))
+		for _, line := range auditLines(r.Audit[max(0, len(r.Audit)-AuditLastLines):]) {
+			fmt.Fprintln(w, line)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// reportFormat picks the export format from the file extension.
+func reportFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return "json", nil
+	case ".md", ".markdown":
+		return "markdown", nil
+	default:
+		return "", fmt.Errorf("report file %s must end in .json or .md", path)
+	}
+}
+
+// WriteReport exports reports to path as JSON or Markdown, depending on its
+// extension. A single unit is written as one object, a plant as an array.
+func WriteReport(path string, reports []Report) error {
+	format, err := reportFormat(path)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if format == "json" {
+		var v any = reports
+		if len(reports) == 1 {
+			v = reports[0]
+		}
+		if data, err = json.MarshalIndent(v, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		var b strings.Builder
+		for _, r := range reports {
+			r.writeMarkdown(&b)
+		}
+		data = []byte(b.String())
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (r Report) writeMarkdown(b *strings.Builder) {
+	if r.Unit > 0 {
+		fmt.Fprint(b, tr("# Reactor Unit %d Report\n\n", r.Unit))
+	} else {
+		fmt.Fprint(b, tr("# Reactor Report\n\n"))
+	}
+	fmt.Fprint(b, tr("- **Outcome:** %s\n", r.Outcome))
+	fmt.Fprint(b, tr("- **Score:** %d\n", r.Score))
+	fmt.Fprint(b, tr("- **Time survived:** %s\n", formatDuration(time.Duration(r.Seconds*float64(time.Second)))))
+	fmt.Fprint(b, tr("- **Difficulty:** %s, **Mode:** %s, **Seed:** %d\n", r.Difficulty, r.Mode, r.Seed))
+	if len(r.Mutators) > 0 {
+		fmt.Fprint(b, tr("- **Mutators:** %s\n", strings.Join(r.Mutators, ", ")))
+	}
+	fmt.Fprint(b, tr("- **Repair kits used:** %d\n", r.KitsUsed))
+	fmt.Fprint(b, tr("- **Events survived:** %d\n\n", r.EventsSurvived))
+
+	fmt.Fprint(b, tr("## Commands (%d issued)\n\n", r.TotalCommands))
+	if len(r.Commands) == 0 {
+		fmt.Fprint(b, tr("No commands issued.\n\n"))
+	} else {
+		fmt.Fprint(b, tr("| Command | Count |\n| --- | ---: |\n"))
+		for _, name := range r.sortedCommands() {
+			fmt.Fprintf(b, "| %s | %d |\n", name, r.Commands[name])
+		}
+		fmt.Fprintln(b)
+	}
+
+	fmt.Fprint(b, tr("## Systems\n\n| System | Damage taken | Lowest value | Time in critical |\n| --- | ---: | ---: | ---: |\n"))
+	for _, sr := range r.Systems {
+		fmt.Fprintf(b, "| %s | %d | %d | %.0fs |\n", sr.Name, sr.DamageTaken, sr.Lowest, sr.CriticalSeconds)
+	}
+	fmt.Fprintln(b)
+
+	fmt.Fprint(b, tr("## Command history\n\n"))
+	if len(r.Audit) == 0 {
+		fmt.Fprint(b, tr("No commands issued.\n\n"))
+		return
+	}
+	fmt.Fprint(b, tr("| Time | By | Command | Target | Outcome |\n| --- | --- | --- | --- | --- |\n"))
+	for _, e := range r.Audit {
+		by := e.Operator
+		if e.Rule > 0 {
+			by = tr("rule %d", e.Rule)
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", formatDuration(e.At), by, e.Line(), e.Target, strings.ReplaceAll(e.Outcome, "|", "\\|"))
+	}
+	fmt.Fprintln(b)
+}
+
+// writeReport exports the report of every unit and notes the result on the
+// summary screen.
+func (p *Plant) writeReport(path string) {
+	reports := make([]Report, len(p.Units))
+	for i, unit := range p.Units {
+		reports[i] = unit.Report()
+	}
+	note := theme.Info.Sprint(tr("Run report written to %s.", path))
+	if err := WriteReport(path, reports); err != nil {
+		note = theme.Critical.Sprint(tr("Failed to write report: %v", err))
+	}
+	for _, unit := range p.Units {
+		unit.mu.Lock()
+		unit.reportNote = note
+		unit.mu.Unlock()
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Story. --story plays the run with transmissions from the Plant Director
+// and Off-site Emergency Services: some arrive at set times, others the
+// first time the plant gets into a kind of trouble. Each one shows on the
+// dashboard in a panel of its own and goes into the log, and now and then
+// one asks for a decision, answered with respond yes|no before it lapses.
+
+const (
+	TransmissionTime  = 12 * time.Second // A transmission stays on the dashboard this long
+	StoryChoiceTime   = 20 * time.Second // Time to respond before a decision lapses as no
+	StoryOpeningTime  = 5 * time.Second  // When the first transmission arrives
+	StoryFinalStretch = 60 * time.Second // Before the end of a classic run, the last one
+
+	DemonstrationDamage = 15 // Taken by the healthiest system to put on the demonstration
+	RepairTeamKits      = 2  // Brought by the mobile repair team
+	RepairTeamRadiation = 10 // Released letting the repair team in
+	BypassBoost         = 20 // Restored to the weakest system by bypassing its interlocks
+	BypassLockout       = 60 * time.Second
+)
+
+// storyChoice is the decision a transmission asks for. accept runs on yes;
+// no, or no answer in time, changes nothing. The script is built once the
+// language is set, so its texts are translated already.
+type storyChoice struct {
+	accept func(g *Game)
+	yes    string // The sender's reply to each answer
+	no     string
+}
+
+// transmission is one message of the story, sent once when due first
+// reports true. due runs on the game loop without g.mu held.
+type transmission struct {
+	name   string // Stored in saves
+	sender string
+	due    func(g *Game, elapsed time.Duration) bool
+	lines  func(g *Game) []string
+	choice *storyChoice // nil for a message that needs no answer
+}
+
+func storyScript() []transmission {
+	return []transmission{
+		{
+			name:   "opening",
+			sender: tr("Plant Director"),
+			due:    func(g *Game, elapsed time.Duration) bool { return elapsed >= StoryOpeningTime },
+			lines: func(g *Game) []string {
+				return []string{tr("Morning, operator. The grid is counting on this unit today. Keep her steady and we all go home.")}
+			},
+		},
+		{
+			name:   "first_offline",
+			sender: tr("Plant Director"),
+			due:    func(g *Game, _ time.Duration) bool { return g.anyOffline() },
+			lines: func(g *Game) []string {
+				return []string{tr("A system just went dark. Reboot it before the others are dragged down with it.")}
+			},
+		},
+		{
+			name:   "first_critical",
+			sender: tr("Off-site Emergency Services"),
+			due: func(g *Game, _ time.Duration) bool {
+				g.mu.Lock()
+				defer g.mu.Unlock()
+				return g.Score.Criticals > 0
+			},
+			lines: func(g *Game) []string {
+				weakest := g.weakestSystem()
+				return []string{
+					tr("Our board shows %s (%d) in the red. We can send a mobile repair team with %d repair kits,", weakest.Name, weakest.ID, RepairTeamKits),
+					tr("but opening the gates for them releases %d radiation. Send them?", RepairTeamRadiation),
+				}
+			},
+			choice: &storyChoice{
+				accept: func(g *Game) {
+					g.addItem(ItemRepairKit, RepairTeamKits)
+					g.addRadiation(RepairTeamRadiation)
+				},
+				yes: tr("Team dispatched. They will be through the gates with the kits shortly."),
+				no:  tr("Understood. We stand by."),
+			},
+		},
+		{
+			name:   "first_failed_override",
+			sender: tr("Plant Director"),
+			due: func(g *Game, _ time.Duration) bool {
+				g.mu.Lock()
+				defer g.mu.Unlock()
+				return g.Score.FailedOverrides > 0
+			},
+			lines: func(g *Game) []string {
+				return []string{
+					tr("That override cost us. Engineering can bypass the interlocks on the weakest system: it comes back %d,", BypassBoost),
+					tr("but override stays locked for %.0fs while they work. Authorize it?", BypassLockout.Seconds()),
+				}
+			},
+			choice: &storyChoice{
+				accept: func(g *Game) {
+					weakest := g.weakestSystem()
+					weakest.Boost(BypassBoost)
+					g.setCooldown(CommandOverride, BypassLockout)
+					g.AddLog(LogSuccess, tr("Interlocks bypassed: %s (%d) +%d.", weakest.Name, weakest.ID, BypassBoost))
+				},
+				yes: tr("Engineering is on it. Keep your hands off override."),
+				no:  tr("Your call. Don't make me regret it."),
+			},
+		},
+		{
+			name:   "demonstration",
+			sender: tr("Plant Director"),
+			due:    func(g *Game, elapsed time.Duration) bool { return elapsed >= g.Config.GameDuration/3 },
+			lines: func(g *Game) []string {
+				healthiest := g.healthiestSystem()
+				return []string{
+					tr("The ministry is touring the control room. Headquarters wants %s pushed for the cameras:", healthiest.Name),
+					tr("it takes %d damage, and they will send a repair kit as thanks. Will you do it?", DemonstrationDamage),
+				}
+			},
+			choice: &storyChoice{
+				accept: func(g *Game) {
+					g.harmLogged(g.healthiestSystem(), DemonstrationDamage)
+					g.addItem(ItemRepairKit, 1)
+				},
+				yes: tr("The ministry is delighted. The repair kit is on its way."),
+				no:  tr("I'll tell them the plant comes first."),
+			},
+		},
+		{
+			name:   "final_stretch",
+			sender: tr("Off-site Emergency Services"),
+			due: func(g *Game, elapsed time.Duration) bool {
+				return g.Mode == ModeClassic && elapsed >= g.Config.GameDuration-StoryFinalStretch
+			},
+			lines: func(g *Game) []string {
+				return []string{tr("The relief shift is on the road. Hold the plant for one more minute.")}
+			},
+		},
+	}
+}
+
+// story is the state of a --story run.
+type story struct {
+	script  []transmission
+	sent    map[string]bool
+	current *transmission // On the dashboard, nil when none is
+	lines   []string      // Of current, as sent
+	until   time.Duration // Elapsed time current leaves the dashboard, or its decision lapses
+	pending bool          // current waits for a reply
+}
+
+// startStory turns on story transmissions.
+func (g *Game) startStory() {
+	g.story = &story{script: storyScript(), sent: map[string]bool{}}
+}
+
+// updateStory lapses an unanswered decision and sends the next transmission
+// that is due. Nothing new arrives while a decision is open. It runs from
+// the event loop.
+func (g *Game) updateStory() {
+	g.mu.Lock()
+	s := g.story
+	g.mu.Unlock()
+	if s == nil {
+		return
+	}
+	elapsed := g.Elapsed()
+	var lapsed *transmission
+	g.locked(func() {
+		if s.current != nil && elapsed >= s.until {
+			if s.pending {
+				lapsed = s.current
+			}
+			s.current, s.pending = nil, false
+		}
+	})
+	if lapsed != nil {
+		g.AddLog(LogTransmission, tr("TRANSMISSION (%s): No reply. %s", lapsed.sender, lapsed.choice.no))
+		return
+	}
+	if g.storyWaiting() {
+		return
+	}
+	for i := range s.script {
+		t := &s.script[i]
+		if s.sent[t.name] || !t.due(g, elapsed) {
+			continue
+		}
+		g.sendTransmission(t, t.lines(g), elapsed)
+		return
+	}
+}
+
+// sendTransmission puts t on the dashboard and in the log.
+func (g *Game) sendTransmission(t *transmission, lines []string, elapsed time.Duration) {
+	g.locked(func() {
+		s := g.story
+		s.sent[t.name] = true
+		s.current, s.lines, s.pending = t, lines, t.choice != nil
+		s.until = elapsed + TransmissionTime
+		if s.pending {
+			s.until = elapsed + StoryChoiceTime
+		}
+	})
+	g.AddLog(LogTransmission, tr("TRANSMISSION (%s): %s", t.sender, strings.Join(lines, " ")))
+	if t.choice != nil {
+		g.AddLog(LogTransmission, tr("TRANSMISSION: Type respond yes or respond no within %.0fs.", StoryChoiceTime.Seconds()))
+	}
+}
+
+// storyWaiting reports whether a decision is waiting for a reply.
+func (g *Game) storyWaiting() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.story != nil && g.story.pending
+}
+
+// handleRespond answers the decision on the dashboard.
+func (g *Game) handleRespond(args []string) {
+	if len(args) != 1 || !isStoryAnswer(args[0]) {
+		g.AddLog(LogInfo, tr("Usage: respond yes|no"))
+		return
+	}
+	g.mu.Lock()
+	s := g.story
+	var t *transmission
+	if s != nil && s.pending {
+		t = s.current
+		s.current, s.pending = nil, false
+	}
+	g.mu.Unlock()
+	if t == nil {
+		g.AddLog(LogWarning, tr("Cannot respond: No transmission is waiting for a reply."))
+		return
+	}
+	if args[0] != "yes" && args[0] != strings.ToLower(tr("yes")) {
+		g.AddLog(LogTransmission, tr("TRANSMISSION (%s): %s", t.sender, t.choice.no))
+		return
+	}
+	g.AddLog(LogTransmission, tr("TRANSMISSION (%s): %s", t.sender, t.choice.yes))
+	t.choice.accept(g)
+}
+
+// isStoryAnswer reports whether answer is yes or no, in English or the
+// language of the game.
+func isStoryAnswer(answer string) bool {
+	for _, a := range []string{"yes", "no", tr("yes"), tr("no")} {
+		if answer == strings.ToLower(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyOffline reports whether a system is OFFLINE.
+func (g *Game) anyOffline() bool {
+	for _, sys := range g.Systems {
+		if sys.IsOffline() {
+			return true
+		}
+	}
+	return false
+}
+
+// weakestSystem returns the online system with the lowest value, or the
+// first system when all are offline.
+func (g *Game) weakestSystem() *System {
+	return g.pickSystem(func(a, b int) bool { return a < b })
+}
+
+// healthiestSystem returns the online system with the highest value.
+func (g *Game) healthiestSystem() *System {
+	return g.pickSystem(func(a, b int) bool { return a > b })
+}
+
+func (g *Game) pickSystem(better func(a, b int) bool) *System {
+	best, bestValue := g.Systems[0], 0
+	found := false
+	for _, sys := range g.Systems {
+		sys.mu.Lock()
+		value, offline := sys.Value, sys.Offline
+		sys.mu.Unlock()
+		if !offline && (!found || better(value, bestValue)) {
+			best, bestValue, found = sys, value, true
+		}
+	}
+	return best
+}
+
+// TransmissionState is the transmission on the dashboard, while one is.
+type TransmissionState struct {
+	Sender string        `json:"sender"`
+	Lines  []string      `json:"lines"`
+	Choice bool          `json:"choice,omitempty"` // Waiting for respond yes|no
+	Left   time.Duration `json:"left_ns"`          // Until it lapses or leaves the dashboard
+}
+
+// transmissionLocked copies the transmission on the dashboard at elapsed,
+// or returns nil if there is none. The caller must hold g.mu.
+func (g *Game) transmissionLocked(elapsed time.Duration) *TransmissionState {
+	if g.story == nil || g.story.current == nil {
+		return nil
+	}
+	s := g.story
+	return &TransmissionState{
+		Sender: s.current.sender,
+		Lines:  append([]string(nil), s.lines...),
+		Choice: s.pending,
+		Left:   s.until - elapsed,
+	}
+}
+
+// transmissionPanel renders the transmission on the dashboard, or "".
+func (s GameState) transmissionPanel() string {
+	t := s.Transmission
+	if t == nil {
+		return ""
+	}
+	lines := []string{theme.Accent.With(color.Bold).Sprint(tr(">>> INCOMING TRANSMISSION: %s <<<", t.Sender))}
+	for _, line := range t.Lines {
+		lines = append(lines, theme.Highlight.Sprint("  "+line))
+	}
+	if t.Choice {
+		lines = append(lines, theme.Caution.Sprint(tr(" RESPOND yes|no within %ds ", ceilSeconds(t.Left))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SavedStory is the serialized form of a story.
+type SavedStory struct {
+	Sent    []string      `json:"sent,omitempty"`
+	Current string        `json:"current,omitempty"`
+	Lines   []string      `json:"lines,omitempty"`
+	Left    time.Duration `json:"left_ns,omitempty"`
+	Pending bool          `json:"pending,omitempty"`
+}
+
+// storyStateLocked captures the story at elapsed, or returns nil without
+// one. The caller must hold g.mu.
+func (g *Game) storyStateLocked(elapsed time.Duration) *SavedStory {
+	s := g.story
+	if s == nil {
+		return nil
+	}
+	st := &SavedStory{}
+	for _, t := range s.script {
+		if s.sent[t.name] {
+			st.Sent = append(st.Sent, t.name)
+		}
+	}
+	if s.current != nil {
+		st.Current, st.Lines, st.Left, st.Pending = s.current.name, s.lines, s.until-elapsed, s.pending
+	}
+	return st
+}
+
+// restoreStory rebuilds the story of a save made elapsed into the run.
+func restoreStory(st SavedStory, elapsed time.Duration) (*story, error) {
+	s := &story{script: storyScript(), sent: map[string]bool{}}
+	known := map[string]*transmission{}
+	for i := range s.script {
+		known[s.script[i].name] = &s.script[i]
+	}
+	for _, name := range st.Sent {
+		if known[name] == nil {
+			return nil, fmt.Errorf("save has unknown transmission %q", name)
+		}
+		s.sent[name] = true
+	}
+	if st.Current != "" {
+		s.current = known[st.Current]
+		if s.current == nil {
+			return nil, fmt.Errorf("save has unknown transmission %q", st.Current)
+		}
+		s.lines, s.until = st.Lines, elapsed+st.Left
+		s.pending = st.Pending && s.current.choice != nil
+	}
+	return s, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Style is a set of terminal attributes text is drawn with. An empty Style
+// draws plain text.
+type Style []color.Attribute
+
+func (s Style) Sprintf(format string, a ...any) string {
+	if len(s) == 0 {
+		return fmt.Sprintf(format, a...)
+	}
+	return color.New(s...).Sprintf(format, a...)
+}
+
+func (s Style) Sprint(a ...any) string {
+	if len(s) == 0 {
+		return fmt.Sprint(a...)
+	}
+	return color.New(s...).Sprint(a...)
+}
+
+// With returns s with attrs added.
+func (s Style) With(attrs ...color.Attribute) Style {
+	return append(append(Style(nil), s...), attrs...)
+}
+
+// Theme is the palette the terminal is drawn in: every piece of the screen
+// takes the Style of its role, so a theme can recolor it all.
+type Theme struct {
+	Name      string
+	Title     Style // Screen titles, the command list and the prompt
+	Heading   Style // Section headings
+	OK        Style // Healthy readings
+	Warning   Style // At the warning threshold, and cautions
+	Critical  Style // At the critical threshold, and errors
+	Alert     Style // Critical log entries and tags that need action now
+	Success   Style // Completed actions and good outcomes
+	Info      Style // Events and notices
+	Notice    Style // Outcomes short of success, the current crisis step
+	Highlight Style // Stands out without good or bad news
+	Action    Style // The current timed action
+	Accent    Style // Sandbox and versus banners
+	Dim       Style // Asides
+	Inverse   Style // OFFLINE, major incidents and critical alarms
+	Caution   Style // Warning alarms and the authorization code
+
+	Symbols bool // Tag severities with [CRIT], [WARN] and [OK], for telling them apart without color
+	ASCII   bool // Draw with plain ASCII characters only
+}
+
+var classicTheme = Theme{
+	Name:      "classic",
+	Title:     Style{color.FgCyan},
+	Heading:   Style{color.FgYellow},
+	OK:        Style{color.FgGreen},
+	Warning:   Style{color.FgYellow},
+	Critical:  Style{color.FgRed},
+	Alert:     Style{color.FgHiRed},
+	Success:   Style{color.FgHiGreen},
+	Info:      Style{color.FgHiCyan},
+	Notice:    Style{color.FgHiYellow},
+	Highlight: Style{color.FgHiWhite},
+	Action:    Style{color.FgMagenta},
+	Accent:    Style{color.FgHiMagenta},
+	Dim:       Style{color.FgHiBlack},
+	Inverse:   Style{color.BgRed, color.FgHiWhite, color.Bold},
+	Caution:   Style{color.BgYellow, color.FgBlack},
+}
+
+// phosphorTheme is a single-hue theme in the manner of an old monochrome
+// terminal: severities differ by brightness, weight and underline.
+func phosphorTheme(name string, dim, bright, background color.Attribute) Theme {
+	return Theme{
+		Name:      name,
+		Title:     Style{bright},
+		Heading:   Style{bright, color.Bold},
+		OK:        Style{dim},
+		Warning:   Style{bright},
+		Critical:  Style{bright, color.Bold},
+		Alert:     Style{bright, color.Bold, color.Underline},
+		Success:   Style{bright},
+		Info:      Style{dim},
+		Notice:    Style{bright},
+		Highlight: Style{bright, color.Bold},
+		Action:    Style{bright},
+		Accent:    Style{bright, color.Underline},
+		Dim:       Style{dim},
+		Inverse:   Style{background, color.FgBlack, color.Bold},
+		Caution:   Style{dim, color.ReverseVideo},
+	}
+}
+
+var monoTheme = Theme{
+	Name:      "mono",
+	Heading:   Style{color.Bold},
+	Warning:   Style{color.Bold},
+	Critical:  Style{color.Bold},
+	Alert:     Style{color.Bold, color.Underline},
+	Success:   Style{color.Bold},
+	Notice:    Style{color.Bold},
+	Highlight: Style{color.Bold},
+	Accent:    Style{color.Underline},
+	Inverse:   Style{color.ReverseVideo, color.Bold},
+	Caution:   Style{color.ReverseVideo},
+	Symbols:   true,
+}
+
+var themes = []Theme{
+	classicTheme,
+	phosphorTheme("amber", color.FgYellow, color.FgHiYellow, color.BgHiYellow),
+	phosphorTheme("green-phosphor", color.FgGreen, color.FgHiGreen, color.BgHiGreen),
+	monoTheme,
+}
+
+// theme is the palette every screen is drawn in, set once from --theme,
+// --no-color and --ascii before the game starts.
+var theme = classicTheme
+
+func themeNames() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// ThemeByName looks up a theme by its name.
+func ThemeByName(name string) (Theme, error) {
+	for _, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (choose %s)", name, strings.Join(themeNames(), "|"))
+}
+
+// Severity tags, for telling states apart without color.
+const (
+	TagOK       = "[OK]"
+	TagWarning  = "[WARN]"
+	TagCritical = "[CRIT]"
+	TagOffline  = "[OFF]"
+)
+
+// tag returns t padded to a fixed width and followed by a space, or "" when
+// the theme does not tag severities.
+func (th Theme) tag(t string) string {
+	if !th.Symbols {
+		return ""
+	}
+	return fmt.Sprintf("%-6s ", t)
+}
+
+// healthTag tags the reading of sys, or sys as offline.
+func (sys SystemState) healthTag() string {
+	switch {
+	case sys.Offline:
+		return theme.tag(TagOffline)
+	case sys.Reading <= sys.Critical:
+		return theme.tag(TagCritical)
+	case sys.Reading <= sys.Warning:
+		return theme.tag(TagWarning)
+	}
+	return theme.tag(TagOK)
+}
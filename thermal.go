@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ThermalConfig drives one system (Core Temp by default) from the state of
+// the plant instead of a flat degradation rate: power heats the core, coolant
+// flow and pressure carry the heat away, and inertia makes the result lag.
+// Gains are integrity points per tick at 100% of the source system.
+type ThermalConfig struct {
+	System          string  `toml:"system"` // Driven system; empty disables the model
+	HeatSource      string  `toml:"heat_source"`
+	Coolant         string  `toml:"coolant"`
+	Pressure        string  `toml:"pressure"`
+	HeatGain        float64 `toml:"heat_gain"`
+	CoolantCooling  float64 `toml:"coolant_cooling"`
+	PressureCooling float64 `toml:"pressure_cooling"`
+	Inertia         float64 `toml:"inertia"` // 0 reacts instantly; closer to 1 lags longer
+}
+
+func defaultThermal() ThermalConfig {
+	return ThermalConfig{
+		System:          "Core Temp",
+		HeatSource:      "Power Output",
+		Coolant:         "Coolant Flow",
+		Pressure:        "Pressure Ctrl",
+		HeatGain:        6,
+		CoolantCooling:  3,
+		PressureCooling: 2,
+		Inertia:         0.6,
+	}
+}
+
+func (t ThermalConfig) validate() error {
+	switch {
+	case t.HeatGain < 0 || t.CoolantCooling < 0 || t.PressureCooling < 0:
+		return fmt.Errorf("thermal gains cannot be negative")
+	case t.Inertia < 0 || t.Inertia >= 1:
+		return fmt.Errorf("thermal inertia must be within 0 and 1 (exclusive)")
+	}
+	return nil
+}
+
+// thermalModel is a ThermalConfig resolved against the current layout.
+type thermalModel struct {
+	ThermalConfig
+	core, heat, coolant, pressure *System // Missing sources contribute nothing
+	Rate                          float64 // Current change per tick, after inertia
+	carry                         float64 // Fractional change not yet applied
+}
+
+// resolveThermal links the thermal model to this game's systems. The driven
+// system loses its flat degradation rate; events can still add to it.
+func (g *Game) resolveThermal() {
+	g.thermal = nil
+	t := g.Config.Thermal
+	core := g.systemNamed(t.System, nil)
+	if t.System == "" || core == nil {
+		return
+	}
+	g.thermal = &thermalModel{
+		ThermalConfig: t,
+		core:          core,
+		heat:          g.systemNamed(t.HeatSource, nil),
+		coolant:       g.systemNamed(t.Coolant, nil),
+		pressure:      g.systemNamed(t.Pressure, nil),
+	}
+	core.mu.Lock()
+	core.DegradationRate = 0
+	core.mu.Unlock()
+}
+
+// applyThermal moves the driven system by the model's rate after a
+// degradation tick. values holds each system's integrity, indexed by ID.
+func (g *Game) applyThermal(values []int) {
+	m := g.thermal
+	if m == nil {
+		return
+	}
+	share := func(sys *System, gain float64) float64 {
+		if sys == nil || sys.IsOffline() {
+			return 0
+		}
+		return gain * float64(values[sys.ID]) / float64(sys.MaxValue)
+	}
+	target := share(m.coolant, m.CoolantCooling) + share(m.pressure, m.PressureCooling) - share(m.heat, m.HeatGain)
+
+	g.mu.Lock()
+	m.Rate += (target - m.Rate) * (1 - m.Inertia)
+	m.carry += m.Rate
+	step := math.Trunc(m.carry)
+	m.carry -= step
+	g.mu.Unlock()
+
+	if step > 0 {
+		m.core.Boost(int(step))
+	} else if step < 0 {
+		m.core.Harm(int(-step))
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Value histories. Every degradation tick records each system's reading, as
+// the dashboard shows it. The last SparklineSamples make the sparkline beside
+// the bar; `trend <id>` graphs the whole run in place of the dashboard.
+const (
+	SparklineSamples = 60
+	SparklineWidth   = 20 // Columns; each averages SparklineSamples/SparklineWidth samples
+	TrendWidth       = 60
+	TrendHeight      = 12
+)
+
+var (
+	sparkLevels      = []rune("▁▂▃▄▅▆▇█")
+	asciiSparkLevels = []rune("_.:-=+*#") // For --ascii
+)
+
+// TrendViewState is the graph an operator is reading.
+type TrendViewState struct {
+	ID      int   `json:"system_id"`
+	Samples []int `json:"samples"` // One per degradation tick, from the start
+}
+
+// recordTrend adds a tick's readings to the histories.
+func (g *Game) recordTrend() {
+	readings := make([]int, len(g.Systems))
+	for i, sys := range g.Systems {
+		readings[i] = sys.Reading()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.trend) != len(readings) {
+		g.trend = make([][]int, len(readings))
+	}
+	for i, val := range readings {
+		g.trend[i] = append(g.trend[i], val)
+	}
+}
+
+// sparklineLocked returns the latest samples of system id. The caller must
+// hold g.mu.
+func (g *Game) sparklineLocked(id int) []int {
+	if id >= len(g.trend) {
+		return nil
+	}
+	samples := g.trend[id]
+	return append([]int(nil), samples[max(0, len(samples)-SparklineSamples):]...)
+}
+
+// handleTrend shows the graph of a system's history to the current operator
+// in place of the dashboard.
+func (g *Game) handleTrend(sysID int) {
+	if sysID < 0 || sysID >= len(g.Systems) {
+		g.AddLog(LogWarning, tr("Error: Invalid system ID for trend."))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.trendOpen, g.trendSystem, g.logOpen, g.helpOpen = true, sysID, false, false
+}
+
+// handleTrendView closes the graph if the current operator is reading one,
+// and reports whether input was spent doing so. Any command but q runs as
+// usual once the graph is closed. It must run on the game loop.
+func (g *Game) handleTrendView(input string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.trendOpen {
+		return false
+	}
+	g.trendOpen = false
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "", "q", "close":
+		return true
+	}
+	return false
+}
+
+// trendViewLocked returns the graph op is reading, or nil if op is elsewhere.
+// The caller must hold g.mu.
+func (g *Game) trendViewLocked(op *Operator) *TrendViewState {
+	if !op.trendOpen || op.trendSystem >= len(g.trend) {
+		return nil
+	}
+	return &TrendViewState{ID: op.trendSystem, Samples: append([]int(nil), g.trend[op.trendSystem]...)}
+}
+
+// downsample averages samples into at most width buckets.
+func downsample(samples []int, width int) []int {
+	if len(samples) <= width {
+		return samples
+	}
+	out := make([]int, width)
+	for i := range out {
+		from, to := i*len(samples)/width, (i+1)*len(samples)/width
+		sum := 0
+		for _, v := range samples[from:to] {
+			sum += v
+		}
+		out[i] = sum / (to - from)
+	}
+	return out
+}
+
+// sparkline renders samples between 0 and max in width columns, padded on
+// the left while there are fewer samples.
+func sparkline(samples []int, max, width int) string {
+	points := downsample(samples, width)
+	levels := sparkLevels
+	if theme.ASCII {
+		levels = asciiSparkLevels
+	}
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(points)))
+	for _, v := range points {
+		level := v * (len(levels) - 1) / max
+		b.WriteRune(levels[min(len(levels)-1, level)])
+	}
+	return b.String()
+}
+
+// drawTrendView renders the graph of a system's history in place of the
+// dashboard, with the warning and critical thresholds marked, narrowed to
+// fit width.
+func (s GameState) drawTrendView(w io.Writer, width int) {
+	v := s.TrendView
+	sys := s.Systems[v.ID]
+	name := sys.Name
+	fmt.Fprintln(w, theme.Title.Sprint(tr("--- TREND: %s (%d), %s of history ---", name, v.ID, formatDuration(s.Elapsed))))
+	fmt.Fprintln(w, theme.Dim.Sprint(tr("The reactor keeps running while you read.")))
+	fmt.Fprintln(w)
+
+	graphWidth := max(10, min(TrendWidth, width-1-6))
+	points := downsample(v.Samples, graphWidth)
+	if n := len(points); n > 0 && n < graphWidth { // Early on, stretch the samples across the axis
+		stretched := make([]int, graphWidth)
+		for i := range stretched {
+			stretched[i] = points[i*n/graphWidth]
+		}
+		points = stretched
+	}
+	row := func(value int) int { return min(TrendHeight-1, max(0, value*(TrendHeight-1)/sys.MaxValue)) }
+	warn, crit := row(sys.Warning), row(sys.Critical)
+	for r := TrendHeight - 1; r >= 0; r-- {
+		line := make([]byte, graphWidth)
+		fill := byte(' ')
+		if r == warn || r == crit {
+			fill = '-'
+		}
+		for i := range line {
+			line[i] = fill
+		}
+		for i, val := range points {
+			if row(val) == r {
+				line[i] = '*'
+			}
+		}
+		text := string(line)
+		switch {
+		case r <= crit:
+			text = theme.Critical.Sprintf(text)
+		case r <= warn:
+			text = theme.Warning.Sprintf(text)
+		default:
+			text = theme.OK.Sprintf(text)
+		}
+		fmt.Fprintf(w, "%4d |%s\n", (r*sys.MaxValue+TrendHeight-2)/(TrendHeight-1), text)
+	}
+	fmt.Fprintf(w, "     +%s\n", strings.Repeat("-", graphWidth))
+	fmt.Fprintf(w, "      %-*s%s\n", graphWidth-5, "00:00", formatDuration(s.Elapsed))
+	fmt.Fprintln(w, theme.Title.Sprint(tr("\n  q: back to the dashboard (or type any command)")))
+	fmt.Fprint(w, theme.Title.Sprint(tr("Enter command: ")))
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tutorial. --tutorial plays the classic reactor with a guide: at each
+// teaching moment the simulation pauses, the part of the dashboard in
+// question is picked out, and the game waits for the one command the step
+// teaches. Once every step is done the random events start and the run
+// plays out as usual, off the record.
+
+// Dashboard panels a tutorial step can pick out, besides system rows.
+const (
+	PanelRadiation = "radiation"
+	PanelLog       = "log"
+	PanelCommands  = "commands"
+)
+
+// TutorialState is the tutorial step on screen, while one is.
+type TutorialState struct {
+	Step    int      `json:"step"` // 1-based
+	Steps   int      `json:"steps"`
+	Lines   []string `json:"lines"`
+	Command string   `json:"command"`           // What to type to go on
+	Systems []int    `json:"systems,omitempty"` // The status rows picked out
+	Panel   string   `json:"panel,omitempty"`   // The panel picked out, if any
+}
+
+// tutorialStep is one teaching moment. The step starts once ready reports
+// true, or at once when it is nil: setup arranges the reactor for it and the
+// simulation pauses until the player types command.
+type tutorialStep struct {
+	lines   []string
+	command string
+	systems []string // By name
+	panel   string
+	ready   func(g *Game) bool
+	setup   func(g *Game)
+}
+
+// Systems the tutorial teaches with, by name; it plays on the default layout.
+const (
+	tutorialCoolant = "Coolant Flow"
+	tutorialCore    = "Core Temp"
+	tutorialPower   = "Power Output"
+)
+
+func tutorialSteps() []tutorialStep {
+	idle := func(g *Game) bool { return !g.IsPlayerBusy() }
+	return []tutorialStep{
+		{
+			lines: []string{
+				tr("Welcome to the control room. Each row of SYSTEM STATUS is a reactor system,"),
+				tr("and every one of them decays. Coolant Flow has slipped into WARNING."),
+				tr("stabilize restores a system to full, for a repair kit and a few seconds."),
+			},
+			command: "stabilize {Coolant Flow}",
+			systems: []string{tutorialCoolant},
+			setup:   func(g *Game) { g.setTutorialValue(tutorialCoolant, 40) },
+		},
+		{
+			lines: []string{
+				tr("Stabilizing takes time you will not always have. Core Temp is CRITICAL:"),
+				tr("divert moves 10 to 30 integrity at once from a healthy system to a weak one."),
+				tr("Some of it is lost on the way, so divert a little more than you need."),
+			},
+			command: "divert {Power Output} {Core Temp} 15",
+			systems: []string{tutorialPower, tutorialCore},
+			ready:   idle,
+			setup: func(g *Game) {
+				g.setTutorialValue(tutorialCore, 18)
+				g.setTutorialValue(tutorialPower, 95)
+			},
+		},
+		{
+			lines: []string{
+				tr("vent gives a weak system an instant boost, but releases radiation and may"),
+				tr("damage another system (a %d%% chance). Keep an eye on the RADIATION gauge:", VentBackflowChance),
+				tr("above %.0f for %.0fs and the crew evacuates.", RadiationLimit, EvacuationDelay.Seconds()),
+			},
+			command: "vent {Core Temp}",
+			systems: []string{tutorialCore},
+			panel:   PanelRadiation,
+			ready:   idle,
+			setup:   func(g *Game) { g.setTutorialValue(tutorialCore, 35) },
+		},
+		{
+			lines: []string{
+				tr("The EVENT LOG tells you what every command and event did. log opens the"),
+				tr("whole history; q brings you back."),
+			},
+			command: "log",
+			panel:   PanelLog,
+			ready:   idle,
+		},
+		{
+			lines: []string{
+				tr("That is the basics. help <command> explains any command, with its odds."),
+				tr("From now on random events strike. Two systems OFFLINE at once, for 30s, is a meltdown:"),
+				tr("survive until the clock runs out."),
+			},
+			command: "help",
+			panel:   PanelCommands,
+			ready: func(g *Game) bool {
+				g.mu.Lock()
+				defer g.mu.Unlock()
+				return !g.logOpen
+			},
+		},
+	}
+}
+
+// tutorial is the progress of a tutorial run.
+type tutorial struct {
+	steps  []tutorialStep
+	step   int  // The current or next step
+	active bool // The step is on screen, and the simulation paused for it
+}
+
+// startTutorial turns the game into a tutorial run.
+func (g *Game) startTutorial() error {
+	for _, name := range []string{tutorialCoolant, tutorialCore, tutorialPower} {
+		if g.systemNamed(name, nil) == nil {
+			return fmt.Errorf("the tutorial needs the default systems, and this layout has no %s", name)
+		}
+	}
+	g.tutorial = &tutorial{steps: tutorialSteps()}
+	g.conditions = nil // The lessons script the plant themselves
+	return nil
+}
+
+// tutorialRunning reports whether steps of the tutorial are left.
+func (g *Game) tutorialRunning() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tutorial != nil && g.tutorial.step < len(g.tutorial.steps)
+}
+
+func (g *Game) setTutorialValue(name string, value int) {
+	sys := g.systemNamed(name, nil)
+	sys.locked(func() {
+		sys.Value = value
+		sys.clampLocked()
+	})
+}
+
+// tutorialCommand fills in the system IDs of a step's command.
+func (g *Game) tutorialCommand(command string) string {
+	for _, sys := range g.Systems {
+		command = strings.ReplaceAll(command, "{"+sys.Name+"}", strconv.Itoa(sys.ID))
+	}
+	return command
+}
+
+// updateTutorial starts the next step once it is due, and reports whether
+// the simulation is paused for one. It must run on the game loop.
+func (g *Game) updateTutorial() bool {
+	g.mu.Lock()
+	t := g.tutorial
+	if t == nil || t.step >= len(t.steps) {
+		g.mu.Unlock()
+		return false
+	}
+	if t.active {
+		g.mu.Unlock()
+		return true
+	}
+	step := t.steps[t.step]
+	g.mu.Unlock()
+	if step.ready != nil && !step.ready(g) {
+		return false
+	}
+	if step.setup != nil {
+		step.setup(g)
+	}
+	g.locked(func() {
+		t.active = true
+		g.setPausedLocked(true)
+	})
+	return true
+}
+
+// tutorialBlocks holds back input other than the command the current step
+// asks for, and reports whether it did. The command itself resumes the
+// simulation and runs. It must run on the game loop.
+func (g *Game) tutorialBlocks(input string) bool {
+	g.mu.Lock()
+	t := g.tutorial
+	if t == nil || !t.active {
+		g.mu.Unlock()
+		return false
+	}
+	want := g.tutorialCommand(t.steps[t.step].command)
+	g.mu.Unlock()
+	if g.canonicalCommand(input) != want {
+		g.AddLog(LogWarning, tr("TUTORIAL: Not yet. Type: %s", want))
+		return true
+	}
+	g.mu.Lock()
+	t.active = false
+	t.step++
+	done := t.step == len(t.steps)
+	g.setPausedLocked(false)
+	g.mu.Unlock()
+	if done {
+		g.AddLog(LogSuccess, tr("TUTORIAL COMPLETE: You are on your own now. Good luck, engineer."))
+		g.scheduleRandomEvent()
+	}
+	return false
+}
+
+// tutorialLocked returns the step on screen, or nil. The caller must hold
+// g.mu.
+func (g *Game) tutorialLocked() *TutorialState {
+	t := g.tutorial
+	if t == nil || !t.active {
+		return nil
+	}
+	step := t.steps[t.step]
+	v := &TutorialState{Step: t.step + 1, Steps: len(t.steps), Lines: step.lines, Command: g.tutorialCommand(step.command), Panel: step.panel}
+	for _, name := range step.systems {
+		v.Systems = append(v.Systems, g.systemNamed(name, nil).ID)
+	}
+	return v
+}
+
+// setPausedLocked stops the game clock, or starts it again: the elapsed time
+// stands still while paused. The caller must hold g.mu.
+func (g *Game) setPausedLocked(paused bool) {
+	now := g.clock.Now()
+	g.scaleBase = g.elapsedAtLocked(now)
+	g.scaleMark = now
+	if g.timescale == 0 {
+		g.timescale = 1
+	}
+	g.paused = paused
+}
+
+func (g *Game) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// canonicalCommand spells input out the way a tutorial step names its
+// command: aliases expanded, verbs resolved, lower case, and systems by ID.
+func (g *Game) canonicalCommand(input string) string {
+	parts := splitCommand(strings.ToLower(g.resolveInputVerb(g.expandAlias(input))))
+	if len(parts) == 0 {
+		return ""
+	}
+	for _, i := range systemArgs[parts[0]] {
+		if i >= len(parts) {
+			continue
+		}
+		if _, err := strconv.Atoi(parts[i]); err != nil {
+			if matches := g.matchSystems(parts[i]); len(matches) == 1 {
+				parts[i] = strconv.Itoa(matches[0].ID)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// tutorialPanel renders the step on screen, or "" when there is none.
+func (s GameState) tutorialPanel() string {
+	v := s.Tutorial
+	if v == nil {
+		return ""
+	}
+	lines := []string{theme.Accent.Sprint(tr("TUTORIAL %d/%d: SIMULATION PAUSED", v.Step, v.Steps))}
+	lines = append(lines, v.Lines...)
+	lines = append(lines, theme.Caution.Sprint(tr(" NOW TYPE: %s ", v.Command)))
+	return strings.Join(lines, "\n")
+}
+
+// spotlight redraws text in the highlight of the tutorial when the step on
+// screen picks out panel.
+func (s GameState) spotlight(panel, text string) string {
+	if s.Tutorial == nil || s.Tutorial.Panel != panel {
+		return text
+	}
+	return theme.Caution.Sprint(ansiEscape.ReplaceAllString(text, ""))
+}
+
+// spotlightSystem is spotlight for the status row of system id.
+func (s GameState) spotlightSystem(id int, text string) string {
+	if s.Tutorial != nil {
+		for _, picked := range s.Tutorial.Systems {
+			if picked == id {
+				return theme.Caution.Sprint(text)
+			}
+		}
+	}
+	return text
+}
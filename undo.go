@@ -0,0 +1,95 @@
+package main
+
+import "time"
+
+// Undo. A divert typed the wrong way round can be taken back within
+// UndoWindow: undo puts back what it moved between the two systems. The
+// target gives back no more than it holds above MinValue, so an undo never
+// takes it OFFLINE, and the source gets back that share of what it gave. The
+// power it drew and its cooldown stay spent, and a game has only UndoLimit
+// undos. Each operator undoes only their own divert.
+
+const (
+	UndoWindow = 3 * time.Second
+	UndoLimit  = 2
+)
+
+// divertUndo is what a divert moved, for undo to put back.
+type divertUndo struct {
+	from, to *System
+	taken    int           // Taken from the source
+	given    int           // Given to the target, after losses and clamping
+	at       time.Duration // Elapsed game time of the divert
+}
+
+// recordDivert remembers a divert for undo.
+func (g *Game) recordDivert(from, to *System, taken, given int) {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	g.lastDivert = &divertUndo{from: from, to: to, taken: taken, given: given, at: elapsed}
+	g.mu.Unlock()
+}
+
+// boostGained boosts s by amount and returns how much it actually gained.
+func (s *System) boostGained(amount int) int {
+	s.mu.Lock()
+	before := s.Value
+	s.mu.Unlock()
+	s.Boost(amount)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Value - before
+}
+
+// adjust moves s by delta, neither as damage nor as a repair.
+func (s *System) adjust(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Value += delta
+	s.clampLocked()
+}
+
+// takeBack lowers s by up to amount, keeping it above MinValue, and returns
+// how much it took.
+func (s *System) takeBack(amount int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taken := max(0, min(amount, s.Value-s.MinValue-1))
+	s.Value -= taken
+	s.clampLocked()
+	return taken
+}
+
+func (g *Game) handleUndo() {
+	elapsed := g.Elapsed()
+	g.mu.Lock()
+	last, left := g.lastDivert, UndoLimit-g.undosUsed
+	g.mu.Unlock()
+	switch {
+	case left <= 0:
+		g.AddLog(LogWarning, tr("Cannot undo: All %d undos of this run are spent.", UndoLimit))
+		return
+	case last == nil:
+		g.AddLog(LogWarning, tr("Cannot undo: No divert to take back."))
+		return
+	case elapsed-last.at > UndoWindow:
+		g.AddLog(LogWarning, tr("Cannot undo: The last divert is more than %.0fs old.", UndoWindow.Seconds()))
+		return
+	case last.from.IsOffline() || last.to.IsOffline():
+		g.AddLog(LogWarning, tr("Cannot undo: A system of the divert is OFFLINE."))
+		return
+	}
+	given := last.to.takeBack(last.given)
+	taken := last.taken
+	if given < last.given {
+		taken = last.taken * given / last.given
+	}
+	last.from.adjust(taken)
+	g.mu.Lock()
+	g.lastDivert = nil
+	g.undosUsed++
+	left = UndoLimit - g.undosUsed
+	g.mu.Unlock()
+	g.AddLog(LogSuccess, tr("UNDO: Put %d back into %s (%d) and took %d from %s (%d). %d undos left.",
+		taken, last.from.Name, last.from.ID, given, last.to.Name, last.to.ID, left))
+}
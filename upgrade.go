@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Campaign upgrades. Clearing a campaign level for the first time earns the
+// profile a pick, offered before the next level starts or taken with the
+// `upgrade` command. Upgrades are kept in the profile for good and apply to
+// every campaign level it plays, so the campaign grows easier to hold as it
+// grows harder to survive.
+
+const (
+	UpgradeQuickHands  = "quick_hands"
+	UpgradeSpareParts  = "spare_parts"
+	UpgradeShields     = "reinforced_shields"
+	UpgradeBypassRoute = "bypass_route"
+
+	QuickHandsCut = 0.8 // Stabilize time under Quick Hands
+	ShieldsDamage = 0.8 // Event damage under Reinforced Shields
+	BypassSources = 3   // Systems every system takes diverts from under Bypass Route
+)
+
+// Upgrade is a persistent campaign bonus.
+type Upgrade struct {
+	ID   string
+	Name string
+	Desc string
+}
+
+var upgrades = []Upgrade{
+	{UpgradeQuickHands, "Quick Hands", "Stabilize takes 20% less time"},
+	{UpgradeSpareParts, "Spare Parts", "Start every level with one more repair kit"},
+	{UpgradeShields, "Reinforced Shields", "Events deal 20% less damage"},
+	{UpgradeBypassRoute, "Bypass Route", "Every system takes diverts from at least three others"},
+}
+
+// UpgradeByID looks up an upgrade.
+func UpgradeByID(id string) (Upgrade, error) {
+	for _, u := range upgrades {
+		if u.ID == strings.ToLower(id) {
+			return u, nil
+		}
+	}
+	ids := make([]string, len(upgrades))
+	for i, u := range upgrades {
+		ids[i] = u.ID
+	}
+	return Upgrade{}, fmt.Errorf("unknown upgrade %q (choose %s)", id, strings.Join(ids, "|"))
+}
+
+// upgradeChoices returns the upgrades the profile does not have yet.
+func (p *Profile) upgradeChoices() []Upgrade {
+	var choices []Upgrade
+	for _, u := range upgrades {
+		if !slices.Contains(p.Upgrades, u.ID) {
+			choices = append(choices, u)
+		}
+	}
+	return choices
+}
+
+// earnUpgrade grants the profile a pick, unless it has one waiting for every
+// upgrade left. It reports whether it did.
+func (p *Profile) earnUpgrade() bool {
+	if p.UpgradePicks >= len(p.upgradeChoices()) {
+		return false
+	}
+	p.UpgradePicks++
+	return true
+}
+
+// pickUpgrade spends a pick on the upgrade id.
+func (p *Profile) pickUpgrade(id string) (Upgrade, error) {
+	u, err := UpgradeByID(id)
+	switch {
+	case err != nil:
+		return u, err
+	case p.UpgradePicks == 0:
+		return u, fmt.Errorf("profile %s has no upgrade to pick: clear a new campaign level to earn one", p.Name)
+	case slices.Contains(p.Upgrades, u.ID):
+		return u, fmt.Errorf("profile %s already has %s", p.Name, u.Name)
+	}
+	p.UpgradePicks--
+	p.Upgrades = append(p.Upgrades, u.ID)
+	return u, nil
+}
+
+// runUpgrade is the `upgrade` command: it lists the choices, or with an ID
+// spends a pick on it.
+func (p *Profile) runUpgrade(args []string) error {
+	if len(args) == 0 {
+		p.printUpgrades()
+		return nil
+	}
+	u, err := p.pickUpgrade(args[0])
+	if err != nil {
+		return err
+	}
+	if err := p.Save(); err != nil {
+		return err
+	}
+	fmt.Println(theme.Success.Sprint(tr("UPGRADE INSTALLED: %s (%s)", u.Name, tr(u.Desc))))
+	return nil
+}
+
+// printUpgrades lists the profile's upgrades and the ones it may pick.
+func (p *Profile) printUpgrades() {
+	fmt.Println(theme.Heading.Sprint(tr("CAMPAIGN UPGRADES (%d/%d):", len(p.Upgrades), len(upgrades))))
+	for _, u := range upgrades {
+		mark := " "
+		if slices.Contains(p.Upgrades, u.ID) {
+			mark = "x"
+		}
+		fmt.Print(tr("  [%s] %-20s %-20s %s\n", mark, u.ID, u.Name, tr(u.Desc)))
+	}
+	if p.UpgradePicks > 0 {
+		fmt.Println(theme.Warning.Sprint(tr("  %d to pick: choose one with the upgrade <id> command.", p.UpgradePicks)))
+	}
+}
+
+// offerUpgrade asks the player at the terminal to spend the profile's picks
+// before a campaign level starts. An empty answer keeps the pick for later.
+func offerUpgrade(p *Profile) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	in := bufio.NewReader(os.Stdin)
+	for p.UpgradePicks > 0 {
+		choices := p.upgradeChoices()
+		fmt.Println(theme.Heading.Sprint(tr("UPGRADE EARNED: Choose one for the rest of the campaign.")))
+		for i, u := range choices {
+			fmt.Print(tr("  %d. %-20s %s\n", i+1, u.Name, tr(u.Desc)))
+		}
+		fmt.Print(tr("Upgrade [1-%d, Enter to decide later]: ", len(choices)))
+		answer, _ := in.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(choices) {
+			fmt.Println(theme.Warning.Sprint(tr("Choose a number from 1 to %d.", len(choices))))
+			continue
+		}
+		u, _ := p.pickUpgrade(choices[n-1].ID)
+		if err := p.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, theme.Critical.Sprint(tr("Failed to save profile: %v", err)))
+			return
+		}
+		fmt.Println(theme.Success.Sprint(tr("UPGRADE INSTALLED: %s (%s)", u.Name, tr(u.Desc))))
+	}
+}
+
+// applyUpgrades installs the upgrades ids on a new campaign game.
+func (g *Game) applyUpgrades(ids []string) {
+	g.Upgrades = append([]string(nil), ids...)
+	if g.hasUpgrade(UpgradeSpareParts) && !hasMutator(g.Mutators, MutatorNoKits) {
+		g.Inventory[ItemRepairKit]++
+	}
+	if g.hasUpgrade(UpgradeBypassRoute) {
+		g.addBypassRoutes()
+	}
+}
+
+func (g *Game) hasUpgrade(id string) bool {
+	return slices.Contains(g.Upgrades, id)
+}
+
+// upgradeNames returns the names of the game's upgrades.
+func (g *Game) upgradeNames() string {
+	names := make([]string, 0, len(g.Upgrades))
+	for _, id := range g.Upgrades {
+		if u, err := UpgradeByID(id); err == nil {
+			names = append(names, u.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// upgradeDamageScale is the damage multiplier from the game's upgrades.
+func (g *Game) upgradeDamageScale() float64 {
+	if g.hasUpgrade(UpgradeShields) {
+		return ShieldsDamage
+	}
+	return 1
+}
+
+// addBypassRoutes links every system to take diverts from at least
+// BypassSources others, adding the missing routes from the systems after it
+// in ID order.
+func (g *Game) addBypassRoutes() {
+	links := g.matrix.Links
+	if links == nil {
+		return // Every system is linked already
+	}
+	n := len(g.Systems)
+	for to := range links {
+		sources := 0
+		for from := range links {
+			if links[from][to] {
+				sources++
+			}
+		}
+		for step := 1; step < n && sources < BypassSources; step++ {
+			if from := (to + step) % n; !links[from][to] {
+				links[from][to] = true
+				sources++
+			}
+		}
+	}
+}
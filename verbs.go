@@ -0,0 +1,255 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// Command verbs. A command line starts with a verb, which the verb table
+// resolves to the command it stands for before anything else reads the
+// line. Besides the English verbs, which always work, the table holds the
+// verbs of the language of the game, from locales/verbs/<lang>.json, and the
+// config's [verbs] table, such as purge = "vent". The commands themselves
+// run from commandTable.
+
+//go:embed locales/verbs/*.json
+var verbFiles embed.FS
+
+// localeVerbs are the verbs of each language, from verb to command.
+var localeVerbs = loadLocaleVerbs()
+
+func loadLocaleVerbs() map[language.Tag]map[string]string {
+	files, err := verbFiles.ReadDir("locales/verbs")
+	if err != nil {
+		panic(err)
+	}
+	all := map[language.Tag]map[string]string{}
+	for _, f := range files {
+		tag := language.MustParse(strings.TrimSuffix(f.Name(), ".json"))
+		data, err := verbFiles.ReadFile(path.Join("locales/verbs", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var verbs map[string]string
+		if err := json.Unmarshal(data, &verbs); err != nil {
+			panic(fmt.Sprintf("locales/verbs/%s: %v", f.Name(), err))
+		}
+		if err := validateVerbs(verbs); err != nil {
+			panic(fmt.Sprintf("locales/verbs/%s: %v", f.Name(), err))
+		}
+		all[tag] = verbs
+	}
+	return all
+}
+
+// validateVerbs rejects verbs that are not one word, that would hide a
+// command, or that stand for no command.
+func validateVerbs(verbs map[string]string) error {
+	for verb, command := range verbs {
+		switch {
+		case verb == "" || strings.ContainsFunc(verb, unicode.IsSpace) || verb != strings.ToLower(verb):
+			return fmt.Errorf("verbs: %q must be one lower-case word", verb)
+		case isCommand(verb):
+			return fmt.Errorf("verbs: %q is already a command", verb)
+		case !isCommand(command):
+			return fmt.Errorf("verbs: %q stands for %q, which is not a command", verb, command)
+		}
+	}
+	return nil
+}
+
+// resolveVerb returns the command verb stands for: itself when it is a
+// command, or when it is no verb at all.
+func (g *Game) resolveVerb(verb string) string {
+	return resolveVerbWith(g.Config.Verbs, verb)
+}
+
+// resolveVerbWith resolves verb by the config's verbs and those of the
+// language of the game.
+func resolveVerbWith(verbs map[string]string, verb string) string {
+	verb = strings.ToLower(verb)
+	if command, ok := verbs[verb]; ok {
+		return command
+	}
+	if command, ok := localeVerbs[locale][verb]; ok {
+		return command
+	}
+	return verb
+}
+
+// resolveInputVerb replaces the verb at the start of input with the command
+// it stands for.
+func (g *Game) resolveInputVerb(input string) string {
+	words := strings.Fields(input)
+	if len(words) == 0 {
+		return input
+	}
+	command := g.resolveVerb(words[0])
+	if command == strings.ToLower(words[0]) {
+		return input
+	}
+	return strings.Join(append([]string{command}, words[1:]...), " ")
+}
+
+// verbNames returns the verbs of the language of the game and the config.
+func (g *Game) verbNames() []string {
+	var names []string
+	for verb := range localeVerbs[locale] {
+		names = append(names, verb)
+	}
+	for verb := range g.Config.Verbs {
+		if _, ok := localeVerbs[locale][verb]; !ok {
+			names = append(names, verb)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// verbsFor returns the verbs that stand for command.
+func (g *Game) verbsFor(command string) []string {
+	var verbs []string
+	for _, verb := range g.verbNames() {
+		if g.resolveVerb(verb) == command {
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
+
+// commandFunc runs a command: parts is the command line in lower case, its
+// verb resolved and its system names turned into IDs, and rawParts the same
+// in the case it was typed.
+type commandFunc func(g *Game, parts, rawParts []string)
+
+// commandTable holds what runs each in-game command other than quit and
+// reactor, which the terminal handles before the game sees them.
+var commandTable = map[string]commandFunc{
+	"stabilize":    systemCommand((*Game).handleStabilize),
+	"divert":       runDivert,
+	"vent":         systemCommand((*Game).handleVent),
+	"reboot":       systemCommand((*Game).handleReboot),
+	"use":          runUse,
+	"fabricate":    func(g *Game, _, _ []string) { g.handleFabricate() },
+	"rally":        func(g *Game, _, _ []string) { g.handleRally() },
+	"inspect":      systemCommand((*Game).handleInspect),
+	"sweep":        systemCommand((*Game).handleSweep),
+	"scram":        func(g *Game, _, _ []string) { g.handleScram() },
+	"cancel":       func(g *Game, _, _ []string) { g.handleCancel() },
+	"undo":         func(g *Game, _, _ []string) { g.handleUndo() },
+	"rewind":       func(g *Game, parts, _ []string) { g.handleRewind(parts[1:]) },
+	"override":     systemCommand((*Game).handleOverride),
+	"overcharge":   systemCommand((*Game).handleOvercharge),
+	CrisisSuppress: func(g *Game, parts, _ []string) { g.handleCrisis(parts[0], parts[1:]) },
+	CrisisIsolate:  func(g *Game, parts, _ []string) { g.handleCrisis(parts[0], parts[1:]) },
+	CrisisRestart:  func(g *Game, parts, _ []string) { g.handleCrisis(parts[0], parts[1:]) },
+	"log":          func(g *Game, _, _ []string) { g.openLogView() },
+	"schematic":    func(g *Game, _, _ []string) { g.toggleSchematic() },
+	"view":         func(g *Game, parts, _ []string) { g.handleView(parts[1:]) },
+	"advise":       func(g *Game, parts, _ []string) { g.handleAdvise(parts[1:]) },
+	"spectators":   func(g *Game, _, rawParts []string) { g.handleSpectators(rawParts[1:]) },
+	"help":         func(g *Game, parts, _ []string) { g.handleHelp(parts[1:]) },
+	ManualTopic:    func(g *Game, _, _ []string) { g.openHelp(ManualTopic) },
+	KeysTopic:      func(g *Game, _, _ []string) { g.openHelp(KeysTopic) },
+	"trend":        systemCommand((*Game).handleTrend),
+	"ack":          func(g *Game, parts, _ []string) { g.handleAck(parts[1:]) },
+	"rule":         func(g *Game, parts, _ []string) { g.handleRule(parts[1:]) },
+	"debug":        func(g *Game, _, _ []string) { g.handleDebug() },
+	DebugSet:       func(g *Game, parts, _ []string) { g.handleCheat(parts[0], parts[1:]) },
+	DebugEvent:     func(g *Game, parts, _ []string) { g.handleCheat(parts[0], parts[1:]) },
+	DebugKits:      func(g *Game, parts, _ []string) { g.handleCheat(parts[0], parts[1:]) },
+	DebugTimescale: func(g *Game, parts, _ []string) { g.handleCheat(parts[0], parts[1:]) },
+	"sabotage":     func(g *Game, parts, _ []string) { g.handleSabotage(parts[1:]) },
+	"save":         runSave,
+	"reload":       func(g *Game, _, _ []string) { g.handleReload() },
+	HistoryTopic:   func(g *Game, _, rawParts []string) { g.handleHistory(rawParts) },
+	SavesTopic:     runSaves,
+	"load":         runLoad,
+	"respond":      func(g *Game, parts, _ []string) { g.handleRespond(parts[1:]) },
+	"autopilot":    func(g *Game, parts, _ []string) { g.handleAutopilot(parts[1:]) },
+}
+
+// systemCommand is a command whose one argument is a system ID.
+func systemCommand(handle func(g *Game, sysID int)) commandFunc {
+	return func(g *Game, parts, _ []string) {
+		if len(parts) < 2 {
+			g.AddLog(LogInfo, tr("Usage: %s <system_id>", parts[0]))
+		} else if sysID, err := strconv.Atoi(parts[1]); err != nil {
+			g.AddLog(LogWarning, tr("Error: Invalid system ID format."))
+		} else {
+			handle(g, sysID)
+		}
+	}
+}
+
+func runDivert(g *Game, parts, _ []string) {
+	if len(parts) < 4 {
+		g.AddLog(LogInfo, tr("Usage: divert <from_id> <to_id> <amount>"))
+		return
+	}
+	fromID, err1 := strconv.Atoi(parts[1])
+	toID, err2 := strconv.Atoi(parts[2])
+	amount, err3 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil {
+		g.AddLog(LogWarning, tr("Error: Invalid ID or amount format for divert."))
+		return
+	}
+	g.handleDivert(fromID, toID, amount)
+}
+
+func runUse(g *Game, parts, _ []string) {
+	if len(parts) < 2 {
+		g.AddLog(LogInfo, tr("Usage: use <%s> [system_id]", strings.Join(itemNames(), "|")))
+		return
+	}
+	g.handleUse(parts[1], parts[2:])
+}
+
+func runSave(g *Game, _, rawParts []string) {
+	switch {
+	case !g.localPlayer():
+		g.AddLog(LogWarning, tr("Save failed: Only the host, at the machine the game runs on, can save it."))
+	case g.plant != nil:
+		g.AddLog(LogWarning, tr("Save failed: Multi-reactor runs cannot be saved."))
+	case g.rival != nil:
+		g.AddLog(LogWarning, tr("Save failed: Versus matches cannot be saved."))
+	case g.Sandbox:
+		g.AddLog(LogWarning, tr("Save failed: Sandbox runs cannot be saved."))
+	case g.tutorial != nil:
+		g.AddLog(LogWarning, tr("Save failed: Tutorial runs cannot be saved."))
+	case g.practice != nil:
+		g.AddLog(LogWarning, tr("Save failed: Practice runs cannot be saved."))
+	case g.ironman != nil:
+		g.AddLog(LogWarning, tr("Save failed: An ironman run saves itself, and only itself."))
+	case len(rawParts) < 2:
+		g.AddLog(LogInfo, tr("Usage: save <slot or file>"))
+	case g.savesDir != "" && isSlotName(rawParts[1]):
+		if strings.HasPrefix(rawParts[1], autosavePrefix) {
+			g.AddLog(LogWarning, tr("Save failed: Slots named %s... are the autosaves'.", autosavePrefix))
+			return
+		}
+		if rawParts[1] == IronmanSlot {
+			g.AddLog(LogWarning, tr("Save failed: The %s slot is the ironman run's.", IronmanSlot))
+			return
+		}
+		if err := g.SaveToFile(slotPath(g.savesDir, rawParts[1])); err != nil {
+			g.AddLog(LogWarning, tr("Save failed: %v", err))
+		} else {
+			g.AddLog(LogSuccess, tr("Game saved to slot %s. Resume it with --load %s.", rawParts[1], rawParts[1]))
+		}
+	default:
+		if err := g.SaveToFile(rawParts[1]); err != nil {
+			g.AddLog(LogWarning, tr("Save failed: %v", err))
+		} else {
+			g.AddLog(LogSuccess, tr("Game saved successfully to %s.", rawParts[1]))
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Versus match. The host waits for one rival to join over the co-op protocol,
+// then both play their own reactor, built from the same seed. Score earns
+// sabotage points, which the sabotage command spends on events fired at the
+// rival's reactor. A player whose reactor melts down loses to one whose
+// reactor holds; otherwise the higher score wins once both have finished.
+const SabotagePointScore = 250 // Score per sabotage point earned
+
+// sabotageCosts prices the events a player can fire at their rival. Events
+// that help the reactor are not for sale.
+var sabotageCosts = map[string]int{
+	"sensor_glitch":        1,
+	"crew_error":           1,
+	"grid_fluctuation":     1,
+	"power_surge":          2,
+	"coolant_leak":         2,
+	"cosmic_rays":          2,
+	"turbine_trip":         2,
+	"pressure_transient":   2,
+	"pump_cavitation":      2,
+	"emergency_code":       2,
+	"control_rod_jam":      3,
+	"steam_void":           3,
+	"electrical_fire":      3,
+	"xenon_poisoning":      3,
+	"solar_flare":          3,
+	"micro_fracture":       3,
+	"earthquake":           5,
+	"turbine_hall_fire":    5,
+	"primary_loop_rupture": 5,
+}
+
+// VersusState is the match as one player sees it.
+type VersusState struct {
+	Rival           string `json:"rival"`
+	RivalScore      int    `json:"rival_score"`
+	RivalEnded      bool   `json:"rival_ended"`
+	RivalMeltedDown bool   `json:"rival_melted_down"`
+	Points          int    `json:"sabotage_points"`  // Left to spend
+	Result          string `json:"result,omitempty"` // Once both reactors are done
+}
+
+// matchSide is one player's standing in a match.
+type matchSide struct {
+	Name       string
+	Score      int
+	Ended      bool
+	MeltedDown bool
+}
+
+// rivalSeat is the connection of the player who joined a versus match.
+type rivalSeat struct {
+	Name    string
+	conn    net.Conn
+	scanner *bufio.Scanner
+	send    func(CoopFrame) bool
+	ln      net.Listener
+}
+
+// awaitRival listens on addr until a player joins, and turns away anyone who
+// connects after them.
+func awaitRival(addr string) (*rivalSeat, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			ln.Close()
+			return nil, err
+		}
+		scanner, send := coopStream(conn)
+		name, ok := readHello(scanner, send)
+		if !ok {
+			conn.Close()
+			continue
+		}
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return // Closed as the game exits
+				}
+				scanner, send := coopStream(conn)
+				if _, ok := readHello(scanner, send); ok { // Read first: closing on unread data would lose the reply
+					send(CoopFrame{Error: "the match has already started"})
+				}
+				conn.Close()
+			}
+		}()
+		return &rivalSeat{Name: name, conn: conn, scanner: scanner, send: send, ln: ln}, nil
+	}
+}
+
+// Close hangs up on the rival and stops listening.
+func (r *rivalSeat) Close() {
+	r.ln.Close()
+	r.conn.Close()
+}
+
+// startMatch pairs g, the host's game, with rival, the seated player's, and
+// runs the rival's game loop and session. The returned stop ends them.
+func (g *Game) startMatch(rival *Game, seat *rivalSeat) (stop func()) {
+	g.rival, rival.rival = rival, g
+	rival.Name = seat.Name
+	if strings.EqualFold(rival.Name, g.Name) {
+		rival.Name += "-2"
+	}
+
+	player := rival.Operator
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go rival.run(&wg, quit)
+	rival.AddLog(LogInfo, tr("SYSTEM BOOT: Reactor control online. Good luck, engineer.")) // Play boots the host's
+	for _, side := range []*Game{g, rival} {
+		side.AddLog(LogEvent, tr("VERSUS: %s against %s. Every %d points scored earn a sabotage point.", g.Name, rival.Name, SabotagePointScore))
+	}
+	go func() {
+		rival.crewSession(player, seat.scanner, seat.send)
+		for _, side := range []*Game{g, rival} {
+			side.AddLog(LogEvent, tr("VERSUS: %s left the match.", rival.Name))
+		}
+	}()
+	return func() {
+		close(quit)
+		wg.Wait()
+		seat.Close()
+	}
+}
+
+// matchSide returns the player's standing.
+func (g *Game) matchSide() matchSide {
+	score := g.CurrentScore().Total
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return matchSide{Name: g.operators[0].Name, Score: score, Ended: g.GameOver || g.GameWon, MeltedDown: g.GameOver && !g.Scrammed}
+}
+
+// matchResult names the winner once both players are done, or returns "".
+func matchResult(me, rival matchSide) string {
+	if !me.Ended || !rival.Ended {
+		return ""
+	}
+	winner := ""
+	switch {
+	case me.MeltedDown != rival.MeltedDown:
+		winner = me.Name
+		if me.MeltedDown {
+			winner = rival.Name
+		}
+	case me.Score > rival.Score:
+		winner = me.Name
+	case rival.Score > me.Score:
+		winner = rival.Name
+	default:
+		return tr("The match is a draw.")
+	}
+	return tr("%s wins the match.", winner)
+}
+
+// sabotagePointsLocked returns the points left to spend. The caller must hold
+// g.mu.
+func (g *Game) sabotagePointsLocked(score int) int {
+	return max(0, score/SabotagePointScore-g.sabotageSpent)
+}
+
+// handleSabotage spends sabotage points on an event at the rival's reactor.
+func (g *Game) handleSabotage(args []string) {
+	rival := g.rival
+	if rival == nil {
+		g.AddLog(LogWarning, tr("Sabotage is only available in a versus match."))
+		return
+	}
+	rivalName := rival.crew()[0].Name
+	if len(args) < 2 {
+		g.AddLog(LogInfo, tr("Usage: sabotage %s <event>", strings.ToLower(rivalName)))
+		return
+	}
+	if !strings.EqualFold(args[0], rivalName) {
+		g.AddLog(LogWarning, tr("Error: No rival called %s. Your rival is %s.", args[0], rivalName))
+		return
+	}
+	cost, ok := sabotageCosts[args[1]]
+	ev, registered := rival.Events.Lookup(args[1])
+	if !ok || !registered {
+		g.AddLog(LogWarning, tr("Error: Unknown sabotage %q. See the VERSUS panel for the price list.", args[1]))
+		return
+	}
+	if rival.ended() {
+		g.AddLog(LogWarning, tr("Cannot sabotage: %s's reactor is already shut down.", rivalName))
+		return
+	}
+	score := g.CurrentScore().Total
+	g.mu.Lock()
+	points := g.sabotagePointsLocked(score)
+	if points >= cost {
+		g.sabotageSpent += cost
+	}
+	g.mu.Unlock()
+	if points < cost {
+		g.AddLog(LogWarning, tr("Cannot sabotage: %s costs %d points and you have %d.", ev.Name(), cost, points))
+		return
+	}
+	g.AddLog(LogEvent, tr("SABOTAGE: Fired %s at %s's reactor. Sabotage points spent: %d.", ev.Name(), rivalName, cost))
+	attacker := g.crew()[0].Name
+	go rival.post(func() { // Not from this loop: the rival's may be posting to it just now
+		if rival.ended() {
+			return
+		}
+		rival.AddLog(LogEvent, tr("SABOTAGE: %s fired %s at your reactor!", attacker, ev.Name()))
+		rival.triggerEvent(ev, rival.rng.Intn(len(rival.Systems)))
+	})
+}
+
+// matchLine reports the match on the summary screen, or returns "" outside
+// a versus match.
+func (g *Game) matchLine() string {
+	if g.rival == nil {
+		return ""
+	}
+	me, rival := g.matchSide(), g.rival.matchSide()
+	if result := matchResult(me, rival); result != "" {
+		return theme.Accent.Sprint(tr("VERSUS: %s (%s %d, %s %d)", result, me.Name, me.Score, rival.Name, rival.Score))
+	}
+	return theme.Accent.Sprint(tr("VERSUS: %s is still at the controls with %d points.", rival.Name, rival.Score))
+}
+
+// versusPanel renders the rival's standing and the sabotage price list, or
+// "" outside a versus match.
+func (s GameState) versusPanel() string {
+	v := s.Versus
+	if v == nil {
+		return ""
+	}
+	standing := tr("score %d", v.RivalScore)
+	switch {
+	case v.RivalMeltedDown:
+		standing += theme.Alert.Sprint(tr(" MELTDOWN"))
+	case v.RivalEnded:
+		standing += " (finished)"
+	}
+	lines := []string{
+		fmt.Sprintf("  %s: %s", v.Rival, standing),
+		tr("  Sabotage points: %d, one per %d scored. Spend with 'sabotage %s <event>':", v.Points, SabotagePointScore, strings.ToLower(v.Rival)),
+	}
+	byCost := make(map[int][]string)
+	for name, cost := range sabotageCosts {
+		byCost[cost] = append(byCost[cost], name)
+	}
+	var costs []int
+	for cost := range byCost {
+		costs = append(costs, cost)
+	}
+	sort.Ints(costs)
+	for _, cost := range costs {
+		sort.Strings(byCost[cost])
+		line := fmt.Sprintf("    %d: %s", cost, strings.Join(byCost[cost], ", "))
+		if cost > v.Points {
+			line = theme.Dim.Sprintf(line)
+		}
+		lines = append(lines, line)
+	}
+	if v.Result != "" {
+		lines = append(lines, theme.Accent.Sprintf("  %s", v.Result))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// Status views. `view` picks how the status table draws the systems: bars,
+// one line each with a bar and a sparkline; compact, one short line each for
+// tiny terminals; or gauges, tall columns side by side for wide ones, with
+// the warning and critical thresholds marked. The view is the operator's
+// own, like the schematic, which it closes.
+
+const (
+	ViewBars    = "bars"
+	ViewCompact = "compact"
+	ViewGauges  = "gauges"
+
+	GaugeHeight = 10 // Rows of a gauge
+	gaugeAxis   = 5  // Columns of the percent scale left of the gauges
+	gaugeWidth  = 8  // Columns of a gauge, at most
+	gaugeMin    = 4  // Columns of a gauge, at least; narrower falls back to bars
+	compactName = 10 // Columns of a system's name in the compact view
+)
+
+var viewNames = []string{ViewCompact, ViewBars, ViewGauges}
+
+// handleView switches the current operator's status view, or logs the one
+// in use.
+func (g *Game) handleView(args []string) {
+	if len(args) == 0 {
+		g.mu.Lock()
+		view := g.view
+		g.mu.Unlock()
+		if view == "" {
+			view = ViewBars
+		}
+		g.AddLog(LogInfo, tr("VIEW: %s. Usage: view %s", view, strings.Join(viewNames, "|")))
+		return
+	}
+	view := strings.ToLower(args[0])
+	if !slices.Contains(viewNames, view) {
+		g.AddLog(LogInfo, tr("Usage: view %s", strings.Join(viewNames, "|")))
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.view, g.schematicOpen = view, false
+}
+
+// drawStatus draws the systems in the view picked, to fit col columns.
+func (s GameState) drawStatus(w io.Writer, col int) {
+	switch s.View {
+	case ViewCompact:
+		s.drawCompact(w)
+	case ViewGauges:
+		if n := len(s.Systems); n > 0 && gaugeAxis+n*gaugeMin <= col {
+			s.drawGauges(w, min(gaugeWidth, (col-gaugeAxis)/n))
+			return
+		}
+		s.drawBars(w, col)
+	default:
+		s.drawBars(w, col)
+	}
+}
+
+// drawBars draws a line per system: its reading, a bar, a sparkline of its
+// latest readings when there is room, and what is wrong with it.
+func (s GameState) drawBars(w io.Writer, col int) {
+	tags, tagged := make([]string, len(s.Systems)), 0
+	for i, sys := range s.Systems {
+		cascadeTag := ""
+		if sys.Offline {
+			cascadeTag = theme.Alert.Sprint(tr(" << REBOOT REQUIRED"))
+		} else if sys.SensorFault {
+			cascadeTag = theme.Highlight.Sprint(tr(" << SENSOR NOISE"))
+		} else if sys.Reading > sys.MaxValue {
+			cascadeTag = theme.Info.Sprint(tr(" << OVERCHARGED"))
+		} else if sys.Cascading {
+			cascadeTag = theme.Alert.Sprint(tr(" << CASCADE"))
+		}
+		if sys.Thermal && !sys.Offline {
+			cascadeTag += theme.Dim.Sprint(tr(" (thermal %+.1f/tick)", sys.ThermalRate))
+		}
+		tags[i], tagged = cascadeTag, max(tagged, visibleLen(cascadeTag))
+	}
+	for i, sys := range s.Systems {
+		barWidth, sparkWidth := statusWidths(col, len(theme.tag("")), tagged, visibleLen(tags[i]))
+		bar := sys.renderBar(barWidth)
+		spark := ""
+		if sparkWidth > 0 {
+			spark = " " + theme.Dim.Sprint(sparkline(sys.Trend, sys.MaxValue, sparkWidth))
+		}
+		label := s.spotlightSystem(sys.ID, fmt.Sprintf("[%d] %-18s", sys.ID, tr(sys.Name)))
+		fmt.Fprintf(w, "%s: %s%s %s%s%s\n", label, sys.healthTag(), sys.readingText(), bar, spark, tags[i])
+	}
+}
+
+// drawCompact draws a short line per system: its reading and, in a word,
+// what is wrong with it.
+func (s GameState) drawCompact(w io.Writer) {
+	for _, sys := range s.Systems {
+		label := s.spotlightSystem(sys.ID, fmt.Sprintf("[%d] %-*s", sys.ID, compactName, shorten(tr(sys.Name), compactName)))
+		tag := sys.shortTag()
+		if tag != "" {
+			tag = " " + tag
+		}
+		fmt.Fprintf(w, "%s %s%s%s\n", label, sys.healthTag(), sys.readingText(), tag)
+	}
+}
+
+// drawGauges draws a column of width per system, filled to its reading, on
+// a percent scale. An empty cell at a system's warning or critical threshold
+// is marked in the color of that zone, and a + tops an overcharged system.
+func (s GameState) drawGauges(w io.Writer, width int) {
+	full, empty, mark := "█", "·", "-"
+	if theme.ASCII {
+		full, empty = "#", "."
+	}
+	glyphs := max(1, width-3)
+	cell := func(style Style, glyph string) string {
+		return " " + style.Sprint(strings.Repeat(glyph, glyphs)) + strings.Repeat(" ", width-1-glyphs)
+	}
+	level := func(value, maxValue int) int {
+		if maxValue <= 0 {
+			return 0
+		}
+		return value * GaugeHeight / maxValue
+	}
+	var line strings.Builder
+	overcharged := false
+	line.WriteString(strings.Repeat(" ", gaugeAxis))
+	for _, sys := range s.Systems {
+		if !sys.Offline && sys.Reading > sys.MaxValue {
+			line.WriteString(cell(theme.Info, "+"))
+			overcharged = true
+		} else {
+			line.WriteString(strings.Repeat(" ", width))
+		}
+	}
+	if overcharged {
+		fmt.Fprintln(w, line.String())
+	}
+	for row := GaugeHeight; row >= 1; row-- {
+		line.Reset()
+		axis := "    "
+		if row == GaugeHeight || row == GaugeHeight/2 {
+			axis = fmt.Sprintf("%3d%%", row*100/GaugeHeight)
+		}
+		line.WriteString(theme.Dim.Sprint(axis + "|"))
+		for _, sys := range s.Systems {
+			switch {
+			case !sys.Offline && level(sys.Reading, sys.MaxValue) >= row:
+				line.WriteString(cell(s.schematicStyle(&sys), full))
+			case level(sys.Critical, sys.MaxValue) == row:
+				line.WriteString(cell(theme.Critical, mark))
+			case level(sys.Warning, sys.MaxValue) == row:
+				line.WriteString(cell(theme.Warning, mark))
+			default:
+				line.WriteString(cell(theme.Dim, empty))
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+	fmt.Fprintln(w, theme.Dim.Sprint(fmt.Sprintf("%3d%%+", 0)+strings.Repeat("-", width*len(s.Systems))))
+	rows := [3]strings.Builder{}
+	for _, sys := range s.Systems {
+		reading := fmt.Sprint(sys.Reading)
+		if sys.Offline {
+			reading = tr("OFF")
+		}
+		cells := [3]string{
+			s.spotlightSystem(sys.ID, center(fmt.Sprintf("[%d]", sys.ID), width)),
+			center(shorten(tr(sys.Name), width-1), width),
+			s.schematicStyle(&sys).Sprint(center(reading, width)),
+		}
+		for i, c := range cells {
+			rows[i].WriteString(c)
+		}
+	}
+	for i := range rows {
+		fmt.Fprintln(w, strings.Repeat(" ", gaugeAxis)+rows[i].String())
+	}
+	var tags []string
+	for _, sys := range s.Systems {
+		if tag := sys.shortTag(); tag != "" {
+			tags = append(tags, fmt.Sprintf("[%d] %s", sys.ID, tag))
+		}
+	}
+	if len(tags) > 0 {
+		fmt.Fprintln(w, strings.Repeat(" ", gaugeAxis)+strings.Join(tags, "  "))
+	}
+}
+
+// readingText renders the reading of sys out of its maximum, in the color
+// of its zone, or OFFLINE.
+func (sys SystemState) readingText() string {
+	switch {
+	case sys.Offline:
+		return theme.Inverse.Sprint(tr("OFFLINE"))
+	case sys.Reading <= sys.Critical:
+		return theme.Critical.With(color.Bold).Sprintf("%3d/%3d", sys.Reading, sys.MaxValue)
+	case sys.Reading <= sys.Warning:
+		return theme.Warning.Sprintf("%3d/%3d", sys.Reading, sys.MaxValue)
+	}
+	return theme.OK.Sprintf("%3d/%3d", sys.Reading, sys.MaxValue)
+}
+
+// shortTag names in a word what is wrong with sys, or "".
+func (sys SystemState) shortTag() string {
+	switch {
+	case sys.Offline:
+		return theme.Alert.Sprint(tr("REBOOT"))
+	case sys.SensorFault:
+		return theme.Highlight.Sprint(tr("NOISE"))
+	case sys.Reading > sys.MaxValue:
+		return theme.Info.Sprint(tr("OVER"))
+	case sys.Cascading:
+		return theme.Alert.Sprint(tr("CASCADE"))
+	}
+	return ""
+}
+
+// shorten cuts text to width runes.
+func shorten(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	return string([]rune(text)[:max(0, width)])
+}
+
+// center pads text to width runes, as evenly as it can on either side.
+func center(text string, width int) string {
+	gap := max(0, width-utf8.RuneCountInString(text))
+	return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+}
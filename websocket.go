@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal server side of the WebSocket protocol (RFC 6455), enough to push
+// text messages to spectators and take their chat: no extensions and no
+// fragmented messages either way. A short text message from the client goes
+// to the handler the connection was upgraded with; anything else it sends
+// apart from control frames is ignored. A handshake a browser makes from a
+// page served by another host is refused, so a site the spectator visits
+// cannot watch or chat in their name.
+
+const (
+	wsGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // Fixed by the RFC for the accept key
+	wsWriteTimeout = 5 * time.Second                        // A spectator this far behind is dropped
+	wsMaxControl   = 125                                    // Largest control frame payload
+	wsMaxText      = 4096                                   // Largest text message taken from the client
+
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+)
+
+// wsConn is an upgraded WebSocket connection. Writes are safe from several
+// goroutines; Closed is closed once the client has gone.
+type wsConn struct {
+	conn   net.Conn
+	rw     *bufio.ReadWriter
+	mu     sync.Mutex // Serializes frame writes
+	closed chan struct{}
+	once   sync.Once
+	onText func(msg []byte) (reply []byte) // Handles the client's text messages; nil to ignore them
+}
+
+// upgradeWebSocket answers a WebSocket handshake and takes over the
+// connection. onText, if not nil, is called with every text message the
+// client sends, from one goroutine, and a reply it returns is sent back. On
+// error a response has already been written.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, onText func(msg []byte) (reply []byte)) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerHas(r.Header, "Connection", "upgrade") || !headerHas(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, errors.New("not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin WebSocket handshake refused", http.StatusForbidden)
+		return nil, errors.New("cross-origin websocket handshake")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return nil, errors.New("response writer cannot be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &wsConn{conn: conn, rw: rw, closed: make(chan struct{}), onText: onText}
+	go c.readLoop()
+	return c, nil
+}
+
+// sameOrigin reports whether r's Origin header, if it has one, names the host
+// r was sent to. Clients other than browsers send none, and are let through.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
+// headerHas reports whether the comma-separated header name lists token.
+func headerHas(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteText sends one text message.
+func (c *wsConn) WriteText(data []byte) error {
+	return c.writeFrame(wsOpText, data)
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	header := []byte{0x80 | op} // FIN: every message is a single frame
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readLoop answers pings and closes, hands text messages to onText, and
+// discards everything else, until the client goes away.
+func (c *wsConn) readLoop() {
+	defer c.Close()
+	for {
+		var head [2]byte
+		if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+			return
+		}
+		fin, op := head[0]&0x80 != 0, head[0]&0x0f
+		masked := head[1]&0x80 != 0
+		n := uint64(head[1] & 0x7f)
+		switch n {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return
+			}
+			n = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return
+			}
+			n = binary.BigEndian.Uint64(ext[:])
+		}
+		var mask [4]byte
+		if masked {
+			if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+				return
+			}
+		}
+		text := op == wsOpText && fin && n <= wsMaxText && c.onText != nil
+		if op < wsOpClose && !text { // Binary, fragmented or oversized: nothing the spectator page sends
+			if _, err := io.CopyN(io.Discard, c.rw, int64(n)); err != nil {
+				return
+			}
+			continue
+		}
+		if op >= wsOpClose && n > wsMaxControl {
+			return // Protocol error
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.rw, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+		switch op {
+		case wsOpText:
+			if reply := c.onText(payload); reply != nil && c.writeFrame(wsOpText, reply) != nil {
+				return
+			}
+		case wsOpClose:
+			c.writeFrame(wsOpClose, payload) // Echo the status code back
+			return
+		case wsOpPing:
+			if c.writeFrame(wsOpPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+// Closed is closed once the connection has ended.
+func (c *wsConn) Closed() <-chan struct{} { return c.closed }
+
+// Close drops the connection.
+func (c *wsConn) Close() {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
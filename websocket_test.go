@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientFrame encodes a frame the way a browser sends one: masked, with the
+// payload length in the shortest form.
+func clientFrame(fin bool, op byte, payload []byte) []byte {
+	first := op
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	mask := [4]byte{0x37, 0xfa, 0x21, 0x3d}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+type wsFrame struct {
+	op      byte
+	payload string
+}
+
+// readServerFrame reads one unmasked frame the server sent.
+func readServerFrame(r io.Reader) (wsFrame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return wsFrame{}, err
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return wsFrame{}, err
+		}
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return wsFrame{}, err
+	}
+	return wsFrame{head[0] & 0x0f, string(payload)}, nil
+}
+
+func TestWebSocketFrames(t *testing.T) {
+	long := strings.Repeat("x", 300) // Takes the 16-bit length
+	for _, tc := range []struct {
+		name   string
+		send   [][]byte
+		want   []wsFrame
+		closes bool // The server ends the connection
+	}{
+		{
+			name: "text",
+			send: [][]byte{clientFrame(true, wsOpText, []byte("hello"))},
+			want: []wsFrame{{wsOpText, "echo hello"}},
+		},
+		{
+			name: "extended length",
+			send: [][]byte{clientFrame(true, wsOpText, []byte(long))},
+			want: []wsFrame{{wsOpText, "echo " + long}},
+		},
+		{
+			name: "ping",
+			send: [][]byte{clientFrame(true, wsOpPing, []byte("are you there"))},
+			want: []wsFrame{{wsOpPong, "are you there"}},
+		},
+		{
+			name: "binary ignored",
+			send: [][]byte{clientFrame(true, 0x2, []byte{1, 2, 3}), clientFrame(true, wsOpText, []byte("after"))},
+			want: []wsFrame{{wsOpText, "echo after"}},
+		},
+		{
+			name: "fragment ignored",
+			send: [][]byte{clientFrame(false, wsOpText, []byte("part")), clientFrame(true, wsOpText, []byte("whole"))},
+			want: []wsFrame{{wsOpText, "echo whole"}},
+		},
+		{
+			name: "oversized text ignored",
+			send: [][]byte{clientFrame(true, wsOpText, bytes.Repeat([]byte("y"), wsMaxText+1)), clientFrame(true, wsOpText, []byte("short"))},
+			want: []wsFrame{{wsOpText, "echo short"}},
+		},
+		{
+			name:   "close echoed",
+			send:   [][]byte{clientFrame(true, wsOpClose, []byte{0x03, 0xe8})},
+			want:   []wsFrame{{wsOpClose, "\x03\xe8"}},
+			closes: true,
+		},
+		{
+			name:   "oversized control",
+			send:   [][]byte{clientFrame(true, wsOpPing, bytes.Repeat([]byte("z"), wsMaxControl+1))},
+			closes: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer client.Close()
+			c := &wsConn{
+				conn:   server,
+				rw:     bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)),
+				closed: make(chan struct{}),
+				onText: func(msg []byte) []byte { return append([]byte("echo "), msg...) },
+			}
+			go c.readLoop()
+			defer c.Close()
+			client.SetDeadline(time.Now().Add(5 * time.Second))
+
+			send := tc.send
+			if !tc.closes {
+				send = append(send, clientFrame(true, wsOpPing, []byte("done"))) // Shows everything before it was read
+			}
+			go func() {
+				for _, frame := range send {
+					if _, err := client.Write(frame); err != nil {
+						return
+					}
+				}
+			}()
+			want := tc.want
+			if !tc.closes {
+				want = append(want, wsFrame{wsOpPong, "done"})
+			}
+			for _, w := range want {
+				got, err := readServerFrame(client)
+				if err != nil {
+					t.Fatalf("reading %q: %v", w.payload, err)
+				}
+				if got != w {
+					t.Fatalf("got frame %#x %q, want %#x %q", got.op, got.payload, w.op, w.payload)
+				}
+			}
+			if tc.closes {
+				select {
+				case <-c.Closed():
+				case <-time.After(5 * time.Second):
+					t.Fatal("the connection is still open")
+				}
+			}
+		})
+	}
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		header map[string]string
+		status int
+	}{
+		{"not an upgrade", map[string]string{"Sec-WebSocket-Version": "13"}, 400},
+		{"old version", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ==", "Sec-WebSocket-Version": "8"}, 426},
+		{"other origin", map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ==", "Sec-WebSocket-Version": "13", "Origin": "http://evil.example"}, 403},
+	} {
+		r := httptest.NewRequest("GET", "http://localhost:8080/watch", nil)
+		for name, value := range tc.header {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		if _, err := upgradeWebSocket(w, r, nil); err == nil {
+			t.Errorf("%s: upgraded", tc.name)
+		}
+		if w.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.status)
+		}
+	}
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgradeWebSocket(w, r, nil)
+		if err != nil {
+			return
+		}
+		c.WriteText([]byte("welcome"))
+		<-c.Closed()
+	}))
+	defer srv.Close()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	host := srv.Listener.Addr().String()
+	io.WriteString(conn, "GET /watch HTTP/1.1\r\nHost: "+host+"\r\nOrigin: http://"+host+"\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" { // The RFC's own example
+		t.Errorf("accept key %q", got)
+	}
+	if f, err := readServerFrame(r); err != nil || f != (wsFrame{wsOpText, "welcome"}) {
+		t.Errorf("first frame %#x %q, %v", f.op, f.payload, err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package main
+
+// notifyResize does nothing where there is no SIGWINCH: the dashboard picks
+// up a new size on its next refresh.
+func notifyResize(resized chan<- struct{}) {}
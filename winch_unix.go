@@ -0,0 +1,23 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize sends on resized whenever the terminal changes size.
+func notifyResize(resized chan<- struct{}) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	go func() {
+		for range winch {
+			select {
+			case resized <- struct{}{}:
+			default: // A redraw is already due
+			}
+		}
+	}()
+}